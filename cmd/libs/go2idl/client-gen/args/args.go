@@ -45,6 +45,10 @@ type Args struct {
 	ClientsetOnly bool
 	// FakeClient determines if client-gen generates the fake clients.
 	FakeClient bool
+	// DiscoveryFallback determines if the generated NewForConfig probes the
+	// server's discovery information and skips constructing clients for
+	// groups the server does not expose.
+	DiscoveryFallback bool
 	// CmdArgs is the command line arguments supplied when the client-gen is called.
 	CmdArgs string
 }
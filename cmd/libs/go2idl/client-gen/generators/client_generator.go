@@ -29,6 +29,7 @@ import (
 	clientgenargs "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/args"
 	"k8s.io/kubernetes/cmd/libs/go2idl/client-gen/generators/fake"
 	"k8s.io/kubernetes/cmd/libs/go2idl/client-gen/generators/normalization"
+	"k8s.io/kubernetes/pkg/api/meta"
 	"k8s.io/kubernetes/pkg/api/unversioned"
 
 	"github.com/golang/glog"
@@ -36,9 +37,9 @@ import (
 
 // NameSystems returns the name system used by the generators in this package.
 func NameSystems() namer.NameSystems {
-	pluralExceptions := map[string]string{
-		"Endpoints": "Endpoints",
-	}
+	// Shared with the RESTMapper, so a single meta.RegisterPluralException
+	// call covers both the generated clients and KindToResource.
+	pluralExceptions := meta.PluralExceptions()
 	return namer.NameSystems{
 		"public":             namer.NewPublicNamer(0),
 		"private":            namer.NewPrivateNamer(0),
@@ -142,10 +143,11 @@ func packageForClientset(customArgs clientgenargs.Args, typedClientBasePath stri
 					DefaultGen: generator.DefaultGen{
 						OptionalName: "clientset",
 					},
-					groupVersions:   customArgs.GroupVersions,
-					typedClientPath: typedClientBasePath,
-					outputPackage:   customArgs.ClientsetName,
-					imports:         generator.NewImportTracker(),
+					groupVersions:     customArgs.GroupVersions,
+					typedClientPath:   typedClientBasePath,
+					outputPackage:     customArgs.ClientsetName,
+					discoveryFallback: customArgs.DiscoveryFallback,
+					imports:           generator.NewImportTracker(),
 				},
 			}
 			return generators
@@ -74,6 +74,22 @@ func hasObjectMeta(t *types.Type) bool {
 	return false
 }
 
+// hasStatusPhase returns true if the type's status declares a Phase member,
+// as pods, namespaces and persistent volumes do. Such types support the
+// "status.phase" field selector on the real server.
+func hasStatusPhase(t *types.Type) bool {
+	for _, m := range t.Members {
+		if m.Name == "Status" && strings.Contains(m.Tags, `json:"status`) {
+			for _, sm := range m.Type.Members {
+				if sm.Name == "Phase" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // GenerateType makes the body of a file implementing the individual typed client for type t.
 func (g *genFakeForType) GenerateType(c *generator.Context, t *types.Type, w io.Writer) error {
 	sw := generator.NewSnippetWriter(w, c, "$", "$")
@@ -105,6 +121,7 @@ func (g *genFakeForType) GenerateType(c *generator.Context, t *types.Type, w io.
 		"package":              pkg,
 		"Package":              namer.IC(pkg),
 		"namespaced":           namespaced,
+		"hasStatusPhase":       hasStatusPhase(t),
 		"Group":                namer.IC(g.group),
 		"group":                canonicalGroup,
 		"groupName":            groupName,
@@ -115,6 +132,9 @@ func (g *genFakeForType) GenerateType(c *generator.Context, t *types.Type, w io.
 		"GroupVersionResource": c.Universe.Type(types.Name{Package: "k8s.io/kubernetes/pkg/api/unversioned", Name: "GroupVersionResource"}),
 		"PatchType":            c.Universe.Type(types.Name{Package: "k8s.io/kubernetes/pkg/api", Name: "PatchType"}),
 		"Everything":           c.Universe.Function(types.Name{Package: "k8s.io/kubernetes/pkg/labels", Name: "Everything"}),
+		"FieldsEverything":     c.Universe.Function(types.Name{Package: "k8s.io/kubernetes/pkg/fields", Name: "Everything"}),
+		"FieldsSet":            c.Universe.Type(types.Name{Package: "k8s.io/kubernetes/pkg/fields", Name: "Set"}),
+		"Errorf":               c.Universe.Function(types.Name{Package: "fmt", Name: "Errorf"}),
 
 		"NewRootListAction":              c.Universe.Function(types.Name{Package: pkgTestingCore, Name: "NewRootListAction"}),
 		"NewListAction":                  c.Universe.Function(types.Name{Package: pkgTestingCore, Name: "NewListAction"}),
@@ -158,6 +178,7 @@ func (g *genFakeForType) GenerateType(c *generator.Context, t *types.Type, w io.
 		sw.Do(deleteCollectionTemplate, m)
 		sw.Do(getTemplate, m)
 		if hasObjectMeta(t) {
+			sw.Do(fieldsSetTemplate, m)
 			sw.Do(listUsingOptionsTemplate, m)
 		} else {
 			sw.Do(listTemplate, m)
@@ -202,6 +223,18 @@ func (c *Fake$.type|publicPlural$) List(opts $.apiListOptions|raw$) (result *$.t
 }
 `
 
+var fieldsSetTemplate = `
+// $.type|private$Fields returns the field set the fake server matches field
+// selectors against, mirroring the selectable fields of the real server.
+func $.type|private$Fields(obj *$.type|raw$) $.FieldsSet|raw$ {
+	return $.FieldsSet|raw${
+		"metadata.name": obj.Name,
+		$if .namespaced$"metadata.namespace": obj.Namespace,
+		$end$$if .hasStatusPhase$"status.phase": string(obj.Status.Phase),
+		$end$}
+}
+`
+
 var listUsingOptionsTemplate = `
 func (c *Fake$.type|publicPlural$) List(opts $.apiListOptions|raw$) (result *$.type|raw$List, err error) {
 	obj, err := c.Fake.
@@ -215,9 +248,19 @@ func (c *Fake$.type|publicPlural$) List(opts $.apiListOptions|raw$) (result *$.t
 	if label == nil {
 		label = $.Everything|raw$()
 	}
+	field := opts.FieldSelector
+	if field == nil {
+		field = $.FieldsEverything|raw$()
+	}
+	supportedFields := $.type|private$Fields(&$.type|raw${})
+	for _, req := range field.Requirements() {
+		if _, ok := supportedFields[req.Field]; !ok {
+			return nil, $.Errorf|raw$("field label not supported: %s", req.Field)
+		}
+	}
 	list := &$.type|raw$List{}
 	for _, item := range obj.(*$.type|raw$List).Items {
-		if label.Matches(labels.Set(item.Labels)) {
+		if label.Matches(labels.Set(item.Labels)) && field.Matches($.type|private$Fields(&item)) {
 			list.Items = append(list.Items, item)
 		}
 	}
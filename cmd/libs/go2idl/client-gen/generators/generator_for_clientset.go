@@ -34,6 +34,7 @@ type genClientset struct {
 	groupVersions      []unversioned.GroupVersion
 	typedClientPath    string
 	outputPackage      string
+	discoveryFallback  bool
 	imports            namer.ImportTracker
 	clientsetGenerated bool
 }
@@ -62,6 +63,9 @@ func (g *genClientset) Imports(c *generator.Context) (imports []string) {
 		group = normalization.BeforeFirstDot(group)
 		imports = append(imports, fmt.Sprintf("%s%s \"%s\"", version, group, typedClientPath))
 	}
+	if g.discoveryFallback {
+		imports = append(imports, "fmt")
+	}
 	imports = append(imports, "github.com/golang/glog")
 	imports = append(imports, "k8s.io/kubernetes/pkg/util/flowcontrol")
 	// import solely to initialize client auth plugins.
@@ -78,6 +82,7 @@ func (g *genClientset) GenerateType(c *generator.Context, t *types.Type, w io.Wr
 
 	type arg struct {
 		Group       string
+		GroupName   string
 		PackageName string
 	}
 
@@ -85,7 +90,12 @@ func (g *genClientset) GenerateType(c *generator.Context, t *types.Type, w io.Wr
 	for _, gv := range g.groupVersions {
 		group := normalization.BeforeFirstDot(normalization.Group(gv.Group))
 		version := normalization.Version(gv.Version)
-		allGroups = append(allGroups, arg{namer.IC(group), version + group})
+		groupName := gv.Group
+		if groupName == "api" {
+			// The legacy core group has the empty name in discovery.
+			groupName = ""
+		}
+		allGroups = append(allGroups, arg{Group: namer.IC(group), GroupName: groupName, PackageName: version + group})
 	}
 
 	m := map[string]interface{}{
@@ -101,11 +111,23 @@ func (g *genClientset) GenerateType(c *generator.Context, t *types.Type, w io.Wr
 	}
 	sw.Do(clientsetInterfaceTemplate, m)
 	sw.Do(clientsetTemplate, m)
-	for _, g := range allGroups {
-		sw.Do(clientsetInterfaceImplTemplate, g)
+	for _, group := range allGroups {
+		sw.Do(clientsetInterfaceImplTemplate, group)
+	}
+	if g.discoveryFallback {
+		sw.Do(groupNotAvailableErrorTemplate, m)
+		for _, group := range allGroups {
+			sw.Do(clientsetGroupOrErrorTemplate, group)
+		}
 	}
 	sw.Do(getDiscoveryTemplate, m)
-	sw.Do(newClientsetForConfigTemplate, m)
+	sw.Do(setUserAgentTemplate, m)
+	if g.discoveryFallback {
+		sw.Do(discoveredGroupsTemplate, m)
+		sw.Do(newClientsetForConfigFallbackTemplate, m)
+	} else {
+		sw.Do(newClientsetForConfigTemplate, m)
+	}
 	sw.Do(newClientsetForConfigOrDieTemplate, m)
 	sw.Do(newClientsetForRESTClientTemplate, m)
 
@@ -146,8 +168,21 @@ func (c *Clientset) Discovery() $.DiscoveryInterface|raw$ {
 }
 `
 
+var setUserAgentTemplate = `
+// SetUserAgent returns a shallow copy of the config with the given user
+// agent set. All group clients created from the returned config will send
+// the user agent.
+func SetUserAgent(c *$.Config|raw$, userAgent string) *$.Config|raw$ {
+	configShallowCopy := *c
+	configShallowCopy.UserAgent = userAgent
+	return &configShallowCopy
+}
+`
+
 var newClientsetForConfigTemplate = `
-// NewForConfig creates a new Clientset for the given config.
+// NewForConfig creates a new Clientset for the given config. The config is
+// copied once per group, so the per-group settings (e.g. QPS, Burst or
+// UserAgent) of one group client can be changed without affecting the others.
 func NewForConfig(c *$.Config|raw$) (*Clientset, error) {
 	configShallowCopy := *c
 	if configShallowCopy.RateLimiter == nil && configShallowCopy.QPS > 0 {
@@ -155,7 +190,8 @@ func NewForConfig(c *$.Config|raw$) (*Clientset, error) {
 	}
 	var clientset Clientset
 	var err error
-$range .allGroups$    clientset.$.Group$Client, err =$.PackageName$.NewForConfig(&configShallowCopy)
+$range .allGroups$    $.PackageName$Config := configShallowCopy
+	clientset.$.Group$Client, err =$.PackageName$.NewForConfig(&$.PackageName$Config)
 	if err!=nil {
 		return nil, err
 	}
@@ -169,6 +205,81 @@ $end$
 }
 `
 
+var groupNotAvailableErrorTemplate = `
+// GroupNotAvailableError is returned by the group getters when NewForConfig
+// skipped the group's client because the server does not expose the group.
+type GroupNotAvailableError struct {
+	Group string
+}
+
+func (e *GroupNotAvailableError) Error() string {
+	return fmt.Sprintf("the server does not expose the group %q", e.Group)
+}
+`
+
+var clientsetGroupOrErrorTemplate = `
+// $.Group$OrError retrieves the $.Group$Client, or a GroupNotAvailableError
+// if NewForConfig skipped the client because the server does not expose the
+// group.
+func (c *Clientset) $.Group$OrError() ($.PackageName$.$.Group$Interface, error) {
+	if c == nil || c.$.Group$Client == nil {
+		return nil, &GroupNotAvailableError{Group: "$.GroupName$"}
+	}
+	return c.$.Group$Client, nil
+}
+`
+
+var discoveredGroupsTemplate = `
+// discoveredGroups returns the names of the groups the server exposes, or nil
+// when discovery is unavailable, in which case NewForConfig constructs every
+// group client strictly.
+func discoveredGroups(d $.DiscoveryInterface|raw$) map[string]bool {
+	groupList, err := d.ServerGroups()
+	if err != nil {
+		glog.V(2).Infof("failed to discover the server's groups, constructing all group clients: %v", err)
+		return nil
+	}
+	groups := make(map[string]bool)
+	for i := range groupList.Groups {
+		groups[groupList.Groups[i].Name] = true
+	}
+	return groups
+}
+`
+
+var newClientsetForConfigFallbackTemplate = `
+// NewForConfig creates a new Clientset for the given config. The config is
+// copied once per group, so the per-group settings (e.g. QPS, Burst or
+// UserAgent) of one group client can be changed without affecting the others.
+// Groups the server's discovery information does not list are skipped instead
+// of failing construction; their getters return a GroupNotAvailableError.
+func NewForConfig(c *$.Config|raw$) (*Clientset, error) {
+	configShallowCopy := *c
+	if configShallowCopy.RateLimiter == nil && configShallowCopy.QPS > 0 {
+		configShallowCopy.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(configShallowCopy.QPS, configShallowCopy.Burst)
+	}
+	var clientset Clientset
+	var err error
+	clientset.DiscoveryClient, err = $.NewDiscoveryClientForConfig|raw$(&configShallowCopy)
+	if err!=nil {
+		glog.Errorf("failed to create the DiscoveryClient: %v", err)
+		return nil, err
+	}
+	serverGroups := discoveredGroups(clientset.DiscoveryClient)
+$range .allGroups$    if serverGroups == nil || serverGroups["$.GroupName$"] {
+		$.PackageName$Config := configShallowCopy
+		clientset.$.Group$Client, err =$.PackageName$.NewForConfig(&$.PackageName$Config)
+		if err!=nil {
+			return nil, err
+		}
+	} else {
+		glog.V(2).Infof("the server does not expose the group %q, skipping its client", "$.GroupName$")
+	}
+$end$
+	return &clientset, nil
+}
+`
+
 var newClientsetForConfigOrDieTemplate = `
 // NewForConfigOrDie creates a new Clientset for the given config and
 // panics if there is an error in the config.
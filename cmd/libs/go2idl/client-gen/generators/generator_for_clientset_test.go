@@ -0,0 +1,52 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewForConfigEmitsPerGroupConfigCopies(t *testing.T) {
+	// Every group client must be created from its own copy of the config so
+	// that per-group mutations do not leak into the other groups.
+	if !strings.Contains(newClientsetForConfigTemplate, "$.PackageName$Config := configShallowCopy") {
+		t.Errorf("NewForConfig template does not copy the config per group:\n%s", newClientsetForConfigTemplate)
+	}
+	if !strings.Contains(newClientsetForConfigTemplate, "NewForConfig(&$.PackageName$Config)") {
+		t.Errorf("NewForConfig template does not pass the per-group config copy:\n%s", newClientsetForConfigTemplate)
+	}
+}
+
+func TestSetUserAgentTemplateCopiesConfig(t *testing.T) {
+	if !strings.Contains(setUserAgentTemplate, "configShallowCopy := *c") {
+		t.Errorf("SetUserAgent template must not mutate the caller's config:\n%s", setUserAgentTemplate)
+	}
+}
+
+func TestDiscoveryFallbackTemplateProbesGroups(t *testing.T) {
+	if !strings.Contains(newClientsetForConfigFallbackTemplate, "serverGroups := discoveredGroups(clientset.DiscoveryClient)") {
+		t.Errorf("fallback NewForConfig template does not probe discovery:\n%s", newClientsetForConfigFallbackTemplate)
+	}
+	if !strings.Contains(newClientsetForConfigFallbackTemplate, `serverGroups == nil || serverGroups["$.GroupName$"]`) {
+		t.Errorf("fallback NewForConfig template does not construct strictly when discovery is unavailable:\n%s", newClientsetForConfigFallbackTemplate)
+	}
+	// NewForConfigOrDie keeps strict behavior regardless of the option.
+	if strings.Contains(newClientsetForConfigOrDieTemplate, "discoveredGroups") {
+		t.Errorf("NewForConfigOrDie template must not probe discovery:\n%s", newClientsetForConfigOrDieTemplate)
+	}
+}
@@ -52,6 +52,7 @@ var (
 	clientsetPath          = flag.String("clientset-path", "k8s.io/kubernetes/pkg/client/clientset_generated/", "the generated clientset will be output to <clientset-path>/<clientset-name>. Default to \"k8s.io/kubernetes/pkg/client/clientset_generated/\"")
 	clientsetOnly          = flag.Bool("clientset-only", false, "when set, client-gen only generates the clientset shell, without generating the individual typed clients")
 	fakeClient             = flag.Bool("fake-clientset", true, "when set, client-gen will generate the fake clientset that can be used in tests")
+	discoveryFallback      = flag.Bool("discovery-fallback", false, "when set, the generated NewForConfig probes the server's discovery information and skips the clients of groups the server does not expose")
 )
 
 func versionToPath(gvPath string, group string, version string) (path string) {
@@ -166,6 +167,7 @@ func main() {
 			ClientsetOutputPath: "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/testoutput/clientset_generated/",
 			ClientsetOnly:       false,
 			FakeClient:          true,
+			DiscoveryFallback:   true,
 			CmdArgs:             cmdArgs,
 		}
 	} else {
@@ -187,6 +189,7 @@ func main() {
 			ClientsetOutputPath:     *clientsetPath,
 			ClientsetOnly:           *clientsetOnly,
 			FakeClient:              *fakeClient,
+			DiscoveryFallback:       *discoveryFallback,
 			CmdArgs:                 cmdArgs,
 			IncludedTypesOverrides:  includedTypesOverrides,
 		}
@@ -17,6 +17,8 @@ limitations under the License.
 package test_internalclientset
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 	unversionedtestgroup "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/testoutput/clientset_generated/test_internalclientset/typed/testgroup.k8s.io/unversioned"
 	restclient "k8s.io/kubernetes/pkg/client/restclient"
@@ -45,12 +47,61 @@ func (c *Clientset) Testgroup() unversionedtestgroup.TestgroupInterface {
 	return c.TestgroupClient
 }
 
+// GroupNotAvailableError is returned by the group getters when NewForConfig
+// skipped the group's client because the server does not expose the group.
+type GroupNotAvailableError struct {
+	Group string
+}
+
+func (e *GroupNotAvailableError) Error() string {
+	return fmt.Sprintf("the server does not expose the group %q", e.Group)
+}
+
+// TestgroupOrError retrieves the TestgroupClient, or a GroupNotAvailableError
+// if NewForConfig skipped the client because the server does not expose the
+// group.
+func (c *Clientset) TestgroupOrError() (unversionedtestgroup.TestgroupInterface, error) {
+	if c == nil || c.TestgroupClient == nil {
+		return nil, &GroupNotAvailableError{Group: "testgroup.k8s.io"}
+	}
+	return c.TestgroupClient, nil
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	return c.DiscoveryClient
 }
 
-// NewForConfig creates a new Clientset for the given config.
+// SetUserAgent returns a shallow copy of the config with the given user
+// agent set. All group clients created from the returned config will send
+// the user agent.
+func SetUserAgent(c *restclient.Config, userAgent string) *restclient.Config {
+	configShallowCopy := *c
+	configShallowCopy.UserAgent = userAgent
+	return &configShallowCopy
+}
+
+// discoveredGroups returns the names of the groups the server exposes, or nil
+// when discovery is unavailable, in which case NewForConfig constructs every
+// group client strictly.
+func discoveredGroups(d discovery.DiscoveryInterface) map[string]bool {
+	groupList, err := d.ServerGroups()
+	if err != nil {
+		glog.V(2).Infof("failed to discover the server's groups, constructing all group clients: %v", err)
+		return nil
+	}
+	groups := make(map[string]bool)
+	for i := range groupList.Groups {
+		groups[groupList.Groups[i].Name] = true
+	}
+	return groups
+}
+
+// NewForConfig creates a new Clientset for the given config. The config is
+// copied once per group, so the per-group settings (e.g. QPS, Burst or
+// UserAgent) of one group client can be changed without affecting the others.
+// Groups the server's discovery information does not list are skipped instead
+// of failing construction; their getters return a GroupNotAvailableError.
 func NewForConfig(c *restclient.Config) (*Clientset, error) {
 	configShallowCopy := *c
 	if configShallowCopy.RateLimiter == nil && configShallowCopy.QPS > 0 {
@@ -58,16 +109,22 @@ func NewForConfig(c *restclient.Config) (*Clientset, error) {
 	}
 	var clientset Clientset
 	var err error
-	clientset.TestgroupClient, err = unversionedtestgroup.NewForConfig(&configShallowCopy)
-	if err != nil {
-		return nil, err
-	}
-
 	clientset.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
 		glog.Errorf("failed to create the DiscoveryClient: %v", err)
 		return nil, err
 	}
+	serverGroups := discoveredGroups(clientset.DiscoveryClient)
+	if serverGroups == nil || serverGroups["testgroup.k8s.io"] {
+		unversionedtestgroupConfig := configShallowCopy
+		clientset.TestgroupClient, err = unversionedtestgroup.NewForConfig(&unversionedtestgroupConfig)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		glog.V(2).Infof("the server does not expose the group %q, skipping its client", "testgroup.k8s.io")
+	}
+
 	return &clientset, nil
 }
 
@@ -17,8 +17,13 @@ limitations under the License.
 package test_internalclientset
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	_ "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/test_apis/testgroup.k8s.io/install"
+	"k8s.io/kubernetes/pkg/api/unversioned"
 	restclient "k8s.io/kubernetes/pkg/client/restclient"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
 )
@@ -41,3 +46,75 @@ func ClientSetRateLimiterTest(t *testing.T) {
 		t.Errorf("Clients in client set should use rateLimiter passed in config:\noriginal: %v\ntestGroup: %v", rateLimiter, testGroupThrottler)
 	}
 }
+
+func TestSetUserAgent(t *testing.T) {
+	config := restclient.Config{}
+	copied := SetUserAgent(&config, "test-agent")
+	if copied.UserAgent != "test-agent" {
+		t.Errorf("expected user agent %q, got %q", "test-agent", copied.UserAgent)
+	}
+	if config.UserAgent != "" {
+		t.Errorf("the original config should not be mutated, got user agent %q", config.UserAgent)
+	}
+}
+
+// newDiscoveryTestServer serves just enough discovery information for
+// NewForConfig to decide which groups the server exposes.
+func newDiscoveryTestServer(t *testing.T, groups []unversioned.APIGroup) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var obj interface{}
+		switch req.URL.Path {
+		case "/api":
+			obj = &unversioned.APIVersions{}
+		case "/apis":
+			obj = &unversioned.APIGroupList{Groups: groups}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		output, err := json.Marshal(obj)
+		if err != nil {
+			t.Errorf("unexpected encoding error: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(output)
+	}))
+}
+
+func TestNewForConfigSkipsGroupsMissingFromDiscovery(t *testing.T) {
+	server := newDiscoveryTestServer(t, []unversioned.APIGroup{{Name: "othergroup"}})
+	defer server.Close()
+
+	clientSet, err := NewForConfig(&restclient.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clientSet.TestgroupClient != nil {
+		t.Errorf("expected the testgroup client to be skipped")
+	}
+	_, err = clientSet.TestgroupOrError()
+	if err == nil {
+		t.Fatalf("expected a GroupNotAvailableError")
+	}
+	if _, ok := err.(*GroupNotAvailableError); !ok {
+		t.Errorf("expected a GroupNotAvailableError, got %T: %v", err, err)
+	}
+}
+
+func TestNewForConfigConstructsDiscoveredGroups(t *testing.T) {
+	server := newDiscoveryTestServer(t, []unversioned.APIGroup{{Name: "testgroup.k8s.io"}})
+	defer server.Close()
+
+	clientSet, err := NewForConfig(&restclient.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clientSet.TestgroupClient == nil {
+		t.Fatalf("expected the testgroup client to be constructed")
+	}
+	if _, err := clientSet.TestgroupOrError(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
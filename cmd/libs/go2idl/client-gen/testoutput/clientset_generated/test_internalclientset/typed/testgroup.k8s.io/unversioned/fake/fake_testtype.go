@@ -17,10 +17,12 @@ limitations under the License.
 package fake
 
 import (
+	fmt "fmt"
 	testgroup_k8s_io "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/test_apis/testgroup.k8s.io"
 	api "k8s.io/kubernetes/pkg/api"
 	unversioned "k8s.io/kubernetes/pkg/api/unversioned"
 	core "k8s.io/kubernetes/pkg/client/testing/core"
+	fields "k8s.io/kubernetes/pkg/fields"
 	labels "k8s.io/kubernetes/pkg/labels"
 	watch "k8s.io/kubernetes/pkg/watch"
 )
@@ -87,6 +89,15 @@ func (c *FakeTestTypes) Get(name string) (result *testgroup_k8s_io.TestType, err
 	return obj.(*testgroup_k8s_io.TestType), err
 }
 
+// testTypeFields returns the field set the fake server matches field
+// selectors against, mirroring the selectable fields of the real server.
+func testTypeFields(obj *testgroup_k8s_io.TestType) fields.Set {
+	return fields.Set{
+		"metadata.name":      obj.Name,
+		"metadata.namespace": obj.Namespace,
+	}
+}
+
 func (c *FakeTestTypes) List(opts api.ListOptions) (result *testgroup_k8s_io.TestTypeList, err error) {
 	obj, err := c.Fake.
 		Invokes(core.NewListAction(testtypesResource, c.ns, opts), &testgroup_k8s_io.TestTypeList{})
@@ -99,9 +110,19 @@ func (c *FakeTestTypes) List(opts api.ListOptions) (result *testgroup_k8s_io.Tes
 	if label == nil {
 		label = labels.Everything()
 	}
+	field := opts.FieldSelector
+	if field == nil {
+		field = fields.Everything()
+	}
+	supportedFields := testTypeFields(&testgroup_k8s_io.TestType{})
+	for _, req := range field.Requirements() {
+		if _, ok := supportedFields[req.Field]; !ok {
+			return nil, fmt.Errorf("field label not supported: %s", req.Field)
+		}
+	}
 	list := &testgroup_k8s_io.TestTypeList{}
 	for _, item := range obj.(*testgroup_k8s_io.TestTypeList).Items {
-		if label.Matches(labels.Set(item.Labels)) {
+		if label.Matches(labels.Set(item.Labels)) && field.Matches(testTypeFields(&item)) {
 			list.Items = append(list.Items, item)
 		}
 	}
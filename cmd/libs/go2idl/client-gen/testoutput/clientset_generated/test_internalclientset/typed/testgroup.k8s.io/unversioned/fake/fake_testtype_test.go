@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake_test
+
+import (
+	"testing"
+
+	testgroup "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/test_apis/testgroup.k8s.io"
+	_ "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/test_apis/testgroup.k8s.io/install"
+	clientsetfake "k8s.io/kubernetes/cmd/libs/go2idl/client-gen/testoutput/clientset_generated/test_internalclientset/fake"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+func newTestType(namespace, name string, labels map[string]string) *testgroup.TestType {
+	return &testgroup.TestType{
+		ObjectMeta: api.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+	}
+}
+
+func TestListTestTypesWithLabelSelector(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset(
+		newTestType(api.NamespaceDefault, "foo", map[string]string{"app": "foo"}),
+		newTestType(api.NamespaceDefault, "bar", map[string]string{"app": "bar"}),
+	)
+	options := api.ListOptions{LabelSelector: labels.Set{"app": "foo"}.AsSelector()}
+	list, err := client.Testgroup().TestTypes(api.NamespaceDefault).List(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "foo" {
+		t.Errorf("expected only the labeled test type, got %#v", list.Items)
+	}
+}
+
+func TestListTestTypesWithFieldSelector(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset(
+		newTestType(api.NamespaceDefault, "foo", nil),
+		newTestType(api.NamespaceDefault, "bar", nil),
+	)
+	options := api.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", "bar")}
+	list, err := client.Testgroup().TestTypes(api.NamespaceDefault).List(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "bar" {
+		t.Errorf("expected only the test type selected by name, got %#v", list.Items)
+	}
+}
+
+func TestListTestTypesWithUnsupportedFieldSelector(t *testing.T) {
+	client := clientsetfake.NewSimpleClientset(newTestType(api.NamespaceDefault, "foo", nil))
+	options := api.ListOptions{FieldSelector: fields.OneTermEqualSelector("status.blah", "good")}
+	if _, err := client.Testgroup().TestTypes(api.NamespaceDefault).List(options); err == nil {
+		t.Errorf("expected an error for the unsupported field selector, got none")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	cache "k8s.io/kubernetes/pkg/client/cache"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/release_1_5"
 	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/fields"
 	pkg_runtime "k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/wait"
 	"k8s.io/kubernetes/pkg/util/workqueue"
@@ -56,6 +57,25 @@ type clusterCache struct {
 type clusterClientCache struct {
 	rwlock    sync.Mutex // protects serviceMap
 	clientMap map[string]*clusterCache
+	// A cache of the federated services, used to restrict the endpoint
+	// watches to namespaces that contain federated services.
+	serviceCache *serviceCache
+}
+
+// endpointListOptions restricts the endpoint list/watch to the namespaces
+// that contain federated services, when that set is small enough to express
+// as a field selector. A field selector can only match a single namespace,
+// so the restriction applies only when exactly one namespace has federated
+// services; otherwise all endpoints are watched as before.
+func (cc *clusterClientCache) endpointListOptions(options api.ListOptions) api.ListOptions {
+	if cc.serviceCache == nil || options.FieldSelector != nil {
+		return options
+	}
+	namespaces := cc.serviceCache.federatedNamespaces()
+	if namespaces.Len() == 1 {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.namespace", namespaces.List()[0])
+	}
+	return options
 }
 
 func (cc *clusterClientCache) startClusterLW(cluster *v1beta1.Cluster, clusterName string) {
@@ -93,10 +113,10 @@ func (cc *clusterClientCache) startClusterLW(cluster *v1beta1.Cluster, clusterNa
 		cachedClusterClient.endpointStore.Store, cachedClusterClient.endpointController = cache.NewInformer(
 			&cache.ListWatch{
 				ListFunc: func(options api.ListOptions) (pkg_runtime.Object, error) {
-					return clientset.Core().Endpoints(v1.NamespaceAll).List(options)
+					return clientset.Core().Endpoints(v1.NamespaceAll).List(cc.endpointListOptions(options))
 				},
 				WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
-					return clientset.Core().Endpoints(v1.NamespaceAll).Watch(options)
+					return clientset.Core().Endpoints(v1.NamespaceAll).Watch(cc.endpointListOptions(options))
 				},
 			},
 			&v1.Endpoints{},
@@ -19,11 +19,13 @@ package service
 import (
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/golang/glog"
 
 	"k8s.io/kubernetes/federation/pkg/dnsprovider"
 	"k8s.io/kubernetes/federation/pkg/dnsprovider/rrstype"
+	"k8s.io/kubernetes/pkg/api/v1"
 )
 
 const (
@@ -149,10 +151,25 @@ func getResolvedEndpoints(endpoints []string) ([]string, error) {
 	return resolvedEndpoints, nil
 }
 
+/* ownerRecordName returns the DNS name at which the TXT ownership record for dnsName is kept.  The ownership record lives at a
+   separate name because a CNAME cannot coexist with records of other types at the same name.
+*/
+func ownerRecordName(dnsName string) string {
+	return "owner." + dnsName
+}
+
+// ownerRecordValue returns the TXT rrdata identifying this federation and service as the owner of a managed DNS name.
+func (s *ServiceController) ownerRecordValue(service *v1.Service) string {
+	return fmt.Sprintf("\"owner=%s/%s\"", s.federationName, service.UID)
+}
+
 /* ensureDnsRrsets ensures (idempotently, and with minimum mutations) that all of the DNS resource record sets for dnsName are consistent with endpoints.
    if endpoints is nil or empty, a CNAME record to uplevelCname is ensured.
+   Every managed record is accompanied by a TXT ownership record identifying the federation and service that own it.  If an
+   ownership record for another owner already exists, the records for dnsName are left untouched, a warning event is recorded
+   against service, and nil is returned (retrying will not help until the conflicting owner goes away).
 */
-func (s *ServiceController) ensureDnsRrsets(dnsZoneName, dnsName string, endpoints []string, uplevelCname string) error {
+func (s *ServiceController) ensureDnsRrsets(dnsZoneName, dnsName string, endpoints []string, uplevelCname string, service *v1.Service) error {
 	dnsZone, err := getDnsZone(dnsZoneName, s.dnsZones)
 	if err != nil {
 		return err
@@ -165,6 +182,26 @@ func (s *ServiceController) ensureDnsRrsets(dnsZoneName, dnsName string, endpoin
 	if err != nil {
 		return err
 	}
+	ownerName := ownerRecordName(dnsName)
+	ownerRrset, err := getRrset(ownerName, rrsets)
+	if err != nil {
+		return err
+	}
+	owner := s.ownerRecordValue(service)
+	if ownerRrset != nil && (len(ownerRrset.Rrdatas()) != 1 || ownerRrset.Rrdatas()[0] != owner) {
+		msg := fmt.Sprintf("DNS record %q is owned by %q, not by this federation (%s), refusing to modify it", dnsName, strings.Join(ownerRrset.Rrdatas(), ","), owner)
+		glog.Warningf("%s", msg)
+		s.eventRecorder.Event(service, v1.EventTypeWarning, "DNSRecordNotOwned", msg)
+		return nil
+	}
+	if (len(endpoints) > 0 || uplevelCname != "") && ownerRrset == nil {
+		// We are about to create or adopt the record at dnsName, so claim ownership of it first.
+		newOwnerRrset := rrsets.New(ownerName, []string{owner}, minDnsTtl, rrstype.TXT)
+		glog.V(4).Infof("Claiming ownership of %q by adding recordset %v", dnsName, newOwnerRrset)
+		if err := rrsets.StartChangeset().Add(newOwnerRrset).Apply(); err != nil {
+			return err
+		}
+	}
 	if rrset == nil {
 		glog.V(4).Infof("No recordsets found for DNS name %q.  Need to add either A records (if we have healthy endpoints), or a CNAME record to %q", dnsName, uplevelCname)
 		if len(endpoints) < 1 {
@@ -180,6 +217,12 @@ func (s *ServiceController) ensureDnsRrsets(dnsZoneName, dnsName string, endpoin
 				glog.V(4).Infof("Successfully created CNAME to %q for %q", uplevelCname, dnsName)
 			} else {
 				glog.V(4).Infof("We want no record for %q, and we have no record, so we're all good.", dnsName)
+				if ownerRrset != nil {
+					glog.V(4).Infof("Removing leftover ownership recordset %v for %q", ownerRrset, dnsName)
+					if err := rrsets.StartChangeset().Remove(ownerRrset).Apply(); err != nil {
+						return err
+					}
+				}
 			}
 		} else {
 			// We have valid endpoint addresses, so just add them as A records.
@@ -221,10 +264,14 @@ func (s *ServiceController) ensureDnsRrsets(dnsZoneName, dnsName string, endpoin
 					}
 					glog.V(4).Infof("Successfully replaced needed recordset %v -> %v", rrset, newRrset)
 				} else {
+					// No record wanted at this level any more, so remove the ownership record along with the data record.
+					if ownerRrset != nil {
+						changeSet.Remove(ownerRrset)
+					}
 					if err := changeSet.Apply(); err != nil {
 						return err
 					}
-					glog.V(4).Infof("Successfully removed existing recordset %v", rrset)
+					glog.V(4).Infof("Successfully removed existing recordset %v and its ownership recordset", rrset)
 					glog.V(4).Infof("Uplevel CNAME is empty string. Not adding recordset %v", newRrset)
 				}
 			}
@@ -318,7 +365,7 @@ func (s *ServiceController) ensureDnsRecords(clusterName string, cachedService *
 	endpoints := [][]string{zoneEndpoints, regionEndpoints, globalEndpoints}
 
 	for i, endpoint := range endpoints {
-		if err = s.ensureDnsRrsets(dnsZoneName, dnsNames[i], endpoint, dnsNames[i+1]); err != nil {
+		if err = s.ensureDnsRrsets(dnsZoneName, dnsNames[i], endpoint, dnsNames[i+1], cachedService.lastState); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,146 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/federation/pkg/dnsprovider"
+	"k8s.io/kubernetes/federation/pkg/dnsprovider/providers/google/clouddns" // Only for unit testing purposes.
+	"k8s.io/kubernetes/federation/pkg/dnsprovider/rrstype"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/record"
+)
+
+// newDnsTestServiceController returns a ServiceController wired to the fake clouddns provider
+// (whose only zone is "example.com"), along with the zone's recordsets and the fake event recorder.
+func newDnsTestServiceController(t *testing.T) (*ServiceController, dnsprovider.ResourceRecordSets, *record.FakeRecorder) {
+	fakedns, _ := clouddns.NewFakeInterface() // No need to check for unsupported interfaces, as the fake interface supports everything that's required.
+	fakednsZones, ok := fakedns.Zones()
+	if !ok {
+		t.Fatalf("fake DNS provider does not support the Zones interface")
+	}
+	recorder := record.NewFakeRecorder(10)
+	serviceController := &ServiceController{
+		dns:            fakedns,
+		dnsZones:       fakednsZones,
+		federationName: "myfederation",
+		zoneName:       "example.com",
+		eventRecorder:  recorder,
+	}
+	zone, err := getDnsZone("example.com", fakednsZones)
+	if err != nil {
+		t.Fatalf("failed to get fake DNS zone: %v", err)
+	}
+	rrsets, ok := zone.ResourceRecordSets()
+	if !ok {
+		t.Fatalf("fake DNS zone does not support the ResourceRecordSets interface")
+	}
+	return serviceController, rrsets, recorder
+}
+
+// mustGetRrset returns the recordset with the given name, or nil if no such recordset exists.
+func mustGetRrset(t *testing.T, rrsets dnsprovider.ResourceRecordSets, name string) dnsprovider.ResourceRecordSet {
+	rrset, err := getRrset(name, rrsets)
+	if err != nil {
+		t.Fatalf("failed to get recordset %q: %v", name, err)
+	}
+	return rrset
+}
+
+func TestEnsureDnsRrsetsClaimsOwnership(t *testing.T) {
+	s, rrsets, _ := newDnsTestServiceController(t)
+	service := &v1.Service{ObjectMeta: v1.ObjectMeta{Name: "servicename", Namespace: "servicenamespace", UID: "12345"}}
+	dnsName := "servicename.servicenamespace.myfederation.svc.example.com"
+
+	if err := s.ensureDnsRrsets("example.com", dnsName, []string{"198.51.100.1"}, "", service); err != nil {
+		t.Fatalf("failed to ensure DNS records: %v", err)
+	}
+	rrset := mustGetRrset(t, rrsets, dnsName)
+	if rrset == nil {
+		t.Fatalf("expected an A recordset for %q, found none", dnsName)
+	}
+	if !reflect.DeepEqual(rrset.Rrdatas(), []string{"198.51.100.1"}) {
+		t.Errorf("expected A recordset with rrdatas [198.51.100.1], got %v", rrset.Rrdatas())
+	}
+	ownerRrset := mustGetRrset(t, rrsets, ownerRecordName(dnsName))
+	if ownerRrset == nil {
+		t.Fatalf("expected an ownership recordset for %q, found none", dnsName)
+	}
+	if ownerRrset.Type() != rrstype.TXT {
+		t.Errorf("expected ownership recordset of type TXT, got %v", ownerRrset.Type())
+	}
+	if !reflect.DeepEqual(ownerRrset.Rrdatas(), []string{s.ownerRecordValue(service)}) {
+		t.Errorf("expected ownership recordset with rrdatas [%s], got %v", s.ownerRecordValue(service), ownerRrset.Rrdatas())
+	}
+}
+
+func TestEnsureDnsRrsetsPreservesForeignRecords(t *testing.T) {
+	s, rrsets, recorder := newDnsTestServiceController(t)
+	service := &v1.Service{ObjectMeta: v1.ObjectMeta{Name: "servicename", Namespace: "servicenamespace", UID: "12345"}}
+	dnsName := "servicename.servicenamespace.myfederation.svc.example.com"
+
+	// Seed a pre-existing record owned by someone else.
+	foreignRrset := rrsets.New(dnsName, []string{"203.0.113.1"}, minDnsTtl, rrstype.A)
+	foreignOwnerRrset := rrsets.New(ownerRecordName(dnsName), []string{"\"owner=otherfederation/67890\""}, minDnsTtl, rrstype.TXT)
+	if err := rrsets.StartChangeset().Add(foreignRrset).Add(foreignOwnerRrset).Apply(); err != nil {
+		t.Fatalf("failed to seed foreign recordsets: %v", err)
+	}
+
+	if err := s.ensureDnsRrsets("example.com", dnsName, []string{"198.51.100.1"}, "", service); err != nil {
+		t.Fatalf("expected ensureDnsRrsets to refuse the foreign record without error, got %v", err)
+	}
+	rrset := mustGetRrset(t, rrsets, dnsName)
+	if rrset == nil || !reflect.DeepEqual(rrset.Rrdatas(), []string{"203.0.113.1"}) {
+		t.Errorf("expected the foreign A recordset to be preserved, got %v", rrset)
+	}
+	ownerRrset := mustGetRrset(t, rrsets, ownerRecordName(dnsName))
+	if ownerRrset == nil || !reflect.DeepEqual(ownerRrset.Rrdatas(), []string{"\"owner=otherfederation/67890\""}) {
+		t.Errorf("expected the foreign ownership recordset to be preserved, got %v", ownerRrset)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "DNSRecordNotOwned") {
+			t.Errorf("expected a DNSRecordNotOwned event, saw %q", event)
+		}
+	default:
+		t.Errorf("expected an event for the foreign record, saw none")
+	}
+}
+
+func TestEnsureDnsRrsetsRemovesOwnershipRecordOnDeletion(t *testing.T) {
+	s, rrsets, _ := newDnsTestServiceController(t)
+	service := &v1.Service{ObjectMeta: v1.ObjectMeta{Name: "servicename", Namespace: "servicenamespace", UID: "12345"}}
+	dnsName := "servicename.servicenamespace.myfederation.svc.example.com"
+
+	if err := s.ensureDnsRrsets("example.com", dnsName, []string{"198.51.100.1"}, "", service); err != nil {
+		t.Fatalf("failed to ensure DNS records: %v", err)
+	}
+	// The service is gone: no healthy endpoints remain and there is no uplevel CNAME target,
+	// so both the data record and the ownership record must be removed.
+	if err := s.ensureDnsRrsets("example.com", dnsName, []string{}, "", service); err != nil {
+		t.Fatalf("failed to ensure DNS records after deletion: %v", err)
+	}
+	if rrset := mustGetRrset(t, rrsets, dnsName); rrset != nil {
+		t.Errorf("expected the A recordset to be removed, got %v", rrset)
+	}
+	if ownerRrset := mustGetRrset(t, rrsets, ownerRecordName(dnsName)); ownerRrset != nil {
+		t.Errorf("expected the ownership recordset to be removed, got %v", ownerRrset)
+	}
+}
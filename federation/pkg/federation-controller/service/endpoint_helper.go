@@ -17,10 +17,12 @@ limitations under the License.
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
 	fedclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_release_1_5"
+	"k8s.io/kubernetes/pkg/api/errors"
 	v1 "k8s.io/kubernetes/pkg/api/v1"
 	cache "k8s.io/kubernetes/pkg/client/cache"
 	"k8s.io/kubernetes/pkg/controller"
@@ -87,37 +89,49 @@ func (cc *clusterClientCache) syncEndpoint(key, clusterName string, clusterCache
 		clusterCache.endpointQueue.Add(key)
 		return err
 	}
+	var needUpdate bool
 	if exists {
 		endpoint, ok := endpointInterface.(*v1.Endpoints)
 		if ok {
 			glog.V(4).Infof("Found endpoint for federation service %s/%s from cluster %s", endpoint.Namespace, endpoint.Name, clusterName)
-			err = cc.processEndpointUpdate(cachedService, endpoint, clusterName, serviceController)
+			needUpdate, err = cc.processEndpointUpdate(cachedService, endpoint, clusterName, serviceController)
 		} else {
 			_, ok := endpointInterface.(cache.DeletedFinalStateUnknown)
 			if !ok {
 				return fmt.Errorf("Object contained wasn't a service or a deleted key: %+v", endpointInterface)
 			}
 			glog.Infof("Found tombstone for %v", key)
-			err = cc.processEndpointDeletion(cachedService, clusterName, serviceController)
+			needUpdate, err = cc.processEndpointDeletion(cachedService, clusterName, serviceController)
 		}
 	} else {
 		// service absence in store means watcher caught the deletion, ensure LB info is cleaned
 		glog.Infof("Can not get endpoint %v for cluster %s from endpointStore", key, clusterName)
-		err = cc.processEndpointDeletion(cachedService, clusterName, serviceController)
+		needUpdate, err = cc.processEndpointDeletion(cachedService, clusterName, serviceController)
 	}
 	if err != nil {
 		glog.Errorf("Failed to sync service: %+v, put back to service queue", err)
 		clusterCache.endpointQueue.Add(key)
 	}
+	if needUpdate {
+		if err := cc.persistEndpointCounts(cachedService, fedClient); err != nil {
+			glog.Errorf("Failed to persist endpoint counts for %v: %v, put back to service queue", key, err)
+			clusterCache.endpointQueue.Add(key)
+		}
+	}
 	cachedService.resetDNSUpdateDelay()
 	return nil
 }
 
-func (cc *clusterClientCache) processEndpointDeletion(cachedService *cachedService, clusterName string, serviceController *ServiceController) error {
+func (cc *clusterClientCache) processEndpointDeletion(cachedService *cachedService, clusterName string, serviceController *ServiceController) (bool, error) {
 	glog.V(4).Infof("Processing endpoint deletion for %s/%s, cluster %s", cachedService.lastState.Namespace, cachedService.lastState.Name, clusterName)
 	var err error
 	cachedService.rwlock.Lock()
 	defer cachedService.rwlock.Unlock()
+	if !wantsEndpointProcessing(cachedService.lastState) {
+		glog.V(4).Infof("Skipping endpoint deletion for %s/%s, cluster %s: service has no cross-cluster DNS records", cachedService.lastState.Namespace, cachedService.lastState.Name, clusterName)
+		return false, nil
+	}
+	needUpdate := cachedService.removeEndpointCounts(clusterName)
 	_, ok := cachedService.endpointMap[clusterName]
 	// TODO remove ok checking? if service controller is restarted, then endpointMap for the cluster does not exist
 	// need to query dns info from dnsprovider and make sure of if deletion is needed
@@ -128,68 +142,140 @@ func (cc *clusterClientCache) processEndpointDeletion(cachedService *cachedServi
 		for i := 0; i < clientRetryCount; i++ {
 			err := serviceController.ensureDnsRecords(clusterName, cachedService)
 			if err == nil {
-				return nil
+				return needUpdate, nil
 			}
 			glog.V(4).Infof("Error ensuring DNS Records: %v", err)
 			time.Sleep(cachedService.nextDNSUpdateDelay())
 		}
 	}
-	return err
+	return needUpdate, err
+}
+
+// endpointCountsOf sums the ready and not-ready addresses over all subsets
+// of the given endpoints object.
+func endpointCountsOf(endpoint *v1.Endpoints) endpointCounts {
+	var counts endpointCounts
+	for _, subset := range endpoint.Subsets {
+		counts.Ready += len(subset.Addresses)
+		counts.NotReady += len(subset.NotReadyAddresses)
+	}
+	return counts
 }
 
 // Update dns info when endpoint update event received
-// We do not care about the endpoint info, what we need to make sure here is len(endpoints.subsets)>0
-func (cc *clusterClientCache) processEndpointUpdate(cachedService *cachedService, endpoint *v1.Endpoints, clusterName string, serviceController *ServiceController) error {
+// The ready address count decides whether the cluster keeps its DNS records,
+// and the ready/not-ready counts are recorded for the federated service's
+// endpoint counts annotation.
+func (cc *clusterClientCache) processEndpointUpdate(cachedService *cachedService, endpoint *v1.Endpoints, clusterName string, serviceController *ServiceController) (bool, error) {
 	glog.V(4).Infof("Processing endpoint update for %s/%s, cluster %s", endpoint.Namespace, endpoint.Name, clusterName)
 	var err error
 	cachedService.rwlock.Lock()
-	var reachable bool
 	defer cachedService.rwlock.Unlock()
-	_, ok := cachedService.endpointMap[clusterName]
-	if !ok {
-		for _, subset := range endpoint.Subsets {
-			if len(subset.Addresses) > 0 {
-				reachable = true
-				break
-			}
-		}
-		if reachable {
-			// first time get endpoints, update dns record
-			glog.V(4).Infof("Reachable endpoint was found for %s/%s, cluster %s, building endpointMap", endpoint.Namespace, endpoint.Name, clusterName)
-			cachedService.endpointMap[clusterName] = 1
+	if !wantsEndpointProcessing(cachedService.lastState) {
+		glog.V(4).Infof("Skipping endpoint update for %s/%s, cluster %s: service has no cross-cluster DNS records", endpoint.Namespace, endpoint.Name, clusterName)
+		return false, nil
+	}
+	selected, err := clusterSelectedForService(cachedService.lastState, clusterName)
+	if err != nil {
+		glog.Errorf("Failed to evaluate cluster selection for %s/%s: %v", endpoint.Namespace, endpoint.Name, err)
+		serviceController.eventRecorder.Event(cachedService.lastState, v1.EventTypeWarning, "InvalidClusterSelection", err.Error())
+		return false, nil
+	}
+	if !selected {
+		// The cluster is outside the service's selection; if it contributed
+		// DNS records or endpoint counts earlier they must be removed now.
+		needUpdate := cachedService.removeEndpointCounts(clusterName)
+		if _, ok := cachedService.endpointMap[clusterName]; ok {
+			glog.V(4).Infof("Cluster %s is no longer selected for %s/%s, removing its DNS records", clusterName, endpoint.Namespace, endpoint.Name)
+			delete(cachedService.endpointMap, clusterName)
 			for i := 0; i < clientRetryCount; i++ {
 				err := serviceController.ensureDnsRecords(clusterName, cachedService)
 				if err == nil {
-					return nil
+					return needUpdate, nil
 				}
 				glog.V(4).Infof("Error ensuring DNS Records: %v", err)
 				time.Sleep(cachedService.nextDNSUpdateDelay())
 			}
-			return err
+			return needUpdate, err
 		}
+		glog.V(4).Infof("Skipping endpoint update for %s/%s: cluster %s is not selected by the service", endpoint.Namespace, endpoint.Name, clusterName)
+		return needUpdate, nil
+	}
+	counts := endpointCountsOf(endpoint)
+	needUpdate := cachedService.setEndpointCounts(clusterName, counts)
+	// The endpointMap entry is the ready count recorded above, so the DNS
+	// decision and the reported counts always come from the same numbers.
+	reachableBefore := cachedService.endpointMap[clusterName] > 0
+	if counts.Ready > 0 {
+		cachedService.endpointMap[clusterName] = counts.Ready
 	} else {
-		for _, subset := range endpoint.Subsets {
-			if len(subset.Addresses) > 0 {
-				reachable = true
-				break
-			}
-		}
-		if !reachable {
+		delete(cachedService.endpointMap, clusterName)
+	}
+	if reachableBefore != (counts.Ready > 0) {
+		if counts.Ready > 0 {
 			// first time get endpoints, update dns record
+			glog.V(4).Infof("Reachable endpoint was found for %s/%s, cluster %s, building endpointMap", endpoint.Namespace, endpoint.Name, clusterName)
+		} else {
 			glog.V(4).Infof("Reachable endpoint was lost for %s/%s, cluster %s, deleting endpointMap", endpoint.Namespace, endpoint.Name, clusterName)
-			delete(cachedService.endpointMap, clusterName)
-			for i := 0; i < clientRetryCount; i++ {
-				err := serviceController.ensureDnsRecords(clusterName, cachedService)
-				if err == nil {
-					return nil
-				}
-				glog.V(4).Infof("Error ensuring DNS Records: %v", err)
-				time.Sleep(cachedService.nextDNSUpdateDelay())
+		}
+		for i := 0; i < clientRetryCount; i++ {
+			err := serviceController.ensureDnsRecords(clusterName, cachedService)
+			if err == nil {
+				return needUpdate, nil
 			}
-			return err
+			glog.V(4).Infof("Error ensuring DNS Records: %v", err)
+			time.Sleep(cachedService.nextDNSUpdateDelay())
 		}
+		return needUpdate, err
 	}
-	return nil
+	return needUpdate, nil
+}
+
+// persistEndpointCounts writes the per-cluster endpoint readiness counts to
+// the ServiceAnnotationEndpointCounts annotation of the federated service.
+// The annotation is only written when its value would actually change, so
+// steady endpoint churn does not translate into federation apiserver updates.
+func (cc *clusterClientCache) persistEndpointCounts(cachedService *cachedService, fedClient fedclientset.Interface) error {
+	cachedService.rwlock.Lock()
+	payload, err := json.Marshal(cachedService.endpointCountMap)
+	namespace := cachedService.lastState.Namespace
+	name := cachedService.lastState.Name
+	cachedService.rwlock.Unlock()
+	if err != nil {
+		return err
+	}
+	glog.V(5).Infof("Persist endpoint counts for federation service %s/%s", namespace, name)
+	for i := 0; i < clientRetryCount; i++ {
+		var service *v1.Service
+		service, err = fedClient.Core().Services(namespace).Get(name)
+		if errors.IsNotFound(err) {
+			glog.Infof("Not persisting endpoint counts for service '%s/%s' that no longer exists: %v",
+				namespace, name, err)
+			return nil
+		}
+		if err == nil {
+			if service.Annotations[ServiceAnnotationEndpointCounts] == string(payload) {
+				glog.V(4).Infof("Same endpoint counts found for %s/%s", namespace, name)
+				return nil
+			}
+			if service.Annotations == nil {
+				service.Annotations = make(map[string]string)
+			}
+			service.Annotations[ServiceAnnotationEndpointCounts] = string(payload)
+			_, err = fedClient.Core().Services(namespace).Update(service)
+			if err == nil {
+				glog.V(2).Infof("Successfully updated endpoint counts for service %s/%s to federation apiserver", namespace, name)
+				return nil
+			}
+			if errors.IsNotFound(err) {
+				glog.Infof("Not persisting endpoint counts for service '%s/%s' that no longer exists: %v",
+					namespace, name, err)
+				return nil
+			}
+		}
+		time.Sleep(cachedService.nextFedUpdateDelay())
+	}
+	return err
 }
 
 // obj could be an *api.Endpoints, or a DeletionFinalStateUnknown marker item.
@@ -17,11 +17,14 @@ limitations under the License.
 package service
 
 import (
+	"strings"
 	"testing"
 
 	"k8s.io/kubernetes/federation/apis/federation/v1beta1"
+	fake_fedclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_release_1_5/fake"
 	"k8s.io/kubernetes/federation/pkg/dnsprovider/providers/google/clouddns" // Only for unit testing purposes.
 	v1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/util/sets"
 )
 
@@ -40,6 +43,27 @@ var fakeServiceController = ServiceController{
 	knownClusterSet: make(sets.String),
 }
 
+// buildFederatedLBService returns a LoadBalancer service carrying the
+// federation DNS annotation, whose endpoint events need to be processed.
+func buildFederatedLBService() *v1.Service {
+	return &v1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Annotations: map[string]string{ServiceAnnotationFederatedDNS: "true"},
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+		},
+	}
+}
+
+// buildClusterSelectedLBService returns a federated LoadBalancer service
+// whose cross-cluster DNS records are restricted to the given clusters.
+func buildClusterSelectedLBService(clusterNames string) *v1.Service {
+	service := buildFederatedLBService()
+	service.Annotations[ServiceAnnotationFederatedClusters] = clusterNames
+	return service
+}
+
 func buildEndpoint(subsets [][]string) *v1.Endpoints {
 	endpoint := &v1.Endpoints{
 		Subsets: []v1.EndpointSubset{
@@ -53,6 +77,17 @@ func buildEndpoint(subsets [][]string) *v1.Endpoints {
 	return endpoint
 }
 
+// buildMixedEndpoint returns an endpoints object carrying both ready and
+// not-ready addresses in a single subset.
+func buildMixedEndpoint(ready, notReady [][]string) *v1.Endpoints {
+	endpoint := buildEndpoint(ready)
+	for _, element := range notReady {
+		address := v1.EndpointAddress{IP: element[0], Hostname: element[1], TargetRef: nil}
+		endpoint.Subsets[0].NotReadyAddresses = append(endpoint.Subsets[0].NotReadyAddresses, address)
+	}
+	return endpoint
+}
+
 func TestProcessEndpointUpdate(t *testing.T) {
 	clusterName := "foo"
 	cc := clusterClientCache{
@@ -77,7 +112,7 @@ func TestProcessEndpointUpdate(t *testing.T) {
 		{
 			"no-cache",
 			&cachedService{
-				lastState:   &v1.Service{},
+				lastState:   buildFederatedLBService(),
 				endpointMap: make(map[string]int),
 			},
 			buildEndpoint([][]string{{"ip1", ""}}),
@@ -87,7 +122,7 @@ func TestProcessEndpointUpdate(t *testing.T) {
 		{
 			"has-cache",
 			&cachedService{
-				lastState: &v1.Service{},
+				lastState: buildFederatedLBService(),
 				endpointMap: map[string]int{
 					"foo": 1,
 				},
@@ -96,6 +131,76 @@ func TestProcessEndpointUpdate(t *testing.T) {
 			clusterName,
 			1,
 		},
+		{
+			// A non-LoadBalancer service has no cross-cluster DNS records,
+			// so its endpoints must not be counted in the endpointMap.
+			"non-loadbalancer-service",
+			&cachedService{
+				lastState: &v1.Service{
+					ObjectMeta: v1.ObjectMeta{
+						Annotations: map[string]string{ServiceAnnotationFederatedDNS: "true"},
+					},
+				},
+				endpointMap: make(map[string]int),
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			0,
+		},
+		{
+			// A LoadBalancer service without the federation DNS annotation
+			// is skipped as well.
+			"unannotated-loadbalancer-service",
+			&cachedService{
+				lastState: &v1.Service{
+					Spec: v1.ServiceSpec{
+						Type: v1.ServiceTypeLoadBalancer,
+					},
+				},
+				endpointMap: make(map[string]int),
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			0,
+		},
+		{
+			// A cluster inside the service's cluster selection is counted
+			// as usual.
+			"cluster-inside-selection",
+			&cachedService{
+				lastState:   buildClusterSelectedLBService("foo,bar"),
+				endpointMap: make(map[string]int),
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			1,
+		},
+		{
+			// Endpoints from a cluster outside the selection must not be
+			// counted in the endpointMap.
+			"cluster-outside-selection",
+			&cachedService{
+				lastState:   buildClusterSelectedLBService("bar,baz"),
+				endpointMap: make(map[string]int),
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			0,
+		},
+		{
+			// A cluster that contributed records before falling out of the
+			// selection has its contribution removed.
+			"cluster-fell-out-of-selection",
+			&cachedService{
+				lastState: buildClusterSelectedLBService("bar"),
+				endpointMap: map[string]int{
+					clusterName: 1,
+				},
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			0,
+		},
 	}
 	fakeServiceController.clusterCache = &cc
 	for _, test := range tests {
@@ -106,6 +211,42 @@ func TestProcessEndpointUpdate(t *testing.T) {
 	}
 }
 
+func TestProcessEndpointUpdateInvalidClusterSelection(t *testing.T) {
+	clusterName := "foo"
+	cc := clusterClientCache{
+		clientMap: map[string]*clusterCache{
+			clusterName: {
+				cluster: &v1beta1.Cluster{
+					Status: v1beta1.ClusterStatus{
+						Zones:  []string{"foozone"},
+						Region: "fooregion",
+					},
+				},
+			},
+		},
+	}
+	recorder := record.NewFakeRecorder(1)
+	fakeServiceController.clusterCache = &cc
+	fakeServiceController.eventRecorder = recorder
+	// The annotation contains an empty cluster name, so it is malformed.
+	cachedService := &cachedService{
+		lastState:   buildClusterSelectedLBService("foo,,bar"),
+		endpointMap: make(map[string]int),
+	}
+	cc.processEndpointUpdate(cachedService, buildEndpoint([][]string{{"ip1", ""}}), clusterName, &fakeServiceController)
+	if len(cachedService.endpointMap) != 0 {
+		t.Errorf("expected endpointMap to stay empty for a malformed cluster selection, saw %v", cachedService.endpointMap)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "InvalidClusterSelection") {
+			t.Errorf("expected an InvalidClusterSelection event, saw %q", event)
+		}
+	default:
+		t.Errorf("expected an event for the malformed cluster selection, saw none")
+	}
+}
+
 func TestProcessEndpointDeletion(t *testing.T) {
 	clusterName := "foo"
 	cc := clusterClientCache{
@@ -130,7 +271,7 @@ func TestProcessEndpointDeletion(t *testing.T) {
 		{
 			"no-cache",
 			&cachedService{
-				lastState:   &v1.Service{},
+				lastState:   buildFederatedLBService(),
 				endpointMap: make(map[string]int),
 			},
 			buildEndpoint([][]string{{"ip1", ""}}),
@@ -140,7 +281,7 @@ func TestProcessEndpointDeletion(t *testing.T) {
 		{
 			"has-cache",
 			&cachedService{
-				lastState: &v1.Service{},
+				lastState: buildFederatedLBService(),
 				endpointMap: map[string]int{
 					clusterName: 1,
 				},
@@ -149,6 +290,20 @@ func TestProcessEndpointDeletion(t *testing.T) {
 			clusterName,
 			0,
 		},
+		{
+			// Endpoint deletions for services without cross-cluster DNS
+			// records are skipped, leaving the endpointMap untouched.
+			"non-loadbalancer-service-has-cache",
+			&cachedService{
+				lastState: &v1.Service{},
+				endpointMap: map[string]int{
+					clusterName: 1,
+				},
+			},
+			buildEndpoint([][]string{{"ip1", ""}}),
+			clusterName,
+			1,
+		},
 	}
 	fakeServiceController.clusterCache = &cc
 	for _, test := range tests {
@@ -158,3 +313,102 @@ func TestProcessEndpointDeletion(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessEndpointUpdateEndpointCounts(t *testing.T) {
+	clusterName := "foo"
+	cc := clusterClientCache{
+		clientMap: map[string]*clusterCache{
+			clusterName: {
+				cluster: &v1beta1.Cluster{
+					Status: v1beta1.ClusterStatus{
+						Zones:  []string{"foozone"},
+						Region: "fooregion",
+					},
+				},
+			},
+		},
+	}
+	fakeServiceController.clusterCache = &cc
+	cachedService := &cachedService{
+		lastState:        buildFederatedLBService(),
+		endpointMap:      make(map[string]int),
+		endpointCountMap: make(map[string]endpointCounts),
+	}
+
+	// Two ready addresses and one not-ready address: the counts are
+	// recorded, and the ready count feeds the endpointMap DNS relies on.
+	needUpdate, err := cc.processEndpointUpdate(cachedService, buildMixedEndpoint([][]string{{"ip1", ""}, {"ip2", ""}}, [][]string{{"ip3", ""}}), clusterName, &fakeServiceController)
+	if err != nil {
+		t.Fatalf("processEndpointUpdate failed: %v", err)
+	}
+	if !needUpdate {
+		t.Errorf("expected changed endpoint counts to need a federation update")
+	}
+	if counts := cachedService.endpointCountMap[clusterName]; counts != (endpointCounts{Ready: 2, NotReady: 1}) {
+		t.Errorf("unexpected endpoint counts, saw %+v", counts)
+	}
+	if cachedService.endpointMap[clusterName] != 2 {
+		t.Errorf("expected endpointMap to carry the ready count 2, saw %v", cachedService.endpointMap[clusterName])
+	}
+
+	// The same endpoints again: nothing changed, no federation update.
+	needUpdate, err = cc.processEndpointUpdate(cachedService, buildMixedEndpoint([][]string{{"ip1", ""}, {"ip2", ""}}, [][]string{{"ip3", ""}}), clusterName, &fakeServiceController)
+	if err != nil {
+		t.Fatalf("processEndpointUpdate failed: %v", err)
+	}
+	if needUpdate {
+		t.Errorf("expected unchanged endpoint counts to need no federation update")
+	}
+
+	// All addresses turning not-ready removes the cluster's DNS
+	// contribution while still reporting the not-ready count.
+	needUpdate, err = cc.processEndpointUpdate(cachedService, buildMixedEndpoint(nil, [][]string{{"ip1", ""}, {"ip2", ""}, {"ip3", ""}}), clusterName, &fakeServiceController)
+	if err != nil {
+		t.Fatalf("processEndpointUpdate failed: %v", err)
+	}
+	if !needUpdate {
+		t.Errorf("expected changed endpoint counts to need a federation update")
+	}
+	if counts := cachedService.endpointCountMap[clusterName]; counts != (endpointCounts{Ready: 0, NotReady: 3}) {
+		t.Errorf("unexpected endpoint counts, saw %+v", counts)
+	}
+	if _, ok := cachedService.endpointMap[clusterName]; ok {
+		t.Errorf("expected endpointMap entry to be removed when no address is ready, saw %v", cachedService.endpointMap[clusterName])
+	}
+}
+
+func TestPersistEndpointCounts(t *testing.T) {
+	service := buildFederatedLBService()
+	service.Namespace = "default"
+	service.Name = "test-service"
+	fedClient := fake_fedclientset.NewSimpleClientset(service)
+	cc := clusterClientCache{clientMap: make(map[string]*clusterCache)}
+	cachedService := &cachedService{
+		lastState: service,
+		endpointCountMap: map[string]endpointCounts{
+			"foo": {Ready: 2, NotReady: 1},
+		},
+	}
+	if err := cc.persistEndpointCounts(cachedService, fedClient); err != nil {
+		t.Fatalf("persistEndpointCounts failed: %v", err)
+	}
+	updated, err := fedClient.Core().Services("default").Get("test-service")
+	if err != nil {
+		t.Fatalf("failed to get service back: %v", err)
+	}
+	want := `{"foo":{"ready":2,"notReady":1}}`
+	if got := updated.Annotations[ServiceAnnotationEndpointCounts]; got != want {
+		t.Errorf("unexpected endpoint counts annotation, got %q, want %q", got, want)
+	}
+
+	// Persisting unchanged counts must not issue another update.
+	fedClient.ClearActions()
+	if err := cc.persistEndpointCounts(cachedService, fedClient); err != nil {
+		t.Fatalf("persistEndpointCounts failed: %v", err)
+	}
+	for _, action := range fedClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("expected no update for unchanged endpoint counts, saw %v", action)
+		}
+	}
+}
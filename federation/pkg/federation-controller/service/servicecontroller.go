@@ -18,6 +18,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -69,16 +70,52 @@ const (
 	KubeAPIBurst  = 30
 
 	maxNoOfClusters = 100
+
+	// ServiceAnnotationFederatedDNS is the annotation on a federated
+	// service that indicates cross-cluster DNS records should be
+	// maintained for it. Endpoint events for services without the
+	// annotation are ignored by the endpoint workers.
+	ServiceAnnotationFederatedDNS = "federation.kubernetes.io/dns-record"
+
+	// ServiceAnnotationFederatedClusters is the annotation on a federated
+	// service that restricts its cross-cluster DNS records to a
+	// comma-separated list of cluster names. Endpoints from clusters
+	// outside the list are ignored, and records they contributed earlier
+	// are removed. Services without the annotation federate to all
+	// known clusters.
+	ServiceAnnotationFederatedClusters = "federation.kubernetes.io/service-clusters"
+
+	// ServiceAnnotationEndpointCounts is the annotation the service
+	// controller keeps up to date on a federated service, recording how
+	// many ready and not-ready endpoint addresses each member cluster
+	// contributes, as a JSON object keyed by cluster name. It is only
+	// written when the counts actually change, so watching it does not
+	// expose users to per-event update churn.
+	ServiceAnnotationEndpointCounts = "federation.kubernetes.io/endpoint-counts"
 )
 
+// endpointCounts records how many ready and not-ready endpoint addresses a
+// single cluster contributes to a federated service.
+type endpointCounts struct {
+	Ready    int `json:"ready"`
+	NotReady int `json:"notReady"`
+}
+
 type cachedService struct {
 	lastState *v1.Service
 	// The state as successfully applied to the DNS server
 	appliedState *v1.Service
 	// cluster endpoint map hold subset info from kubernetes clusters
 	// key clusterName
-	// value is a flag that if there is ready address, 1 means there is ready address
+	// value is the number of ready endpoint addresses the cluster
+	// contributes; a missing or zero entry means the cluster has none
 	endpointMap map[string]int
+	// endpointCountMap holds the ready and not-ready endpoint address
+	// counts each cluster contributes, keyed on clusterName. The
+	// endpointMap entries DNS decisions rely on are derived from the
+	// ready counts kept here, so the reported counts and the DNS records
+	// cannot disagree.
+	endpointCountMap map[string]endpointCounts
 	// serviceStatusMap map holds service status info from kubernetes clusters, keyed on clusterName
 	serviceStatusMap map[string]v1.LoadBalancerStatus
 	// Ensures only one goroutine can operate on this service at any given time.
@@ -156,6 +193,7 @@ func New(federationClient fedclientset.Interface, dns dnsprovider.Interface, fed
 		queue:            workqueue.New(),
 		knownClusterSet:  make(sets.String),
 	}
+	s.clusterCache.serviceCache = s.serviceCache
 	s.serviceStore.Indexer, s.serviceController = cache.NewIndexerInformer(
 		&cache.ListWatch{
 			ListFunc: func(options api.ListOptions) (pkg_runtime.Object, error) {
@@ -319,6 +357,41 @@ func wantsDNSRecords(service *v1.Service) bool {
 	return service.Spec.Type == v1.ServiceTypeLoadBalancer
 }
 
+// wantsEndpointProcessing returns whether endpoint events for the given
+// service need to be processed. Only LoadBalancer services carrying the
+// federation DNS annotation have cross-cluster DNS records to maintain,
+// so endpoint churn for any other service can be ignored.
+func wantsEndpointProcessing(service *v1.Service) bool {
+	if service == nil || !wantsDNSRecords(service) {
+		return false
+	}
+	_, found := service.Annotations[ServiceAnnotationFederatedDNS]
+	return found
+}
+
+// clusterSelectedForService returns whether the given cluster is part of
+// the service's cluster selection, as expressed by the
+// ServiceAnnotationFederatedClusters annotation. A service without the
+// annotation selects every cluster. An annotation that contains an empty
+// cluster name is considered malformed and yields an error.
+func clusterSelectedForService(service *v1.Service, clusterName string) (bool, error) {
+	selection, found := service.Annotations[ServiceAnnotationFederatedClusters]
+	if !found {
+		return true, nil
+	}
+	selected := false
+	for _, name := range strings.Split(selection, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return false, fmt.Errorf("invalid value %q for annotation %s: cluster names must be non-empty", selection, ServiceAnnotationFederatedClusters)
+		}
+		if name == clusterName {
+			selected = true
+		}
+	}
+	return selected, nil
+}
+
 // processServiceForCluster creates or updates service to all registered running clusters,
 // update DNS records and update the service info with DNS entries to federation apiserver.
 // the function returns any error caught
@@ -477,6 +550,20 @@ func (s *serviceCache) allServices() []*cachedService {
 	return services
 }
 
+// federatedNamespaces returns the set of namespaces that contain federated
+// services.
+func (s *serviceCache) federatedNamespaces() sets.String {
+	s.rwlock.Lock()
+	defer s.rwlock.Unlock()
+	namespaces := sets.String{}
+	for _, cachedService := range s.fedServiceMap {
+		if cachedService.lastState != nil {
+			namespaces.Insert(cachedService.lastState.Namespace)
+		}
+	}
+	return namespaces
+}
+
 func (s *serviceCache) get(serviceName string) (*cachedService, bool) {
 	s.rwlock.Lock()
 	defer s.rwlock.Unlock()
@@ -491,6 +578,7 @@ func (s *serviceCache) getOrCreate(serviceName string) *cachedService {
 	if !ok {
 		service = &cachedService{
 			endpointMap:      make(map[string]int),
+			endpointCountMap: make(map[string]endpointCounts),
 			serviceStatusMap: make(map[string]v1.LoadBalancerStatus),
 		}
 		s.fedServiceMap[serviceName] = service
@@ -823,6 +911,29 @@ func (s *cachedService) resetDNSUpdateDelay() {
 	s.lastDNSUpdateDelay = time.Duration(0)
 }
 
+// setEndpointCounts records the endpoint counts a cluster contributes and
+// reports whether they changed. mutex must be held.
+func (s *cachedService) setEndpointCounts(clusterName string, counts endpointCounts) bool {
+	if s.endpointCountMap == nil {
+		s.endpointCountMap = make(map[string]endpointCounts)
+	}
+	if s.endpointCountMap[clusterName] == counts {
+		return false
+	}
+	s.endpointCountMap[clusterName] = counts
+	return true
+}
+
+// removeEndpointCounts drops the recorded endpoint counts for a cluster and
+// reports whether an entry was removed. mutex must be held.
+func (s *cachedService) removeEndpointCounts(clusterName string) bool {
+	if _, ok := s.endpointCountMap[clusterName]; !ok {
+		return false
+	}
+	delete(s.endpointCountMap, clusterName)
+	return true
+}
+
 // syncService will sync the Service with the given key if it has had its expectations fulfilled,
 // meaning it did not expect to see any more of its pods created or deleted. This function is not meant to be
 // invoked concurrently with the same key.
@@ -286,6 +286,17 @@ func NewTimeoutError(message string, retryAfterSeconds int) *StatusError {
 	}}
 }
 
+// NewRequestEntityTooLargeError returns an error indicating that the request
+// entity was too large.
+func NewRequestEntityTooLargeError(message string) *StatusError {
+	return &StatusError{unversioned.Status{
+		Status:  unversioned.StatusFailure,
+		Code:    http.StatusRequestEntityTooLarge,
+		Reason:  unversioned.StatusReasonRequestEntityTooLarge,
+		Message: fmt.Sprintf("Request entity too large: %s", message),
+	}}
+}
+
 // NewGenericServerResponse returns a new error for server responses that are not in a recognizable form.
 func NewGenericServerResponse(code int, verb string, qualifiedResource unversioned.GroupResource, name, serverMessage string, retryAfterSeconds int, isUnexpectedResponse bool) *StatusError {
 	reason := unversioned.StatusReasonUnknown
@@ -387,6 +398,12 @@ func IsMethodNotSupported(err error) bool {
 	return reasonForError(err) == unversioned.StatusReasonMethodNotAllowed
 }
 
+// IsRequestEntityTooLargeError determines if err is an error which indicates
+// the request entity was too large.
+func IsRequestEntityTooLargeError(err error) bool {
+	return reasonForError(err) == unversioned.StatusReasonRequestEntityTooLarge
+}
+
 // IsBadRequest determines if err is an error which indicates that the request is invalid.
 func IsBadRequest(err error) bool {
 	return reasonForError(err) == unversioned.StatusReasonBadRequest
@@ -31,6 +31,19 @@ func NewDefaultRESTMapper(defaultGroupVersions []unversioned.GroupVersion, inter
 	return NewDefaultRESTMapperFromScheme(defaultGroupVersions, interfacesFunc, importPathPrefix, ignoredKinds, rootScoped, Scheme)
 }
 
+// allCategoryKinds are the kinds the "all" resource category expands to,
+// mirroring the primary, user facing resources used by client tools.
+var allCategoryKinds = sets.NewString(
+	"Pod",
+	"ReplicationController",
+	"Service",
+	"PetSet",
+	"HorizontalPodAutoscaler",
+	"Job",
+	"Deployment",
+	"ReplicaSet",
+)
+
 // Instantiates a DefaultRESTMapper based on types registered in the given scheme.
 func NewDefaultRESTMapperFromScheme(defaultGroupVersions []unversioned.GroupVersion, interfacesFunc meta.VersionInterfacesFunc,
 	importPathPrefix string, ignoredKinds, rootScoped sets.String, scheme *runtime.Scheme) *meta.DefaultRESTMapper {
@@ -38,6 +51,7 @@ func NewDefaultRESTMapperFromScheme(defaultGroupVersions []unversioned.GroupVers
 	mapper := meta.NewDefaultRESTMapper(defaultGroupVersions, interfacesFunc)
 	// enumerate all supported versions, get the kinds, and register with the mapper how to address
 	// our resources.
+	var allKinds []unversioned.GroupVersionKind
 	for _, gv := range defaultGroupVersions {
 		for kind, oType := range scheme.KnownTypes(gv) {
 			gvk := gv.WithKind(kind)
@@ -52,7 +66,11 @@ func NewDefaultRESTMapperFromScheme(defaultGroupVersions []unversioned.GroupVers
 				scope = meta.RESTScopeRoot
 			}
 			mapper.Add(gvk, scope)
+			if allCategoryKinds.Has(kind) {
+				allKinds = append(allKinds, gvk)
+			}
 		}
 	}
+	mapper.RegisterCategory("all", allKinds)
 	return mapper
 }
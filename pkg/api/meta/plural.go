@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import "sync"
+
+// pluralExceptions maps kind names to plural resource names for nouns whose
+// plural cannot be derived by appending "s", "es" or "ies". The table is
+// shared with the client-gen namers so that a single registration covers
+// both the RESTMapper and the generated clients.
+var (
+	pluralExceptionsLock sync.RWMutex
+	pluralExceptions     = map[string]string{
+		"Endpoints": "Endpoints",
+	}
+)
+
+// RegisterPluralException records an irregular plural for the given kind
+// name, e.g. RegisterPluralException("Endpoints", "Endpoints"). Extension
+// API authors should call this during initialization, before the kind is
+// added to a RESTMapper.
+func RegisterPluralException(kind, plural string) {
+	pluralExceptionsLock.Lock()
+	defer pluralExceptionsLock.Unlock()
+	pluralExceptions[kind] = plural
+}
+
+// PluralExceptions returns a copy of the pluralization exception table,
+// keyed by kind name. It is consumed by the client-gen namers.
+func PluralExceptions() map[string]string {
+	pluralExceptionsLock.RLock()
+	defer pluralExceptionsLock.RUnlock()
+
+	out := make(map[string]string, len(pluralExceptions))
+	for kind, plural := range pluralExceptions {
+		out[kind] = plural
+	}
+	return out
+}
+
+// pluralException returns the registered plural for the kind, if any.
+func pluralException(kind string) (string, bool) {
+	pluralExceptionsLock.RLock()
+	defer pluralExceptionsLock.RUnlock()
+	plural, ok := pluralExceptions[kind]
+	return plural, ok
+}
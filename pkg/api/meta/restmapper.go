@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/runtime"
@@ -72,6 +73,10 @@ var RESTScopeRoot = &restScope{
 type DefaultRESTMapper struct {
 	defaultGroupVersions []unversioned.GroupVersion
 
+	// lock guards the maps below; Add and the other mutators may run
+	// concurrently with lookups, for example during watch handler setup.
+	lock sync.RWMutex
+
 	resourceToKind       map[unversioned.GroupVersionResource]unversioned.GroupVersionKind
 	kindToPluralResource map[unversioned.GroupVersionKind]unversioned.GroupVersionResource
 	kindToScope          map[unversioned.GroupVersionKind]RESTScope
@@ -82,9 +87,15 @@ type DefaultRESTMapper struct {
 
 	// aliasToResource is used for mapping aliases to resources
 	aliasToResource map[string][]string
+
+	// categoryToGVKs maps category names, such as "all", to the kinds the
+	// category expands to
+	categoryToGVKs map[string][]unversioned.GroupVersionKind
 }
 
 func (m *DefaultRESTMapper) String() string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	return fmt.Sprintf("DefaultRESTMapper{kindToPluralResource=%v}", m.kindToPluralResource)
 }
 
@@ -106,6 +117,7 @@ func NewDefaultRESTMapper(defaultGroupVersions []unversioned.GroupVersion, f Ver
 	singularToPlural := make(map[unversioned.GroupVersionResource]unversioned.GroupVersionResource)
 	pluralToSingular := make(map[unversioned.GroupVersionResource]unversioned.GroupVersionResource)
 	aliasToResource := make(map[string][]string)
+	categoryToGVKs := make(map[string][]unversioned.GroupVersionKind)
 	// TODO: verify name mappings work correctly when versions differ
 
 	return &DefaultRESTMapper{
@@ -116,6 +128,7 @@ func NewDefaultRESTMapper(defaultGroupVersions []unversioned.GroupVersion, f Ver
 		singularToPlural:     singularToPlural,
 		pluralToSingular:     pluralToSingular,
 		aliasToResource:      aliasToResource,
+		categoryToGVKs:       categoryToGVKs,
 		interfacesFunc:       f,
 	}
 }
@@ -123,6 +136,9 @@ func NewDefaultRESTMapper(defaultGroupVersions []unversioned.GroupVersion, f Ver
 func (m *DefaultRESTMapper) Add(kind unversioned.GroupVersionKind, scope RESTScope) {
 	plural, singular := KindToResource(kind)
 
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
 	m.singularToPlural[singular] = plural
 	m.pluralToSingular[plural] = singular
 
@@ -152,6 +168,10 @@ func KindToResource(kind unversioned.GroupVersionKind) ( /*plural*/ unversioned.
 	singularName := strings.ToLower(kindName)
 	singular := kind.GroupVersion().WithResource(singularName)
 
+	if plural, ok := pluralException(kindName); ok {
+		return kind.GroupVersion().WithResource(strings.ToLower(plural)), singular
+	}
+
 	for _, skip := range unpluralizedSuffixes {
 		if strings.HasSuffix(singularName, skip) {
 			return singular, singular
@@ -178,6 +198,8 @@ func (m *DefaultRESTMapper) ResourceSingularizer(resourceType string) (string, e
 	}
 
 	singular := unversioned.GroupVersionResource{}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	for _, curr := range resources {
 		currSingular, ok := m.pluralToSingular[curr]
 		if !ok {
@@ -221,6 +243,8 @@ func (m *DefaultRESTMapper) ResourcesFor(input unversioned.GroupVersionResource)
 		return nil, fmt.Errorf("a resource must be present, got: %v", resource)
 	}
 
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	ret := []unversioned.GroupVersionResource{}
 	switch {
 	case hasGroup && hasVersion:
@@ -320,6 +344,8 @@ func (m *DefaultRESTMapper) KindsFor(input unversioned.GroupVersionResource) ([]
 		return nil, fmt.Errorf("a resource must be present, got: %v", resource)
 	}
 
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	ret := []unversioned.GroupVersionKind{}
 	switch {
 	// fully qualified.  Find the exact match
@@ -470,6 +496,8 @@ func (o resourceByPreferredGroupVersion) Less(i, j int) bool {
 // version should be used to access the named group/kind.
 // TODO: consider refactoring to use RESTMappings in a way that preserves version ordering and preference
 func (m *DefaultRESTMapper) RESTMapping(gk unversioned.GroupKind, versions ...string) (*RESTMapping, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	// Pick an appropriate version
 	var gvk *unversioned.GroupVersionKind
 	hadVersion := false
@@ -544,6 +572,8 @@ func (m *DefaultRESTMapper) RESTMapping(gk unversioned.GroupKind, versions ...st
 // RESTMappings returns the RESTMappings for the provided group kind in a rough internal preferred order. If no
 // kind is found it will return a NoResourceMatchError.
 func (m *DefaultRESTMapper) RESTMappings(gk unversioned.GroupKind) ([]*RESTMapping, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	// Use the default preferred versions
 	var mappings []*RESTMapping
 	for _, gv := range m.defaultGroupVersions {
@@ -589,13 +619,125 @@ func (m *DefaultRESTMapper) AddResourceAlias(alias string, resources ...string)
 	if len(resources) == 0 {
 		return
 	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	m.aliasToResource[alias] = resources
 }
 
 // AliasesForResource returns whether a resource has an alias or not
 func (m *DefaultRESTMapper) AliasesForResource(alias string) ([]string, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	if res, ok := m.aliasToResource[alias]; ok {
 		return res, true
 	}
 	return nil, false
 }
+
+// RegisterCategory registers a named category of kinds, such as "all", that
+// can later be expanded into resources with ResourcesForCategory. Registering
+// a category again replaces its previous kinds.
+func (m *DefaultRESTMapper) RegisterCategory(name string, gvks []unversioned.GroupVersionKind) {
+	if len(gvks) == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.categoryToGVKs[name] = gvks
+}
+
+// ResourcesForCategory expands a category registered with RegisterCategory
+// into the plural resources of its kinds. Kinds that have not been added to
+// the mapper are skipped, so a category may name kinds that only exist in
+// other group mappers.
+func (m *DefaultRESTMapper) ResourcesForCategory(name string) ([]unversioned.GroupVersionResource, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	gvks, ok := m.categoryToGVKs[name]
+	if !ok {
+		return nil, fmt.Errorf("no category %q has been registered", name)
+	}
+	resources := []unversioned.GroupVersionResource{}
+	for _, gvk := range gvks {
+		plural, ok := m.kindToPluralResource[gvk]
+		if !ok {
+			continue
+		}
+		resources = append(resources, plural)
+	}
+	sort.Sort(resourcesByGroupVersionResource(resources))
+	return resources, nil
+}
+
+// RegisteredResource describes a single resource registered with a
+// DefaultRESTMapper via Add, carrying both the plural and singular forms of
+// the resource and whether it is namespaced or root scoped.
+type RegisteredResource struct {
+	Kind     unversioned.GroupVersionKind
+	Plural   unversioned.GroupVersionResource
+	Singular unversioned.GroupVersionResource
+	Scope    RESTScope
+}
+
+type resourcesByGroupVersionResource []unversioned.GroupVersionResource
+
+func (s resourcesByGroupVersionResource) Len() int      { return len(s) }
+func (s resourcesByGroupVersionResource) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s resourcesByGroupVersionResource) Less(i, j int) bool {
+	return s[i].String() < s[j].String()
+}
+
+type registeredResourcesByKind []RegisteredResource
+
+func (s registeredResourcesByKind) Len() int      { return len(s) }
+func (s registeredResourcesByKind) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s registeredResourcesByKind) Less(i, j int) bool {
+	return s[i].Kind.String() < s[j].Kind.String()
+}
+
+// Resources returns every resource registered with the mapper via Add, in
+// both plural and singular form, sorted for deterministic output. It returns
+// an error if nothing has been registered.
+func (m *DefaultRESTMapper) Resources() ([]unversioned.GroupVersionResource, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if len(m.pluralToSingular) == 0 {
+		return nil, fmt.Errorf("no resources have been registered")
+	}
+	resources := []unversioned.GroupVersionResource{}
+	for plural, singular := range m.pluralToSingular {
+		resources = append(resources, plural)
+		// Some resources, such as endpoints, have identical plural and
+		// singular forms and are only listed once.
+		if singular != plural {
+			resources = append(resources, singular)
+		}
+	}
+	sort.Sort(resourcesByGroupVersionResource(resources))
+	return resources, nil
+}
+
+// KindsForGroupVersion returns a RegisteredResource for every kind registered
+// with the mapper via Add under the given group version, sorted by kind. It
+// returns an error if no kinds are registered for the group version.
+func (m *DefaultRESTMapper) KindsForGroupVersion(gv unversioned.GroupVersion) ([]RegisteredResource, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	ret := []RegisteredResource{}
+	for kind, plural := range m.kindToPluralResource {
+		if kind.GroupVersion() != gv {
+			continue
+		}
+		ret = append(ret, RegisteredResource{
+			Kind:     kind,
+			Plural:   plural,
+			Singular: m.pluralToSingular[plural],
+			Scope:    m.kindToScope[kind],
+		})
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no kinds have been registered for group version %v", gv)
+	}
+	sort.Sort(registeredResourcesByKind(ret))
+	return ret, nil
+}
@@ -18,8 +18,10 @@ package meta
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"k8s.io/kubernetes/pkg/api/unversioned"
@@ -467,6 +469,10 @@ func TestRESTMapperResourcesFor(t *testing.T) {
 }
 
 func TestKindToResource(t *testing.T) {
+	// A registered exception overrides the suffix rules for both the
+	// RESTMapper and the generated clients.
+	RegisterPluralException("Alumnus", "Alumni")
+
 	testCases := []struct {
 		Kind             string
 		Plural, Singular string
@@ -481,6 +487,10 @@ func TestKindToResource(t *testing.T) {
 		{Kind: "miss", Plural: "misses", Singular: "miss"},
 		// Add "s" otherwise
 		{Kind: "lowercase", Plural: "lowercases", Singular: "lowercase"},
+
+		// Covered by the pluralization exception table
+		{Kind: "Endpoints", Plural: "endpoints", Singular: "endpoints"},
+		{Kind: "Alumnus", Plural: "alumni", Singular: "alumnus"},
 	}
 	for i, testCase := range testCases {
 		version := unversioned.GroupVersion{}
@@ -663,3 +673,158 @@ func TestRESTMapperReportsErrorOnBadVersion(t *testing.T) {
 		t.Errorf("unexpected non-error")
 	}
 }
+
+func TestRESTMapperResources(t *testing.T) {
+	gv1 := unversioned.GroupVersion{Group: "tgroup", Version: "test1"}
+	gv2 := unversioned.GroupVersion{Group: "tgroup2", Version: "test2"}
+
+	mapper := NewDefaultRESTMapper([]unversioned.GroupVersion{gv1, gv2}, fakeInterfaces)
+	if _, err := mapper.Resources(); err == nil {
+		t.Errorf("expected error for an empty mapper")
+	}
+
+	mapper.Add(gv1.WithKind("InternalObject"), RESTScopeNamespace)
+	mapper.Add(gv1.WithKind("Policy"), RESTScopeNamespace)
+	mapper.Add(gv2.WithKind("ClusterObject"), RESTScopeRoot)
+
+	resources, err := mapper.Resources()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []unversioned.GroupVersionResource{
+		gv1.WithResource("internalobject"),
+		gv1.WithResource("internalobjects"),
+		gv1.WithResource("policies"),
+		gv1.WithResource("policy"),
+		gv2.WithResource("clusterobject"),
+		gv2.WithResource("clusterobjects"),
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected resources %v, got %v", expected, resources)
+	}
+}
+
+func TestRESTMapperKindsForGroupVersion(t *testing.T) {
+	gv1 := unversioned.GroupVersion{Group: "tgroup", Version: "test1"}
+	gv2 := unversioned.GroupVersion{Group: "tgroup2", Version: "test2"}
+
+	mapper := NewDefaultRESTMapper([]unversioned.GroupVersion{gv1, gv2}, fakeInterfaces)
+	mapper.Add(gv1.WithKind("InternalObject"), RESTScopeNamespace)
+	mapper.Add(gv1.WithKind("ClusterObject"), RESTScopeRoot)
+	mapper.Add(gv2.WithKind("OtherObject"), RESTScopeNamespace)
+
+	registered, err := mapper.KindsForGroupVersion(gv1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []RegisteredResource{
+		{
+			Kind:     gv1.WithKind("ClusterObject"),
+			Plural:   gv1.WithResource("clusterobjects"),
+			Singular: gv1.WithResource("clusterobject"),
+			Scope:    RESTScopeRoot,
+		},
+		{
+			Kind:     gv1.WithKind("InternalObject"),
+			Plural:   gv1.WithResource("internalobjects"),
+			Singular: gv1.WithResource("internalobject"),
+			Scope:    RESTScopeNamespace,
+		},
+	}
+	if !reflect.DeepEqual(registered, expected) {
+		t.Errorf("expected registered resources %v, got %v", expected, registered)
+	}
+
+	if _, err := mapper.KindsForGroupVersion(unversioned.GroupVersion{Group: "other", Version: "v9"}); err == nil {
+		t.Errorf("expected error for a group version with no registered kinds")
+	}
+}
+
+func TestRESTMapperResourcesForCategory(t *testing.T) {
+	corev1 := unversioned.GroupVersion{Version: "v1"}
+	extensionsv1beta1 := unversioned.GroupVersion{Group: "extensions", Version: "v1beta1"}
+
+	mapper := NewDefaultRESTMapper([]unversioned.GroupVersion{corev1, extensionsv1beta1}, fakeInterfaces)
+	mapper.Add(corev1.WithKind("Pod"), RESTScopeNamespace)
+	mapper.Add(corev1.WithKind("ReplicationController"), RESTScopeNamespace)
+	mapper.Add(corev1.WithKind("Service"), RESTScopeNamespace)
+	mapper.Add(corev1.WithKind("Node"), RESTScopeRoot)
+	mapper.Add(extensionsv1beta1.WithKind("ReplicaSet"), RESTScopeNamespace)
+
+	mapper.RegisterCategory("all", []unversioned.GroupVersionKind{
+		corev1.WithKind("Pod"),
+		corev1.WithKind("ReplicationController"),
+		corev1.WithKind("Service"),
+		extensionsv1beta1.WithKind("ReplicaSet"),
+		// A kind the category names but this mapper does not know; it must
+		// be skipped rather than fail the expansion.
+		extensionsv1beta1.WithKind("Deployment"),
+	})
+
+	resources, err := mapper.ResourcesForCategory("all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[unversioned.GroupVersionResource]bool{
+		corev1.WithResource("pods"):                   false,
+		corev1.WithResource("replicationcontrollers"): false,
+		corev1.WithResource("services"):               false,
+		extensionsv1beta1.WithResource("replicasets"): false,
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("expected %d resources, got %v", len(expected), resources)
+	}
+	for _, resource := range resources {
+		if _, ok := expected[resource]; !ok {
+			t.Errorf("unexpected resource in category: %v", resource)
+		}
+		expected[resource] = true
+	}
+	for resource, found := range expected {
+		if !found {
+			t.Errorf("resource %v missing from category", resource)
+		}
+	}
+
+	if _, err := mapper.ResourcesForCategory("bogus"); err == nil {
+		t.Errorf("expected error for an unregistered category")
+	}
+}
+
+func TestRESTMapperConcurrentAddAndLookup(t *testing.T) {
+	mapper := NewDefaultRESTMapper([]unversioned.GroupVersion{{Group: "tgroup", Version: "test"}}, fakeInterfaces)
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 100; j++ {
+				gvk := unversioned.GroupVersionKind{Group: "tgroup", Version: "test", Kind: fmt.Sprintf("Kind%d_%d", i, j)}
+				mapper.Add(gvk, RESTScopeNamespace)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			for j := 0; j < 100; j++ {
+				// The result does not matter; running concurrently with Add
+				// under -race is the point.
+				mapper.KindFor(unversioned.GroupVersionResource{Resource: fmt.Sprintf("kind%d_%ds", i, j)})
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 100; j++ {
+			resource := unversioned.GroupVersionResource{Resource: fmt.Sprintf("kind%d_%ds", i, j)}
+			if _, err := mapper.KindFor(resource); err != nil {
+				t.Errorf("unexpected error looking up %v after concurrent adds: %v", resource, err)
+			}
+		}
+	}
+}
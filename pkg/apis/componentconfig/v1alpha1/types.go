@@ -240,6 +240,14 @@ type KubeletConfiguration struct {
 	// pulls to burst to this number, while still not exceeding registryQps.
 	// Only used if registryQPS > 0.
 	RegistryBurst int32 `json:"registryBurst"`
+	// allowedImageRegistries is a list of glob patterns over the
+	// registry/repository portion of an image reference. If non-empty, only
+	// images matching one of the patterns may be pulled.
+	AllowedImageRegistries []string `json:"allowedImageRegistries"`
+	// deniedImageRegistries is a list of glob patterns over the
+	// registry/repository portion of an image reference. Matching images are
+	// never pulled, even if they also match an allowed pattern.
+	DeniedImageRegistries []string `json:"deniedImageRegistries"`
 	// eventRecordQPS is the maximum event creations per second. If 0, there
 	// is no limit enforced.
 	EventRecordQPS *int32 `json:"eventRecordQPS"`
@@ -424,6 +432,11 @@ type KubeletConfiguration struct {
 	// run docker daemon with version  < 1.9 or an Aufs storage backend.
 	// Issue #10959 has more details.
 	SerializeImagePulls *bool `json:"serializeImagePulls"`
+	// imagePullTimeout is the maximum duration a single image pull may take
+	// before the kubelet gives up on it and retries with back-off. A pod's
+	// remaining activeDeadlineSeconds may bound a pull further. Zero means
+	// pulls are not bounded by the kubelet.
+	ImagePullTimeout unversioned.Duration `json:"imagePullTimeout"`
 	// experimentalFlannelOverlay enables experimental support for starting the
 	// kubelet with the default overlay network (flannel). Assumes flanneld
 	// is already running in client mode.
@@ -175,6 +175,20 @@ func DeepCopy_v1alpha1_KubeletConfiguration(in interface{}, out interface{}, c *
 			out.RegistryPullQPS = nil
 		}
 		out.RegistryBurst = in.RegistryBurst
+		if in.AllowedImageRegistries != nil {
+			in, out := &in.AllowedImageRegistries, &out.AllowedImageRegistries
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		} else {
+			out.AllowedImageRegistries = nil
+		}
+		if in.DeniedImageRegistries != nil {
+			in, out := &in.DeniedImageRegistries, &out.DeniedImageRegistries
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		} else {
+			out.DeniedImageRegistries = nil
+		}
 		if in.EventRecordQPS != nil {
 			in, out := &in.EventRecordQPS, &out.EventRecordQPS
 			*out = new(int32)
@@ -331,6 +345,7 @@ func DeepCopy_v1alpha1_KubeletConfiguration(in interface{}, out interface{}, c *
 		} else {
 			out.SerializeImagePulls = nil
 		}
+		out.ImagePullTimeout = in.ImagePullTimeout
 		out.ExperimentalFlannelOverlay = in.ExperimentalFlannelOverlay
 		out.OutOfDiskTransitionFrequency = in.OutOfDiskTransitionFrequency
 		out.NodeIP = in.NodeIP
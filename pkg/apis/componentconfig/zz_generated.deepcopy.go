@@ -237,6 +237,20 @@ func DeepCopy_componentconfig_KubeletConfiguration(in interface{}, out interface
 		}
 		out.RegistryPullQPS = in.RegistryPullQPS
 		out.RegistryBurst = in.RegistryBurst
+		if in.AllowedImageRegistries != nil {
+			in, out := &in.AllowedImageRegistries, &out.AllowedImageRegistries
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		} else {
+			out.AllowedImageRegistries = nil
+		}
+		if in.DeniedImageRegistries != nil {
+			in, out := &in.DeniedImageRegistries, &out.DeniedImageRegistries
+			*out = make([]string, len(*in))
+			copy(*out, *in)
+		} else {
+			out.DeniedImageRegistries = nil
+		}
 		out.EventRecordQPS = in.EventRecordQPS
 		out.EventBurst = in.EventBurst
 		out.EnableDebuggingHandlers = in.EnableDebuggingHandlers
@@ -297,6 +311,7 @@ func DeepCopy_componentconfig_KubeletConfiguration(in interface{}, out interface
 		out.KubeAPIQPS = in.KubeAPIQPS
 		out.KubeAPIBurst = in.KubeAPIBurst
 		out.SerializeImagePulls = in.SerializeImagePulls
+		out.ImagePullTimeout = in.ImagePullTimeout
 		out.ExperimentalFlannelOverlay = in.ExperimentalFlannelOverlay
 		out.OutOfDiskTransitionFrequency = in.OutOfDiskTransitionFrequency
 		out.NodeIP = in.NodeIP
@@ -91,6 +91,12 @@ type APIGroupVersion struct {
 
 	MinRequestTimeout time.Duration
 
+	// MaxRequestBodyBytes bounds the size of request bodies read into memory
+	// by the create, update, patch and delete handlers. Watch, connect and
+	// proxied requests stream and are not subject to the limit. If zero, a
+	// default of a few megabytes is applied.
+	MaxRequestBodyBytes int64
+
 	// SubresourceGroupVersionKind contains the GroupVersionKind overrides for each subresource that is
 	// accessible from this API group version. The GroupVersionKind is that of the external version of
 	// the subresource. The key of this map should be the path of the subresource. The keys here should
@@ -132,13 +138,42 @@ func (g *APIGroupVersion) InstallREST(container *restful.Container) error {
 	apiResources, registrationErrors := installer.Install(ws)
 	lister := g.ResourceLister
 	if lister == nil {
-		lister = staticLister{apiResources}
+		lister = staticLister{g.apiResourcesFromMapper(apiResources)}
 	}
 	AddSupportedResourcesWebService(g.Serializer, ws, g.GroupVersion, lister)
 	container.Add(ws)
 	return utilerrors.NewAggregate(registrationErrors)
 }
 
+// apiResourcesFromMapper makes the mapper registrations the source of truth
+// for the kind and scope of the resources reported by discovery, rather than
+// the installer's accumulation. Subresources and storages the mapper does not
+// know about keep their installer-provided entries, so the output stays
+// identical for currently-registered types.
+func (g *APIGroupVersion) apiResourcesFromMapper(installed []unversioned.APIResource) []unversioned.APIResource {
+	mapper, ok := g.Mapper.(*meta.DefaultRESTMapper)
+	if !ok {
+		return installed
+	}
+	registered, err := mapper.KindsForGroupVersion(g.GroupVersion)
+	if err != nil {
+		return installed
+	}
+	byPlural := make(map[string]meta.RegisteredResource, len(registered))
+	for _, r := range registered {
+		byPlural[r.Plural.Resource] = r
+	}
+	resources := make([]unversioned.APIResource, 0, len(installed))
+	for _, resource := range installed {
+		if r, found := byPlural[resource.Name]; found {
+			resource.Kind = r.Kind.Kind
+			resource.Namespaced = r.Scope.Name() == meta.RESTScopeNameNamespace
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
 // UpdateREST registers the REST handlers for this APIGroupVersion to an existing web service
 // in the restful Container.  It will use the prefix (root/version) to find the existing
 // web service.  If a web service does not exist within the container to support the prefix
@@ -160,7 +195,7 @@ func (g *APIGroupVersion) UpdateREST(container *restful.Container) error {
 	apiResources, registrationErrors := installer.Install(ws)
 	lister := g.ResourceLister
 	if lister == nil {
-		lister = staticLister{apiResources}
+		lister = staticLister{g.apiResourcesFromMapper(apiResources)}
 	}
 	AddSupportedResourcesWebService(g.Serializer, ws, g.GroupVersion, lister)
 	return utilerrors.NewAggregate(registrationErrors)
@@ -475,7 +510,28 @@ func parseTimeout(str string) time.Duration {
 	return 30 * time.Second
 }
 
-func readBody(req *http.Request) ([]byte, error) {
+// defaultMaxRequestBodyBytes bounds how much of a request body the apiserver
+// is willing to read into memory when no explicit limit is configured on the
+// APIGroupVersion.
+const defaultMaxRequestBodyBytes int64 = 3 * 1024 * 1024
+
+// readBody reads at most limit bytes of the request body into memory; a limit
+// of zero or less applies the default. Oversized bodies are rejected with a
+// 413 status error naming the limit. Watch, connect and proxied requests
+// stream their data instead of passing through here, so they are not subject
+// to the limit.
+func readBody(w http.ResponseWriter, req *http.Request, limit int64) ([]byte, error) {
 	defer req.Body.Close()
-	return ioutil.ReadAll(req.Body)
+	if limit <= 0 {
+		limit = defaultMaxRequestBodyBytes
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, limit))
+	if err != nil {
+		// MaxBytesReader does not expose a typed error for exceeding the limit.
+		if err.Error() == "http: request body too large" {
+			return nil, apierrors.NewRequestEntityTooLargeError(fmt.Sprintf("limit is %d bytes", limit))
+		}
+		return nil, err
+	}
+	return data, nil
 }
@@ -378,6 +378,10 @@ type SimpleRESTStorage struct {
 	// If non-nil, called inside the WorkFunc when answering update, delete, create.
 	// obj receives the original input to the update, delete, or create call.
 	injectedFunction func(obj runtime.Object) (returnObj runtime.Object, err error)
+
+	// If non-nil, List blocks on this channel before returning, so tests
+	// can hold a not-older-than read past its timeout.
+	blockList chan struct{}
 }
 
 func (storage *SimpleRESTStorage) Export(ctx api.Context, name string, opts unversioned.ExportOptions) (runtime.Object, error) {
@@ -408,6 +412,13 @@ func (storage *SimpleRESTStorage) List(ctx api.Context, options *api.ListOptions
 	if options != nil && options.FieldSelector != nil {
 		storage.requestedFieldSelector = options.FieldSelector
 	}
+	storage.requestedResourceVersion = ""
+	if options != nil {
+		storage.requestedResourceVersion = options.ResourceVersion
+	}
+	if storage.blockList != nil {
+		<-storage.blockList
+	}
 	return result, storage.errors["list"]
 }
 
@@ -1065,6 +1076,68 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListResourceVersionReadModes(t *testing.T) {
+	testCases := []struct {
+		query           string
+		resourceVersion string
+	}{
+		// No resourceVersion keeps the quorum read.
+		{"", ""},
+		// resourceVersion=0 may be served from the watch cache.
+		{"?resourceVersion=0", "0"},
+		// A specific resourceVersion asks for a not-older-than read.
+		{"?resourceVersion=10", "10"},
+	}
+	for i, testCase := range testCases {
+		storage := map[string]rest.Storage{}
+		simpleStorage := SimpleRESTStorage{}
+		storage["simple"] = &simpleStorage
+		handler := handle(storage)
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/" + prefix + "/" + testGroupVersion.Group + "/" + testGroupVersion.Version + "/simple" + testCase.query)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %v", i, err)
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%d: unexpected status: %d, Expected: %d, %#v", i, resp.StatusCode, http.StatusOK, resp)
+			continue
+		}
+		if simpleStorage.requestedResourceVersion != testCase.resourceVersion {
+			t.Errorf("%d: unexpected resource version: %q, Expected: %q", i, simpleStorage.requestedResourceVersion, testCase.resourceVersion)
+		}
+	}
+}
+
+func TestListNotOlderThanTimeout(t *testing.T) {
+	storage := map[string]rest.Storage{}
+	simpleStorage := SimpleRESTStorage{
+		blockList: make(chan struct{}),
+	}
+	defer close(simpleStorage.blockList)
+	storage["simple"] = &simpleStorage
+	handler := handle(storage)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// The storage cannot catch up to resourceVersion 10 within the request
+	// timeout, so the request should fail with a retryable timeout.
+	resp, err := http.Get(server.URL + "/" + prefix + "/" + testGroupVersion.Group + "/" + testGroupVersion.Version + "/simple?resourceVersion=10&timeoutSeconds=1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("Unexpected status: %d, Expected: %d, %#v", resp.StatusCode, http.StatusGatewayTimeout, resp)
+	}
+	if resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("Unexpected Retry-After header: %v", resp.Header)
+	}
+}
+
 func TestLogs(t *testing.T) {
 	handler := handle(map[string]rest.Storage{})
 	server := httptest.NewServer(handler)
@@ -2895,6 +2968,35 @@ func expectApiStatus(t *testing.T, method, url string, data []byte, code int) *u
 	return &status
 }
 
+func TestCreateRejectsOversizedBody(t *testing.T) {
+	storage := SimpleRESTStorage{}
+	handler := handle(map[string]rest.Storage{"foo": &storage})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	data := bytes.Repeat([]byte("x"), int(defaultMaxRequestBodyBytes)+1)
+	status := expectApiStatus(t, "POST", fmt.Sprintf("%s/"+prefix+"/"+testGroupVersion.Group+"/"+testGroupVersion.Version+"/namespaces/default/foo", server.URL), data, http.StatusRequestEntityTooLarge)
+	if status.Status != unversioned.StatusFailure || status.Reason != unversioned.StatusReasonRequestEntityTooLarge {
+		t.Errorf("Unexpected status %#v", status)
+	}
+	if !strings.Contains(status.Message, fmt.Sprintf("%d", defaultMaxRequestBodyBytes)) {
+		t.Errorf("expected the limit in the message, got: %q", status.Message)
+	}
+}
+
+func TestUpdateRejectsOversizedBody(t *testing.T) {
+	storage := SimpleRESTStorage{}
+	handler := handle(map[string]rest.Storage{"foo": &storage})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	data := bytes.Repeat([]byte("x"), int(defaultMaxRequestBodyBytes)+1)
+	status := expectApiStatus(t, "PUT", fmt.Sprintf("%s/"+prefix+"/"+testGroupVersion.Group+"/"+testGroupVersion.Version+"/namespaces/default/foo/bar", server.URL), data, http.StatusRequestEntityTooLarge)
+	if status.Status != unversioned.StatusFailure || status.Reason != unversioned.StatusReasonRequestEntityTooLarge {
+		t.Errorf("Unexpected status %#v", status)
+	}
+}
+
 func TestDelayReturnsError(t *testing.T) {
 	storage := SimpleRESTStorage{
 		injectedFunction: func(obj runtime.Object) (runtime.Object, error) {
@@ -22,6 +22,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -70,6 +71,39 @@ var _ http.CloseNotifier = &fancyResponseWriterDelegator{}
 var _ http.Flusher = &fancyResponseWriterDelegator{}
 var _ http.Hijacker = &fancyResponseWriterDelegator{}
 
+// AuditIDHeader is the response header carrying the id of the audit log
+// entries written for the request, so callers can correlate their request
+// with the audit trail.
+const AuditIDHeader = "Audit-ID"
+
+// AuditPolicy selects which requests are audited. The zero value audits
+// every request.
+type AuditPolicy struct {
+	// ExcludeReadOnly suppresses audit logging of read-only requests
+	// (GET, HEAD and OPTIONS).
+	ExcludeReadOnly bool
+	// ExcludePathPrefixes suppresses audit logging of requests whose URL path
+	// starts with one of the given prefixes, such as /healthz or /metrics.
+	ExcludePathPrefixes []string
+}
+
+// auditsRequest returns whether the policy wants an audit trail for the
+// given request.
+func (p AuditPolicy) auditsRequest(req *http.Request) bool {
+	if p.ExcludeReadOnly {
+		switch req.Method {
+		case "GET", "HEAD", "OPTIONS":
+			return false
+		}
+	}
+	for _, prefix := range p.ExcludePathPrefixes {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
 // WithAudit decorates a http.Handler with audit logging information for all the
 // requests coming to the server. If out is nil, no decoration takes place.
 // Each audit log contains two entries:
@@ -84,11 +118,22 @@ var _ http.Hijacker = &fancyResponseWriterDelegator{}
 // 2. the response line containing:
 //    - the unique id from 1
 //    - response code
+// The audit id is also returned to the caller in the Audit-ID response header.
 func WithAudit(handler http.Handler, attributeGetter RequestAttributeGetter, out io.Writer) http.Handler {
+	return WithAuditPolicy(handler, attributeGetter, out, AuditPolicy{})
+}
+
+// WithAuditPolicy is WithAudit restricted to the requests selected by the
+// given policy.
+func WithAuditPolicy(handler http.Handler, attributeGetter RequestAttributeGetter, out io.Writer, policy AuditPolicy) http.Handler {
 	if out == nil {
 		return handler
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !policy.auditsRequest(req) {
+			handler.ServeHTTP(w, req)
+			return
+		}
 		attribs, err := attributeGetter.GetAttribs(req)
 		if err != nil {
 			internalError(w, req, err)
@@ -109,6 +154,7 @@ func WithAudit(handler http.Handler, attributeGetter RequestAttributeGetter, out
 		if _, err := fmt.Fprint(out, line); err != nil {
 			glog.Errorf("Unable to write audit log: %s, the error is: %v", line, err)
 		}
+		w.Header().Set(AuditIDHeader, id)
 		respWriter := decorateResponseWriter(w, out, id)
 		handler.ServeHTTP(respWriter, req)
 	})
@@ -81,7 +81,11 @@ func TestAudit(t *testing.T) {
 
 	req, _ := http.NewRequest("GET", "/api/v1/namespaces/default/pods", nil)
 	req.RemoteAddr = "127.0.0.1"
-	handler.ServeHTTP(httptest.NewRecorder(), req)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	if id := recorder.Header().Get(AuditIDHeader); id == "" {
+		t.Errorf("Expected %s response header to be set", AuditIDHeader)
+	}
 	line := strings.Split(strings.TrimSpace(buf.String()), "\n")
 	if len(line) != 2 {
 		t.Fatalf("Unexpected amount of lines in audit log: %d", len(line))
@@ -102,6 +106,67 @@ func TestAudit(t *testing.T) {
 	}
 }
 
+func TestAuditPolicy(t *testing.T) {
+	attributeGetter := NewRequestAttributeGetter(&fakeRequestContextMapper{
+		user: &user.DefaultInfo{Name: "admin"},
+	})
+
+	tests := []struct {
+		name    string
+		policy  AuditPolicy
+		method  string
+		path    string
+		audited bool
+	}{
+		{
+			"zero-policy-audits-everything",
+			AuditPolicy{},
+			"GET", "/api/v1/namespaces/default/pods",
+			true,
+		},
+		{
+			"read-only-excluded",
+			AuditPolicy{ExcludeReadOnly: true},
+			"GET", "/api/v1/namespaces/default/pods",
+			false,
+		},
+		{
+			"write-audited-despite-read-only-exclusion",
+			AuditPolicy{ExcludeReadOnly: true},
+			"POST", "/api/v1/namespaces/default/pods",
+			true,
+		},
+		{
+			"excluded-path-prefix",
+			AuditPolicy{ExcludePathPrefixes: []string{"/healthz", "/metrics"}},
+			"GET", "/healthz/ping",
+			false,
+		},
+		{
+			"non-excluded-path-prefix",
+			AuditPolicy{ExcludePathPrefixes: []string{"/healthz", "/metrics"}},
+			"GET", "/api/v1/namespaces/default/pods",
+			true,
+		},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		handler := WithAuditPolicy(&fakeHTTPHandler{}, attributeGetter, &buf, test.policy)
+		req, _ := http.NewRequest(test.method, test.path, nil)
+		req.RemoteAddr = "127.0.0.1"
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		audited := buf.Len() > 0
+		if audited != test.audited {
+			t.Errorf("%s: expected audited=%v, got audit log %q", test.name, test.audited, buf.String())
+		}
+		hasID := recorder.Header().Get(AuditIDHeader) != ""
+		if hasID != test.audited {
+			t.Errorf("%s: expected %s header presence to be %v", test.name, AuditIDHeader, test.audited)
+		}
+	}
+}
+
 type fakeRequestContextMapper struct {
 	user *user.DefaultInfo
 }
@@ -81,6 +81,10 @@ type RequestScope struct {
 	Resource    unversioned.GroupVersionResource
 	Kind        unversioned.GroupVersionKind
 	Subresource string
+
+	// MaxRequestBodyBytes bounds the size of request bodies read into memory.
+	// If zero, readBody applies its default.
+	MaxRequestBodyBytes int64
 }
 
 func (scope *RequestScope) err(err error, w http.ResponseWriter, req *http.Request) {
@@ -231,6 +235,19 @@ func (r *responder) Error(err error) {
 }
 
 // ListResource returns a function that handles retrieving a list of resources from a rest.Storage object.
+// defaultListTimeout bounds a list request that may block waiting for the
+// watch cache to catch up, when neither the request nor the server specify
+// a timeout.
+const defaultListTimeout = time.Minute
+
+// isNotOlderThanListRead returns true if the list options ask for a result at
+// least as fresh as a specific resourceVersion. An empty resourceVersion is a
+// quorum read and "0" may be served straight from the watch cache; neither
+// should block.
+func isNotOlderThanListRead(opts *api.ListOptions) bool {
+	return opts.ResourceVersion != "" && opts.ResourceVersion != "0"
+}
+
 func ListResource(r rest.Lister, rw rest.Watcher, scope RequestScope, forceWatch bool, minRequestTimeout time.Duration) restful.RouteFunction {
 	return func(req *restful.Request, res *restful.Response) {
 		// For performance tracking purposes.
@@ -313,7 +330,35 @@ func ListResource(r rest.Lister, rw rest.Watcher, scope RequestScope, forceWatch
 		// Log only long List requests (ignore Watch).
 		defer trace.LogIfLong(500 * time.Millisecond)
 		trace.Step("About to List from storage")
-		result, err := r.List(ctx, &opts)
+		// The resourceVersion in the list options carries read semantics which
+		// the storage honors: an empty version keeps the quorum read from etcd,
+		// "0" may be served from the watch cache, and any other value asks for
+		// a result at least as fresh as that version, for which the storage may
+		// block until its cache catches up. Bound such not-older-than reads
+		// with the request timeout, so a lagging cache produces a retryable
+		// timeout instead of a stuck request.
+		var result runtime.Object
+		if isNotOlderThanListRead(&opts) {
+			timeout := time.Duration(0)
+			if opts.TimeoutSeconds != nil {
+				timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+			}
+			if timeout == 0 {
+				if minRequestTimeout > 0 {
+					timeout = minRequestTimeout
+				} else {
+					timeout = defaultListTimeout
+				}
+			}
+			result, err = finishRequest(timeout, func() (runtime.Object, error) {
+				return r.List(ctx, &opts)
+			})
+			if status, ok := err.(*errors.StatusError); ok && status.Status().Reason == unversioned.StatusReasonTimeout {
+				err = errors.NewTimeoutError(fmt.Sprintf("the server could not produce a list not older than resourceVersion %s in the allowed time; try again", opts.ResourceVersion), 1)
+			}
+		} else {
+			result, err = r.List(ctx, &opts)
+		}
 		if err != nil {
 			scope.err(err, res.ResponseWriter, req.Request)
 			return
@@ -336,6 +381,7 @@ func createHandler(r rest.NamedCreater, scope RequestScope, typer runtime.Object
 		trace := util.NewTrace("Create " + req.Request.URL.Path)
 		defer trace.LogIfLong(250 * time.Millisecond)
 
+		span := spanForRequest(req.Request)
 		w := res.ResponseWriter
 
 		// TODO: we either want to remove timeout or document it (if we document, move timeout out of this function and declare it in api_installer)
@@ -366,7 +412,7 @@ func createHandler(r rest.NamedCreater, scope RequestScope, typer runtime.Object
 		}
 		decoder := scope.Serializer.DecoderToVersion(s, unversioned.GroupVersion{Group: gv.Group, Version: runtime.APIVersionInternal})
 
-		body, err := readBody(req.Request)
+		body, err := readBody(res.ResponseWriter, req.Request, scope.MaxRequestBodyBytes)
 		if err != nil {
 			scope.err(err, res.ResponseWriter, req.Request)
 			return
@@ -396,6 +442,7 @@ func createHandler(r rest.NamedCreater, scope RequestScope, typer runtime.Object
 				scope.err(err, res.ResponseWriter, req.Request)
 				return
 			}
+			span.LogEvent("Admission control done")
 		}
 
 		trace.Step("About to store object in database")
@@ -411,6 +458,7 @@ func createHandler(r rest.NamedCreater, scope RequestScope, typer runtime.Object
 			return
 		}
 		trace.Step("Object stored in database")
+		span.LogEvent("Object stored in database")
 
 		if err := setSelfLink(result, req, scope.Namer); err != nil {
 			scope.err(err, res.ResponseWriter, req.Request)
@@ -419,6 +467,7 @@ func createHandler(r rest.NamedCreater, scope RequestScope, typer runtime.Object
 		trace.Step("Self-link added")
 
 		write(http.StatusCreated, scope.Kind.GroupVersion(), scope.Serializer, result, w, req.Request)
+		span.LogEvent("Response serialized")
 	}
 }
 
@@ -474,7 +523,7 @@ func PatchResource(r rest.Patcher, scope RequestScope, typer runtime.ObjectTyper
 		}
 		patchType := api.PatchType(contentType)
 
-		patchJS, err := readBody(req.Request)
+		patchJS, err := readBody(res.ResponseWriter, req.Request, scope.MaxRequestBodyBytes)
 		if err != nil {
 			scope.err(err, res.ResponseWriter, req.Request)
 			return
@@ -671,7 +720,7 @@ func UpdateResource(r rest.Updater, scope RequestScope, typer runtime.ObjectType
 		ctx := scope.ContextFunc(req)
 		ctx = api.WithNamespace(ctx, namespace)
 
-		body, err := readBody(req.Request)
+		body, err := readBody(res.ResponseWriter, req.Request, scope.MaxRequestBodyBytes)
 		if err != nil {
 			scope.err(err, res.ResponseWriter, req.Request)
 			return
@@ -760,7 +809,7 @@ func DeleteResource(r rest.GracefulDeleter, checkBody bool, scope RequestScope,
 
 		options := &api.DeleteOptions{}
 		if checkBody {
-			body, err := readBody(req.Request)
+			body, err := readBody(res.ResponseWriter, req.Request, scope.MaxRequestBodyBytes)
 			if err != nil {
 				scope.err(err, res.ResponseWriter, req.Request)
 				return
@@ -877,7 +926,7 @@ func DeleteCollection(r rest.CollectionDeleter, checkBody bool, scope RequestSco
 
 		options := &api.DeleteOptions{}
 		if checkBody {
-			body, err := readBody(req.Request)
+			body, err := readBody(res.ResponseWriter, req.Request, scope.MaxRequestBodyBytes)
 			if err != nil {
 				scope.err(err, res.ResponseWriter, req.Request)
 				return
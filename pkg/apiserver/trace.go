@@ -0,0 +1,171 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util"
+
+	"github.com/emicklei/go-restful"
+	"golang.org/x/net/context"
+)
+
+// Tracer produces spans for API server requests. The interface mirrors the
+// OpenTracing API, so an OpenTracing implementation can be plugged in with a
+// thin adapter. The default tracer records spans as util.Trace objects and
+// logs the slow ones, matching the existing per-handler tracing.
+type Tracer interface {
+	// StartSpan begins a span for the named operation.
+	StartSpan(operationName string) Span
+}
+
+// Span records the progress of a single operation.
+type Span interface {
+	// SetTag annotates the span with a key/value pair.
+	SetTag(key, value string)
+	// LogEvent records a named point-in-time event within the span.
+	LogEvent(event string)
+	// Finish marks the end of the span. It must be called exactly once.
+	Finish()
+}
+
+var tracer = struct {
+	sync.RWMutex
+	t Tracer
+}{t: logTracer{}}
+
+// SetTracer replaces the tracer used for API server request spans and returns
+// the previous one. It is intended to be called during server setup.
+func SetTracer(t Tracer) Tracer {
+	tracer.Lock()
+	defer tracer.Unlock()
+	previous := tracer.t
+	tracer.t = t
+	return previous
+}
+
+func currentTracer() Tracer {
+	tracer.RLock()
+	defer tracer.RUnlock()
+	return tracer.t
+}
+
+// The key type is unexported to prevent collisions.
+type spanKeyType int
+
+const spanKey spanKeyType = 0
+
+// requestSpans associates in-flight requests with their spans, in the same way
+// RequestContextMapper associates them with contexts. Handlers deeper in the
+// chain do not receive the context returned by TraceRequest, so they look
+// their span up by request instead.
+var requestSpans = struct {
+	sync.Mutex
+	m map[*http.Request]Span
+}{m: make(map[*http.Request]Span)}
+
+// TraceRequest starts a span covering the remainder of the given request and
+// returns a context carrying it. The span stays associated with the request
+// until finishRequestSpan is called.
+func TraceRequest(req *http.Request, requestID string) context.Context {
+	span := currentTracer().StartSpan(req.Method + " " + req.URL.Path)
+	if len(requestID) > 0 {
+		span.SetTag("request-id", requestID)
+	}
+	requestSpans.Lock()
+	requestSpans.m[req] = span
+	requestSpans.Unlock()
+	return context.WithValue(context.Background(), spanKey, span)
+}
+
+// SpanFromContext returns the span stored by TraceRequest, or a no-op span if
+// the context does not carry one.
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// spanForRequest returns the span associated with an in-flight request, or a
+// no-op span for requests that did not pass through TraceRequest.
+func spanForRequest(req *http.Request) Span {
+	requestSpans.Lock()
+	defer requestSpans.Unlock()
+	if span, ok := requestSpans.m[req]; ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// finishRequestSpan finishes the request's span and drops the association.
+func finishRequestSpan(req *http.Request) {
+	requestSpans.Lock()
+	span, ok := requestSpans.m[req]
+	delete(requestSpans.m, req)
+	requestSpans.Unlock()
+	if ok {
+		span.Finish()
+	}
+}
+
+// traceRouteFunc wraps a route function so that every request to it runs
+// under a span tagged with the verb and resource. The span is finished once
+// the handler has written its response.
+func traceRouteFunc(verb, resource string, routeFunc restful.RouteFunction) restful.RouteFunction {
+	return restful.RouteFunction(func(req *restful.Request, res *restful.Response) {
+		ctx := TraceRequest(req.Request, req.Request.Header.Get("X-Request-Id"))
+		span := SpanFromContext(ctx)
+		span.SetTag("verb", verb)
+		span.SetTag("resource", resource)
+		defer finishRequestSpan(req.Request)
+		routeFunc(req, res)
+	})
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetTag(key, value string) {}
+func (noopSpan) LogEvent(event string)    {}
+func (noopSpan) Finish()                  {}
+
+// logTracer is the default Tracer.
+type logTracer struct{}
+
+func (logTracer) StartSpan(operationName string) Span {
+	return &logSpan{trace: util.NewTrace(operationName)}
+}
+
+type logSpan struct {
+	trace *util.Trace
+}
+
+func (s *logSpan) SetTag(key, value string) {
+	s.trace.Step(fmt.Sprintf("%s=%s", key, value))
+}
+
+func (s *logSpan) LogEvent(event string) {
+	s.trace.Step(event)
+}
+
+func (s *logSpan) Finish() {
+	s.trace.LogIfLong(250 * time.Millisecond)
+}
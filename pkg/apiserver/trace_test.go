@@ -0,0 +1,122 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/rest"
+	apiservertesting "k8s.io/kubernetes/pkg/apiserver/testing"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+type recordedSpan struct {
+	operationName string
+	tags          map[string]string
+	events        []string
+	finished      bool
+}
+
+func (s *recordedSpan) SetTag(key, value string) {
+	s.tags[key] = value
+}
+
+func (s *recordedSpan) LogEvent(event string) {
+	s.events = append(s.events, event)
+}
+
+func (s *recordedSpan) Finish() {
+	s.finished = true
+}
+
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) StartSpan(operationName string) Span {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	span := &recordedSpan{operationName: operationName, tags: map[string]string{}}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestCreateProducesSpan(t *testing.T) {
+	recorder := &recordingTracer{}
+	previous := SetTracer(recorder)
+	defer SetTracer(previous)
+
+	storage := SimpleRESTStorage{}
+	handler := handle(map[string]rest.Storage{"foo": &storage})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	simple := &apiservertesting.Simple{
+		Other: "bar",
+	}
+	data, err := runtime.Encode(testCodec, simple)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request, err := http.NewRequest("POST", server.URL+"/"+prefix+"/"+testGroupVersion.Group+"/"+testGroupVersion.Version+"/namespaces/default/foo", bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request.Header.Set("X-Request-Id", "12345")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected status: %d, expected: %d", response.StatusCode, http.StatusCreated)
+	}
+
+	if len(recorder.spans) != 1 {
+		t.Fatalf("expected a single span, got %d: %#v", len(recorder.spans), recorder.spans)
+	}
+	span := recorder.spans[0]
+	if !span.finished {
+		t.Errorf("span was not finished")
+	}
+	if !strings.HasPrefix(span.operationName, "POST ") || !strings.HasSuffix(span.operationName, "/namespaces/default/foo") {
+		t.Errorf("unexpected operation name: %q", span.operationName)
+	}
+	expectedTags := map[string]string{
+		"request-id": "12345",
+		"verb":       "POST",
+		"resource":   "foo",
+	}
+	if !reflect.DeepEqual(span.tags, expectedTags) {
+		t.Errorf("unexpected tags: %#v, expected: %#v", span.tags, expectedTags)
+	}
+	expectedEvents := []string{
+		"Admission control done",
+		"Object stored in database",
+		"Response serialized",
+	}
+	if !reflect.DeepEqual(span.events, expectedEvents) {
+		t.Errorf("unexpected events: %#v, expected: %#v", span.events, expectedEvents)
+	}
+}
@@ -97,8 +97,12 @@ func apiVersionsToAPIGroup(apiVersions *unversioned.APIVersions) (apiGroup unver
 		groupVersions = append(groupVersions, groupVersion)
 	}
 	apiGroup.Versions = groupVersions
-	// There should be only one groupVersion returned at /api
-	apiGroup.PreferredVersion = groupVersions[0]
+	// There should be only one groupVersion returned at /api, but a
+	// pre-1.0-style server can answer with none at all; leave the preferred
+	// version unset rather than panic in that case.
+	if len(groupVersions) > 0 {
+		apiGroup.PreferredVersion = groupVersions[0]
+	}
 	return
 }
 
@@ -95,6 +95,38 @@ func TestGetServerGroupsWithV1Server(t *testing.T) {
 	}
 }
 
+func TestGetServerGroupsWithEmptyLegacyServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var obj interface{}
+		switch req.URL.Path {
+		case "/api":
+			obj = &unversioned.APIVersions{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		output, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("unexpected encoding error: %v", err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(output)
+	}))
+	defer server.Close()
+	client := NewDiscoveryClientForConfigOrDie(&restclient.Config{Host: server.URL})
+	// An empty version list at /api must not panic ServerGroups.
+	apiGroupList, err := client.ServerGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	groupVersions := unversioned.ExtractGroupVersions(apiGroupList)
+	if len(groupVersions) != 0 {
+		t.Errorf("expected empty list, got: %q", groupVersions)
+	}
+}
+
 func TestGetServerGroupsWithBrokenServer(t *testing.T) {
 	for _, statusCode := range []int{http.StatusNotFound, http.StatusForbidden} {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
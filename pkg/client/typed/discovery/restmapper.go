@@ -19,10 +19,14 @@ package discovery
 import (
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/golang/glog"
 
 	"k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/meta"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/util/wait"
 )
 
 // APIGroupResources is an API group with a mapping of versions to
@@ -180,6 +184,36 @@ func (d *DeferredDiscoveryRESTMapper) Reset() {
 	d.initMu.Unlock()
 }
 
+// Refresh queries the discovery API and rebuilds the mapping from the
+// server's current resource list, picking up resources registered since the
+// last discovery (e.g. third party resources) and dropping resources that no
+// longer exist. Unlike Reset, the old mapping keeps serving requests until
+// the new discovery information has been fetched, so a discovery outage does
+// not take down a previously working mapper.
+func (d *DeferredDiscoveryRESTMapper) Refresh() error {
+	groupResources, err := GetAPIGroupResources(d.cl)
+	if err != nil {
+		return err
+	}
+
+	d.initMu.Lock()
+	d.delegate = NewRESTMapper(groupResources, d.versionInterface)
+	d.initMu.Unlock()
+	return nil
+}
+
+// RunRefreshLoop spawns a goroutine that calls Refresh every interval until
+// stopCh is closed, so the mapper follows resources registered or removed
+// while the process runs. Discovery failures are logged and retried on the
+// next tick.
+func (d *DeferredDiscoveryRESTMapper) RunRefreshLoop(interval time.Duration, stopCh <-chan struct{}) {
+	go wait.Until(func() {
+		if err := d.Refresh(); err != nil {
+			glog.Errorf("Failed to refresh REST mapper from discovery: %v", err)
+		}
+	}, interval, stopCh)
+}
+
 // KindFor takes a partial resource and returns back the single match.
 // It returns an error if there are multiple matches.
 func (d *DeferredDiscoveryRESTMapper) KindFor(resource unversioned.GroupVersionResource) (unversioned.GroupVersionKind, error) {
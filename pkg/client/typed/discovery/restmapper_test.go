@@ -17,10 +17,15 @@ limitations under the License.
 package discovery
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/emicklei/go-restful/swagger"
+
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/version"
 )
 
 func TestRESTMapper(t *testing.T) {
@@ -174,3 +179,131 @@ func TestRESTMapper(t *testing.T) {
 		}
 	}
 }
+
+// mutableFakeDiscovery is a DiscoveryInterface whose resource list can be
+// changed mid-test, simulating resources registered on the server after the
+// mapper was built.
+type mutableFakeDiscovery struct {
+	lock      sync.Mutex
+	groupList *unversioned.APIGroupList
+	resources map[string]*unversioned.APIResourceList
+}
+
+func (c *mutableFakeDiscovery) ServerGroups() (*unversioned.APIGroupList, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.groupList, nil
+}
+
+func (c *mutableFakeDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*unversioned.APIResourceList, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if list, found := c.resources[groupVersion]; found {
+		return list, nil
+	}
+	return nil, fmt.Errorf("GroupVersion %q not found", groupVersion)
+}
+
+func (c *mutableFakeDiscovery) ServerResources() (map[string]*unversioned.APIResourceList, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.resources, nil
+}
+
+func (c *mutableFakeDiscovery) ServerPreferredResources() ([]unversioned.GroupVersionResource, error) {
+	return nil, nil
+}
+
+func (c *mutableFakeDiscovery) ServerPreferredNamespacedResources() ([]unversioned.GroupVersionResource, error) {
+	return nil, nil
+}
+
+func (c *mutableFakeDiscovery) ServerVersion() (*version.Info, error) {
+	return &version.Info{}, nil
+}
+
+func (c *mutableFakeDiscovery) SwaggerSchema(version unversioned.GroupVersion) (*swagger.ApiDeclaration, error) {
+	return nil, nil
+}
+
+func (c *mutableFakeDiscovery) setResources(groupVersion string, list *unversioned.APIResourceList) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.resources[groupVersion] = list
+}
+
+func TestDeferredDiscoveryRESTMapperRefresh(t *testing.T) {
+	cl := &mutableFakeDiscovery{
+		groupList: &unversioned.APIGroupList{
+			Groups: []unversioned.APIGroup{
+				{
+					Name: "mygroup",
+					Versions: []unversioned.GroupVersionForDiscovery{
+						{GroupVersion: "mygroup/v1", Version: "v1"},
+					},
+					PreferredVersion: unversioned.GroupVersionForDiscovery{GroupVersion: "mygroup/v1", Version: "v1"},
+				},
+			},
+		},
+		resources: map[string]*unversioned.APIResourceList{
+			"mygroup/v1": {
+				GroupVersion: "mygroup/v1",
+				APIResources: []unversioned.APIResource{
+					{Name: "foos", Namespaced: true, Kind: "Foo"},
+				},
+			},
+		},
+	}
+
+	mapper := NewDeferredDiscoveryRESTMapper(cl, nil)
+
+	fooGVR := unversioned.GroupVersionResource{Group: "mygroup", Version: "v1", Resource: "foos"}
+	gvk, err := mapper.KindFor(fooGVR)
+	if err != nil {
+		t.Fatalf("KindFor(%#v) unexpected error: %v", fooGVR, err)
+	}
+	if want := (unversioned.GroupVersionKind{Group: "mygroup", Version: "v1", Kind: "Foo"}); gvk != want {
+		t.Fatalf("KindFor(%#v) = %#v, want %#v", fooGVR, gvk, want)
+	}
+
+	// Register a new resource on the server. The mapper keeps serving its
+	// cached discovery information until it is refreshed.
+	cl.setResources("mygroup/v1", &unversioned.APIResourceList{
+		GroupVersion: "mygroup/v1",
+		APIResources: []unversioned.APIResource{
+			{Name: "foos", Namespaced: true, Kind: "Foo"},
+			{Name: "bars", Namespaced: true, Kind: "Bar"},
+		},
+	})
+
+	barGVR := unversioned.GroupVersionResource{Group: "mygroup", Version: "v1", Resource: "bars"}
+	if _, err := mapper.KindFor(barGVR); err == nil {
+		t.Fatalf("KindFor(%#v) expected error before Refresh", barGVR)
+	}
+
+	if err := mapper.Refresh(); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	gvk, err = mapper.KindFor(barGVR)
+	if err != nil {
+		t.Fatalf("KindFor(%#v) unexpected error after Refresh: %v", barGVR, err)
+	}
+	if want := (unversioned.GroupVersionKind{Group: "mygroup", Version: "v1", Kind: "Bar"}); gvk != want {
+		t.Errorf("KindFor(%#v) = %#v, want %#v", barGVR, gvk, want)
+	}
+
+	// Drop the resource again; after another Refresh the mapping is gone.
+	cl.setResources("mygroup/v1", &unversioned.APIResourceList{
+		GroupVersion: "mygroup/v1",
+		APIResources: []unversioned.APIResource{
+			{Name: "foos", Namespaced: true, Kind: "Foo"},
+		},
+	})
+	if err := mapper.Refresh(); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if _, err := mapper.KindFor(barGVR); err == nil {
+		t.Errorf("KindFor(%#v) expected error after the resource was removed", barGVR)
+	}
+}
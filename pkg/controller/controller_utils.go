@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/meta"
 	"k8s.io/kubernetes/pkg/api/unversioned"
@@ -50,6 +51,16 @@ const (
 	// 500 pods. Just creation is limited to 20qps, and watching happens with ~10-30s
 	// latency/pod at the scale of 3000 pods over 100 nodes.
 	ExpectationsTimeout = 5 * time.Minute
+
+	// When batching pod creates, SlowStartInitialBatchSize is the size of the
+	// initial batch.  The size of each successive batch is twice the size of
+	// the previous batch.  For example, for a value of 1, batch sizes would be
+	// 1, 2, 4, 8, ...  and for a value of 10, batch sizes would be
+	// 10, 20, 40, 80, ...  Setting the value higher means that quota denials
+	// will result in more doomed API calls and associated event spam.  Setting
+	// the value lower will result in more API call round trip periods for
+	// large batches.
+	SlowStartInitialBatchSize = 1
 )
 
 var (
@@ -99,6 +110,7 @@ var ExpKeyFunc = func(obj interface{}) (string, error) {
 // types of controllers, because the keys might conflict across types.
 type ControllerExpectationsInterface interface {
 	GetExpectations(controllerKey string) (*ControlleeExpectations, bool, error)
+	Summaries() []ExpectationSummary
 	SatisfiedExpectations(controllerKey string) bool
 	DeleteExpectations(controllerKey string)
 	SetExpectations(controllerKey string, add, del int) error
@@ -110,9 +122,67 @@ type ControllerExpectationsInterface interface {
 	LowerExpectations(controllerKey string, add, del int)
 }
 
+// expiredExpectationsCount counts expectations that were force-expired before
+// the expected watch events arrived. A steadily increasing count means watch
+// events are being lost, e.g. because the apiserver restarted between an
+// issued create and its watch event.
+var expiredExpectationsCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Subsystem: "controller",
+		Name:      "expectations_expired_total",
+		Help:      "Number of controller expectations that expired before the expected watch events were observed, partitioned by controller kind.",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	prometheus.MustRegister(expiredExpectationsCount)
+}
+
 // ControllerExpectations is a cache mapping controllers to what they expect to see before being woken up for a sync.
 type ControllerExpectations struct {
 	cache.Store
+	// timeout after which unfulfilled expectations are force-expired.
+	// A zero timeout means ExpectationsTimeout.
+	timeout time.Duration
+	// clock is used to age expectations, and is injectable for testing.
+	clock clock.Clock
+	// kind of the owning controller, used to attribute expired expectations
+	// in metrics and logs. Empty for stores that were not constructed with
+	// NewControllerExpectationsForKind.
+	kind string
+}
+
+// kindLabel returns the controller kind for metric labels and logs.
+func (r *ControllerExpectations) kindLabel() string {
+	if r.kind == "" {
+		return "unknown"
+	}
+	return r.kind
+}
+
+// ExpectationSummary is a point-in-time snapshot of a controller's
+// outstanding expectations, used for debugging controllers that appear
+// stuck waiting on watch events.
+type ExpectationSummary struct {
+	Key       string    `json:"key"`
+	Add       int64     `json:"add"`
+	Del       int64     `json:"del"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Summaries returns a snapshot of all expectations in the store.
+func (r *ControllerExpectations) Summaries() []ExpectationSummary {
+	var summaries []ExpectationSummary
+	for _, item := range r.List() {
+		exp, ok := item.(*ControlleeExpectations)
+		if !ok {
+			continue
+		}
+		add, del := exp.GetExpectations()
+		summaries = append(summaries, ExpectationSummary{Key: exp.key, Add: add, Del: del, Timestamp: exp.timestamp})
+	}
+	return summaries
 }
 
 // GetExpectations returns the ControlleeExpectations of the given controller.
@@ -140,8 +210,10 @@ func (r *ControllerExpectations) SatisfiedExpectations(controllerKey string) boo
 	if exp, exists, err := r.GetExpectations(controllerKey); exists {
 		if exp.Fulfilled() {
 			return true
-		} else if exp.isExpired() {
-			glog.V(4).Infof("Controller expectations expired %#v", exp)
+		} else if r.isExpired(exp) {
+			add, del := exp.GetExpectations()
+			glog.V(2).Infof("Expectations for %v %v expired with %d unobserved creations and %d unobserved deletions, forcing sync", r.kindLabel(), controllerKey, add, del)
+			expiredExpectationsCount.WithLabelValues(r.kindLabel()).Inc()
 			return true
 		} else {
 			glog.V(4).Infof("Controller still waiting on expectations %#v", exp)
@@ -164,14 +236,29 @@ func (r *ControllerExpectations) SatisfiedExpectations(controllerKey string) boo
 
 // TODO: Extend ExpirationCache to support explicit expiration.
 // TODO: Make this possible to disable in tests.
-// TODO: Support injection of clock.
-func (exp *ControlleeExpectations) isExpired() bool {
-	return clock.RealClock{}.Since(exp.timestamp) > ExpectationsTimeout
+// isExpired returns true if the expectations have been outstanding for
+// longer than the store's timeout. Expectations younger than the timeout
+// never expire.
+func (r *ControllerExpectations) isExpired(exp *ControlleeExpectations) bool {
+	timeout := r.timeout
+	if timeout == 0 {
+		timeout = ExpectationsTimeout
+	}
+	return r.now().Sub(exp.timestamp) > timeout
+}
+
+// now returns the current time from the store's clock, falling back to the
+// real clock for stores constructed as bare literals.
+func (r *ControllerExpectations) now() time.Time {
+	if r.clock != nil {
+		return r.clock.Now()
+	}
+	return clock.RealClock{}.Now()
 }
 
 // SetExpectations registers new expectations for the given controller. Forgets existing expectations.
 func (r *ControllerExpectations) SetExpectations(controllerKey string, add, del int) error {
-	exp := &ControlleeExpectations{add: int64(add), del: int64(del), key: controllerKey, timestamp: clock.RealClock{}.Now()}
+	exp := &ControlleeExpectations{add: int64(add), del: int64(del), key: controllerKey, timestamp: r.now()}
 	glog.V(4).Infof("Setting expectations %#v", exp)
 	return r.Add(exp)
 }
@@ -246,7 +333,23 @@ func (e *ControlleeExpectations) GetExpectations() (int64, int64) {
 
 // NewControllerExpectations returns a store for ControllerExpectations.
 func NewControllerExpectations() *ControllerExpectations {
-	return &ControllerExpectations{cache.NewStore(ExpKeyFunc)}
+	return NewControllerExpectationsWithTimeout(ExpectationsTimeout)
+}
+
+// NewControllerExpectationsWithTimeout returns a store for
+// ControllerExpectations whose unfulfilled expectations are force-expired
+// after the given timeout instead of the default ExpectationsTimeout.
+func NewControllerExpectationsWithTimeout(timeout time.Duration) *ControllerExpectations {
+	return &ControllerExpectations{Store: cache.NewStore(ExpKeyFunc), timeout: timeout, clock: clock.RealClock{}}
+}
+
+// NewControllerExpectationsForKind returns a store for ControllerExpectations
+// whose expired expectations are attributed to the given controller kind in
+// metrics and logs.
+func NewControllerExpectationsForKind(kind string) *ControllerExpectations {
+	e := NewControllerExpectations()
+	e.kind = kind
+	return e
 }
 
 // UIDSetKeyFunc to parse out the key from a UIDSet.
@@ -513,6 +616,9 @@ type FakePodControl struct {
 	DeletePodName  []string
 	Patches        [][]byte
 	Err            error
+	// CreateLimit fails creations beyond this many calls when positive.
+	CreateLimit     int
+	CreateCallCount int
 }
 
 var _ PodControlInterface = &FakePodControl{}
@@ -530,6 +636,10 @@ func (f *FakePodControl) PatchPod(namespace, name string, data []byte) error {
 func (f *FakePodControl) CreatePods(namespace string, spec *api.PodTemplateSpec, object runtime.Object) error {
 	f.Lock()
 	defer f.Unlock()
+	f.CreateCallCount++
+	if f.CreateLimit != 0 && f.CreateCallCount > f.CreateLimit {
+		return fmt.Errorf("not creating pod, limit %d already reached (create call %d)", f.CreateLimit, f.CreateCallCount)
+	}
 	f.Templates = append(f.Templates, *spec)
 	if f.Err != nil {
 		return f.Err
@@ -540,6 +650,10 @@ func (f *FakePodControl) CreatePods(namespace string, spec *api.PodTemplateSpec,
 func (f *FakePodControl) CreatePodsWithControllerRef(namespace string, spec *api.PodTemplateSpec, object runtime.Object, controllerRef *api.OwnerReference) error {
 	f.Lock()
 	defer f.Unlock()
+	f.CreateCallCount++
+	if f.CreateLimit != 0 && f.CreateCallCount > f.CreateLimit {
+		return fmt.Errorf("not creating pod, limit %d already reached (create call %d)", f.CreateLimit, f.CreateCallCount)
+	}
 	f.Templates = append(f.Templates, *spec)
 	f.ControllerRefs = append(f.ControllerRefs, *controllerRef)
 	if f.Err != nil {
@@ -575,6 +689,8 @@ func (f *FakePodControl) Clear() {
 	f.Templates = []api.PodTemplateSpec{}
 	f.ControllerRefs = []api.OwnerReference{}
 	f.Patches = [][]byte{}
+	f.CreateLimit = 0
+	f.CreateCallCount = 0
 }
 
 // ByLogging allows custom sorting of pods so the best one can be picked for getting its logs.
@@ -26,6 +26,8 @@ import (
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	"k8s.io/kubernetes/pkg/api/unversioned"
@@ -48,7 +50,7 @@ func NewFakeControllerExpectationsLookup(ttl time.Duration) (*ControllerExpectat
 	ttlPolicy := &cache.TTLPolicy{Ttl: ttl, Clock: fakeClock}
 	ttlStore := cache.NewFakeExpirationStore(
 		ExpKeyFunc, nil, ttlPolicy, fakeClock)
-	return &ControllerExpectations{ttlStore}, fakeClock
+	return &ControllerExpectations{Store: ttlStore, clock: fakeClock}, fakeClock
 }
 
 func newReplicationController(replicas int) *api.ReplicationController {
@@ -184,6 +186,86 @@ func TestControllerExpectations(t *testing.T) {
 	}
 }
 
+// TestExpectationsForceExpiry simulates pod creations whose watch events are
+// lost, and verifies that the expectations only expire after the configured
+// timeout has passed.
+func TestExpectationsForceExpiry(t *testing.T) {
+	fakeTime := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(fakeTime)
+	timeout := time.Minute
+	e := &ControllerExpectations{Store: cache.NewStore(ExpKeyFunc), timeout: timeout, clock: fakeClock}
+
+	rc := newReplicationController(1)
+	rcKey, err := KeyFunc(rc)
+	if err != nil {
+		t.Errorf("Couldn't get key for object %#v: %v", rc, err)
+	}
+	// The rc creates 2 pods but never observes the watch events for them.
+	e.ExpectCreations(rcKey, 2)
+
+	summaries := e.Summaries()
+	if len(summaries) != 1 {
+		t.Fatalf("Unexpected expectation summaries %#v", summaries)
+	}
+	if summaries[0].Key != rcKey || summaries[0].Add != 2 || summaries[0].Del != 0 || !summaries[0].Timestamp.Equal(fakeTime) {
+		t.Errorf("Unexpected expectation summary %#v", summaries[0])
+	}
+
+	if e.SatisfiedExpectations(rcKey) {
+		t.Errorf("Expectations satisfied without observed creations or expiry")
+	}
+	// Just under the timeout the expectations must hold.
+	fakeClock.Step(timeout - time.Millisecond)
+	if e.SatisfiedExpectations(rcKey) {
+		t.Errorf("Expectations expired before the timeout")
+	}
+	// Once the timeout has passed, the stale expectations are force-expired
+	// and the controller is unblocked.
+	fakeClock.Step(2 * time.Millisecond)
+	if !e.SatisfiedExpectations(rcKey) {
+		t.Errorf("Stale expectations were not force-expired")
+	}
+}
+
+// expiredExpectationsValue reads the current value of the expired expectations
+// counter for the given controller kind.
+func expiredExpectationsValue(t *testing.T, kind string) float64 {
+	m := &dto.Metric{}
+	if err := expiredExpectationsCount.WithLabelValues(kind).Write(m); err != nil {
+		t.Fatalf("failed to read expired expectations counter for kind %q: %v", kind, err)
+	}
+	return m.Counter.GetValue()
+}
+
+func TestExpiredExpectationsIncrementCounter(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC))
+	timeout := time.Minute
+	e := &ControllerExpectations{Store: cache.NewStore(ExpKeyFunc), timeout: timeout, clock: fakeClock, kind: "ReplicaSet"}
+
+	rc := newReplicationController(1)
+	rcKey, err := KeyFunc(rc)
+	if err != nil {
+		t.Errorf("Couldn't get key for object %#v: %v", rc, err)
+	}
+	e.ExpectCreations(rcKey, 2)
+
+	before := expiredExpectationsValue(t, "ReplicaSet")
+	if e.SatisfiedExpectations(rcKey) {
+		t.Errorf("Expectations satisfied without observed creations or expiry")
+	}
+	if got := expiredExpectationsValue(t, "ReplicaSet"); got != before {
+		t.Errorf("Counter changed without an expiry: %v -> %v", before, got)
+	}
+
+	fakeClock.Step(timeout + time.Second)
+	if !e.SatisfiedExpectations(rcKey) {
+		t.Errorf("Stale expectations were not force-expired")
+	}
+	if got := expiredExpectationsValue(t, "ReplicaSet"); got != before+1 {
+		t.Errorf("Expected the expired expectations counter to increment by 1, got %v -> %v", before, got)
+	}
+}
+
 func TestUIDExpectations(t *testing.T) {
 	uidExp := NewUIDTrackingControllerExpectations(NewControllerExpectations())
 	rcList := []*api.ReplicationController{
@@ -207,13 +207,20 @@ func (f *pvInformer) Lister() *cache.StoreToPVFetcher {
 
 // NewPodInformer returns a SharedIndexInformer that lists and watches all pods
 func NewPodInformer(client clientset.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewScopedPodInformer(client, api.NamespaceAll, resyncPeriod)
+}
+
+// NewScopedPodInformer returns a SharedIndexInformer that lists and watches
+// the pods in the given namespace. NamespaceAll makes it equivalent to
+// NewPodInformer.
+func NewScopedPodInformer(client clientset.Interface, namespace string, resyncPeriod time.Duration) cache.SharedIndexInformer {
 	sharedIndexInformer := cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
-				return client.Core().Pods(api.NamespaceAll).List(options)
+				return client.Core().Pods(namespace).List(options)
 			},
 			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
-				return client.Core().Pods(api.NamespaceAll).Watch(options)
+				return client.Core().Pods(namespace).Watch(options)
 			},
 		},
 		&api.Pod{},
@@ -0,0 +1,165 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/util/clock"
+)
+
+const (
+	// podCreationTimeout is how long an issued pod creation may stay
+	// unmatched by a running pod before it is counted as a failure.
+	podCreationTimeout = 10 * time.Minute
+
+	// maxTrackedCreationsPerReplicaSet bounds the number of outstanding
+	// creations remembered per ReplicaSet, so a ReplicaSet that is scaled
+	// up repeatedly without its pods ever running cannot grow the tracker
+	// without limit. BurstReplicas is the most creations a single sync can
+	// issue.
+	maxTrackedCreationsPerReplicaSet = BurstReplicas
+)
+
+var (
+	podCreationLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: "replicaset_controller",
+			Name:      "pod_creation_latency_seconds",
+			Help:      "Time in seconds from a ReplicaSet scale-up issuing a pod creation until a pod of that ReplicaSet is observed running. Broken down by namespace.",
+		},
+		[]string{"namespace"},
+	)
+	podCreationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: "replicaset_controller",
+			Name:      "pod_creation_failures_total",
+			Help:      "Number of issued pod creations that were never matched by a running pod within the timeout. Broken down by namespace.",
+		},
+		[]string{"namespace"},
+	)
+)
+
+var registerMetricsOnce sync.Once
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(podCreationLatency)
+		prometheus.MustRegister(podCreationFailures)
+	})
+}
+
+// podCreationTracker remembers when pod creations were issued for each
+// ReplicaSet so the latency until the pods actually run can be exported.
+// CreatePods does not return the name of the pod it created, so individual
+// creations cannot be correlated with individual pods; instead the oldest
+// outstanding creation is matched against the next pod of the ReplicaSet
+// observed running, which yields the right distribution in aggregate.
+type podCreationTracker struct {
+	clock clock.Clock
+
+	lock sync.Mutex
+	// pending holds the issue timestamps of unmatched creations, oldest
+	// first, keyed by ReplicaSet key.
+	pending map[string][]time.Time
+}
+
+func newPodCreationTracker(c clock.Clock) *podCreationTracker {
+	return &podCreationTracker{
+		clock:   c,
+		pending: make(map[string][]time.Time),
+	}
+}
+
+// creationsIssued records that count pod creations were just issued for the
+// ReplicaSet, dropping the oldest entries beyond the per-ReplicaSet bound.
+func (t *podCreationTracker) creationsIssued(rsKey string, count int) {
+	if count <= 0 {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.expireLocked(rsKey)
+	pending := t.pending[rsKey]
+	now := t.clock.Now()
+	for i := 0; i < count; i++ {
+		pending = append(pending, now)
+	}
+	if len(pending) > maxTrackedCreationsPerReplicaSet {
+		pending = pending[len(pending)-maxTrackedCreationsPerReplicaSet:]
+	}
+	t.pending[rsKey] = pending
+}
+
+// podRunning matches a pod of the ReplicaSet observed running against the
+// oldest outstanding creation and records the latency. It is a no-op when no
+// creation is outstanding, which is the common case for pods that were
+// already running when the controller started.
+func (t *podCreationTracker) podRunning(rsKey string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.expireLocked(rsKey)
+	pending := t.pending[rsKey]
+	if len(pending) == 0 {
+		return
+	}
+	namespace, _, err := cache.SplitMetaNamespaceKey(rsKey)
+	if err != nil {
+		return
+	}
+	podCreationLatency.WithLabelValues(namespace).Observe(t.clock.Since(pending[0]).Seconds())
+	if len(pending) == 1 {
+		delete(t.pending, rsKey)
+	} else {
+		t.pending[rsKey] = pending[1:]
+	}
+}
+
+// forget drops all outstanding creations for the ReplicaSet. Called when the
+// ReplicaSet is deleted.
+func (t *podCreationTracker) forget(rsKey string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.pending, rsKey)
+}
+
+// expireLocked counts creations older than podCreationTimeout as failures and
+// removes them. Callers must hold t.lock.
+func (t *podCreationTracker) expireLocked(rsKey string) {
+	pending := t.pending[rsKey]
+	expired := 0
+	for _, issued := range pending {
+		if t.clock.Since(issued) < podCreationTimeout {
+			break
+		}
+		expired++
+	}
+	if expired == 0 {
+		return
+	}
+	if namespace, _, err := cache.SplitMetaNamespaceKey(rsKey); err == nil {
+		podCreationFailures.WithLabelValues(namespace).Add(float64(expired))
+	}
+	if expired == len(pending) {
+		delete(t.pending, rsKey)
+	} else {
+		t.pending[rsKey] = pending[expired:]
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/kubernetes/pkg/util/clock"
+)
+
+// latencySample reads back the histogram for the given namespace. The metrics
+// are package globals, so tests use distinct namespaces to isolate their
+// observations.
+func latencySample(t *testing.T, namespace string) (count uint64, sum float64) {
+	m := &dto.Metric{}
+	if err := podCreationLatency.WithLabelValues(namespace).Write(m); err != nil {
+		t.Fatalf("unexpected error reading latency metric: %v", err)
+	}
+	return m.Histogram.GetSampleCount(), m.Histogram.GetSampleSum()
+}
+
+func failureCount(t *testing.T, namespace string) float64 {
+	m := &dto.Metric{}
+	if err := podCreationFailures.WithLabelValues(namespace).Write(m); err != nil {
+		t.Fatalf("unexpected error reading failure metric: %v", err)
+	}
+	return m.Counter.GetValue()
+}
+
+func TestPodCreationTrackerLatency(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := newPodCreationTracker(fakeClock)
+	rsKey := "tracker-latency/foo"
+
+	tracker.creationsIssued(rsKey, 2)
+	fakeClock.Step(3 * time.Second)
+	tracker.podRunning(rsKey)
+	fakeClock.Step(2 * time.Second)
+	tracker.podRunning(rsKey)
+
+	count, sum := latencySample(t, "tracker-latency")
+	if count != 2 {
+		t.Errorf("expected 2 latency observations, got %d", count)
+	}
+	// The first pod ran after 3s, the second after 3s+2s.
+	if expected := 8.0; sum != expected {
+		t.Errorf("expected latency sum %v, got %v", expected, sum)
+	}
+	if len(tracker.pending) != 0 {
+		t.Errorf("expected no outstanding creations, got %v", tracker.pending)
+	}
+
+	// A running pod with no outstanding creation must not be observed.
+	tracker.podRunning(rsKey)
+	count, _ = latencySample(t, "tracker-latency")
+	if count != 2 {
+		t.Errorf("expected observation count to stay at 2, got %d", count)
+	}
+}
+
+func TestPodCreationTrackerExpiration(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := newPodCreationTracker(fakeClock)
+	rsKey := "tracker-expiration/foo"
+
+	tracker.creationsIssued(rsKey, 2)
+	fakeClock.Step(podCreationTimeout / 2)
+	tracker.creationsIssued(rsKey, 1)
+	fakeClock.Step(podCreationTimeout / 2)
+
+	// The first two creations are now past the timeout; the third is not.
+	tracker.podRunning(rsKey)
+	if failures := failureCount(t, "tracker-expiration"); failures != 2 {
+		t.Errorf("expected 2 creation failures, got %v", failures)
+	}
+	count, sum := latencySample(t, "tracker-expiration")
+	if count != 1 {
+		t.Errorf("expected 1 latency observation, got %d", count)
+	}
+	if expected := (podCreationTimeout / 2).Seconds(); sum != expected {
+		t.Errorf("expected latency sum %v, got %v", expected, sum)
+	}
+}
+
+func TestPodCreationTrackerBoundAndForget(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	tracker := newPodCreationTracker(fakeClock)
+	rsKey := "tracker-bound/foo"
+
+	tracker.creationsIssued(rsKey, maxTrackedCreationsPerReplicaSet+10)
+	if got := len(tracker.pending[rsKey]); got != maxTrackedCreationsPerReplicaSet {
+		t.Errorf("expected pending creations to be bounded at %d, got %d", maxTrackedCreationsPerReplicaSet, got)
+	}
+
+	tracker.forget(rsKey)
+	if len(tracker.pending) != 0 {
+		t.Errorf("expected forget to drop all outstanding creations, got %v", tracker.pending)
+	}
+}
@@ -19,6 +19,7 @@ limitations under the License.
 package replicaset
 
 import (
+	"expvar"
 	"fmt"
 	"reflect"
 	"sort"
@@ -39,7 +40,9 @@ import (
 	"k8s.io/kubernetes/pkg/controller/informers"
 	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/clock"
 	utilerrors "k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/integer"
 	"k8s.io/kubernetes/pkg/util/metrics"
 	utilruntime "k8s.io/kubernetes/pkg/util/runtime"
 	"k8s.io/kubernetes/pkg/util/wait"
@@ -65,6 +68,10 @@ const (
 	statusUpdateRetries = 1
 )
 
+// publishExpectationsOnce guards the expvar registration, since expvar
+// panics on duplicate names and tests construct multiple controllers.
+var publishExpectationsOnce sync.Once
+
 func getRSKind() unversioned.GroupVersionKind {
 	return v1beta1.SchemeGroupVersion.WithKind("ReplicaSet")
 }
@@ -75,6 +82,10 @@ type ReplicaSetController struct {
 	kubeClient clientset.Interface
 	podControl controller.PodControlInterface
 
+	// eventRecorder is used to surface sync problems, e.g. force-expired
+	// expectations, on the ReplicaSet objects themselves.
+	eventRecorder record.EventRecorder
+
 	// internalPodInformer is used to hold a personal informer.  If we're using
 	// a normal shared informer, then the informer will be started for us.  If
 	// we have a personal informer, we must start it ourselves.   If you start
@@ -105,6 +116,14 @@ type ReplicaSetController struct {
 
 	lookupCache *controller.MatchingCache
 
+	// creationTracker records when pod creations were issued so the latency
+	// until the pods run can be exported.
+	creationTracker *podCreationTracker
+
+	// namespace limits the ReplicaSets this controller manages; NamespaceAll
+	// means no limit.
+	namespace string
+
 	// Controllers that need to be synced
 	queue *workqueue.Type
 
@@ -113,22 +132,38 @@ type ReplicaSetController struct {
 	garbageCollectorEnabled bool
 }
 
-// NewReplicaSetController creates a new ReplicaSetController.
+// NewReplicaSetController creates a new ReplicaSetController watching all namespaces.
 func NewReplicaSetController(podInformer cache.SharedIndexInformer, kubeClient clientset.Interface, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int, garbageCollectorEnabled bool) *ReplicaSetController {
+	return NewNamespacedReplicaSetController(podInformer, kubeClient, api.NamespaceAll, resyncPeriod, burstReplicas, lookupCacheSize, garbageCollectorEnabled)
+}
+
+// NewNamespacedReplicaSetController creates a ReplicaSetController that only
+// watches and syncs ReplicaSets in the given namespace, so multiple
+// controller instances can divide an installation between them. The pod
+// informer may be shared; pods outside the namespace are ignored.
+// NamespaceAll removes the limit.
+func NewNamespacedReplicaSetController(podInformer cache.SharedIndexInformer, kubeClient clientset.Interface, namespace string, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int, garbageCollectorEnabled bool) *ReplicaSetController {
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(glog.Infof)
 	eventBroadcaster.StartRecordingToSink(&unversionedcore.EventSinkImpl{Interface: kubeClient.Core().Events("")})
 
+	component := "replicaset-controller"
+	if namespace != api.NamespaceAll {
+		// Make events attributable to the controller instance responsible
+		// for the namespace.
+		component = fmt.Sprintf("%s-%s", component, namespace)
+	}
 	return newReplicaSetController(
-		eventBroadcaster.NewRecorder(api.EventSource{Component: "replicaset-controller"}),
-		podInformer, kubeClient, resyncPeriod, burstReplicas, lookupCacheSize, garbageCollectorEnabled)
+		eventBroadcaster.NewRecorder(api.EventSource{Component: component}),
+		podInformer, kubeClient, namespace, resyncPeriod, burstReplicas, lookupCacheSize, garbageCollectorEnabled)
 }
 
 // newReplicaSetController configures a replica set controller with the specified event recorder
-func newReplicaSetController(eventRecorder record.EventRecorder, podInformer cache.SharedIndexInformer, kubeClient clientset.Interface, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int, garbageCollectorEnabled bool) *ReplicaSetController {
+func newReplicaSetController(eventRecorder record.EventRecorder, podInformer cache.SharedIndexInformer, kubeClient clientset.Interface, namespace string, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int, garbageCollectorEnabled bool) *ReplicaSetController {
 	if kubeClient != nil && kubeClient.Core().GetRESTClient().GetRateLimiter() != nil {
 		metrics.RegisterMetricAndTrackRateLimiterUsage("replicaset_controller", kubeClient.Core().GetRESTClient().GetRateLimiter())
 	}
+	registerMetrics()
 
 	rsc := &ReplicaSetController{
 		kubeClient: kubeClient,
@@ -136,19 +171,30 @@ func newReplicaSetController(eventRecorder record.EventRecorder, podInformer cac
 			KubeClient: kubeClient,
 			Recorder:   eventRecorder,
 		},
-		burstReplicas: burstReplicas,
-		expectations:  controller.NewUIDTrackingControllerExpectations(controller.NewControllerExpectations()),
-		queue:         workqueue.NewNamed("replicaset"),
+		eventRecorder:   eventRecorder,
+		burstReplicas:   burstReplicas,
+		expectations:    controller.NewUIDTrackingControllerExpectations(controller.NewControllerExpectationsForKind("ReplicaSet")),
+		namespace:       namespace,
+		queue:           workqueue.NewNamed("replicaset"),
+		creationTracker: newPodCreationTracker(clock.RealClock{}),
 		garbageCollectorEnabled: garbageCollectorEnabled,
 	}
 
+	// Expose the outstanding expectations for debugging ReplicaSets that
+	// appear stuck waiting on watch events.
+	publishExpectationsOnce.Do(func() {
+		expvar.Publish("replicaset_expectations", expvar.Func(func() interface{} {
+			return rsc.expectations.Summaries()
+		}))
+	})
+
 	rsc.rsStore.Store, rsc.rsController = cache.NewInformer(
 		&cache.ListWatch{
 			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
-				return rsc.kubeClient.Extensions().ReplicaSets(api.NamespaceAll).List(options)
+				return rsc.kubeClient.Extensions().ReplicaSets(rsc.namespace).List(options)
 			},
 			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
-				return rsc.kubeClient.Extensions().ReplicaSets(api.NamespaceAll).Watch(options)
+				return rsc.kubeClient.Extensions().ReplicaSets(rsc.namespace).Watch(options)
 			},
 		},
 		&extensions.ReplicaSet{},
@@ -183,9 +229,16 @@ func newReplicaSetController(eventRecorder record.EventRecorder, podInformer cac
 
 // NewReplicationManagerFromClient creates a new ReplicationManager that runs its own informer.
 func NewReplicaSetControllerFromClient(kubeClient clientset.Interface, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int) *ReplicaSetController {
-	podInformer := informers.NewPodInformer(kubeClient, resyncPeriod())
+	return NewNamespacedReplicaSetControllerFromClient(kubeClient, api.NamespaceAll, resyncPeriod, burstReplicas, lookupCacheSize)
+}
+
+// NewNamespacedReplicaSetControllerFromClient creates a ReplicaSetController
+// limited to the given namespace that runs its own pod informer, scoped to
+// the same namespace.
+func NewNamespacedReplicaSetControllerFromClient(kubeClient clientset.Interface, namespace string, resyncPeriod controller.ResyncPeriodFunc, burstReplicas int, lookupCacheSize int) *ReplicaSetController {
+	podInformer := informers.NewScopedPodInformer(kubeClient, namespace, resyncPeriod())
 	garbageCollectorEnabled := false
-	rsc := NewReplicaSetController(podInformer, kubeClient, resyncPeriod, burstReplicas, lookupCacheSize, garbageCollectorEnabled)
+	rsc := NewNamespacedReplicaSetController(podInformer, kubeClient, namespace, resyncPeriod, burstReplicas, lookupCacheSize, garbageCollectorEnabled)
 	rsc.internalPodInformer = podInformer
 	return rsc
 }
@@ -196,6 +249,7 @@ func (rsc *ReplicaSetController) SetEventRecorder(recorder record.EventRecorder)
 	// TODO: Hack. We can't cleanly shutdown the event recorder, so benchmarks
 	// need to pass in a fake.
 	rsc.podControl = controller.RealPodControl{KubeClient: rsc.kubeClient, Recorder: recorder}
+	rsc.eventRecorder = recorder
 }
 
 // Run begins watching and syncing.
@@ -344,6 +398,9 @@ func (rsc *ReplicaSetController) addPod(obj interface{}) {
 		return
 	}
 	rsc.expectations.CreationObserved(rsKey)
+	if pod.Status.Phase == api.PodRunning {
+		rsc.creationTracker.podRunning(rsKey)
+	}
 	rsc.enqueueReplicaSet(rs)
 }
 
@@ -384,6 +441,11 @@ func (rsc *ReplicaSetController) updatePod(old, cur interface{}) {
 	}
 
 	if curRS := rsc.getPodReplicaSet(curPod); curRS != nil {
+		if oldPod.Status.Phase != api.PodRunning && curPod.Status.Phase == api.PodRunning {
+			if rsKey, err := controller.KeyFunc(curRS); err == nil {
+				rsc.creationTracker.podRunning(rsKey)
+			}
+		}
 		rsc.enqueueReplicaSet(curRS)
 	}
 }
@@ -476,36 +538,42 @@ func (rsc *ReplicaSetController) manageReplicas(filteredPods []*api.Pod, rs *ext
 		// into a performance bottleneck. We should generate a UID for the pod
 		// beforehand and store it via ExpectCreations.
 		rsc.expectations.ExpectCreations(rsKey, diff)
-		var wg sync.WaitGroup
-		wg.Add(diff)
 		glog.V(2).Infof("Too few %q/%q replicas, need %d, creating %d", rs.Namespace, rs.Name, rs.Spec.Replicas, diff)
-		for i := 0; i < diff; i++ {
-			go func() {
-				defer wg.Done()
-				var err error
-
-				if rsc.garbageCollectorEnabled {
-					var trueVar = true
-					controllerRef := &api.OwnerReference{
-						APIVersion: getRSKind().GroupVersion().String(),
-						Kind:       getRSKind().Kind,
-						Name:       rs.Name,
-						UID:        rs.UID,
-						Controller: &trueVar,
-					}
-					err = rsc.podControl.CreatePodsWithControllerRef(rs.Namespace, &rs.Spec.Template, rs, controllerRef)
-				} else {
-					err = rsc.podControl.CreatePods(rs.Namespace, &rs.Spec.Template, rs)
-				}
-				if err != nil {
-					// Decrement the expected number of creates because the informer won't observe this pod
-					glog.V(2).Infof("Failed creation, decrementing expectations for replica set %q/%q", rs.Namespace, rs.Name)
-					rsc.expectations.CreationObserved(rsKey)
-					utilruntime.HandleError(err)
+		// Batch the pod creates. Batch sizes start at SlowStartInitialBatchSize
+		// and double with each successful iteration in a kind of "slow start".
+		// This handles attempts to start large numbers of pods that would
+		// likely all fail with the same error. For example a project with a
+		// low quota that attempts to create a large number of pods will be
+		// prevented from spamming the API service with the pod create requests
+		// after one of its pods fails.  Conveniently, this also prevents the
+		// event spam that those failures would generate.
+		successfulCreations, err := slowStartBatch(diff, controller.SlowStartInitialBatchSize, func() error {
+			if rsc.garbageCollectorEnabled {
+				var trueVar = true
+				controllerRef := &api.OwnerReference{
+					APIVersion: getRSKind().GroupVersion().String(),
+					Kind:       getRSKind().Kind,
+					Name:       rs.Name,
+					UID:        rs.UID,
+					Controller: &trueVar,
 				}
-			}()
+				return rsc.podControl.CreatePodsWithControllerRef(rs.Namespace, &rs.Spec.Template, rs, controllerRef)
+			}
+			return rsc.podControl.CreatePods(rs.Namespace, &rs.Spec.Template, rs)
+		})
+		rsc.creationTracker.creationsIssued(rsKey, successfulCreations)
+		// Any pods that were never created, whether because their create call
+		// failed or because their batch was skipped, won't be observed by the
+		// informer, so their expectations have to be lowered by hand.
+		if skippedPods := diff - successfulCreations; skippedPods > 0 {
+			glog.V(2).Infof("Slow-start failure. Skipping creation of %d pods, decrementing expectations for replica set %q/%q", skippedPods, rs.Namespace, rs.Name)
+			for i := 0; i < skippedPods; i++ {
+				rsc.expectations.CreationObserved(rsKey)
+			}
+		}
+		if err != nil {
+			utilruntime.HandleError(err)
 		}
-		wg.Wait()
 	} else if diff > 0 {
 		if diff > rsc.burstReplicas {
 			diff = rsc.burstReplicas
@@ -547,6 +615,43 @@ func (rsc *ReplicaSetController) manageReplicas(filteredPods []*api.Pod, rs *ext
 	}
 }
 
+// slowStartBatch tries to call the provided function a total of 'count' times,
+// starting slow to check for errors, then speeding up if calls succeed.
+//
+// It groups the calls into batches, starting with a group of initialBatchSize.
+// Within each batch, it may call the function multiple times concurrently.
+//
+// If a whole batch succeeds, the next batch may get exponentially larger.
+// If there are any failures in a batch, all remaining batches are skipped
+// after waiting for the current batch to complete.
+//
+// It returns the number of successful calls to the function.
+func slowStartBatch(count int, initialBatchSize int, fn func() error) (int, error) {
+	remaining := count
+	successes := 0
+	for batchSize := integer.IntMin(remaining, initialBatchSize); batchSize > 0; batchSize = integer.IntMin(2*batchSize, remaining) {
+		errCh := make(chan error, batchSize)
+		var wg sync.WaitGroup
+		wg.Add(batchSize)
+		for i := 0; i < batchSize; i++ {
+			go func() {
+				defer wg.Done()
+				if err := fn(); err != nil {
+					errCh <- err
+				}
+			}()
+		}
+		wg.Wait()
+		curSuccesses := batchSize - len(errCh)
+		successes += curSuccesses
+		if len(errCh) > 0 {
+			return successes, <-errCh
+		}
+		remaining -= batchSize
+	}
+	return successes, nil
+}
+
 // syncReplicaSet will sync the ReplicaSet with the given key if it has had its expectations fulfilled,
 // meaning it did not expect to see any more of its pods created or deleted. This function is not meant to be
 // invoked concurrently with the same key.
@@ -556,6 +661,15 @@ func (rsc *ReplicaSetController) syncReplicaSet(key string) error {
 		glog.V(4).Infof("Finished syncing replica set %q (%v)", key, time.Now().Sub(startTime))
 	}()
 
+	if rsc.namespace != api.NamespaceAll {
+		// Keys can arrive from a shared pod informer that watches more
+		// namespaces than this controller manages; drop them defensively.
+		if namespace, _, err := cache.SplitMetaNamespaceKey(key); err != nil || namespace != rsc.namespace {
+			glog.V(4).Infof("Skipping ReplicaSet %v outside of managed namespace %v", key, rsc.namespace)
+			return nil
+		}
+	}
+
 	if !rsc.podStoreSynced() {
 		// Sleep so we give the pod reflector goroutine a chance to run.
 		time.Sleep(PodStoreSyncedPollPeriod)
@@ -568,6 +682,7 @@ func (rsc *ReplicaSetController) syncReplicaSet(key string) error {
 	if !exists {
 		glog.Infof("ReplicaSet has been deleted %v", key)
 		rsc.expectations.DeleteExpectations(key)
+		rsc.creationTracker.forget(key)
 		return nil
 	}
 	if err != nil {
@@ -586,11 +701,33 @@ func (rsc *ReplicaSetController) syncReplicaSet(key string) error {
 		glog.Errorf("Couldn't get key for ReplicaSet %#v: %v", rs, err)
 		return err
 	}
+	exp, hasExpectations, _ := rsc.expectations.GetExpectations(rsKey)
 	rsNeedsSync := rsc.expectations.SatisfiedExpectations(rsKey)
+	if rsNeedsSync && hasExpectations && !exp.Fulfilled() {
+		// The sync only proceeds with unfulfilled expectations when they
+		// have been force-expired, which usually means watch events for
+		// the expected pods were lost.
+		add, del := exp.GetExpectations()
+		glog.Warningf("Expectations for ReplicaSet %v expired with %d adds and %d dels unobserved, forcing sync", rsKey, add, del)
+		rsc.eventRecorder.Eventf(&rs, api.EventTypeWarning, "ExpectationsExpired",
+			"Outstanding expectations expired (%d pod creations, %d pod deletions unobserved), forcing sync", add, del)
+	}
 	selector, err := unversioned.LabelSelectorAsSelector(rs.Spec.Selector)
 	if err != nil {
-		glog.Errorf("Error converting pod selector to selector: %v", err)
-		return err
+		// Retrying cannot fix a selector that does not parse; surface the
+		// problem on the ReplicaSet and skip pod management until the
+		// selector is updated.
+		glog.Errorf("Error converting pod selector to selector for rs %q: %v", key, err)
+		rsc.eventRecorder.Eventf(&rs, api.EventTypeWarning, "ReplicaFailure", "Error converting pod selector to selector: %v", err)
+		return nil
+	}
+	if !selector.Matches(labels.Set(rs.Spec.Template.Labels)) {
+		// Validation normally prevents this, but objects that predate it
+		// can reach the sync loop; the pods created from the template will
+		// never be selected by the ReplicaSet. Log rather than emit an
+		// event, since the condition persists across syncs and an event per
+		// sync period would spam the API server.
+		glog.Warningf("Selector for ReplicaSet %v does not match its pod template labels", rsKey)
 	}
 
 	// NOTE: filteredPods are pointing to objects from cache - if you need to
@@ -23,6 +23,7 @@ import (
 	"math/rand"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"k8s.io/kubernetes/pkg/client/cache"
 	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
 	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/client/restclient"
 	"k8s.io/kubernetes/pkg/client/testing/core"
 	"k8s.io/kubernetes/pkg/controller"
@@ -240,6 +242,68 @@ func TestSyncReplicaSetCreates(t *testing.T) {
 	validateSyncReplicaSet(t, &fakePodControl, 2, 0, 0)
 }
 
+func TestSyncReplicaSetNamespaceIsolation(t *testing.T) {
+	// Two controllers scoped to different namespaces against a shared client.
+	client := clientset.NewForConfigOrDie(&restclient.Config{Host: "", ContentConfig: restclient.ContentConfig{GroupVersion: testapi.Default.GroupVersion()}})
+	managerA := NewNamespacedReplicaSetControllerFromClient(client, "ns-a", controller.NoResyncPeriodFunc, BurstReplicas, 0)
+	managerA.podStoreSynced = alwaysReady
+	managerB := NewNamespacedReplicaSetControllerFromClient(client, "ns-b", controller.NoResyncPeriodFunc, BurstReplicas, 0)
+	managerB.podStoreSynced = alwaysReady
+
+	labelMap := map[string]string{"foo": "bar"}
+	rs := newReplicaSet(2, labelMap)
+	rs.Namespace = "ns-a"
+	// Both controllers see the ReplicaSet, as with a shared informer.
+	managerA.rsStore.Store.Add(rs)
+	managerB.rsStore.Store.Add(rs)
+
+	fakePodControlA := controller.FakePodControl{}
+	managerA.podControl = &fakePodControlA
+	fakePodControlB := controller.FakePodControl{}
+	managerB.podControl = &fakePodControlB
+
+	// Only the controller managing ns-a acts on the ReplicaSet.
+	managerA.syncReplicaSet(getKey(rs, t))
+	validateSyncReplicaSet(t, &fakePodControlA, 2, 0, 0)
+	managerB.syncReplicaSet(getKey(rs, t))
+	validateSyncReplicaSet(t, &fakePodControlB, 0, 0, 0)
+}
+
+func TestSyncReplicaSetInvalidSelector(t *testing.T) {
+	client := clientset.NewForConfigOrDie(&restclient.Config{Host: "", ContentConfig: restclient.ContentConfig{GroupVersion: testapi.Default.GroupVersion()}})
+	manager := NewReplicaSetControllerFromClient(client, controller.NoResyncPeriodFunc, BurstReplicas, 0)
+	manager.podStoreSynced = alwaysReady
+	fakeRecorder := record.NewFakeRecorder(10)
+	manager.eventRecorder = fakeRecorder
+
+	labelMap := map[string]string{"foo": "bar"}
+	rs := newReplicaSet(2, labelMap)
+	rs.Spec.Selector = &unversioned.LabelSelector{
+		MatchExpressions: []unversioned.LabelSelectorRequirement{
+			{Key: "foo", Operator: "InvalidOperator", Values: []string{"bar"}},
+		},
+	}
+	manager.rsStore.Store.Add(rs)
+
+	fakePodControl := controller.FakePodControl{}
+	manager.podControl = &fakePodControl
+
+	if err := manager.syncReplicaSet(getKey(rs, t)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// No pods may be created or deleted for a selector that does not parse.
+	validateSyncReplicaSet(t, &fakePodControl, 0, 0, 0)
+
+	select {
+	case event := <-fakeRecorder.Events:
+		if !strings.Contains(event, "ReplicaFailure") {
+			t.Errorf("expected ReplicaFailure event, got %q", event)
+		}
+	default:
+		t.Errorf("expected a ReplicaFailure event for the invalid selector")
+	}
+}
+
 func TestStatusUpdatesWithoutReplicasChange(t *testing.T) {
 	// Setup a fake server to listen for requests, and run the ReplicaSet controller in steady state
 	fakeHandler := utiltesting.FakeHandler{
@@ -1173,8 +1237,15 @@ func TestPatchPodFails(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	// 2 patches to take control of pod1 and pod2 (both fail), 2 creates.
-	validateSyncReplicaSet(t, fakePodControl, 2, 0, 2)
+	// 2 patches to take control of pod1 and pod2 (both fail), then 1 create:
+	// the first slow-start batch of 1 fails, aborting the remaining batches.
+	validateSyncReplicaSet(t, fakePodControl, 1, 0, 2)
+	// The expectations for the aborted creates must have been lowered by
+	// hand, or the next sync would sit out waiting for them.
+	exp, exists, err := manager.expectations.GetExpectations(getKey(rs, t))
+	if err != nil || !exists || !exp.Fulfilled() {
+		t.Errorf("expected lowered expectations for the skipped creates, got exists=%v, fulfilled=%v, err=%v", exists, exists && exp.Fulfilled(), err)
+	}
 }
 
 func TestPatchExtraPodsThenDelete(t *testing.T) {
@@ -1374,3 +1445,155 @@ func TestAvailableReplicas(t *testing.T) {
 	fakeHandler.ValidateRequest(t, testapi.Extensions.ResourcePath(replicaSetResourceName(), rs.Namespace, rs.Name)+"/status", "PUT", &decRs)
 	validateSyncReplicaSet(t, &fakePodControl, 0, 0, 0)
 }
+
+func TestLostWatchEventsExpireExpectations(t *testing.T) {
+	fakeHandler := utiltesting.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: "{}",
+	}
+	testServer := httptest.NewServer(&fakeHandler)
+	defer testServer.Close()
+
+	client := clientset.NewForConfigOrDie(&restclient.Config{Host: testServer.URL, ContentConfig: restclient.ContentConfig{GroupVersion: testapi.Default.GroupVersion()}})
+	fakePodControl := controller.FakePodControl{}
+	manager := NewReplicaSetControllerFromClient(client, controller.NoResyncPeriodFunc, BurstReplicas, 0)
+	manager.podStoreSynced = alwaysReady
+	manager.podControl = &fakePodControl
+	fakeRecorder := record.NewFakeRecorder(10)
+	manager.eventRecorder = fakeRecorder
+	timeout := 50 * time.Millisecond
+	manager.expectations = controller.NewUIDTrackingControllerExpectations(controller.NewControllerExpectationsWithTimeout(timeout))
+
+	labelMap := map[string]string{"foo": "bar"}
+	rsSpec := newReplicaSet(2, labelMap)
+	manager.rsStore.Store.Add(rsSpec)
+
+	// The first sync creates 2 pods and sets expectations for them, but the
+	// watch events for the creations are never observed.
+	manager.syncReplicaSet(getKey(rsSpec, t))
+	validateSyncReplicaSet(t, &fakePodControl, 2, 0, 0)
+	fakePodControl.Clear()
+
+	// Before the timeout the controller is still waiting on its expectations.
+	manager.syncReplicaSet(getKey(rsSpec, t))
+	validateSyncReplicaSet(t, &fakePodControl, 0, 0, 0)
+
+	// After the timeout the stale expectations are force-expired, unblocking
+	// the sync, which recreates the lost pods.
+	time.Sleep(timeout + 10*time.Millisecond)
+	manager.syncReplicaSet(getKey(rsSpec, t))
+	validateSyncReplicaSet(t, &fakePodControl, 2, 0, 0)
+	select {
+	case event := <-fakeRecorder.Events:
+		if !strings.Contains(event, "ExpectationsExpired") {
+			t.Errorf("Expected ExpectationsExpired event, got %q", event)
+		}
+	default:
+		t.Errorf("Expected a warning event for the force-expired expectations")
+	}
+}
+
+func TestSlowStartBatch(t *testing.T) {
+	fakeErr := fmt.Errorf("fake error")
+	callCnt := 0
+	callLimit := 0
+	var lock sync.Mutex
+	fn := func() error {
+		lock.Lock()
+		defer lock.Unlock()
+		callCnt++
+		if callCnt > callLimit {
+			return fakeErr
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name              string
+		count             int
+		callLimit         int
+		expectedSuccesses int
+		expectedErr       error
+		expectedCallCnt   int
+	}{
+		{
+			name:              "callLimit = 0 (all fail)",
+			count:             10,
+			callLimit:         0,
+			expectedSuccesses: 0,
+			expectedErr:       fakeErr,
+			expectedCallCnt:   1, // the first batch is attempted before aborting
+		},
+		{
+			name:              "callLimit = count (all succeed)",
+			count:             10,
+			callLimit:         10,
+			expectedSuccesses: 10,
+			expectedErr:       nil,
+			expectedCallCnt:   10, // 1 + 2 + 4 + 3
+		},
+		{
+			name:              "callLimit < count (some succeed)",
+			count:             10,
+			callLimit:         5,
+			expectedSuccesses: 5,
+			expectedErr:       fakeErr,
+			expectedCallCnt:   7, // 1 + 2 + 4, the third batch hits the limit
+		},
+	}
+
+	for _, test := range tests {
+		callCnt = 0
+		callLimit = test.callLimit
+		successes, err := slowStartBatch(test.count, 1, fn)
+		if successes != test.expectedSuccesses {
+			t.Errorf("%s: unexpected processed batch size, expected %d, got %d", test.name, test.expectedSuccesses, successes)
+		}
+		if err != test.expectedErr {
+			t.Errorf("%s: unexpected processed batch size, expected %v, got %v", test.name, test.expectedErr, err)
+		}
+		// verify that slowStartBatch stops trying more calls after a batch fails
+		if callCnt != test.expectedCallCnt {
+			t.Errorf("%s: slowStartBatch() still tries calls after a batch fails, expected %d calls, got %d", test.name, test.expectedCallCnt, callCnt)
+		}
+	}
+}
+
+func TestSlowStartFailureDecrementsExpectations(t *testing.T) {
+	fakeHandler := utiltesting.FakeHandler{
+		StatusCode:   200,
+		ResponseBody: "{}",
+	}
+	testServer := httptest.NewServer(&fakeHandler)
+	defer testServer.Close()
+
+	client := clientset.NewForConfigOrDie(&restclient.Config{Host: testServer.URL, ContentConfig: restclient.ContentConfig{GroupVersion: testapi.Default.GroupVersion()}})
+	fakePodControl := controller.FakePodControl{}
+	fakePodControl.CreateLimit = 3
+	manager := NewReplicaSetControllerFromClient(client, controller.NoResyncPeriodFunc, BurstReplicas, 0)
+	manager.podStoreSynced = alwaysReady
+	manager.podControl = &fakePodControl
+
+	labelMap := map[string]string{"foo": "bar"}
+	rsSpec := newReplicaSet(10, labelMap)
+	manager.rsStore.Store.Add(rsSpec)
+
+	manager.syncReplicaSet(getKey(rsSpec, t))
+
+	// Slow start attempts batches of 1, 2 and 4 creates; the third batch runs
+	// into the limit, so 7 creates are attempted and 3 of them succeed.
+	if fakePodControl.CreateCallCount != 7 {
+		t.Errorf("Expected 7 create calls, got %d", fakePodControl.CreateCallCount)
+	}
+	validateSyncReplicaSet(t, &fakePodControl, 3, 0, 0)
+
+	// Expectations for the failed and skipped pods must have been lowered, so
+	// only the 3 created pods are still expected.
+	exp, exists, err := manager.expectations.GetExpectations(getKey(rsSpec, t))
+	if err != nil || !exists {
+		t.Fatalf("Expected expectations for rs, got exists=%v err=%v", exists, err)
+	}
+	if add, del := exp.GetExpectations(); add != 3 || del != 0 {
+		t.Errorf("Expected 3 outstanding creations and no deletions, got add=%d del=%d", add, del)
+	}
+}
@@ -18,6 +18,7 @@ package credentialprovider
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/url"
 	"path/filepath"
@@ -303,26 +304,60 @@ func (k *unionDockerKeyring) Lookup(image string) ([]LazyAuthConfiguration, bool
 	return authConfigs, (len(authConfigs) > 0)
 }
 
+// SecretError associates a parse failure with the name of the image pull
+// secret that caused it.
+type SecretError struct {
+	SecretName string
+	Err        error
+}
+
+func (e SecretError) Error() string {
+	return fmt.Sprintf("image pull secret %s: %v", e.SecretName, e.Err)
+}
+
+// ParseDockerConfigFromSecret extracts the docker credentials carried by the
+// secret. Both the legacy kubernetes.io/dockercfg format and the newer
+// kubernetes.io/dockerconfigjson format are understood. The second return
+// value reports whether the secret carries docker credentials at all; secrets
+// of other types are skipped without error.
+func ParseDockerConfigFromSecret(secret api.Secret) (DockerConfig, bool, error) {
+	if dockerConfigJsonBytes, dockerConfigJsonExists := secret.Data[api.DockerConfigJsonKey]; (secret.Type == api.SecretTypeDockerConfigJson) && dockerConfigJsonExists && (len(dockerConfigJsonBytes) > 0) {
+		dockerConfigJson := DockerConfigJson{}
+		if err := json.Unmarshal(dockerConfigJsonBytes, &dockerConfigJson); err != nil {
+			return nil, true, err
+		}
+
+		return dockerConfigJson.Auths, true, nil
+	}
+	if dockercfgBytes, dockercfgExists := secret.Data[api.DockerConfigKey]; (secret.Type == api.SecretTypeDockercfg) && dockercfgExists && (len(dockercfgBytes) > 0) {
+		dockercfg := DockerConfig{}
+		if err := json.Unmarshal(dockercfgBytes, &dockercfg); err != nil {
+			return nil, true, err
+		}
+
+		return dockercfg, true, nil
+	}
+	return nil, false, nil
+}
+
 // MakeDockerKeyring inspects the passedSecrets to see if they contain any DockerConfig secrets.  If they do,
 // then a DockerKeyring is built based on every hit and unioned with the defaultKeyring.
-// If they do not, then the default keyring is returned
-func MakeDockerKeyring(passedSecrets []api.Secret, defaultKeyring DockerKeyring) (DockerKeyring, error) {
+// If they do not, then the default keyring is returned.
+// Secrets whose docker credentials fail to parse are reported in the returned
+// SecretErrors rather than failing the whole keyring; the keyring still holds
+// every credential that did parse, so the caller can attempt the pull
+// regardless and surface the errors to the user.
+func MakeDockerKeyring(passedSecrets []api.Secret, defaultKeyring DockerKeyring) (DockerKeyring, []SecretError) {
 	passedCredentials := []DockerConfig{}
+	secretErrors := []SecretError{}
 	for _, passedSecret := range passedSecrets {
-		if dockerConfigJsonBytes, dockerConfigJsonExists := passedSecret.Data[api.DockerConfigJsonKey]; (passedSecret.Type == api.SecretTypeDockerConfigJson) && dockerConfigJsonExists && (len(dockerConfigJsonBytes) > 0) {
-			dockerConfigJson := DockerConfigJson{}
-			if err := json.Unmarshal(dockerConfigJsonBytes, &dockerConfigJson); err != nil {
-				return nil, err
-			}
-
-			passedCredentials = append(passedCredentials, dockerConfigJson.Auths)
-		} else if dockercfgBytes, dockercfgExists := passedSecret.Data[api.DockerConfigKey]; (passedSecret.Type == api.SecretTypeDockercfg) && dockercfgExists && (len(dockercfgBytes) > 0) {
-			dockercfg := DockerConfig{}
-			if err := json.Unmarshal(dockercfgBytes, &dockercfg); err != nil {
-				return nil, err
-			}
-
-			passedCredentials = append(passedCredentials, dockercfg)
+		cfg, found, err := ParseDockerConfigFromSecret(passedSecret)
+		if err != nil {
+			secretErrors = append(secretErrors, SecretError{SecretName: passedSecret.Name, Err: err})
+			continue
+		}
+		if found {
+			passedCredentials = append(passedCredentials, cfg)
 		}
 	}
 
@@ -331,8 +366,8 @@ func MakeDockerKeyring(passedSecrets []api.Secret, defaultKeyring DockerKeyring)
 		for _, currCredentials := range passedCredentials {
 			basicKeyring.Add(currCredentials)
 		}
-		return &unionDockerKeyring{[]DockerKeyring{basicKeyring, defaultKeyring}}, nil
+		return &unionDockerKeyring{[]DockerKeyring{basicKeyring, defaultKeyring}}, secretErrors
 	}
 
-	return defaultKeyring, nil
+	return defaultKeyring, secretErrors
 }
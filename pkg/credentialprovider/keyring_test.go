@@ -20,6 +20,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
 )
 
 func TestUrlsMatch(t *testing.T) {
@@ -499,3 +501,74 @@ func TestLazyKeyring(t *testing.T) {
 		t.Errorf("Unexpected number of Provide calls: %v", provider.Count)
 	}
 }
+
+func TestMakeDockerKeyringFromSecrets(t *testing.T) {
+	dockercfgAuth := base64.StdEncoding.EncodeToString([]byte("user-cfg:pass-cfg"))
+	dockercfg := fmt.Sprintf(`{"cfg.registry.example.com": {"auth": %q, "email": "cfg@example.com"}}`, dockercfgAuth)
+
+	configJsonAuth := base64.StdEncoding.EncodeToString([]byte("user-json:pass-json"))
+	configJson := fmt.Sprintf(`{"auths": {"json.registry.example.com": {"auth": %q, "email": "json@example.com"}}}`, configJsonAuth)
+
+	corruptCfg := `{"corrupt.registry.example.com": {"auth": "not!base64!", "email": "corrupt@example.com"}}`
+
+	secrets := []api.Secret{
+		{
+			ObjectMeta: api.ObjectMeta{Name: "cfg-secret"},
+			Type:       api.SecretTypeDockercfg,
+			Data:       map[string][]byte{api.DockerConfigKey: []byte(dockercfg)},
+		},
+		{
+			ObjectMeta: api.ObjectMeta{Name: "json-secret"},
+			Type:       api.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{api.DockerConfigJsonKey: []byte(configJson)},
+		},
+		// A second secret carrying the same credentials; duplicates must be
+		// merged into the keyring, not treated as an error.
+		{
+			ObjectMeta: api.ObjectMeta{Name: "cfg-secret-duplicate"},
+			Type:       api.SecretTypeDockercfg,
+			Data:       map[string][]byte{api.DockerConfigKey: []byte(dockercfg)},
+		},
+		{
+			ObjectMeta: api.ObjectMeta{Name: "corrupt-secret"},
+			Type:       api.SecretTypeDockercfg,
+			Data:       map[string][]byte{api.DockerConfigKey: []byte(corruptCfg)},
+		},
+		// Not a docker secret at all; skipped without error.
+		{
+			ObjectMeta: api.ObjectMeta{Name: "opaque-secret"},
+			Type:       api.SecretTypeOpaque,
+			Data:       map[string][]byte{"token": []byte("abc")},
+		},
+	}
+
+	keyring, secretErrors := MakeDockerKeyring(secrets, &FakeKeyring{})
+	if len(secretErrors) != 1 {
+		t.Fatalf("Unexpected secret errors: %v", secretErrors)
+	}
+	if secretErrors[0].SecretName != "corrupt-secret" {
+		t.Errorf("Expected error for corrupt-secret, got: %v", secretErrors[0])
+	}
+
+	creds, ok := keyring.Lookup("cfg.registry.example.com/foo/bar")
+	if !ok || len(creds) != 2 {
+		t.Fatalf("Expected the duplicated dockercfg credentials, got: %v, %v", creds, ok)
+	}
+	for _, cred := range creds {
+		if cred.Username != "user-cfg" || cred.Password != "pass-cfg" {
+			t.Errorf("Unexpected credentials for dockercfg registry: %+v", cred)
+		}
+	}
+
+	creds, ok = keyring.Lookup("json.registry.example.com/foo/bar")
+	if !ok || len(creds) != 1 {
+		t.Fatalf("Expected the dockerconfigjson credentials, got: %v, %v", creds, ok)
+	}
+	if creds[0].Username != "user-json" || creds[0].Password != "pass-json" {
+		t.Errorf("Unexpected credentials for dockerconfigjson registry: %+v", creds[0])
+	}
+
+	if _, ok := keyring.Lookup("corrupt.registry.example.com/foo/bar"); ok {
+		t.Errorf("Expected no credentials from the corrupt secret")
+	}
+}
@@ -74,7 +74,18 @@ type Config struct {
 	APIPrefix               string
 	APIGroupPrefix          string
 	CorsAllowedOriginList   []string
-	Authenticator           authenticator.Request
+	// Methods and headers advertised to cross-origin callers. Nil selects the
+	// defaults in filters.WithCORS.
+	CorsAllowedMethodList []string
+	CorsAllowedHeaderList []string
+	// Whether cross-origin requests may carry credentials (cookies, client
+	// certificates). When true the matching origin is echoed back rather
+	// than "*".
+	CorsAllowCredentials bool
+	// How long a browser may cache a preflight answer. Zero omits the
+	// Access-Control-Max-Age header.
+	CorsMaxAge    time.Duration
+	Authenticator authenticator.Request
 	// TODO(roberthbailey): Remove once the server no longer supports http basic auth.
 	SupportsBasicAuth      bool
 	Authorizer             authorizer.Authorizer
@@ -184,6 +195,7 @@ func NewConfig(options *options.ServerRunOptions) *Config {
 		APIGroupPrefix:            options.APIGroupPrefix,
 		APIPrefix:                 options.APIPrefix,
 		CorsAllowedOriginList:     options.CorsAllowedOriginList,
+		CorsAllowCredentials:      true,
 		AuditWriter:               auditWriter,
 		EnableGarbageCollection:   options.EnableGarbageCollection,
 		EnableIndex:               true,
@@ -353,7 +365,7 @@ func (c Config) New() (*GenericAPIServer, error) {
 
 func (s *GenericAPIServer) buildHandlerChains(c *Config, handler http.Handler) (secure http.Handler, insecure http.Handler) {
 	// filters which insecure and secure have in common
-	handler = genericfilters.WithCORS(handler, c.CorsAllowedOriginList, nil, nil, "true")
+	handler = genericfilters.WithCORS(handler, c.CorsAllowedOriginList, c.CorsAllowedMethodList, c.CorsAllowedHeaderList, c.CorsAllowCredentials, c.CorsMaxAge)
 
 	// insecure filters
 	insecure = handler
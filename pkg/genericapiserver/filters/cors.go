@@ -19,7 +19,9 @@ package filters
 import (
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 
@@ -35,7 +37,15 @@ import (
 // WithCORS is a simple CORS implementation that wraps an http Handler.
 // Pass nil for allowedMethods and allowedHeaders to use the defaults. If allowedOriginPatterns
 // is empty or nil, no CORS support is installed.
-func WithCORS(handler http.Handler, allowedOriginPatterns []string, allowedMethods []string, allowedHeaders []string, allowCredentials string) http.Handler {
+//
+// Requests whose Origin matches one of the patterns get the matching origin
+// echoed back verbatim; a literal "*" is never sent, since browsers reject it
+// when credentials are allowed. Preflight OPTIONS requests from a matching
+// origin are answered here and never reach the wrapped handler. Requests from
+// non-matching origins are dispatched downstream without CORS headers; it is
+// the browser, not the server, that enforces the policy. A positive maxAge is
+// advertised on preflight responses as Access-Control-Max-Age, in seconds.
+func WithCORS(handler http.Handler, allowedOriginPatterns []string, allowedMethods []string, allowedHeaders []string, allowCredentials bool, maxAge time.Duration) http.Handler {
 	if len(allowedOriginPatterns) == 0 {
 		return handler
 	}
@@ -60,10 +70,15 @@ func WithCORS(handler http.Handler, allowedOriginPatterns []string, allowedMetho
 				}
 				w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowedMethods, ", "))
 				w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
-				w.Header().Set("Access-Control-Allow-Credentials", allowCredentials)
+				if allowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
 
 				// Stop here if its a preflight OPTIONS request
 				if req.Method == "OPTIONS" {
+					if maxAge > 0 {
+						w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge/time.Second)))
+					}
 					w.WriteHeader(http.StatusNoContent)
 					return
 				}
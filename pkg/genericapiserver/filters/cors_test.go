@@ -21,6 +21,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestCORSAllowedOrigins(t *testing.T) {
@@ -39,7 +40,7 @@ func TestCORSAllowedOrigins(t *testing.T) {
 	for _, item := range table {
 		handler := WithCORS(
 			http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
-			item.allowedOrigins, nil, nil, "true",
+			item.allowedOrigins, nil, nil, true, 0,
 		)
 		server := httptest.NewServer(handler)
 		defer server.Close()
@@ -91,3 +92,120 @@ func TestCORSAllowedOrigins(t *testing.T) {
 		}
 	}
 }
+
+func TestCORSPreflight(t *testing.T) {
+	downstreamCalled := false
+	handler := WithCORS(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { downstreamCalled = true }),
+		[]string{"example.com"}, []string{"GET", "PUT"}, []string{"Authorization"}, true, 10*time.Minute,
+	)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	request, err := http.NewRequest("OPTIONS", server.URL+"/version", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request.Header.Set("Origin", "example.com")
+	request.Header.Set("Access-Control-Request-Method", "PUT")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected %v, Got %v", http.StatusNoContent, response.StatusCode)
+	}
+	if downstreamCalled {
+		t.Errorf("Expected the preflight request to be answered without invoking the wrapped handler")
+	}
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "example.com",
+		"Access-Control-Allow-Methods":     "GET, PUT",
+		"Access-Control-Allow-Headers":     "Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "600",
+	}
+	for header, value := range expected {
+		if got := response.Header.Get(header); got != value {
+			t.Errorf("Expected %s: %#v, Got %#v", header, value, got)
+		}
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	for _, allowCredentials := range []bool{true, false} {
+		downstreamCalled := false
+		handler := WithCORS(
+			http.HandlerFunc(func(http.ResponseWriter, *http.Request) { downstreamCalled = true }),
+			[]string{"example.com"}, nil, nil, allowCredentials, 10*time.Minute,
+		)
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		request, err := http.NewRequest("GET", server.URL+"/version", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		request.Header.Set("Origin", "example.com")
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !downstreamCalled {
+			t.Errorf("allowCredentials=%v: Expected the request to be dispatched to the wrapped handler", allowCredentials)
+		}
+		// The matching origin must be echoed back, never "*".
+		if got := response.Header.Get("Access-Control-Allow-Origin"); got != "example.com" {
+			t.Errorf("allowCredentials=%v: Expected Access-Control-Allow-Origin: %#v, Got %#v", allowCredentials, "example.com", got)
+		}
+		expectedCredentials := ""
+		if allowCredentials {
+			expectedCredentials = "true"
+		}
+		if got := response.Header.Get("Access-Control-Allow-Credentials"); got != expectedCredentials {
+			t.Errorf("allowCredentials=%v: Expected Access-Control-Allow-Credentials: %#v, Got %#v", allowCredentials, expectedCredentials, got)
+		}
+		if got := response.Header.Get("Access-Control-Max-Age"); got != "" {
+			t.Errorf("allowCredentials=%v: Expected Access-Control-Max-Age only on preflight responses, Got %#v", allowCredentials, got)
+		}
+	}
+}
+
+func TestCORSNonMatchingOrigin(t *testing.T) {
+	downstreamCalled := false
+	handler := WithCORS(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { downstreamCalled = true }),
+		[]string{"example.com"}, nil, nil, true, 10*time.Minute,
+	)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL+"/version", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request.Header.Set("Origin", "not-allowed.com")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Non-matching origins are not rejected; they are passed through without
+	// CORS headers and the browser enforces the policy.
+	if !downstreamCalled {
+		t.Errorf("Expected the request to be dispatched to the wrapped handler")
+	}
+	for _, header := range []string{
+		"Access-Control-Allow-Origin",
+		"Access-Control-Allow-Methods",
+		"Access-Control-Allow-Headers",
+		"Access-Control-Allow-Credentials",
+		"Access-Control-Max-Age",
+	} {
+		if got := response.Header.Get(header); got != "" {
+			t.Errorf("Expected %s header to not be set, Got %#v", header, got)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 )
 
@@ -90,12 +91,32 @@ func (c *healthzCheck) Check(r *http.Request) error {
 	return c.check(r)
 }
 
+// excludedChecks returns the set of check names the request asked to skip via
+// the "exclude" query parameter. Both repeated parameters and comma-separated
+// lists are accepted.
+func excludedChecks(r *http.Request) map[string]struct{} {
+	excluded := map[string]struct{}{}
+	for _, value := range r.URL.Query()["exclude"] {
+		for _, name := range strings.Split(value, ",") {
+			if len(name) > 0 {
+				excluded[name] = struct{}{}
+			}
+		}
+	}
+	return excluded
+}
+
 // handleRootHealthz returns an http.HandlerFunc that serves the provided checks.
 func handleRootHealthz(checks ...HealthzChecker) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		failed := false
+		excluded := excludedChecks(r)
 		var verboseOut bytes.Buffer
 		for _, check := range checks {
+			if _, found := excluded[check.Name()]; found {
+				fmt.Fprintf(&verboseOut, "[+]%v excluded: ok\n", check.Name())
+				continue
+			}
 			err := check.Check(r)
 			if err != nil {
 				fmt.Fprintf(&verboseOut, "[-]%v failed: %v\n", check.Name(), err)
@@ -55,6 +55,11 @@ func TestMulitipleChecks(t *testing.T) {
 		{"/healthz/ping", "ok", http.StatusOK, true},
 		{"/healthz/bad", "Internal server error: this will fail\n", http.StatusInternalServerError, true},
 		{"/healthz", "[+]ping ok\n[-]bad failed: this will fail\nhealthz check failed\n", http.StatusInternalServerError, true},
+		{"/healthz?exclude=bad", "ok", http.StatusOK, true},
+		{"/healthz?verbose&exclude=bad", "[+]ping ok\n[+]bad excluded: ok\nhealthz check passed\n", http.StatusOK, true},
+		{"/healthz?exclude=ping,bad", "ok", http.StatusOK, true},
+		{"/healthz?exclude=ping", "[+]ping excluded: ok\n[-]bad failed: this will fail\nhealthz check failed\n", http.StatusInternalServerError, true},
+		{"/healthz?exclude=unknown", "[+]ping ok\n[-]bad failed: this will fail\nhealthz check failed\n", http.StatusInternalServerError, true},
 	}
 
 	for i, test := range tests {
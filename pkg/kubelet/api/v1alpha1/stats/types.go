@@ -177,6 +177,9 @@ type FsStats struct {
 	InodesFree *uint64 `json:"inodesFree,omitempty"`
 	// Inodes represents the total inodes in the filesystem.
 	Inodes *uint64 `json:"inodes,omitempty"`
+	// InodesUsed represents the inodes used by a specific task on the filesystem.
+	// e.g. For a volume this is the number of files and directories it contains.
+	InodesUsed *uint64 `json:"inodesUsed,omitempty"`
 }
 
 // UserDefinedMetricType defines how the metric should be interpreted by the user.
@@ -214,3 +214,60 @@ func SandboxToContainerState(state runtimeApi.PodSandBoxState) ContainerState {
 	}
 	return ContainerStateUnknown
 }
+
+// ImagesByID sorts images by their IDs, so that image listings have a
+// deterministic order regardless of how the runtime produced them.
+type ImagesByID []Image
+
+func (images ImagesByID) Len() int           { return len(images) }
+func (images ImagesByID) Swap(i, j int)      { images[i], images[j] = images[j], images[i] }
+func (images ImagesByID) Less(i, j int) bool { return images[i].ID < images[j].ID }
+
+// FilterImages applies the parts of an ImageListFilter that can be
+// evaluated against converted Image records. Runtimes use it for the
+// filter fields their image daemon cannot evaluate itself. Label filtering
+// is not handled here since Image records do not carry labels.
+func FilterImages(images []Image, filter *ImageListFilter) []Image {
+	if filter == nil {
+		return images
+	}
+	filtered := []Image{}
+	for _, image := range images {
+		if filter.Dangling && imageIsTagged(image) {
+			continue
+		}
+		if len(filter.NamePrefix) > 0 && !imageMatchesPrefix(image, filter.NamePrefix) {
+			continue
+		}
+		filtered = append(filtered, image)
+	}
+	return filtered
+}
+
+// imageIsTagged returns true if the image carries at least one real repo
+// tag. Docker reports dangling images with the placeholder tag
+// "<none>:<none>", which does not count as a name.
+func imageIsTagged(image Image) bool {
+	for _, tag := range image.RepoTags {
+		if tag != "" && tag != "<none>" && tag != "<none>:<none>" {
+			return true
+		}
+	}
+	return false
+}
+
+// imageMatchesPrefix returns true if any of the image's repo tags or repo
+// digests starts with the given prefix.
+func imageMatchesPrefix(image Image, prefix string) bool {
+	for _, tag := range image.RepoTags {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	for _, digest := range image.RepoDigests {
+		if strings.HasPrefix(digest, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -54,6 +54,68 @@ type ImageStats struct {
 	TotalStorageBytes uint64
 }
 
+// ContainerStats contains point-in-time resource usage of a single container.
+type ContainerStats struct {
+	// Cumulative CPU usage in nanoseconds.
+	CpuUsage uint64
+	// Memory usage in bytes.
+	MemoryUsage uint64
+}
+
+// RuntimeConditionType is the type of a runtime condition.
+type RuntimeConditionType string
+
+const (
+	// RuntimeReady means the runtime is up and ready to accept basic containers.
+	RuntimeReady RuntimeConditionType = "RuntimeReady"
+	// NetworkReady means the runtime network is up and ready to accept
+	// containers which require network.
+	NetworkReady RuntimeConditionType = "NetworkReady"
+)
+
+// RuntimeCondition contains condition information for the runtime.
+type RuntimeCondition struct {
+	// Type of runtime condition.
+	Type RuntimeConditionType
+	// Status of the condition, one of true/false.
+	Status bool
+	// Reason is a brief CamelCase string containing the reason for the condition's last transition.
+	Reason string
+	// Message is a human readable message indicating details about the condition.
+	Message string
+}
+
+// String formats the runtime condition into a human readable string.
+func (c *RuntimeCondition) String() string {
+	return fmt.Sprintf("%s=%t reason:%s message:%s", c.Type, c.Status, c.Reason, c.Message)
+}
+
+// RuntimeStatus contains the status of the runtime.
+type RuntimeStatus struct {
+	// Conditions is an array of current observed runtime conditions.
+	Conditions []RuntimeCondition
+}
+
+// GetRuntimeCondition gets a specified runtime condition from the runtime status.
+func (s *RuntimeStatus) GetRuntimeCondition(t RuntimeConditionType) *RuntimeCondition {
+	for i := range s.Conditions {
+		c := &s.Conditions[i]
+		if c.Type == t {
+			return c
+		}
+	}
+	return nil
+}
+
+// String formats the runtime status into a human readable string.
+func (s *RuntimeStatus) String() string {
+	var ss []string
+	for _, c := range s.Conditions {
+		ss = append(ss, c.String())
+	}
+	return fmt.Sprintf("Runtime Conditions: %s", strings.Join(ss, ", "))
+}
+
 // Runtime interface defines the interfaces that should be implemented
 // by a container runtime.
 // Thread safety is required from implementations of this interface.
@@ -69,8 +131,10 @@ type Runtime interface {
 	// This may be different from the runtime engine's version.
 	// TODO(random-liu): We should fold this into Version()
 	APIVersion() (Version, error)
-	// Status returns error if the runtime is unhealthy; nil otherwise.
-	Status() error
+	// Status returns the status of the runtime. An error is returned if the
+	// status could not be obtained at all; degraded conditions are reported
+	// through the returned RuntimeStatus instead.
+	Status() (*RuntimeStatus, error)
 	// GetPods returns a list of containers grouped by pods. The boolean parameter
 	// specifies whether the runtime returns all containers including those already
 	// exited and dead containers (used for garbage collection).
@@ -112,6 +176,13 @@ type Runtime interface {
 	GetContainerLogs(pod *api.Pod, containerID ContainerID, logOptions *api.PodLogOptions, stdout, stderr io.Writer) (err error)
 	// Delete a container. If the container is still running, an error is returned.
 	DeleteContainer(containerID ContainerID) error
+	// GetContainerStats returns the resource usage of the container. Runtimes
+	// that do not support direct stats collection return an error.
+	GetContainerStats(containerID ContainerID) (*ContainerStats, error)
+	// UpdateContainerResources updates the resource limits of a running
+	// container without restarting it. Runtimes that do not support live
+	// updates return an error.
+	UpdateContainerResources(containerID ContainerID, resources *api.ResourceRequirements) error
 	// ContainerCommandRunner encapsulates the command runner interfaces for testability.
 	ContainerCommandRunner
 	// ContainerAttach encapsulates the attaching to containers for testability
@@ -348,6 +419,22 @@ type Image struct {
 	Size int64
 }
 
+// ImageListFilter restricts the images a runtime's filtered image listing
+// returns. A nil filter, or the zero value of any field, matches every
+// image. Runtimes translate the fields they can to the image daemon's own
+// filters and apply the rest client-side.
+type ImageListFilter struct {
+	// Dangling selects only images that are not tagged by any name.
+	Dangling bool
+	// NamePrefix selects images with at least one repo tag or repo digest
+	// starting with the given prefix.
+	NamePrefix string
+	// Labels selects images that carry all of the given labels. Label
+	// filtering is only supported by runtimes whose daemon can evaluate
+	// it, since Image records do not carry labels.
+	Labels map[string]string
+}
+
 type EnvVar struct {
 	Name  string
 	Value string
@@ -29,6 +29,7 @@ var (
 type RuntimeCache interface {
 	GetPods() ([]*Pod, error)
 	ForceUpdateIfOlder(time.Time) error
+	UpdatePods([]*Pod, time.Time)
 }
 
 type podsGetter interface {
@@ -78,6 +79,18 @@ func (r *runtimeCache) ForceUpdateIfOlder(minExpectedCacheTime time.Time) error
 	return nil
 }
 
+// UpdatePods replaces the cached pods with the given pods if the timestamp is
+// newer than the cache time. This allows components that already list pods
+// from the runtime (e.g. the pod lifecycle event generator) to share their
+// snapshot instead of triggering another relist.
+func (r *runtimeCache) UpdatePods(pods []*Pod, timestamp time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	if r.cacheTime.Before(timestamp) {
+		r.pods, r.cacheTime = pods, timestamp
+	}
+}
+
 func (r *runtimeCache) updateCache() error {
 	pods, timestamp, err := r.getPodsWithTimestamp()
 	if err != nil {
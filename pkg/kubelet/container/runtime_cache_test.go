@@ -72,3 +72,23 @@ func TestForceUpdateIfOlder(t *testing.T) {
 	actual = cache.GetCachedPods()
 	comparePods(t, newpods, actual)
 }
+
+func TestUpdatePods(t *testing.T) {
+	runtime := &ctest.FakeRuntime{}
+	cache := NewTestRuntimeCache(runtime)
+
+	// Cache old pods.
+	oldpods := []*ctest.FakePod{{Pod: &Pod{ID: "1111"}}}
+	runtime.PodList = oldpods
+	cache.UpdateCacheWithLock()
+
+	newpods := []*ctest.FakePod{{Pod: &Pod{ID: "1111"}}, {Pod: &Pod{ID: "2222"}}}
+
+	// A snapshot older than the cache time should be discarded.
+	cache.UpdatePods([]*Pod{newpods[0].Pod, newpods[1].Pod}, time.Now().Add(-20*time.Minute))
+	comparePods(t, oldpods, cache.GetCachedPods())
+
+	// A newer snapshot should replace the cached pods.
+	cache.UpdatePods([]*Pod{newpods[0].Pod, newpods[1].Pod}, time.Now().Add(20*time.Second))
+	comparePods(t, newpods, cache.GetCachedPods())
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"time"
+)
+
+// SelfTestCheckResult describes the outcome of a single runtime self-test check.
+type SelfTestCheckResult struct {
+	// Name identifies the check, e.g. "docker-version" or "socat".
+	Name string
+	// Critical indicates that the kubelet cannot reliably run pods while this
+	// check fails, and that the node should be marked not ready.
+	Critical bool
+	// Error is the failure observed by the check.
+	Error error
+}
+
+// SelfTester is implemented by runtimes that can validate the node's
+// prerequisites (binary availability, version minimums, network configuration,
+// required images) at kubelet startup, before the first pod exercises a broken
+// path.
+type SelfTester interface {
+	// SelfTest runs the runtime's self-test checks and returns one result per
+	// failed check. An empty result means all checks passed.
+	SelfTest() []SelfTestCheckResult
+}
+
+// RunSelfTestCheck runs check and returns a SelfTestCheckResult if it failed or
+// did not complete within timeout, or nil if it passed. The timeout bounds each
+// individual check so that a wedged runtime daemon or network plugin cannot
+// stall kubelet startup indefinitely.
+func RunSelfTestCheck(name string, critical bool, timeout time.Duration, check func() error) *SelfTestCheckResult {
+	done := make(chan error, 1)
+	go func() { done <- check() }()
+	select {
+	case err := <-done:
+		if err == nil {
+			return nil
+		}
+		return &SelfTestCheckResult{Name: name, Critical: critical, Error: err}
+	case <-time.After(timeout):
+		return &SelfTestCheckResult{Name: name, Critical: critical, Error: fmt.Errorf("check did not complete within %v", timeout)}
+	}
+}
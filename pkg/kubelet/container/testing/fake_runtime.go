@@ -52,6 +52,7 @@ type FakeRuntime struct {
 	VersionInfo       string
 	APIVersionInfo    string
 	RuntimeType       string
+	RuntimeStatus     *RuntimeStatus
 	Err               error
 	InspectErr        error
 	StatusErr         error
@@ -98,6 +99,8 @@ func (f *FakeRuntimeCache) ForceUpdateIfOlder(time.Time) error {
 	return nil
 }
 
+func (f *FakeRuntimeCache) UpdatePods([]*Pod, time.Time) {}
+
 // ClearCalls resets the FakeRuntime to the initial state.
 func (f *FakeRuntime) ClearCalls() {
 	f.Lock()
@@ -113,6 +116,7 @@ func (f *FakeRuntime) ClearCalls() {
 	f.KilledContainers = []string{}
 	f.VersionInfo = ""
 	f.RuntimeType = ""
+	f.RuntimeStatus = nil
 	f.Err = nil
 	f.InspectErr = nil
 	f.StatusErr = nil
@@ -176,12 +180,22 @@ func (f *FakeRuntime) APIVersion() (Version, error) {
 	return &FakeVersion{Version: f.APIVersionInfo}, f.Err
 }
 
-func (f *FakeRuntime) Status() error {
+func (f *FakeRuntime) Status() (*RuntimeStatus, error) {
 	f.Lock()
 	defer f.Unlock()
 
 	f.CalledFunctions = append(f.CalledFunctions, "Status")
-	return f.StatusErr
+	status := f.RuntimeStatus
+	if status == nil {
+		// Report a fully ready runtime unless the test faked a status.
+		status = &RuntimeStatus{
+			Conditions: []RuntimeCondition{
+				{Type: RuntimeReady, Status: true},
+				{Type: NetworkReady, Status: true},
+			},
+		}
+	}
+	return status, f.StatusErr
 }
 
 func (f *FakeRuntime) GetPods(all bool) ([]*Pod, error) {
@@ -393,6 +407,22 @@ func (f *FakeRuntime) DeleteContainer(containerID ContainerID) error {
 	return f.Err
 }
 
+func (f *FakeRuntime) GetContainerStats(containerID ContainerID) (*ContainerStats, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.CalledFunctions = append(f.CalledFunctions, "GetContainerStats")
+	return &ContainerStats{}, f.Err
+}
+
+func (f *FakeRuntime) UpdateContainerResources(containerID ContainerID, resources *api.ResourceRequirements) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.CalledFunctions = append(f.CalledFunctions, "UpdateContainerResources")
+	return f.Err
+}
+
 func (f *FakeRuntime) ImageStats() (*ImageStats, error) {
 	f.Lock()
 	defer f.Unlock()
@@ -54,9 +54,9 @@ func (r *Mock) APIVersion() (Version, error) {
 	return args.Get(0).(Version), args.Error(1)
 }
 
-func (r *Mock) Status() error {
+func (r *Mock) Status() (*RuntimeStatus, error) {
 	args := r.Called()
-	return args.Error(0)
+	return args.Get(0).(*RuntimeStatus), args.Error(1)
 }
 
 func (r *Mock) GetPods(all bool) ([]*Pod, error) {
@@ -149,6 +149,16 @@ func (r *Mock) DeleteContainer(containerID ContainerID) error {
 	return args.Error(0)
 }
 
+func (r *Mock) GetContainerStats(containerID ContainerID) (*ContainerStats, error) {
+	args := r.Called(containerID)
+	return args.Get(0).(*ContainerStats), args.Error(1)
+}
+
+func (r *Mock) UpdateContainerResources(containerID ContainerID, resources *api.ResourceRequirements) error {
+	args := r.Called(containerID, resources)
+	return args.Error(0)
+}
+
 func (r *Mock) ImageStats() (*ImageStats, error) {
 	args := r.Called()
 	return args.Get(0).(*ImageStats), args.Error(1)
@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsuite
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+)
+
+// NewRunningPod returns a two-container pod fixture mirroring the pods
+// used throughout the runtime manager tests.
+func NewRunningPod() *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			RestartPolicy: api.RestartPolicyAlways,
+			Containers: []api.Container{
+				{Name: "bar", Image: "bar-image"},
+				{Name: "baz", Image: "baz-image"},
+			},
+		},
+	}
+}
+
+// NewSingleContainerPod returns a one-container pod fixture with the given
+// restart policy, for scenarios that exercise container lifecycle
+// decisions.
+func NewSingleContainerPod(policy api.RestartPolicy) *api.Pod {
+	pod := NewRunningPod()
+	pod.Spec.Containers = pod.Spec.Containers[:1]
+	pod.Spec.RestartPolicy = policy
+	return pod
+}
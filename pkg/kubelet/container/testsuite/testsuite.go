@@ -0,0 +1,294 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testsuite provides a conformance-style test suite that exercises
+// the kubecontainer.Runtime interface. Runtimes (docker, rkt, fakes) wire
+// the suite into their own test packages by providing a Harness backed by
+// their fakes, so that behavioral divergence between the runtimes fails
+// their unit tests instead of surfacing in e2e.
+package testsuite
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+// Harness gives the suite access to a runtime under test and the fakes
+// backing it. A harness is only used for a single scenario, so
+// implementations do not need to support resetting state.
+type Harness interface {
+	// Runtime returns the runtime under test.
+	Runtime() kubecontainer.Runtime
+	// InjectRunningPod primes the runtime's backing fakes so that the
+	// given pod, including any runtime-internal infrastructure
+	// containers, appears to be running.
+	InjectRunningPod(pod *api.Pod)
+}
+
+// SyncHarness is implemented by harnesses whose fakes are complete enough
+// to drive SyncPod and KillPod. Runtimes that cannot support this (e.g.
+// rkt, whose sync path requires systemd and on-disk unit files) only run
+// the read-only scenarios.
+type SyncHarness interface {
+	Harness
+	// SyncPod runs a single sync iteration for the pod against the
+	// runtime's current state.
+	SyncPod(pod *api.Pod) error
+	// TerminateContainer rewrites a container previously injected with
+	// InjectRunningPod so that it appears exited with the given code.
+	TerminateContainer(pod *api.Pod, containerName string, exitCode int)
+	// StoppedContainers returns the names of the containers the runtime
+	// has stopped, in the order they were stopped. Infrastructure
+	// containers are reported under names not present in the pod spec.
+	StoppedContainers() []string
+}
+
+// Factory constructs a fresh harness backed by a clean runtime instance.
+// It is invoked once per scenario so that scenarios do not share state.
+type Factory func(t *testing.T) Harness
+
+// RunConformance runs all suite scenarios against the runtime produced by
+// the factory. Scenarios that require a SyncHarness are skipped for
+// harnesses that do not implement it.
+func RunConformance(t *testing.T, newHarness Factory) {
+	testGetPods(t, newHarness)
+	testPodStatus(t, newHarness)
+	testKillPod(t, newHarness)
+	testRestartPolicy(t, newHarness)
+	testContainerSpecChange(t, newHarness)
+}
+
+// syncHarness returns a SyncHarness for the scenario, or nil if the
+// runtime's fakes cannot drive SyncPod.
+func syncHarness(t *testing.T, newHarness Factory) SyncHarness {
+	h := newHarness(t)
+	sh, ok := h.(SyncHarness)
+	if !ok {
+		t.Logf("runtime %q does not support sync scenarios, skipping", h.Runtime().Type())
+		return nil
+	}
+	return sh
+}
+
+// testGetPods verifies that a running pod is reported by GetPods with its
+// identity and all of its containers intact.
+func testGetPods(t *testing.T, newHarness Factory) {
+	h := newHarness(t)
+	pod := NewRunningPod()
+	h.InjectRunningPod(pod)
+
+	pods, err := h.Runtime().GetPods(false)
+	if err != nil {
+		t.Errorf("GetPods: unexpected error: %v", err)
+		return
+	}
+	runningPod := kubecontainer.Pods(pods).FindPodByID(pod.UID)
+	if runningPod.IsEmpty() {
+		t.Errorf("GetPods: pod %q not found in %#v", pod.UID, pods)
+		return
+	}
+	if runningPod.Name != pod.Name || runningPod.Namespace != pod.Namespace {
+		t.Errorf("GetPods: expected pod %q/%q, got %q/%q", pod.Namespace, pod.Name, runningPod.Namespace, runningPod.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		c := runningPod.FindContainerByName(container.Name)
+		if c == nil {
+			t.Errorf("GetPods: container %q not found in pod %#v", container.Name, runningPod)
+			continue
+		}
+		if c.ID.IsEmpty() {
+			t.Errorf("GetPods: container %q has no ID", container.Name)
+		}
+	}
+}
+
+// testPodStatus verifies that GetPodStatus populates the pod identity and
+// a running status for every container in the spec.
+func testPodStatus(t *testing.T, newHarness Factory) {
+	h := newHarness(t)
+	pod := NewRunningPod()
+	h.InjectRunningPod(pod)
+
+	status, err := h.Runtime().GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		t.Errorf("GetPodStatus: unexpected error: %v", err)
+		return
+	}
+	if status.ID != pod.UID || status.Name != pod.Name || status.Namespace != pod.Namespace {
+		t.Errorf("GetPodStatus: expected identity %q/%q/%q, got %q/%q/%q",
+			pod.UID, pod.Namespace, pod.Name, status.ID, status.Namespace, status.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		cs := status.FindContainerStatusByName(container.Name)
+		if cs == nil {
+			t.Errorf("GetPodStatus: no status for container %q in %#v", container.Name, status)
+			continue
+		}
+		if cs.ID.IsEmpty() {
+			t.Errorf("GetPodStatus: container %q has no ID", container.Name)
+		}
+		if cs.State != kubecontainer.ContainerStateRunning {
+			t.Errorf("GetPodStatus: container %q in state %q, expected running", container.Name, cs.State)
+		}
+	}
+}
+
+// testKillPod verifies that KillPod stops every container of the pod, and
+// that application containers are stopped before any runtime-internal
+// infrastructure container.
+func testKillPod(t *testing.T, newHarness Factory) {
+	h := syncHarness(t, newHarness)
+	if h == nil {
+		return
+	}
+	pod := NewRunningPod()
+	h.InjectRunningPod(pod)
+
+	pods, err := h.Runtime().GetPods(false)
+	if err != nil {
+		t.Errorf("KillPod: unexpected error: %v", err)
+		return
+	}
+	runningPod := kubecontainer.Pods(pods).FindPodByID(pod.UID)
+	if err := h.Runtime().KillPod(pod, runningPod, nil); err != nil {
+		t.Errorf("KillPod: unexpected error: %v", err)
+		return
+	}
+
+	specContainers := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		specContainers[container.Name] = false
+	}
+	// Application containers must all be stopped, and none of them may be
+	// stopped after an infrastructure container.
+	sawInfra := false
+	for _, name := range h.StoppedContainers() {
+		if _, ok := specContainers[name]; !ok {
+			sawInfra = true
+			continue
+		}
+		if sawInfra {
+			t.Errorf("KillPod: container %q stopped after an infrastructure container", name)
+		}
+		specContainers[name] = true
+	}
+	for name, stopped := range specContainers {
+		if !stopped {
+			t.Errorf("KillPod: container %q was not stopped", name)
+		}
+	}
+
+	pods, err = h.Runtime().GetPods(false)
+	if err != nil {
+		t.Errorf("KillPod: unexpected error: %v", err)
+		return
+	}
+	if p := kubecontainer.Pods(pods).FindPodByID(pod.UID); !p.IsEmpty() {
+		t.Errorf("KillPod: pod still has running containers: %#v", p)
+	}
+}
+
+// testRestartPolicy verifies that a sync iteration after a container exit
+// restarts the container if and only if the pod's restart policy says so.
+func testRestartPolicy(t *testing.T, newHarness Factory) {
+	for _, tc := range []struct {
+		policy   api.RestartPolicy
+		exitCode int
+		restart  bool
+	}{
+		{api.RestartPolicyAlways, 0, true},
+		{api.RestartPolicyAlways, 1, true},
+		{api.RestartPolicyOnFailure, 0, false},
+		{api.RestartPolicyOnFailure, 1, true},
+		{api.RestartPolicyNever, 0, false},
+		{api.RestartPolicyNever, 1, false},
+	} {
+		h := syncHarness(t, newHarness)
+		if h == nil {
+			return
+		}
+		pod := NewSingleContainerPod(tc.policy)
+		containerName := pod.Spec.Containers[0].Name
+		h.InjectRunningPod(pod)
+		h.TerminateContainer(pod, containerName, tc.exitCode)
+		if err := h.SyncPod(pod); err != nil {
+			t.Errorf("restart policy %q, exit code %d: unexpected sync error: %v", tc.policy, tc.exitCode, err)
+			continue
+		}
+		if running := containerIsRunning(t, h, pod, containerName); running != tc.restart {
+			t.Errorf("restart policy %q, exit code %d: container running = %v, expected %v",
+				tc.policy, tc.exitCode, running, tc.restart)
+		}
+	}
+}
+
+// testContainerSpecChange verifies that changing a container's spec causes
+// the next sync iteration to replace the running container.
+func testContainerSpecChange(t *testing.T, newHarness Factory) {
+	h := syncHarness(t, newHarness)
+	if h == nil {
+		return
+	}
+	pod := NewSingleContainerPod(api.RestartPolicyAlways)
+	containerName := pod.Spec.Containers[0].Name
+	h.InjectRunningPod(pod)
+
+	oldID := runningContainerID(t, h, pod, containerName)
+	if oldID.IsEmpty() {
+		t.Errorf("spec change: injected container %q is not running", containerName)
+		return
+	}
+
+	pod.Spec.Containers[0].Image = "changed-image:v2"
+	if err := h.SyncPod(pod); err != nil {
+		t.Errorf("spec change: unexpected sync error: %v", err)
+		return
+	}
+
+	newID := runningContainerID(t, h, pod, containerName)
+	if newID.IsEmpty() {
+		t.Errorf("spec change: container %q is not running after sync", containerName)
+		return
+	}
+	if newID == oldID {
+		t.Errorf("spec change: container %q was not restarted", containerName)
+	}
+}
+
+// containerIsRunning reports whether the named container of the pod shows
+// up as running via GetPods.
+func containerIsRunning(t *testing.T, h Harness, pod *api.Pod, containerName string) bool {
+	id := runningContainerID(t, h, pod, containerName)
+	return !id.IsEmpty()
+}
+
+// runningContainerID returns the ID of the named running container of the
+// pod, or an empty ID if it is not running.
+func runningContainerID(t *testing.T, h Harness, pod *api.Pod, containerName string) kubecontainer.ContainerID {
+	pods, err := h.Runtime().GetPods(false)
+	if err != nil {
+		t.Errorf("GetPods: unexpected error: %v", err)
+		return kubecontainer.ContainerID{}
+	}
+	runningPod := kubecontainer.Pods(pods).FindPodByID(pod.UID)
+	c := runningPod.FindContainerByName(containerName)
+	if c == nil {
+		return kubecontainer.ContainerID{}
+	}
+	return c.ID
+}
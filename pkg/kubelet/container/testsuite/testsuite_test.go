@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsuite
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
+)
+
+// fakeRuntimeHarness runs the suite against containertest.FakeRuntime.
+// The fake records calls rather than modeling lifecycle, so only the
+// read-only scenarios apply.
+type fakeRuntimeHarness struct {
+	runtime *containertest.FakeRuntime
+}
+
+func (h *fakeRuntimeHarness) Runtime() kubecontainer.Runtime {
+	return h.runtime
+}
+
+func (h *fakeRuntimeHarness) InjectRunningPod(pod *api.Pod) {
+	var containers []*kubecontainer.Container
+	var statuses []*kubecontainer.ContainerStatus
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		id := kubecontainer.BuildContainerID("fake", fmt.Sprintf("%s_%s", pod.UID, c.Name))
+		containers = append(containers, &kubecontainer.Container{
+			ID:    id,
+			Name:  c.Name,
+			Image: c.Image,
+			Hash:  kubecontainer.HashContainer(c),
+			State: kubecontainer.ContainerStateRunning,
+		})
+		statuses = append(statuses, &kubecontainer.ContainerStatus{
+			ID:    id,
+			Name:  c.Name,
+			Image: c.Image,
+			State: kubecontainer.ContainerStateRunning,
+		})
+	}
+	h.runtime.PodList = []*containertest.FakePod{{
+		Pod: &kubecontainer.Pod{
+			ID:         pod.UID,
+			Name:       pod.Name,
+			Namespace:  pod.Namespace,
+			Containers: containers,
+		},
+	}}
+	h.runtime.PodStatus = kubecontainer.PodStatus{
+		ID:                pod.UID,
+		Name:              pod.Name,
+		Namespace:         pod.Namespace,
+		ContainerStatuses: statuses,
+	}
+}
+
+func TestFakeRuntimeConformance(t *testing.T) {
+	RunConformance(t, func(t *testing.T) Harness {
+		return &fakeRuntimeHarness{runtime: &containertest.FakeRuntime{}}
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/container/testsuite"
+	"k8s.io/kubernetes/pkg/util/flowcontrol"
+)
+
+// dockerConformanceHarness runs the shared runtime conformance suite
+// against the docker manager backed by FakeDockerClient.
+type dockerConformanceHarness struct {
+	manager    *DockerManager
+	fakeDocker *FakeDockerClient
+	// injected holds the fake containers set up by InjectRunningPod so
+	// that TerminateContainer can rewrite them.
+	injected []*FakeContainer
+	// names maps injected container IDs back to their container names.
+	names map[string]string
+}
+
+func newDockerConformanceHarness(t *testing.T) testsuite.Harness {
+	manager, fakeDocker := newTestDockerManager()
+	return &dockerConformanceHarness{
+		manager:    manager,
+		fakeDocker: fakeDocker,
+		names:      map[string]string{},
+	}
+}
+
+func (h *dockerConformanceHarness) Runtime() kubecontainer.Runtime {
+	return h.manager
+}
+
+func (h *dockerConformanceHarness) InjectRunningPod(pod *api.Pod) {
+	infraID := fmt.Sprintf("%s-infra", pod.UID)
+	h.injected = []*FakeContainer{{
+		ID: infraID,
+		Name: fmt.Sprintf("/k8s_POD.%s_%s_%s_%s_42",
+			strconv.FormatUint(generatePodInfraContainerHash(pod), 16), pod.Name, pod.Namespace, pod.UID),
+	}}
+	h.names[infraID] = PodInfraContainerName
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		id := fmt.Sprintf("%s-%d", pod.UID, i)
+		h.injected = append(h.injected, &FakeContainer{
+			ID: id,
+			Name: fmt.Sprintf("/k8s_%s.%s_%s_%s_%s_42",
+				container.Name, strconv.FormatUint(kubecontainer.HashContainer(container), 16),
+				pod.Name, pod.Namespace, pod.UID),
+		})
+		h.names[id] = container.Name
+	}
+	h.fakeDocker.SetFakeRunningContainers(h.injected)
+}
+
+func (h *dockerConformanceHarness) TerminateContainer(pod *api.Pod, containerName string, exitCode int) {
+	for _, c := range h.injected {
+		if h.names[c.ID] != containerName {
+			continue
+		}
+		c.Running = false
+		c.ExitCode = exitCode
+		c.FinishedAt = time.Now()
+	}
+	h.fakeDocker.SetFakeContainers(h.injected)
+}
+
+func (h *dockerConformanceHarness) SyncPod(pod *api.Pod) error {
+	podStatus, err := h.manager.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		return err
+	}
+	backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+	result := h.manager.SyncPod(pod, api.PodStatus{}, podStatus, []api.Secret{}, backOff)
+	return result.Error()
+}
+
+func (h *dockerConformanceHarness) StoppedContainers() []string {
+	h.fakeDocker.Lock()
+	defer h.fakeDocker.Unlock()
+	var names []string
+	for _, id := range h.fakeDocker.Stopped {
+		if name, ok := h.names[id]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+func TestRuntimeConformance(t *testing.T) {
+	testsuite.RunConformance(t, newDockerConformanceHarness)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/docker/docker/pkg/jsonmessage"
 	dockerapi "github.com/docker/engine-api/client"
 	dockertypes "github.com/docker/engine-api/types"
+	dockercontainer "github.com/docker/engine-api/types/container"
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/credentialprovider"
@@ -81,6 +82,14 @@ type DockerInterface interface {
 	AttachToContainer(string, dockertypes.ContainerAttachOptions, StreamOptions) error
 	ResizeContainerTTY(id string, height, width int) error
 	ResizeExecTTY(id string, height, width int) error
+	ContainerStats(id string) (*dockertypes.Stats, error)
+	UpdateContainer(id string, updateConfig dockercontainer.UpdateConfig) error
+	// CreateCheckpoint checkpoints a running container via CRIU, writing
+	// the checkpoint bundle below checkpointDir.
+	CreateCheckpoint(id string, checkpointID string, checkpointDir string) error
+	// StartContainerFromCheckpoint starts a created container from the
+	// named checkpoint bundle below checkpointDir.
+	StartContainerFromCheckpoint(id string, checkpointID string, checkpointDir string) error
 }
 
 // KubeletContainerName encapsulates a pod name and a Kubernetes container name.
@@ -194,35 +203,28 @@ func matchImageTagOrSHA(inspected dockertypes.ImageInspect, image string) bool {
 	return false
 }
 
-// applyDefaultImageTag parses a docker image string, if it doesn't contain any tag or digest,
-// a default tag will be applied.
-func applyDefaultImageTag(image string) (string, error) {
-	named, err := dockerref.ParseNamed(image)
+// canonicalImageSpelling returns the canonical spelling of the image
+// reference, or the input unchanged when it cannot be parsed.
+func canonicalImageSpelling(image string) string {
+	ref, err := parsers.ParseImageRef(image)
 	if err != nil {
-		return "", fmt.Errorf("couldn't parse image reference %q: %v", image, err)
+		return image
 	}
-	_, isTagged := named.(dockerref.Tagged)
-	_, isDigested := named.(dockerref.Digested)
-	if !isTagged && !isDigested {
-		named, err := dockerref.WithTag(named, parsers.DefaultImageTag)
-		if err != nil {
-			return "", fmt.Errorf("failed to apply default image tag %q: %v", image, err)
-		}
-		image = named.String()
-	}
-	return image, nil
+	return ref.String()
 }
 
 func (p dockerPuller) Pull(image string, secrets []api.Secret) error {
-	// If the image contains no tag or digest, a default tag should be applied.
-	image, err := applyDefaultImageTag(image)
+	// Pull under the canonical spelling, so the pull, the presence check
+	// and the backoff key all agree on one name for the image.
+	ref, err := parsers.ParseImageRef(image)
 	if err != nil {
-		return err
+		return fmt.Errorf("couldn't parse image reference %q: %v", image, err)
 	}
+	image = ref.String()
 
-	keyring, err := credentialprovider.MakeDockerKeyring(secrets, p.keyring)
-	if err != nil {
-		return err
+	keyring, secretErrors := credentialprovider.MakeDockerKeyring(secrets, p.keyring)
+	for _, secretError := range secretErrors {
+		glog.Warningf("Unable to use image pull secret: %v", secretError)
 	}
 
 	// The only used image pull option RegistryAuth will be set in kube_docker_client
@@ -245,8 +247,7 @@ func (p dockerPuller) Pull(image string, secrets []api.Secret) error {
 			return nil
 		}
 
-		// Image spec: [<registry>/]<repository>/<image>[:<version] so we count '/'
-		explicitRegistry := (strings.Count(image, "/") == 2)
+		explicitRegistry := ref.Registry != parsers.DefaultRegistry
 		// Hack, look for a private registry, and decorate the error with the lack of
 		// credentials.  This is heuristic, and really probably could be done better
 		// by talking to the registry API directly from the kubelet here.
@@ -272,7 +273,9 @@ func (p dockerPuller) Pull(image string, secrets []api.Secret) error {
 }
 
 func (p dockerPuller) IsImagePresent(image string) (bool, error) {
-	_, err := p.client.InspectImage(image)
+	// Inspect under the canonical spelling, so an image pulled under an
+	// equivalent spelling is recognized as present.
+	_, err := p.client.InspectImage(canonicalImageSpelling(image))
 	if err == nil {
 		return true, nil
 	}
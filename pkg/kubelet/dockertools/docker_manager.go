@@ -28,6 +28,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +37,7 @@ import (
 	"github.com/coreos/go-semver/semver"
 	dockertypes "github.com/docker/engine-api/types"
 	dockercontainer "github.com/docker/engine-api/types/container"
+	dockerfilters "github.com/docker/engine-api/types/filters"
 	dockerstrslice "github.com/docker/engine-api/types/strslice"
 	dockerapiversion "github.com/docker/engine-api/types/versions"
 	dockernat "github.com/docker/go-connections/nat"
@@ -61,6 +63,9 @@ import (
 	"k8s.io/kubernetes/pkg/security/apparmor"
 	"k8s.io/kubernetes/pkg/securitycontext"
 	kubetypes "k8s.io/kubernetes/pkg/types"
+	utilcache "k8s.io/kubernetes/pkg/util/cache"
+	"k8s.io/kubernetes/pkg/util/clock"
+	utilconfig "k8s.io/kubernetes/pkg/util/config"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/util/oom"
 	"k8s.io/kubernetes/pkg/util/procfs"
@@ -102,6 +107,16 @@ const (
 
 	// The expiration time of version cache.
 	versionCacheTTL = 60 * time.Second
+
+	// How many images one docker ListImages call fetches at most; nodes with
+	// thousands of cached images are listed in batches of this size.
+	imageListPageSize = 1024
+
+	// The default expiration time of inspect cache entries.
+	defaultInspectCacheTTL = 1 * time.Second
+
+	// How many inspect results the inspect cache holds at most.
+	inspectCacheSize = 512
 )
 
 var (
@@ -170,6 +185,12 @@ type DockerManager struct {
 	// Support for gathering custom metrics.
 	enableCustomMetrics bool
 
+	// Container instances an OOMKilling event has already been emitted for,
+	// so repeated inspections of the same dead container do not repeat the
+	// event.
+	oomKilledLock       sync.Mutex
+	oomKilledContainers sets.String
+
 	// If true, the "hairpin mode" flag is set on container interfaces.
 	// A false value means the kubelet just backs off from setting it,
 	// it might already be true.
@@ -181,8 +202,33 @@ type DockerManager struct {
 	// The version cache of docker daemon.
 	versionCache *cache.ObjectCache
 
+	// Cache of recent container inspect results, keyed by container ID, so
+	// that the repeated inspects of one sync cycle hit the docker daemon
+	// only once per container.
+	inspectCache *utilcache.LRUExpireCache
+
+	// How long cached inspect results stay valid.
+	inspectCacheTTL time.Duration
+
 	// Directory to host local seccomp profiles.
 	seccompProfileRoot string
+
+	// Source of all time reads in the manager, so tests can inject a fake
+	// clock instead of faking timestamps in docker state.
+	clock clock.Clock
+
+	// Containers with a create/start currently in flight, keyed by pod UID
+	// and container name, so a sync triggered while a previous slow start is
+	// still running does not create a duplicate container.
+	startingLock       sync.Mutex
+	startingContainers map[containerStartKey]struct{}
+}
+
+// containerStartKey identifies a container within a pod for in-flight start
+// tracking.
+type containerStartKey struct {
+	podUID kubetypes.UID
+	name   string
 }
 
 // A subset of the pod.Manager interface extracted for testing purposes.
@@ -223,9 +269,11 @@ func NewDockerManager(
 	cpuCFSQuota bool,
 	imageBackOff *flowcontrol.Backoff,
 	serializeImagePulls bool,
+	imagePullTimeout time.Duration,
 	enableCustomMetrics bool,
 	hairpinMode bool,
 	seccompProfileRoot string,
+	registryPolicy *images.RegistryPolicy,
 	options ...kubecontainer.Option) *DockerManager {
 	// Wrap the docker client with instrumentedDockerInterface
 	client = NewInstrumentedDockerInterface(client)
@@ -260,12 +308,15 @@ func NewDockerManager(
 		procFs:                 procFs,
 		cpuCFSQuota:            cpuCFSQuota,
 		enableCustomMetrics:    enableCustomMetrics,
+		oomKilledContainers:    sets.NewString(),
 		configureHairpinMode:   hairpinMode,
 		imageStatsProvider:     newImageStatsProvider(client),
 		seccompProfileRoot:     seccompProfileRoot,
+		clock:                  clock.RealClock{},
+		startingContainers:     make(map[containerStartKey]struct{}),
 	}
 	dm.runner = lifecycle.NewHandlerRunner(httpClient, dm, dm)
-	dm.imagePuller = images.NewImageManager(kubecontainer.FilterEventRecorder(recorder), dm, imageBackOff, serializeImagePulls, qps, burst)
+	dm.imagePuller = images.NewImageManager(kubecontainer.FilterEventRecorder(recorder), dm, imageBackOff, serializeImagePulls, qps, burst, imagePullTimeout, registryPolicy)
 	dm.containerGC = NewContainerGC(client, podGetter, containerLogsDir)
 
 	dm.versionCache = cache.NewObjectCache(
@@ -280,6 +331,11 @@ func NewDockerManager(
 		optf(dm)
 	}
 
+	// Built after the options so the cache expires entries against any
+	// injected clock.
+	dm.inspectCache = utilcache.NewLRUExpireCacheWithClock(inspectCacheSize, dm.clock)
+	dm.inspectCacheTTL = defaultInspectCacheTTL
+
 	return dm
 }
 
@@ -364,9 +420,45 @@ func (dm *DockerManager) determineContainerIP(podNamespace, podName string, cont
 	return result, nil
 }
 
+// cachedInspectContainer returns the inspect result for the container,
+// consulting the time-limited inspect cache first. SyncPod inspects the same
+// container several times per sync cycle, and this collapses the repeated
+// round-trips to the docker daemon. Entries are invalidated whenever the
+// container's state is mutated, and the status path refreshes them with
+// freshInspectContainer, so within the TTL a cached result can only be stale
+// with respect to changes made outside the kubelet since the last status
+// poll.
+func (dm *DockerManager) cachedInspectContainer(id string) (*dockertypes.ContainerJSON, error) {
+	if value, ok := dm.inspectCache.Get(id); ok {
+		return value.(*dockertypes.ContainerJSON), nil
+	}
+	return dm.freshInspectContainer(id)
+}
+
+// freshInspectContainer always asks the docker daemon and refreshes the
+// cached entry with the answer. The status path uses it so that a container
+// that dies on its own - the state change the kubelet never performs, and so
+// can never invalidate for - is observed immediately rather than after the
+// TTL, which matters for crash-loop backoff accounting.
+func (dm *DockerManager) freshInspectContainer(id string) (*dockertypes.ContainerJSON, error) {
+	inspect, err := dm.client.InspectContainer(id)
+	if err != nil {
+		dm.invalidateInspectCache(id)
+		return nil, err
+	}
+	dm.inspectCache.Add(id, inspect, dm.inspectCacheTTL)
+	return inspect, nil
+}
+
+// invalidateInspectCache drops the cached inspect result for the container.
+// It must be called after every operation that mutates the container's state.
+func (dm *DockerManager) invalidateInspectCache(id string) {
+	dm.inspectCache.Remove(id)
+}
+
 func (dm *DockerManager) inspectContainer(id string, podName, podNamespace string) (*kubecontainer.ContainerStatus, string, error) {
 	var ip string
-	iResult, err := dm.client.InspectContainer(id)
+	iResult, err := dm.freshInspectContainer(id)
 	if err != nil {
 		return nil, ip, err
 	}
@@ -434,6 +526,10 @@ func (dm *DockerManager) inspectContainer(id string, podName, podNamespace strin
 		// code could be 0.
 		if iResult.State.OOMKilled {
 			reason = "OOMKilled"
+			if message == "" && iResult.HostConfig != nil && iResult.HostConfig.Memory > 0 {
+				message = fmt.Sprintf("Memory limit of %d bytes exceeded", iResult.HostConfig.Memory)
+			}
+			dm.reportOOMKilledContainer(id, containerName, podName, podNamespace, containerInfo.PodUID, iResult.HostConfig)
 		} else if iResult.State.ExitCode == 0 {
 			reason = "Completed"
 		} else if !finishedAt.IsZero() {
@@ -459,6 +555,18 @@ func (dm *DockerManager) inspectContainer(id string, podName, podNamespace strin
 				}
 			}
 		}
+		if message == "" && iResult.State.ExitCode != 0 &&
+			containerInfo.TerminationMessagePolicy == TerminationMessageFallbackToLogsOnError {
+			// The container crashed without writing a termination message, so
+			// fall back to the tail of its log to give the user something
+			// actionable in the container status.
+			if data, err := readLastTerminationMessageFromLogs(iResult.LogPath); err != nil {
+				glog.Errorf("Error on reading termination message from logs %s for container %q of pod %q: %v",
+					iResult.LogPath, containerName, kubecontainer.BuildPodFullName(podName, podNamespace), err)
+			} else {
+				message = data
+			}
+		}
 		status.State = kubecontainer.ContainerStateExited
 		status.Message = message
 		status.Reason = reason
@@ -472,6 +580,77 @@ func (dm *DockerManager) inspectContainer(id string, podName, podNamespace strin
 	return &status, "", nil
 }
 
+// reportOOMKilledContainer emits an OOMKilling warning event on the pod the
+// first time the OOM kill of the given container instance is observed.
+func (dm *DockerManager) reportOOMKilledContainer(id, containerName, podName, podNamespace string, podUID kubetypes.UID, hostConfig *dockercontainer.HostConfig) {
+	dm.oomKilledLock.Lock()
+	alreadyReported := dm.oomKilledContainers.Has(id)
+	dm.oomKilledContainers.Insert(id)
+	dm.oomKilledLock.Unlock()
+	if alreadyReported {
+		return
+	}
+	ref := &api.ObjectReference{
+		Kind:      "Pod",
+		Name:      podName,
+		Namespace: podNamespace,
+		UID:       podUID,
+	}
+	message := fmt.Sprintf("Container %s was OOM killed", containerName)
+	if hostConfig != nil && hostConfig.Memory > 0 {
+		message = fmt.Sprintf("%s (memory limit %d bytes)", message, hostConfig.Memory)
+	}
+	dm.recorder.Event(ref, api.EventTypeWarning, events.OOMKilling, message)
+}
+
+const (
+	// maxTerminationMessageLogLength is the maximum number of bytes read from
+	// the tail of the container log when it serves as the termination message.
+	maxTerminationMessageLogLength = 1024 * 2
+	// maxTerminationMessageLogLines is the maximum number of log lines used as
+	// the termination message.
+	maxTerminationMessageLogLines = 80
+	// terminationMessageTruncationMarker is prepended to a termination message
+	// built from the container log when the log had to be truncated.
+	terminationMessageTruncationMarker = "[log truncated]"
+)
+
+// readLastTerminationMessageFromLogs reads the tail of the container log file
+// at logPath, bounded by maxTerminationMessageLogLength bytes and
+// maxTerminationMessageLogLines lines. If the log is longer than either bound,
+// the returned message starts with terminationMessageTruncationMarker.
+func readLastTerminationMessageFromLogs(logPath string) (string, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := int64(0)
+	truncated := false
+	if info.Size() > maxTerminationMessageLogLength {
+		offset = info.Size() - maxTerminationMessageLogLength
+		truncated = true
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(data, offset); err != nil && err != io.EOF {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > maxTerminationMessageLogLines {
+		lines = lines[len(lines)-maxTerminationMessageLogLines:]
+		truncated = true
+	}
+	message := strings.Join(lines, "\n")
+	if truncated {
+		message = terminationMessageTruncationMarker + "\n" + message
+	}
+	return message, nil
+}
+
 // makeEnvList converts EnvVar list to a list of strings, in the form of
 // '<key>=<value>', which can be understood by docker.
 func makeEnvList(envs []kubecontainer.EnvVar) (result []string) {
@@ -558,6 +737,60 @@ func makePortsAndBindings(portMappings []kubecontainer.PortMapping) (map[dockern
 	return exposedPorts, portBindings
 }
 
+var (
+	// namespacedSysctlPrefixes lists the sysctl prefixes that are namespaced by
+	// the kernel and therefore safe to set per pod. This mirrors the namespacing
+	// knowledge in pkg/kubelet/sysctl, which cannot be imported from here
+	// without creating an import cycle.
+	namespacedSysctlPrefixes = []string{"net.", "kernel.shm", "kernel.msg", "fs.mqueue."}
+	// namespacedSysctls lists the individual namespaced sysctls that none of
+	// the prefixes above cover.
+	namespacedSysctls = []string{"kernel.sem"}
+)
+
+// ForbiddenSysctlError is returned when a pod requests a sysctl that is not
+// known to be namespaced by the kernel, or a net.* sysctl while sharing the
+// host's network namespace. It surfaces through SyncPod as the sync error of
+// the container that could not be created.
+type ForbiddenSysctlError struct {
+	Sysctl string
+	Reason string
+}
+
+func (e *ForbiddenSysctlError) Error() string {
+	return fmt.Sprintf("forbidden sysctl %q: %s", e.Sysctl, e.Reason)
+}
+
+// validatePodSysctls checks that the sysctls requested via the pod's
+// annotations are known to be namespaced by the kernel, and that pods sharing
+// the host's network namespace request no net.* sysctls.
+func validatePodSysctls(pod *api.Pod, sysctlLists ...[]api.Sysctl) error {
+	for _, list := range sysctlLists {
+		for _, s := range list {
+			namespaced := false
+			for _, prefix := range namespacedSysctlPrefixes {
+				if strings.HasPrefix(s.Name, prefix) {
+					namespaced = true
+					break
+				}
+			}
+			for _, name := range namespacedSysctls {
+				if s.Name == name {
+					namespaced = true
+					break
+				}
+			}
+			if !namespaced {
+				return &ForbiddenSysctlError{Sysctl: s.Name, Reason: "not known to be namespaced"}
+			}
+			if strings.HasPrefix(s.Name, "net.") && kubecontainer.IsHostNetworkPod(pod) {
+				return &ForbiddenSysctlError{Sysctl: s.Name, Reason: "not allowed with host networking enabled"}
+			}
+		}
+	}
+	return nil
+}
+
 func (dm *DockerManager) runContainer(
 	pod *api.Pod,
 	container *api.Container,
@@ -673,6 +906,10 @@ func (dm *DockerManager) runContainer(
 		return kubecontainer.ContainerID{}, err
 	}
 	if len(sysctls)+len(unsafeSysctls) > 0 {
+		if err := validatePodSysctls(pod, sysctls, unsafeSysctls); err != nil {
+			dm.recorder.Eventf(ref, api.EventTypeWarning, events.SysctlForbidden, "Failed to create docker container %q of pod %q: %v", container.Name, format.Pod(pod), err)
+			return kubecontainer.ContainerID{}, err
+		}
 		hc.Sysctls = make(map[string]string, len(sysctls)+len(unsafeSysctls))
 		for _, c := range sysctls {
 			hc.Sysctls[c.Name] = c.Value
@@ -730,10 +967,26 @@ func (dm *DockerManager) runContainer(
 	securityContextProvider := securitycontext.NewSimpleSecurityContextProvider()
 	securityContextProvider.ModifyContainerConfig(pod, container, dockerOpts.Config)
 	securityContextProvider.ModifyHostConfig(pod, container, dockerOpts.HostConfig, supplementalGids)
+	createStart := dm.clock.Now()
 	createResp, err := dm.client.CreateContainer(dockerOpts)
+	metrics.SyncPodOperationDuration.WithLabelValues(metrics.CreateContainerOperation).Observe(dm.clock.Since(createStart).Seconds())
+	if err != nil && classifyDockerError(err).Category == DockerErrorConflict {
+		// A stale container is holding the name, e.g. left behind by a create
+		// that succeeded after its result was lost. Remove it and retry the
+		// create once within the same sync.
+		if staleID := conflictingContainerID(err); staleID != "" {
+			glog.V(2).Infof("Removing stale container %q holding name %q of pod %q", staleID, containerName, format.Pod(pod))
+			if removeErr := dm.client.RemoveContainer(staleID, dockertypes.ContainerRemoveOptions{RemoveVolumes: true}); removeErr != nil {
+				glog.Errorf("Failed to remove stale container %q of pod %q: %v", staleID, format.Pod(pod), removeErr)
+			} else {
+				dm.invalidateInspectCache(staleID)
+				createResp, err = dm.client.CreateContainer(dockerOpts)
+			}
+		}
+	}
 	if err != nil {
 		dm.recorder.Eventf(ref, api.EventTypeWarning, events.FailedToCreateContainer, "Failed to create docker container %q of pod %q with error: %v", container.Name, format.Pod(pod), err)
-		return kubecontainer.ContainerID{}, err
+		return kubecontainer.ContainerID{}, classifyDockerError(err)
 	}
 	if len(createResp.Warnings) != 0 {
 		glog.V(2).Infof("Container %q of pod %q created with warnings: %v", container.Name, format.Pod(pod), createResp.Warnings)
@@ -754,10 +1007,14 @@ func (dm *DockerManager) runContainer(
 	}
 	dm.recorder.Eventf(ref, api.EventTypeNormal, events.CreatedContainer, createdEventMsg)
 
-	if err = dm.client.StartContainer(createResp.ID); err != nil {
+	startStart := dm.clock.Now()
+	err = dm.client.StartContainer(createResp.ID)
+	metrics.SyncPodOperationDuration.WithLabelValues(metrics.StartContainerOperation).Observe(dm.clock.Since(startStart).Seconds())
+	dm.invalidateInspectCache(createResp.ID)
+	if err != nil {
 		dm.recorder.Eventf(ref, api.EventTypeWarning, events.FailedToStartContainer,
 			"Failed to start container with docker id %v with error: %v", utilstrings.ShortenString(createResp.ID, 12), err)
-		return kubecontainer.ContainerID{}, err
+		return kubecontainer.ContainerID{}, classifyDockerError(err)
 	}
 	dm.recorder.Eventf(ref, api.EventTypeNormal, events.StartedContainer, "Started container with docker id %v", utilstrings.ShortenString(createResp.ID, 12))
 
@@ -835,10 +1092,16 @@ func (dm *DockerManager) GetContainers(all bool) ([]*kubecontainer.Container, er
 	return result, nil
 }
 
+// sinceInMicroseconds mirrors metrics.SinceInMicroseconds, but measures with
+// the manager's clock so injected fake clocks stay consistent.
+func (dm *DockerManager) sinceInMicroseconds(start time.Time) float64 {
+	return float64(dm.clock.Since(start).Nanoseconds() / time.Microsecond.Nanoseconds())
+}
+
 func (dm *DockerManager) GetPods(all bool) ([]*kubecontainer.Pod, error) {
-	start := time.Now()
+	start := dm.clock.Now()
 	defer func() {
-		metrics.ContainerManagerLatency.WithLabelValues("GetPods").Observe(metrics.SinceInMicroseconds(start))
+		metrics.ContainerManagerLatency.WithLabelValues("GetPods").Observe(dm.sinceInMicroseconds(start))
 	}()
 	pods := make(map[kubetypes.UID]*kubecontainer.Pod)
 	var result []*kubecontainer.Pod
@@ -882,22 +1145,83 @@ func (dm *DockerManager) GetPods(all bool) ([]*kubecontainer.Pod, error) {
 }
 
 // List all images in the local storage.
+// ListImages lists all images, fetching them from docker in batches of
+// imageListPageSize so that nodes with thousands of cached images do not
+// force one huge response. The result is sorted by image ID.
 func (dm *DockerManager) ListImages() ([]kubecontainer.Image, error) {
+	return dm.ListImagesWithFilter(nil)
+}
+
+// ListImagesWithFilter lists the images matching the given filter, sorted by
+// image ID so the order is deterministic. The dangling and label filters are
+// translated to docker's own filter args so filtered listings do not transfer
+// every image record; the name prefix filter is applied client-side.
+func (dm *DockerManager) ListImagesWithFilter(filter *kubecontainer.ImageListFilter) ([]kubecontainer.Image, error) {
 	var images []kubecontainer.Image
 
-	dockerImages, err := dm.client.ListImages(dockertypes.ImageListOptions{})
+	pageToken := ""
+	for {
+		page, nextToken, err := dm.listImages(imageListPageSize, 0, pageToken, filter)
+		if err != nil {
+			return images, err
+		}
+		images = append(images, page...)
+		if len(nextToken) == 0 {
+			break
+		}
+		pageToken = nextToken
+	}
+	images = kubecontainer.FilterImages(images, filter)
+	sort.Sort(kubecontainer.ImagesByID(images))
+	return images, nil
+}
+
+// listImages returns one batch of at most pageSize images; zero means no
+// limit. A non-zero maxAge skips images created before the cutoff, measured
+// with the manager's clock. An empty pageToken starts from the newest image,
+// and batches use docker's "before" image filter as the cursor: the returned
+// token resumes after the last image of the batch and is empty once the
+// listing is exhausted. The parts of the filter docker can evaluate itself
+// are added to the filter args; the rest is left to the caller.
+func (dm *DockerManager) listImages(pageSize int, maxAge time.Duration, pageToken string, filter *kubecontainer.ImageListFilter) ([]kubecontainer.Image, string, error) {
+	opts := dockertypes.ImageListOptions{Filters: dockerfilters.NewArgs()}
+	if filter != nil {
+		if filter.Dangling {
+			opts.Filters.Add("dangling", "true")
+		}
+		for k, v := range filter.Labels {
+			opts.Filters.Add("label", fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+	if len(pageToken) > 0 {
+		opts.Filters.Add("before", pageToken)
+	}
+	dockerImages, err := dm.client.ListImages(opts)
 	if err != nil {
-		return images, err
+		return nil, "", err
 	}
 
+	var cutoff int64
+	if maxAge > 0 {
+		cutoff = dm.clock.Now().Add(-maxAge).Unix()
+	}
+	images := []kubecontainer.Image{}
+	nextToken := ""
 	for _, di := range dockerImages {
+		if maxAge > 0 && di.Created < cutoff {
+			continue
+		}
 		image, err := toRuntimeImage(&di)
 		if err != nil {
 			continue
 		}
 		images = append(images, *image)
+		if pageSize > 0 && len(images) == pageSize {
+			nextToken = di.ID
+			break
+		}
 	}
-	return images, nil
+	return images, nextToken, nil
 }
 
 // PullImage pulls an image from network to local storage.
@@ -932,7 +1256,7 @@ func (dm *DockerManager) podInfraContainerChanged(pod *api.Pod, podInfraContaine
 
 	// Check network mode.
 	if kubecontainer.IsHostNetworkPod(pod) {
-		dockerPodInfraContainer, err := dm.client.InspectContainer(podInfraContainerStatus.ID.ID)
+		dockerPodInfraContainer, err := dm.cachedInspectContainer(podInfraContainerStatus.ID.ID)
 		if err != nil {
 			return false, err
 		}
@@ -1051,12 +1375,27 @@ func (dm *DockerManager) APIVersion() (kubecontainer.Version, error) {
 	return apiVersion(v.APIVersion), nil
 }
 
-// Status returns error if docker daemon is unhealthy, nil otherwise.
-// Now we do this by checking whether:
+// Status returns the status of the docker runtime. The runtime is considered
+// ready when:
 // 1) `docker version` works
 // 2) docker version is compatible with minimum requirement
-func (dm *DockerManager) Status() error {
-	return dm.checkVersionCompatibility()
+// Network readiness is delegated to the network plugin.
+func (dm *DockerManager) Status() (*kubecontainer.RuntimeStatus, error) {
+	runtimeReady := kubecontainer.RuntimeCondition{Type: kubecontainer.RuntimeReady, Status: true}
+	if err := dm.checkVersionCompatibility(); err != nil {
+		runtimeReady.Status = false
+		runtimeReady.Reason = "DockerDaemonNotReady"
+		runtimeReady.Message = fmt.Sprintf("docker daemon is not healthy: %v", err)
+	}
+	networkReady := kubecontainer.RuntimeCondition{Type: kubecontainer.NetworkReady, Status: true}
+	if err := dm.networkPlugin.Status(); err != nil {
+		networkReady.Status = false
+		networkReady.Reason = "NetworkPluginNotReady"
+		networkReady.Message = fmt.Sprintf("network plugin %q is not ready: %v", dm.networkPlugin.Name(), err)
+	}
+	return &kubecontainer.RuntimeStatus{
+		Conditions: []kubecontainer.RuntimeCondition{runtimeReady, networkReady},
+	}, nil
 }
 
 func (dm *DockerManager) checkVersionCompatibility() error {
@@ -1155,7 +1494,7 @@ func (dm *DockerManager) getSeccompOpts(pod *api.Pod, ctrName string) ([]dockerO
 		return defaultSeccompOpt, nil
 	}
 
-	if profile == "docker/default" {
+	if profile == "docker/default" || profile == "runtime/default" {
 		// return nil so docker will load the default seccomp profile
 		return nil, nil
 	}
@@ -1220,8 +1559,14 @@ func (dm *DockerManager) ExecInContainer(containerID kubecontainer.ContainerID,
 		return errors.New("unable to exec without an exec handler")
 	}
 
-	container, err := dm.client.InspectContainer(containerID.ID)
+	container, err := dm.cachedInspectContainer(containerID.ID)
 	if err != nil {
+		if _, ok := err.(containerNotFoundError); ok {
+			// The container (and most likely the whole pod sandbox) is gone;
+			// report the same condition as PortForward so callers trigger a
+			// full pod sync instead of retrying the exec.
+			return &NoPodInfraContainerError{}
+		}
 		return err
 	}
 	if !container.State.Running {
@@ -1260,8 +1605,35 @@ func AttachContainer(client DockerInterface, containerID kubecontainer.Container
 	return client.AttachToContainer(containerID.ID, opts, sopts)
 }
 
-func noPodInfraContainerError(podName, podNamespace string) error {
-	return fmt.Errorf("cannot find pod infra container in pod %q", kubecontainer.BuildPodFullName(podName, podNamespace))
+// NoPodInfraContainerError is returned by operations that need a running pod
+// infra container when the pod has none, e.g. because its sandbox has been
+// torn down. Callers should treat the condition as "this pod needs a full
+// sync" rather than retrying the operation; use IsNoPodInfraContainer to
+// detect it.
+type NoPodInfraContainerError struct {
+	// PodFullName identifies the affected pod when known.
+	PodFullName string
+}
+
+// NewNoPodInfraContainerError returns a NoPodInfraContainerError for the given pod.
+func NewNoPodInfraContainerError(podName, podNamespace string) *NoPodInfraContainerError {
+	return &NoPodInfraContainerError{
+		PodFullName: kubecontainer.BuildPodFullName(podName, podNamespace),
+	}
+}
+
+func (e *NoPodInfraContainerError) Error() string {
+	if e.PodFullName == "" {
+		return "cannot find pod infra container"
+	}
+	return fmt.Sprintf("cannot find pod infra container in pod %q", e.PodFullName)
+}
+
+// IsNoPodInfraContainer returns true if the error reports that a pod has no
+// running pod infra container.
+func IsNoPodInfraContainer(err error) bool {
+	_, ok := err.(*NoPodInfraContainerError)
+	return ok
 }
 
 // PortForward executes socat in the pod's network namespace and copies
@@ -1275,9 +1647,9 @@ func noPodInfraContainerError(podName, podNamespace string) error {
 func (dm *DockerManager) PortForward(pod *kubecontainer.Pod, port uint16, stream io.ReadWriteCloser) error {
 	podInfraContainer := pod.FindContainerByName(PodInfraContainerName)
 	if podInfraContainer == nil {
-		return noPodInfraContainerError(pod.Name, pod.Namespace)
+		return NewNoPodInfraContainerError(pod.Name, pod.Namespace)
 	}
-	container, err := dm.client.InspectContainer(podInfraContainer.ID.ID)
+	container, err := dm.cachedInspectContainer(podInfraContainer.ID.ID)
 	if err != nil {
 		return err
 	}
@@ -1343,66 +1715,70 @@ func (dm *DockerManager) KillPod(pod *api.Pod, runningPod kubecontainer.Pod, gra
 	return result.Error()
 }
 
+// podContainerSpec returns the spec of the named container in the pod, looking
+// at both the app and the init containers. It returns nil if the pod is nil or
+// does not have such a container.
+func podContainerSpec(pod *api.Pod, name string) *api.Container {
+	if pod == nil {
+		return nil
+	}
+	for i, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	for i, c := range pod.Spec.InitContainers {
+		if c.Name == name {
+			return &pod.Spec.InitContainers[i]
+		}
+	}
+	return nil
+}
+
+// containersByName sorts containers lexically by name so that kill order is
+// stable across syncs.
+type containersByName []*kubecontainer.Container
+
+func (c containersByName) Len() int           { return len(c) }
+func (c containersByName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c containersByName) Less(i, j int) bool { return c[i].Name < c[j].Name }
+
 // NOTE(random-liu): The pod passed in could be *nil* when kubelet restarted.
+// The containers are killed in a deterministic order: the app containers
+// first, sorted by name, and the pod infra container always last, so that
+// PreStop handlers can still run in the pod's network namespace and the
+// network is only torn down once every app container is gone.
 func (dm *DockerManager) killPodWithSyncResult(pod *api.Pod, runningPod kubecontainer.Pod, gracePeriodOverride *int64) (result kubecontainer.PodSyncResult) {
-	// Send the kills in parallel since they may take a long time.
-	// There may be len(runningPod.Containers) or len(runningPod.Containers)-1 of result in the channel
-	containerResults := make(chan *kubecontainer.SyncResult, len(runningPod.Containers))
-	wg := sync.WaitGroup{}
 	var (
 		networkContainer *kubecontainer.Container
 		networkSpec      *api.Container
 	)
-	wg.Add(len(runningPod.Containers))
+	containers := make([]*kubecontainer.Container, 0, len(runningPod.Containers))
 	for _, container := range runningPod.Containers {
-		go func(container *kubecontainer.Container) {
-			defer utilruntime.HandleCrash()
-			defer wg.Done()
-
-			var containerSpec *api.Container
-			if pod != nil {
-				for i, c := range pod.Spec.Containers {
-					if c.Name == container.Name {
-						containerSpec = &pod.Spec.Containers[i]
-						break
-					}
-				}
-				if containerSpec == nil {
-					for i, c := range pod.Spec.InitContainers {
-						if c.Name == container.Name {
-							containerSpec = &pod.Spec.InitContainers[i]
-							break
-						}
-					}
-				}
-			}
-
-			// TODO: Handle this without signaling the pod infra container to
-			// adapt to the generic container runtime.
-			if container.Name == PodInfraContainerName {
-				// Store the container runtime for later deletion.
-				// We do this so that PreStop handlers can run in the network namespace.
-				networkContainer = container
-				networkSpec = containerSpec
-				return
-			}
-
-			killContainerResult := kubecontainer.NewSyncResult(kubecontainer.KillContainer, container.Name)
-			err := dm.KillContainerInPod(container.ID, containerSpec, pod, "Need to kill pod.", gracePeriodOverride)
-			if err != nil {
-				killContainerResult.Fail(kubecontainer.ErrKillContainer, err.Error())
-				glog.Errorf("Failed to delete container %v: %v; Skipping pod %q", container.ID.ID, err, runningPod.ID)
-			}
-			containerResults <- killContainerResult
-		}(container)
+		// TODO: Handle this without signaling the pod infra container to
+		// adapt to the generic container runtime.
+		if container.Name == PodInfraContainerName {
+			// Store the container runtime for later deletion.
+			// We do this so that PreStop handlers can run in the network namespace.
+			networkContainer = container
+			networkSpec = podContainerSpec(pod, container.Name)
+			continue
+		}
+		containers = append(containers, container)
 	}
-	wg.Wait()
-	close(containerResults)
-	for containerResult := range containerResults {
-		result.AddSyncResult(containerResult)
+	sort.Sort(containersByName(containers))
+
+	for _, container := range containers {
+		killContainerResult := kubecontainer.NewSyncResult(kubecontainer.KillContainer, container.Name)
+		result.AddSyncResult(killContainerResult)
+		err := dm.KillContainerInPod(container.ID, podContainerSpec(pod, container.Name), pod, "Need to kill pod.", gracePeriodOverride)
+		if err != nil {
+			killContainerResult.Fail(kubecontainer.ErrKillContainer, err.Error())
+			glog.Errorf("Failed to delete container %v: %v; Skipping pod %q", container.ID.ID, err, runningPod.ID)
+		}
 	}
 	if networkContainer != nil {
-		ins, err := dm.client.InspectContainer(networkContainer.ID.ID)
+		ins, err := dm.cachedInspectContainer(networkContainer.ID.ID)
 		if err != nil {
 			err = fmt.Errorf("Error inspecting container %v: %v", networkContainer.ID.ID, err)
 			glog.Error(err)
@@ -1448,7 +1824,7 @@ func (dm *DockerManager) KillContainerInPod(containerID kubecontainer.ContainerI
 
 	case container == nil || pod == nil:
 		// Read information about the container from labels
-		inspect, err := dm.client.InspectContainer(containerID.ID)
+		inspect, err := dm.cachedInspectContainer(containerID.ID)
 		if err != nil {
 			return err
 		}
@@ -1498,7 +1874,10 @@ func (dm *DockerManager) killContainer(containerID kubecontainer.ContainerID, co
 		go func() {
 			defer close(done)
 			defer utilruntime.HandleCrash()
-			if msg, err := dm.runner.Run(containerID, pod, container, container.Lifecycle.PreStop); err != nil {
+			handlerStart := dm.clock.Now()
+			msg, err := dm.runner.Run(containerID, pod, container, container.Lifecycle.PreStop)
+			metrics.SyncPodOperationDuration.WithLabelValues(metrics.RunHandlerOperation).Observe(dm.clock.Since(handlerStart).Seconds())
+			if err != nil {
 				glog.Errorf("preStop hook for container %q failed: %v", name, err)
 				dm.generateFailedContainerEvent(containerID, pod.Name, events.FailedPreStopHook, msg)
 			}
@@ -1526,7 +1905,10 @@ func (dm *DockerManager) killContainer(containerID kubecontainer.ContainerID, co
 		glog.V(2).Infof("Killing container %q, but using %d second grace period override", name, gracePeriod)
 	}
 
+	stopStart := dm.clock.Now()
 	err := dm.client.StopContainer(ID, int(gracePeriod))
+	metrics.SyncPodOperationDuration.WithLabelValues(metrics.StopContainerOperation).Observe(dm.clock.Since(stopStart).Seconds())
+	dm.invalidateInspectCache(ID)
 	if err == nil {
 		glog.V(2).Infof("Container %q exited after %s", name, unversioned.Now().Sub(start.Time))
 	} else {
@@ -1636,9 +2018,9 @@ func (dm *DockerManager) applyOOMScoreAdj(pod *api.Pod, container *api.Container
 // Run a single container from a pod. Returns the docker container ID
 // If do not need to pass labels, just pass nil.
 func (dm *DockerManager) runContainerInPod(pod *api.Pod, container *api.Container, netMode, ipcMode, pidMode, podIP string, restartCount int) (kubecontainer.ContainerID, error) {
-	start := time.Now()
+	start := dm.clock.Now()
 	defer func() {
-		metrics.ContainerManagerLatency.WithLabelValues("runContainerInPod").Observe(metrics.SinceInMicroseconds(start))
+		metrics.ContainerManagerLatency.WithLabelValues("runContainerInPod").Observe(dm.sinceInMicroseconds(start))
 	}()
 
 	ref, err := kubecontainer.GenerateContainerRef(pod, container)
@@ -1671,7 +2053,9 @@ func (dm *DockerManager) runContainerInPod(pod *api.Pod, container *api.Containe
 	}
 
 	if container.Lifecycle != nil && container.Lifecycle.PostStart != nil {
+		handlerStart := dm.clock.Now()
 		msg, handlerErr := dm.runner.Run(id, pod, container, container.Lifecycle.PostStart)
+		metrics.SyncPodOperationDuration.WithLabelValues(metrics.RunHandlerOperation).Observe(dm.clock.Since(handlerStart).Seconds())
 		if handlerErr != nil {
 			err := fmt.Errorf("PostStart handler: %v", handlerErr)
 			dm.generateFailedContainerEvent(id, pod.Name, events.FailedPostStartHook, msg)
@@ -1681,7 +2065,7 @@ func (dm *DockerManager) runContainerInPod(pod *api.Pod, container *api.Containe
 	}
 
 	// Container information is used in adjusting OOM scores, adding ndots and getting the logPath.
-	containerInfo, err := dm.client.InspectContainer(id.ID)
+	containerInfo, err := dm.cachedInspectContainer(id.ID)
 	if err != nil {
 		return kubecontainer.ContainerID{}, fmt.Errorf("InspectContainer: %v", err)
 	}
@@ -1807,9 +2191,9 @@ func appendToFile(filePath, stringToAppend string) error {
 // createPodInfraContainer starts the pod infra container for a pod. Returns the docker container ID of the newly created container.
 // If any error occurs in this function, it will return a brief error and a detailed error message.
 func (dm *DockerManager) createPodInfraContainer(pod *api.Pod) (kubecontainer.DockerID, error, string) {
-	start := time.Now()
+	start := dm.clock.Now()
 	defer func() {
-		metrics.ContainerManagerLatency.WithLabelValues("createPodInfraContainer").Observe(metrics.SinceInMicroseconds(start))
+		metrics.ContainerManagerLatency.WithLabelValues("createPodInfraContainer").Observe(dm.sinceInMicroseconds(start))
 	}()
 	// Use host networking if specified.
 	netNamespace := ""
@@ -1874,10 +2258,33 @@ type podContainerChangesSpec struct {
 	ContainersToKeep     map[kubecontainer.DockerID]int
 }
 
+// findPreferredContainerStatus returns the status for the given container. When
+// duplicate containers exist for the same name (e.g. because a previous sync
+// raced a slow start), it prefers a running instance whose hash matches the
+// current spec, so the duplicate-cleanup path keeps the up-to-date container
+// rather than an arbitrary one. Otherwise it falls back to the first match,
+// matching FindContainerStatusByName.
+func findPreferredContainerStatus(podStatus *kubecontainer.PodStatus, container *api.Container) *kubecontainer.ContainerStatus {
+	expectedHash := kubecontainer.HashContainer(container)
+	var first *kubecontainer.ContainerStatus
+	for _, containerStatus := range podStatus.ContainerStatuses {
+		if containerStatus.Name != container.Name {
+			continue
+		}
+		if containerStatus.State == kubecontainer.ContainerStateRunning && containerStatus.Hash == expectedHash {
+			return containerStatus
+		}
+		if first == nil {
+			first = containerStatus
+		}
+	}
+	return first
+}
+
 func (dm *DockerManager) computePodContainerChanges(pod *api.Pod, podStatus *kubecontainer.PodStatus) (podContainerChangesSpec, error) {
-	start := time.Now()
+	start := dm.clock.Now()
 	defer func() {
-		metrics.ContainerManagerLatency.WithLabelValues("computePodContainerChanges").Observe(metrics.SinceInMicroseconds(start))
+		metrics.ContainerManagerLatency.WithLabelValues("computePodContainerChanges").Observe(dm.sinceInMicroseconds(start))
 	}()
 	glog.V(5).Infof("Syncing Pod %q: %#v", format.Pod(pod), pod)
 
@@ -1936,7 +2343,7 @@ func (dm *DockerManager) computePodContainerChanges(pod *api.Pod, podStatus *kub
 	// check the status of the containers
 	for index, container := range pod.Spec.Containers {
 
-		containerStatus := podStatus.FindContainerStatusByName(container.Name)
+		containerStatus := findPreferredContainerStatus(podStatus, &container)
 		if containerStatus == nil || containerStatus.State != kubecontainer.ContainerStateRunning {
 			if kubecontainer.ShouldContainerBeRestarted(&container, pod, podStatus) {
 				// If we are here it means that the container is dead and should be restarted, or never existed and should
@@ -2027,9 +2434,9 @@ func (dm *DockerManager) computePodContainerChanges(pod *api.Pod, podStatus *kub
 
 // Sync the running pod to match the specified desired pod.
 func (dm *DockerManager) SyncPod(pod *api.Pod, _ api.PodStatus, podStatus *kubecontainer.PodStatus, pullSecrets []api.Secret, backOff *flowcontrol.Backoff) (result kubecontainer.PodSyncResult) {
-	start := time.Now()
+	start := dm.clock.Now()
 	defer func() {
-		metrics.ContainerManagerLatency.WithLabelValues("SyncPod").Observe(metrics.SinceInMicroseconds(start))
+		metrics.ContainerManagerLatency.WithLabelValues("SyncPod").Observe(dm.sinceInMicroseconds(start))
 	}()
 
 	containerChanges, err := dm.computePodContainerChanges(pod, podStatus)
@@ -2119,7 +2526,9 @@ func (dm *DockerManager) SyncPod(pod *api.Pod, _ api.PodStatus, podStatus *kubec
 		result.AddSyncResult(setupNetworkResult)
 		if !kubecontainer.IsHostNetworkPod(pod) {
 			glog.V(3).Infof("Calling network plugin %s to setup pod for %s", dm.networkPlugin.Name(), format.Pod(pod))
+			setupNetworkStart := dm.clock.Now()
 			err = dm.networkPlugin.SetUpPod(pod.Namespace, pod.Name, podInfraContainerID.ContainerID())
+			metrics.SyncPodOperationDuration.WithLabelValues(metrics.SetupNetworkOperation).Observe(dm.clock.Since(setupNetworkStart).Seconds())
 			if err != nil {
 				// TODO: (random-liu) There shouldn't be "Skipping pod" in sync result message
 				message := fmt.Sprintf("Failed to setup network for pod %q using network plugins %q: %v; Skipping pod", format.Pod(pod), dm.networkPlugin.Name(), err)
@@ -2139,7 +2548,7 @@ func (dm *DockerManager) SyncPod(pod *api.Pod, _ api.PodStatus, podStatus *kubec
 			}
 
 			// Setup the host interface unless the pod is on the host's network (FIXME: move to networkPlugin when ready)
-			podInfraContainer, err := dm.client.InspectContainer(string(podInfraContainerID))
+			podInfraContainer, err := dm.cachedInspectContainer(string(podInfraContainerID))
 			if err != nil {
 				glog.Errorf("Failed to inspect pod infra container: %v; Skipping pod %q", err, format.Pod(pod))
 				result.Fail(err)
@@ -2206,10 +2615,22 @@ func (dm *DockerManager) SyncPod(pod *api.Pod, _ api.PodStatus, podStatus *kubec
 			}
 		}
 
+		if !dm.markContainerStarting(pod.UID, container.Name) {
+			glog.V(4).Infof("A start for init container %q in pod %v is already in flight, skipping", container.Name, format.Pod(pod))
+			return
+		}
 		glog.V(4).Infof("Creating init container %+v in pod %v", container, format.Pod(pod))
-		if err, msg := dm.tryContainerStart(container, pod, podStatus, pullSecrets, namespaceMode, pidMode, podIP); err != nil {
+		err, msg := dm.tryContainerStart(container, pod, podStatus, pullSecrets, namespaceMode, pidMode, podIP)
+		dm.finishContainerStarting(pod.UID, container.Name)
+		if err != nil {
 			startContainerResult.Fail(err, msg)
 			utilruntime.HandleError(fmt.Errorf("container start failed: %v: %s", err, msg))
+			if isTransientDockerError(err) {
+				// A transient docker failure (e.g. the daemon restarting)
+				// says nothing about the container itself, so don't count
+				// the attempt against its crash-loop backoff.
+				backOff.Reset(containerBackoffKey(pod, container))
+			}
 			return
 		}
 
@@ -2244,20 +2665,56 @@ func (dm *DockerManager) SyncPod(pod *api.Pod, _ api.PodStatus, podStatus *kubec
 			}
 		}
 
+		if !dm.markContainerStarting(pod.UID, container.Name) {
+			glog.V(4).Infof("A start for container %q in pod %v is already in flight, skipping", container.Name, format.Pod(pod))
+			continue
+		}
 		glog.V(4).Infof("Creating container %+v in pod %v", container, format.Pod(pod))
-		if err, msg := dm.tryContainerStart(container, pod, podStatus, pullSecrets, namespaceMode, pidMode, podIP); err != nil {
+		err, msg := dm.tryContainerStart(container, pod, podStatus, pullSecrets, namespaceMode, pidMode, podIP)
+		dm.finishContainerStarting(pod.UID, container.Name)
+		if err != nil {
 			startContainerResult.Fail(err, msg)
 			utilruntime.HandleError(fmt.Errorf("container start failed: %v: %s", err, msg))
+			if isTransientDockerError(err) {
+				// See the init container start path above: transient docker
+				// failures don't advance the crash-loop backoff.
+				backOff.Reset(containerBackoffKey(pod, container))
+			}
 			continue
 		}
 	}
 	return
 }
 
+// markContainerStarting records that a create/start for the given container is
+// in flight. It returns false if a start for the same container is already in
+// flight, in which case the caller must skip creating the container instead of
+// racing the earlier start and producing a duplicate.
+func (dm *DockerManager) markContainerStarting(podUID kubetypes.UID, name string) bool {
+	dm.startingLock.Lock()
+	defer dm.startingLock.Unlock()
+	key := containerStartKey{podUID: podUID, name: name}
+	if _, inFlight := dm.startingContainers[key]; inFlight {
+		return false
+	}
+	dm.startingContainers[key] = struct{}{}
+	return true
+}
+
+// finishContainerStarting clears the in-flight marker set by
+// markContainerStarting, regardless of whether the start succeeded.
+func (dm *DockerManager) finishContainerStarting(podUID kubetypes.UID, name string) {
+	dm.startingLock.Lock()
+	defer dm.startingLock.Unlock()
+	delete(dm.startingContainers, containerStartKey{podUID: podUID, name: name})
+}
+
 // tryContainerStart attempts to pull and start the container, returning an error and a reason string if the start
 // was not successful.
 func (dm *DockerManager) tryContainerStart(container *api.Container, pod *api.Pod, podStatus *kubecontainer.PodStatus, pullSecrets []api.Secret, namespaceMode, pidMode, podIP string) (err error, reason string) {
+	pullStart := dm.clock.Now()
 	err, msg := dm.imagePuller.EnsureImageExists(pod, container, pullSecrets)
+	metrics.SyncPodOperationDuration.WithLabelValues(metrics.PullImageOperation).Observe(dm.clock.Since(pullStart).Seconds())
 	if err != nil {
 		return err, msg
 	}
@@ -2278,6 +2735,11 @@ func (dm *DockerManager) tryContainerStart(container *api.Container, pod *api.Po
 
 	_, err = dm.runContainerInPod(pod, container, namespaceMode, namespaceMode, pidMode, podIP, restartCount)
 	if err != nil {
+		// Preserve the docker error category so SyncPod can tell transient
+		// daemon failures apart from genuinely failed starts.
+		if derr := classifyDockerError(err); derr.Category != DockerErrorFatal {
+			return derr, err.Error()
+		}
 		// TODO(bburns) : Perhaps blacklist a container after N failures?
 		return kubecontainer.ErrRunContainer, err.Error()
 	}
@@ -2313,6 +2775,7 @@ func (dm *DockerManager) pruneInitContainersBeforeStart(pod *api.Pod, podStatus
 			// prune all other init containers that match this container name
 			// TODO: we may not need aggressive pruning
 			glog.V(4).Infof("Removing init container %q instance %q %d", status.Name, status.ID.ID, count)
+			dm.invalidateInspectCache(status.ID.ID)
 			if err := dm.client.RemoveContainer(status.ID.ID, dockertypes.ContainerRemoveOptions{RemoveVolumes: true}); err != nil {
 				if _, ok := err.(containerNotFoundError); ok {
 					count--
@@ -2443,12 +2906,7 @@ func (dm *DockerManager) doBackOff(pod *api.Pod, container *api.Container, podSt
 		glog.Infof("checking backoff for container %q in pod %q", container.Name, pod.Name)
 		ts := cStatus.FinishedAt
 		// found a container that requires backoff
-		dockerName := KubeletContainerName{
-			PodFullName:   kubecontainer.GetPodFullName(pod),
-			PodUID:        pod.UID,
-			ContainerName: container.Name,
-		}
-		stableName, _, _ := BuildDockerName(dockerName, container)
+		stableName := containerBackoffKey(pod, container)
 		if backOff.IsInBackOffSince(stableName, ts) {
 			if ref, err := kubecontainer.GenerateContainerRef(pod, container); err == nil {
 				dm.recorder.Eventf(ref, api.EventTypeWarning, events.BackOffStartContainer, "Back-off restarting failed docker container")
@@ -2462,6 +2920,18 @@ func (dm *DockerManager) doBackOff(pod *api.Pod, container *api.Container, podSt
 	return false, nil, ""
 }
 
+// containerBackoffKey returns the key used to track restart backoff for the
+// given container.
+func containerBackoffKey(pod *api.Pod, container *api.Container) string {
+	dockerName := KubeletContainerName{
+		PodFullName:   kubecontainer.GetPodFullName(pod),
+		PodUID:        pod.UID,
+		ContainerName: container.Name,
+	}
+	stableName, _, _ := BuildDockerName(dockerName, container)
+	return stableName
+}
+
 // getPidMode returns the pid mode to use on the docker container based on pod.Spec.HostPID.
 func getPidMode(pod *api.Pod) string {
 	pidMode := ""
@@ -2484,9 +2954,36 @@ func (dm *DockerManager) DeleteContainer(containerID kubecontainer.ContainerID)
 	return dm.containerGC.deleteContainer(containerID.ID)
 }
 
+// GetContainerStats queries docker for a one-shot stats sample of the container.
+func (dm *DockerManager) GetContainerStats(containerID kubecontainer.ContainerID) (*kubecontainer.ContainerStats, error) {
+	stats, err := dm.client.ContainerStats(containerID.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &kubecontainer.ContainerStats{
+		CpuUsage:    stats.CPUStats.CPUUsage.TotalUsage,
+		MemoryUsage: stats.MemoryStats.Usage,
+	}, nil
+}
+
+// UpdateContainerResources updates the cgroup limits of a running container
+// via the docker update API, without restarting the container.
+func (dm *DockerManager) UpdateContainerResources(containerID kubecontainer.ContainerID, resources *api.ResourceRequirements) error {
+	updateConfig := dockercontainer.UpdateConfig{}
+	if memoryLimit := resources.Limits.Memory().Value(); memoryLimit != 0 {
+		updateConfig.Memory = memoryLimit
+	}
+	if cpuLimit := resources.Limits.Cpu(); !cpuLimit.IsZero() {
+		cpuQuota, cpuPeriod := milliCPUToQuota(cpuLimit.MilliValue())
+		updateConfig.CPUQuota = cpuQuota
+		updateConfig.CPUPeriod = cpuPeriod
+	}
+	return dm.client.UpdateContainer(containerID.ID, updateConfig)
+}
+
 // GetNetNS returns the network namespace path for the given container
 func (dm *DockerManager) GetNetNS(containerID kubecontainer.ContainerID) (string, error) {
-	inspectResult, err := dm.client.InspectContainer(containerID.ID)
+	inspectResult, err := dm.cachedInspectContainer(containerID.ID)
 	if err != nil {
 		glog.Errorf("Error inspecting container: '%v'", err)
 		return "", err
@@ -2602,3 +3099,49 @@ func truncateMsg(msg string, max int) string {
 	end := len(msg) - (max - (len(truncatedMsg) + begin))
 	return msg[:begin] + truncatedMsg + msg[end:]
 }
+
+// managedCheckpointID is the name under which container checkpoints are
+// stored in a checkpoint directory, so a restore can find the bundle from
+// the directory alone.
+const managedCheckpointID = "kubelet-checkpoint"
+
+// CheckpointContainer checkpoints the running container via CRIU, writing
+// the checkpoint bundle below checkpointDir. The container keeps running;
+// stopping it is the caller's decision. Guarded by the DockerCRIU feature
+// gate.
+func (dm *DockerManager) CheckpointContainer(id kubecontainer.ContainerID, checkpointDir string) error {
+	if !utilconfig.DefaultFeatureGate.DockerCRIU() {
+		return fmt.Errorf("checkpoint/restore is disabled by the DockerCRIU feature gate")
+	}
+	glog.V(4).Infof("Checkpointing container %q into %q", id.ID, checkpointDir)
+	return dm.client.CreateCheckpoint(id.ID, managedCheckpointID, checkpointDir)
+}
+
+// RestoreContainer creates a new docker container for the given pod's
+// container and starts it from the checkpoint bundle below checkpointDir,
+// returning the ID of the restored container. Guarded by the DockerCRIU
+// feature gate.
+func (dm *DockerManager) RestoreContainer(checkpointDir string, pod *api.Pod, container *api.Container) (kubecontainer.ContainerID, error) {
+	if !utilconfig.DefaultFeatureGate.DockerCRIU() {
+		return kubecontainer.ContainerID{}, fmt.Errorf("checkpoint/restore is disabled by the DockerCRIU feature gate")
+	}
+	glog.V(4).Infof("Restoring container %q of pod %q from %q", container.Name, format.Pod(pod), checkpointDir)
+
+	dockerName := KubeletContainerName{kubecontainer.GetPodFullName(pod), pod.UID, container.Name}
+	_, containerName, _ := BuildDockerName(dockerName, container)
+	createResp, err := dm.client.CreateContainer(dockertypes.ContainerCreateConfig{
+		Name: containerName,
+		Config: &dockercontainer.Config{
+			Image:  container.Image,
+			Labels: newLabels(container, pod, 0, dm.enableCustomMetrics),
+		},
+	})
+	if err != nil {
+		return kubecontainer.ContainerID{}, fmt.Errorf("failed to create container for restore: %v", err)
+	}
+	id := kubecontainer.DockerID(createResp.ID).ContainerID()
+	if err := dm.client.StartContainerFromCheckpoint(createResp.ID, managedCheckpointID, checkpointDir); err != nil {
+		return kubecontainer.ContainerID{}, fmt.Errorf("failed to restore container %q: %v", createResp.ID, err)
+	}
+	return id, nil
+}
@@ -29,6 +29,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -37,8 +38,10 @@ import (
 	dockerstrslice "github.com/docker/engine-api/types/strslice"
 	"github.com/golang/mock/gomock"
 	cadvisorapi "github.com/google/cadvisor/info/v1"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	"k8s.io/kubernetes/pkg/apis/componentconfig"
 	"k8s.io/kubernetes/pkg/client/record"
@@ -46,6 +49,7 @@ import (
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
 	"k8s.io/kubernetes/pkg/kubelet/events"
 	"k8s.io/kubernetes/pkg/kubelet/images"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 	"k8s.io/kubernetes/pkg/kubelet/network"
 	"k8s.io/kubernetes/pkg/kubelet/network/mock_network"
 	nettest "k8s.io/kubernetes/pkg/kubelet/network/testing"
@@ -54,22 +58,29 @@ import (
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/security/apparmor"
 	kubetypes "k8s.io/kubernetes/pkg/types"
-	"k8s.io/kubernetes/pkg/util/clock"
+	utilconfig "k8s.io/kubernetes/pkg/util/config"
 	uexec "k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/util/intstr"
-	"k8s.io/kubernetes/pkg/util/sets"
 	utilstrings "k8s.io/kubernetes/pkg/util/strings"
+	"k8s.io/kubernetes/pkg/util/wait"
 )
 
 type fakeHTTP struct {
-	url string
-	err error
+	url     string
+	timeout time.Duration
+	err     error
+	resp    *http.Response
 }
 
 func (f *fakeHTTP) Get(url string) (*http.Response, error) {
 	f.url = url
-	return nil, f.err
+	return f.resp, f.err
+}
+
+func (f *fakeHTTP) GetWithTimeout(url string, timeout time.Duration) (*http.Response, error) {
+	f.timeout = timeout
+	return f.Get(url)
 }
 
 // fakeRuntimeHelper implementes kubecontainer.RuntimeHelper inter
@@ -383,22 +394,99 @@ func TestGetPods(t *testing.T) {
 
 func TestListImages(t *testing.T) {
 	manager, fakeDocker := newTestDockerManager()
-	dockerImages := []dockertypes.Image{{ID: "1111"}, {ID: "2222"}, {ID: "3333"}}
-	expected := sets.NewString([]string{"1111", "2222", "3333"}...)
+	// Deliberately out of order: ListImages sorts the result by ID.
+	fakeDocker.Images = []dockertypes.Image{
+		{ID: "3333", RepoTags: []string{"example.com/app:v2"}},
+		{ID: "1111", RepoTags: []string{"gcr.io/pause:3.0"}},
+		{ID: "5555"},
+		{ID: "2222", RepoTags: []string{"example.com/app:v1"}},
+		{ID: "4444", RepoTags: []string{"<none>:<none>"}},
+	}
+
+	imageIDs := func(images []kubecontainer.Image) []string {
+		ids := []string{}
+		for _, i := range images {
+			ids = append(ids, i.ID)
+		}
+		return ids
+	}
 
-	fakeDocker.Images = dockerImages
 	actualImages, err := manager.ListImages()
 	if err != nil {
 		t.Fatalf("unexpected error %v", err)
 	}
-	actual := sets.NewString()
+	expected := []string{"1111", "2222", "3333", "4444", "5555"}
+	if actual := imageIDs(actualImages); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	// A name prefix filter only returns images with a matching repo tag.
+	actualImages, err = manager.ListImagesWithFilter(&kubecontainer.ImageListFilter{NamePrefix: "example.com/app"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = []string{"2222", "3333"}
+	if actual := imageIDs(actualImages); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	// A dangling filter returns only untagged images, including docker's
+	// "<none>:<none>" placeholder tag.
+	actualImages, err = manager.ListImagesWithFilter(&kubecontainer.ImageListFilter{Dangling: true})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = []string{"4444", "5555"}
+	if actual := imageIDs(actualImages); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestListImagesPaging(t *testing.T) {
+	manager, fakeDocker := newTestDockerManager()
+	fakeDocker.Images = []dockertypes.Image{{ID: "1111"}, {ID: "2222"}, {ID: "3333"}}
+
+	actual := []string{}
+	pageToken := ""
+	for {
+		page, nextToken, err := manager.listImages(1, 0, pageToken, nil)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for _, i := range page {
+			actual = append(actual, i.ID)
+		}
+		if len(nextToken) == 0 {
+			break
+		}
+		pageToken = nextToken
+	}
+	expected := []string{"1111", "2222", "3333"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestListImagesWithAgeFilter(t *testing.T) {
+	manager, fakeDocker := newTestDockerManager()
+	now := manager.fakeClock().Now()
+	fakeDocker.Images = []dockertypes.Image{
+		{ID: "1111", Created: now.Unix()},
+		{ID: "2222", Created: now.Add(-time.Hour).Unix()},
+		{ID: "3333", Created: now.Add(-48 * time.Hour).Unix()},
+	}
+
+	actualImages, _, err := manager.listImages(0, 24*time.Hour, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	actual := []string{}
 	for _, i := range actualImages {
-		actual.Insert(i.ID)
+		actual = append(actual, i.ID)
 	}
-	// We can compare the two sets directly because util.StringSet.List()
-	// returns a "sorted" list.
-	if !reflect.DeepEqual(expected.List(), actual.List()) {
-		t.Errorf("expected %#v, got %#v", expected.List(), actual.List())
+	expected := []string{"1111", "2222"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
 	}
 }
 
@@ -519,6 +607,11 @@ func TestKillContainerInPodWithPreStop(t *testing.T) {
 	if !reflect.DeepEqual(expectedCmd, fakeDocker.execCmd) {
 		t.Errorf("expected: %v, got %v", expectedCmd, fakeDocker.execCmd)
 	}
+	// The preStop hook must run in the container being killed, not in the
+	// other container of the pod.
+	if fakeDocker.execContainerID != containerToKill.ID {
+		t.Errorf("expected exec in container %q, got %q", containerToKill.ID, fakeDocker.execContainerID)
+	}
 }
 
 func TestKillContainerInPodWithError(t *testing.T) {
@@ -618,7 +711,7 @@ func TestSyncPodCreateNetAndContainer(t *testing.T) {
 	runSyncPod(t, dm, fakeDocker, pod, nil, false)
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -643,6 +736,52 @@ func TestSyncPodCreateNetAndContainer(t *testing.T) {
 	fakeDocker.Unlock()
 }
 
+// syncPodOperationCount returns the number of observations recorded so far for
+// the given sync operation. The metric is a package global, so tests compare
+// counts before and after the call they exercise.
+func syncPodOperationCount(t *testing.T, operation string) uint64 {
+	m := &dto.Metric{}
+	if err := metrics.SyncPodOperationDuration.WithLabelValues(operation).Write(m); err != nil {
+		t.Fatalf("failed to read metric for operation %q: %v", operation, err)
+	}
+	return m.Histogram.GetSampleCount()
+}
+
+func TestSyncPodRecordsOperationLatencies(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+
+	operations := []string{
+		metrics.PullImageOperation,
+		metrics.CreateContainerOperation,
+		metrics.StartContainerOperation,
+		metrics.SetupNetworkOperation,
+	}
+	before := make(map[string]uint64)
+	for _, operation := range operations {
+		before[operation] = syncPodOperationCount(t, operation)
+	}
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	for _, operation := range operations {
+		if after := syncPodOperationCount(t, operation); after <= before[operation] {
+			t.Errorf("expected observations for operation %q, count stayed at %d", operation, after)
+		}
+	}
+}
+
 func TestSyncPodCreatesNetAndContainerPullsImage(t *testing.T) {
 	dm, fakeDocker := newTestDockerManager()
 	dm.podInfraContainerImage = "pod_infra_image"
@@ -666,7 +805,7 @@ func TestSyncPodCreatesNetAndContainerPullsImage(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -745,23 +884,64 @@ func TestSyncPodDeletesWithNoPodInfraContainer(t *testing.T) {
 		// Kill the container since pod infra container is not running.
 		"stop",
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
 
-	// A map iteration is used to delete containers, so must not depend on
-	// order here.
-	expectedToStop := map[string]bool{
-		"1234": true,
-	}
+	// Containers are killed in a deterministic order, so the stop order can
+	// be asserted directly.
 	fakeDocker.Lock()
-	if len(fakeDocker.Stopped) != 1 || !expectedToStop[fakeDocker.Stopped[0]] {
+	if !reflect.DeepEqual(fakeDocker.Stopped, []string{"1234"}) {
 		t.Errorf("Wrong containers were stopped: %v", fakeDocker.Stopped)
 	}
 	fakeDocker.Unlock()
 }
 
+func TestKillPodStopsContainersInOrder(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+				{Name: "abc"},
+			},
+		},
+	}
+	fakeDocker.SetFakeRunningContainers([]*FakeContainer{
+		{ID: "9876", Name: "/k8s_POD_foo_new_12345678_0"},
+		{ID: "1111", Name: "/k8s_bar_foo_new_12345678_0"},
+		{ID: "2222", Name: "/k8s_abc_foo_new_12345678_0"},
+	})
+	runningPod := kubecontainer.Pod{
+		ID:        "12345678",
+		Name:      "foo",
+		Namespace: "new",
+		Containers: []*kubecontainer.Container{
+			{ID: kubecontainer.DockerID("1111").ContainerID(), Name: "bar"},
+			{ID: kubecontainer.DockerID("9876").ContainerID(), Name: PodInfraContainerName},
+			{ID: kubecontainer.DockerID("2222").ContainerID(), Name: "abc"},
+		},
+	}
+
+	if err := dm.KillPod(pod, runningPod, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// App containers are stopped sorted by name, the infra container last.
+	expectedOrder := []string{"2222", "1111", "9876"}
+	fakeDocker.Lock()
+	if !reflect.DeepEqual(fakeDocker.Stopped, expectedOrder) {
+		t.Errorf("expected stop order %v, got %v", expectedOrder, fakeDocker.Stopped)
+	}
+	fakeDocker.Unlock()
+}
+
 func TestSyncPodDeletesDuplicate(t *testing.T) {
 	dm, fakeDocker := newTestDockerManager()
 	pod := &api.Pod{
@@ -803,6 +983,132 @@ func TestSyncPodDeletesDuplicate(t *testing.T) {
 	}
 }
 
+func TestSyncPodDeletesDuplicateKeepsMatchingHash(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "bar",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "foo"},
+			},
+		},
+	}
+
+	// "4567" carries the hash of the current spec, "1234" does not. The
+	// cleanup must keep the up-to-date container, not an arbitrary one.
+	fakeDocker.SetFakeRunningContainers([]*FakeContainer{
+		{
+			ID:   "1234",
+			Name: "/k8s_foo.deadbeef_bar_new_12345678_1111",
+		},
+		{
+			ID:   "9876",
+			Name: "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_bar_new_12345678_2222",
+		},
+		{
+			ID:   "4567",
+			Name: "/k8s_foo." + strconv.FormatUint(kubecontainer.HashContainer(&pod.Spec.Containers[0]), 16) + "_bar_new_12345678_3333",
+		}})
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	verifyCalls(t, fakeDocker, []string{
+		// Kill the duplicated container.
+		"stop",
+	})
+	if !reflect.DeepEqual(fakeDocker.Stopped, []string{"1234"}) {
+		t.Errorf("Wrong containers were stopped: %v", fakeDocker.Stopped)
+	}
+}
+
+func TestSyncPodSkipsStartAlreadyInFlight(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar", Image: "test_image"},
+			},
+		},
+	}
+	fakeDocker.SetFakeRunningContainers([]*FakeContainer{{
+		ID: "9876",
+		// Pod infra container.
+		Name: "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
+	}})
+
+	// Hold the first create in flight until the racing sync has finished.
+	createInFlight := make(chan struct{})
+	release := make(chan struct{})
+	// Releasing unconditionally keeps the first sync's goroutine from
+	// blocking in the create hook forever if the test fails early.
+	defer close(release)
+	// Only the first create blocks; were a duplicate create to slip through,
+	// it would be caught by the count below rather than deadlocking the test.
+	var once sync.Once
+	fakeDocker.CreateHook = func(c dockertypes.ContainerCreateConfig) {
+		once.Do(func() {
+			close(createInFlight)
+			<-release
+		})
+	}
+
+	podStatus, err := dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+
+	firstSync := make(chan kubecontainer.PodSyncResult, 1)
+	go func() {
+		firstSync <- dm.SyncPod(pod, api.PodStatus{}, podStatus, []api.Secret{}, backOff)
+	}()
+	select {
+	case <-createInFlight:
+	case firstResult := <-firstSync:
+		t.Fatalf("first sync finished without creating a container: %v", firstResult.Error())
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for the first sync to reach create")
+	}
+
+	// The second sync sees the same pod status, so without the in-flight
+	// tracking it would create a duplicate "bar" container.
+	result := dm.SyncPod(pod, api.PodStatus{}, podStatus, []api.Secret{}, backOff)
+	if err := result.Error(); err != nil {
+		t.Errorf("unexpected error from concurrent sync: %v", err)
+	}
+
+	release <- struct{}{}
+	select {
+	case firstResult := <-firstSync:
+		if err := firstResult.Error(); err != nil {
+			t.Errorf("unexpected error from first sync: %v", err)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for the first sync to finish")
+	}
+
+	fakeDocker.Lock()
+	var created []string
+	for _, name := range fakeDocker.Created {
+		if matchString(t, "/k8s_bar\\.[a-f0-9]+_foo_new_", name) {
+			created = append(created, name)
+		}
+	}
+	fakeDocker.Unlock()
+	if len(created) != 1 {
+		t.Errorf("expected exactly one container to be created, got %v", created)
+	}
+}
+
 func TestSyncPodBadHash(t *testing.T) {
 	dm, fakeDocker := newTestDockerManager()
 	pod := &api.Pod{
@@ -976,9 +1282,9 @@ func TestSyncPodWithRestartPolicy(t *testing.T) {
 		{
 			api.RestartPolicyNever,
 			[]string{
-				// Check the pod infra container.
-				"inspect_container", "inspect_container",
-				// Stop the last pod infra container.
+				// Stop the last pod infra container. The pod infra container
+				// was already inspected while computing the pod status, so the
+				// kill path hits the inspect cache.
 				"stop",
 			},
 			[]string{},
@@ -1003,10 +1309,10 @@ func TestSyncPodWithRestartPolicy(t *testing.T) {
 }
 
 func TestSyncPodBackoff(t *testing.T) {
-	var fakeClock = clock.NewFakeClock(time.Now())
-	startTime := fakeClock.Now()
-
 	dm, fakeDocker := newTestDockerManager()
+	fakeClock := dm.fakeClock()
+	fakeDocker.Clock = fakeClock
+	startTime := fakeClock.Now()
 	containers := []api.Container{
 		{Name: "good"},
 		{Name: "bad"},
@@ -1088,9 +1394,11 @@ func TestSyncPodBackoff(t *testing.T) {
 		}
 
 		if len(fakeDocker.Created) > 0 {
-			// pretend kill the container
+			// Pretend the container was killed: advance the clock to the
+			// kill time and stamp the finish time from it.
 			fakeDocker.Created = nil
-			dockerContainers[2].FinishedAt = startTime.Add(time.Duration(c.killDelay) * time.Second)
+			fakeClock.SetTime(startTime.Add(time.Duration(c.killDelay) * time.Second))
+			dockerContainers[2].FinishedAt = fakeClock.Now()
 		}
 	}
 }
@@ -1268,59 +1576,69 @@ func TestSyncPodWithPodInfraCreatesContainerCallsHandler(t *testing.T) {
 	if fakeHTTPClient.url != "http://foo:8080/bar" {
 		t.Errorf("unexpected handler: %q", fakeHTTPClient.url)
 	}
+	// The default hook timeout must be applied to the request.
+	if fakeHTTPClient.timeout != 10*time.Second {
+		t.Errorf("unexpected handler timeout: %v", fakeHTTPClient.timeout)
+	}
 }
 
 func TestSyncPodEventHandlerFails(t *testing.T) {
-	// Simulate HTTP failure.
-	fakeHTTPClient := &fakeHTTP{err: fmt.Errorf("test error")}
-	dm, fakeDocker := newTestDockerManagerWithHTTPClient(fakeHTTPClient)
+	fakeHTTPClients := []*fakeHTTP{
+		// Simulate HTTP failure, e.g. a timed out request.
+		{err: fmt.Errorf("test error")},
+		// Simulate a non-2xx response, which must not count as success.
+		{resp: &http.Response{StatusCode: 500, Body: ioutil.NopCloser(strings.NewReader("server error"))}},
+	}
+	for _, fakeHTTPClient := range fakeHTTPClients {
+		dm, fakeDocker := newTestDockerManagerWithHTTPClient(fakeHTTPClient)
 
-	pod := &api.Pod{
-		ObjectMeta: api.ObjectMeta{
-			UID:       "12345678",
-			Name:      "foo",
-			Namespace: "new",
-		},
-		Spec: api.PodSpec{
-			Containers: []api.Container{
-				{Name: "bar",
-					Lifecycle: &api.Lifecycle{
-						PostStart: &api.Handler{
-							HTTPGet: &api.HTTPGetAction{
-								Host: "does.no.exist",
-								Port: intstr.FromInt(8080),
-								Path: "bar",
+		pod := &api.Pod{
+			ObjectMeta: api.ObjectMeta{
+				UID:       "12345678",
+				Name:      "foo",
+				Namespace: "new",
+			},
+			Spec: api.PodSpec{
+				Containers: []api.Container{
+					{Name: "bar",
+						Lifecycle: &api.Lifecycle{
+							PostStart: &api.Handler{
+								HTTPGet: &api.HTTPGetAction{
+									Host: "does.no.exist",
+									Port: intstr.FromInt(8080),
+									Path: "bar",
+								},
 							},
 						},
 					},
 				},
 			},
-		},
-	}
+		}
 
-	fakeDocker.SetFakeRunningContainers([]*FakeContainer{{
-		ID:   "9876",
-		Name: "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
-	}})
-	runSyncPod(t, dm, fakeDocker, pod, nil, true)
+		fakeDocker.SetFakeRunningContainers([]*FakeContainer{{
+			ID:   "9876",
+			Name: "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
+		}})
+		runSyncPod(t, dm, fakeDocker, pod, nil, true)
 
-	verifyCalls(t, fakeDocker, []string{
-		// Create the container.
-		"create", "start",
-		// Kill the container since event handler fails.
-		"stop",
-	})
+		verifyCalls(t, fakeDocker, []string{
+			// Create the container.
+			"create", "start",
+			// Kill the container since event handler fails.
+			"stop",
+		})
 
-	// TODO(yifan): Check the stopped container's name.
-	if len(fakeDocker.Stopped) != 1 {
-		t.Fatalf("Wrong containers were stopped: %v", fakeDocker.Stopped)
-	}
-	dockerName, _, err := ParseDockerName(fakeDocker.Stopped[0])
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
-	if dockerName.ContainerName != "bar" {
-		t.Errorf("Wrong stopped container, expected: bar, get: %q", dockerName.ContainerName)
+		// TODO(yifan): Check the stopped container's name.
+		if len(fakeDocker.Stopped) != 1 {
+			t.Fatalf("Wrong containers were stopped: %v", fakeDocker.Stopped)
+		}
+		dockerName, _, err := ParseDockerName(fakeDocker.Stopped[0])
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if dockerName.ContainerName != "bar" {
+			t.Errorf("Wrong stopped container, expected: bar, get: %q", dockerName.ContainerName)
+		}
 	}
 }
 
@@ -1348,12 +1666,36 @@ func TestPortForwardNoSuchContainer(t *testing.T) {
 	if err == nil {
 		t.Fatal("unexpected non-error")
 	}
-	expectedErr := noPodInfraContainerError(podName, podNamespace)
-	if !reflect.DeepEqual(err, expectedErr) {
+	if !IsNoPodInfraContainer(err) {
+		t.Fatalf("expected a NoPodInfraContainerError, but saw %v", err)
+	}
+	expectedErr := NewNoPodInfraContainerError(podName, podNamespace)
+	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected %v, but saw %v", expectedErr, err)
 	}
 }
 
+func TestExecInContainerNoSuchContainer(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	fakeDocker.InjectError("inspect_container", containerNotFoundError{ID: "nosuchcontainer"})
+
+	err := dm.ExecInContainer(
+		kubecontainer.ContainerID{Type: "docker", ID: "nosuchcontainer"},
+		[]string{"ls"},
+		nil,
+		nil,
+		nil,
+		false,
+		nil,
+	)
+	if err == nil {
+		t.Fatal("unexpected non-error")
+	}
+	if !IsNoPodInfraContainer(err) {
+		t.Fatalf("expected a NoPodInfraContainerError, but saw %v", err)
+	}
+}
+
 func TestSyncPodWithTerminationLog(t *testing.T) {
 	dm, fakeDocker := newTestDockerManager()
 	container := api.Container{
@@ -1376,7 +1718,7 @@ func TestSyncPodWithTerminationLog(t *testing.T) {
 	runSyncPod(t, dm, fakeDocker, pod, nil, false)
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -1390,49 +1732,229 @@ func TestSyncPodWithTerminationLog(t *testing.T) {
 		t.Errorf("unexpected containers created %v", fakeDocker.Created)
 	}
 	fakeDocker.Unlock()
-	newContainer, err := fakeDocker.InspectContainer(fakeDocker.Created[1])
-	if err != nil {
-		t.Fatalf("unexpected error %v", err)
-	}
-	parts := strings.Split(newContainer.HostConfig.Binds[0], ":")
-	if !matchString(t, testPodContainerDir+"/[a-f0-9]", parts[0]) {
-		t.Errorf("unexpected host path: %s", parts[0])
+	newContainer := fakeDocker.Created[1]
+	if err := fakeDocker.AssertVolumeMounted(newContainer, "", "/dev/somepath"); err != nil {
+		t.Errorf("termination log not mounted: %v", err)
 	}
-	if parts[1] != "/dev/somepath" {
-		t.Errorf("unexpected container path: %s", parts[1])
+	if !matchString(t, "^"+testPodContainerDir+"/[a-f0-9]+:/dev/somepath", fakeDocker.VolumeMounts[newContainer][0]) {
+		t.Errorf("unexpected termination log bind: %s", fakeDocker.VolumeMounts[newContainer][0])
 	}
 }
 
-func TestSyncPodWithHostNetwork(t *testing.T) {
+func TestGetPodStatusTerminationMessageFromLogs(t *testing.T) {
 	dm, fakeDocker := newTestDockerManager()
 	pod := &api.Pod{
 		ObjectMeta: api.ObjectMeta{
 			UID:       "12345678",
 			Name:      "foo",
 			Namespace: "new",
+			Annotations: map[string]string{
+				TerminationMessagePolicyAnnotation: TerminationMessageFallbackToLogsOnError,
+			},
 		},
 		Spec: api.PodSpec{
 			Containers: []api.Container{
 				{Name: "bar"},
 			},
-			SecurityContext: &api.PodSecurityContext{
-				HostNetwork: true,
-			},
 		},
 	}
 
-	runSyncPod(t, dm, fakeDocker, pod, nil, false)
-
-	verifyCalls(t, fakeDocker, []string{
-		// Create pod infra container.
-		"create", "start", "inspect_container",
-		// Create container.
-		"create", "start", "inspect_container",
-	})
+	logFile, err := ioutil.TempFile("", "termination-message")
+	if err != nil {
+		t.Fatalf("unexpected error creating log file: %v", err)
+	}
+	defer os.Remove(logFile.Name())
+	logContent := "something bad happened\npanic: exit\n"
+	if err := ioutil.WriteFile(logFile.Name(), []byte(logContent), 0644); err != nil {
+		t.Fatalf("unexpected error writing log file: %v", err)
+	}
 
-	fakeDocker.Lock()
-	if len(fakeDocker.Created) != 2 ||
-		!matchString(t, "/k8s_POD\\.[a-f0-9]+_foo_new_", fakeDocker.Created[0]) ||
+	fakeDocker.SetFakeContainers([]*FakeContainer{
+		{
+			ID:         "1234",
+			Name:       "/k8s_bar." + strconv.FormatUint(kubecontainer.HashContainer(&pod.Spec.Containers[0]), 16) + "_foo_new_12345678_0",
+			ExitCode:   42,
+			StartedAt:  time.Now(),
+			FinishedAt: time.Now(),
+			LogPath:    logFile.Name(),
+			Config: &dockercontainer.Config{
+				Labels: newLabels(&pod.Spec.Containers[0], pod, 0, false),
+			},
+		},
+	})
+
+	podStatus, err := dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	status := podStatus.FindContainerStatusByName("bar")
+	if status == nil {
+		t.Fatalf("container status for %q not found in %+v", "bar", podStatus)
+	}
+	expectedMessage := strings.TrimRight(logContent, "\n")
+	if status.Message != expectedMessage {
+		t.Errorf("expected termination message %q, got %q", expectedMessage, status.Message)
+	}
+
+	// A non-empty termination message file still takes precedence over the logs.
+	terminationFile, err := ioutil.TempFile("", "termination-message")
+	if err != nil {
+		t.Fatalf("unexpected error creating termination message file: %v", err)
+	}
+	defer os.Remove(terminationFile.Name())
+	if err := ioutil.WriteFile(terminationFile.Name(), []byte("from the file"), 0644); err != nil {
+		t.Fatalf("unexpected error writing termination message file: %v", err)
+	}
+	pod.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+	container := fakeDocker.ContainerMap["1234"]
+	container.Config.Labels = newLabels(&pod.Spec.Containers[0], pod, 0, false)
+	container.Mounts = []dockertypes.MountPoint{
+		{
+			Source:      terminationFile.Name(),
+			Destination: pod.Spec.Containers[0].TerminationMessagePath,
+		},
+	}
+
+	podStatus, err = dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	status = podStatus.FindContainerStatusByName("bar")
+	if status == nil {
+		t.Fatalf("container status for %q not found in %+v", "bar", podStatus)
+	}
+	if status.Message != "from the file" {
+		t.Errorf("expected termination message %q, got %q", "from the file", status.Message)
+	}
+}
+
+func TestGetPodStatusOOMKilled(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	// We want to capture events.
+	recorder := record.NewFakeRecorder(20)
+	dm.recorder = recorder
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+
+	const memoryLimit = int64(64 * 1024 * 1024)
+	fakeDocker.SetFakeContainers([]*FakeContainer{
+		{
+			ID:         "1234",
+			Name:       "/k8s_bar." + strconv.FormatUint(kubecontainer.HashContainer(&pod.Spec.Containers[0]), 16) + "_foo_new_12345678_0",
+			ExitCode:   137,
+			OOMKilled:  true,
+			StartedAt:  time.Now(),
+			FinishedAt: time.Now(),
+			Config: &dockercontainer.Config{
+				Labels: newLabels(&pod.Spec.Containers[0], pod, 0, false),
+			},
+			HostConfig: &dockercontainer.HostConfig{
+				Resources: dockercontainer.Resources{Memory: memoryLimit},
+			},
+		},
+	})
+
+	podStatus, err := dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	status := podStatus.FindContainerStatusByName("bar")
+	if status == nil {
+		t.Fatalf("container status for %q not found in %+v", "bar", podStatus)
+	}
+	if status.Reason != "OOMKilled" {
+		t.Errorf("expected reason %q, got %q", "OOMKilled", status.Reason)
+	}
+	expectedMessage := fmt.Sprintf("Memory limit of %d bytes exceeded", memoryLimit)
+	if status.Message != expectedMessage {
+		t.Errorf("expected message %q, got %q", expectedMessage, status.Message)
+	}
+	if err := expectEvent(recorder, api.EventTypeWarning, events.OOMKilling,
+		fmt.Sprintf("Container bar was OOM killed (memory limit %d bytes)", memoryLimit)); err != nil {
+		t.Error(err)
+	}
+
+	// Inspecting the same dead container again must not repeat the event.
+	if _, err := dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("unexpected repeated event %q", event)
+	default:
+	}
+}
+
+func TestReadLastTerminationMessageFromLogsTruncation(t *testing.T) {
+	logFile, err := ioutil.TempFile("", "termination-message")
+	if err != nil {
+		t.Fatalf("unexpected error creating log file: %v", err)
+	}
+	defer os.Remove(logFile.Name())
+	var lines []string
+	for i := 0; i < 2*maxTerminationMessageLogLines; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	if err := ioutil.WriteFile(logFile.Name(), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("unexpected error writing log file: %v", err)
+	}
+
+	message, err := readLastTerminationMessageFromLogs(logFile.Name())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(message, terminationMessageTruncationMarker) {
+		t.Errorf("expected message to start with the truncation marker, got %q", message)
+	}
+	if !strings.HasSuffix(message, fmt.Sprintf("line %d", 2*maxTerminationMessageLogLines-1)) {
+		t.Errorf("expected message to end with the last log line, got %q", message)
+	}
+	if got := len(strings.Split(message, "\n")); got > maxTerminationMessageLogLines+1 {
+		t.Errorf("expected at most %d lines plus the truncation marker, got %d", maxTerminationMessageLogLines, got)
+	}
+}
+
+func TestSyncPodWithHostNetwork(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+			SecurityContext: &api.PodSecurityContext{
+				HostNetwork: true,
+			},
+		},
+	}
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	verifyCalls(t, fakeDocker, []string{
+		// Create pod infra container.
+		"create", "start", "inspect_container",
+		// Create container.
+		"create", "start", "inspect_container",
+	})
+
+	fakeDocker.Lock()
+	if len(fakeDocker.Created) != 2 ||
+		!matchString(t, "/k8s_POD\\.[a-f0-9]+_foo_new_", fakeDocker.Created[0]) ||
 		!matchString(t, "/k8s_bar\\.[a-f0-9]+_foo_new_", fakeDocker.Created[1]) {
 		t.Errorf("unexpected containers created %v", fakeDocker.Created)
 	}
@@ -1661,6 +2183,34 @@ func TestSyncPodWithPullPolicy(t *testing.T) {
 	}
 }
 
+func TestSyncPodWithPullPolicyEquivalentSpelling(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	puller := dm.dockerPuller.(*FakeDockerPuller)
+	// The image was recorded under its canonical spelling; the pod refers to
+	// it by its shorthand.
+	puller.HasImages = []string{"docker.io/library/existing:latest"}
+	dm.podInfraContainerImage = "pod_infra_image"
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar", Image: "existing", ImagePullPolicy: api.PullIfNotPresent},
+			},
+		},
+	}
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	// Only the infra image is pulled; "existing" is recognized as present
+	// even though it was recorded under an equivalent spelling.
+	assert.Equal(t, []string{"pod_infra_image"}, puller.ImagesPulled)
+}
+
 // This test only covers SyncPod with PullImageFailure, CreateContainerFailure and StartContainerFailure.
 // There are still quite a few failure cases not covered.
 // TODO(random-liu): Better way to test the SyncPod failures.
@@ -1679,19 +2229,19 @@ func TestSyncPodWithFailure(t *testing.T) {
 		expected    []*kubecontainer.SyncResult
 	}{
 		"PullImageFailure": {
-			api.Container{Name: "bar", Image: "realImage", ImagePullPolicy: api.PullAlways},
+			api.Container{Name: "bar", Image: "real_image", ImagePullPolicy: api.PullAlways},
 			map[string]error{},
 			[]error{fmt.Errorf("can't pull image")},
 			[]*kubecontainer.SyncResult{{kubecontainer.StartContainer, "bar", images.ErrImagePull, "can't pull image"}},
 		},
 		"CreateContainerFailure": {
-			api.Container{Name: "bar", Image: "alreadyPresent"},
+			api.Container{Name: "bar", Image: "already_present"},
 			map[string]error{"create": fmt.Errorf("can't create container")},
 			[]error{},
 			[]*kubecontainer.SyncResult{{kubecontainer.StartContainer, "bar", kubecontainer.ErrRunContainer, "can't create container"}},
 		},
 		"StartContainerFailure": {
-			api.Container{Name: "bar", Image: "alreadyPresent"},
+			api.Container{Name: "bar", Image: "already_present"},
 			map[string]error{"start": fmt.Errorf("can't start container")},
 			[]error{},
 			[]*kubecontainer.SyncResult{{kubecontainer.StartContainer, "bar", kubecontainer.ErrRunContainer, "can't start container"}},
@@ -1806,6 +2356,12 @@ func TestGetSecurityOpts(t *testing.T) {
 			api.SeccompContainerAnnotationKeyPrefix + containerName: "docker/default",
 		}),
 		expectedOpts: nil,
+	}, {
+		msg: "Seccomp runtime/default",
+		pod: makePod(map[string]string{
+			api.SeccompContainerAnnotationKeyPrefix + containerName: "runtime/default",
+		}),
+		expectedOpts: nil,
 	}, {
 		msg: "AppArmor runtime/default",
 		pod: makePod(map[string]string{
@@ -1863,7 +2419,7 @@ func TestSeccompIsUnconfinedByDefaultWithDockerV110(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -1909,7 +2465,7 @@ func TestUnconfinedSeccompProfileWithDockerV110(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -1951,7 +2507,7 @@ func TestDefaultSeccompProfileWithDockerV110(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -1994,7 +2550,7 @@ func TestSeccompContainerAnnotationTrumpsPod(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -2074,7 +2630,7 @@ func TestSeccompLocalhostProfileIsLoaded(t *testing.T) {
 
 		verifyCalls(t, fakeDocker, []string{
 			// Create pod infra container.
-			"create", "start", "inspect_container", "inspect_container",
+			"create", "start", "inspect_container",
 			// Create container.
 			"create", "start", "inspect_container",
 		})
@@ -2119,7 +2675,7 @@ func TestSecurityOptsAreNilWithDockerV19(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -2197,7 +2753,7 @@ func TestCreateAppArmorContanier(t *testing.T) {
 
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -2356,7 +2912,7 @@ func TestGetPodStatusNoSuchContainer(t *testing.T) {
 	// failed.
 	verifyCalls(t, fakeDocker, []string{
 		// Start a new infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Start a new container.
 		"create", "start", "inspect_container",
 	})
@@ -2531,7 +3087,7 @@ func TestSyncPodGetsPodIPFromNetworkPlugin(t *testing.T) {
 	runSyncPod(t, dm, fakeDocker, pod, nil, false)
 	verifyCalls(t, fakeDocker, []string{
 		// Create pod infra container.
-		"create", "start", "inspect_container", "inspect_container",
+		"create", "start", "inspect_container",
 		// Create container.
 		"create", "start", "inspect_container",
 	})
@@ -2568,3 +3124,494 @@ func TestContainerAndPodFromLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestGetContainerStats(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	containerID := kubecontainer.ContainerID{Type: "docker", ID: "foo"}
+	fakeDocker.StatsMap = map[string]*dockertypes.Stats{
+		containerID.ID: {
+			CPUStats:    dockertypes.CPUStats{CPUUsage: dockertypes.CPUUsage{TotalUsage: 123456}},
+			MemoryStats: dockertypes.MemoryStats{Usage: 654321},
+		},
+	}
+
+	stats, err := dm.GetContainerStats(containerID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.CpuUsage != 123456 {
+		t.Errorf("expected cpu usage 123456, got: %d", stats.CpuUsage)
+	}
+	if stats.MemoryUsage != 654321 {
+		t.Errorf("expected memory usage 654321, got: %d", stats.MemoryUsage)
+	}
+}
+
+func TestUpdateContainerResources(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	containerID := kubecontainer.ContainerID{Type: "docker", ID: "foo"}
+	resources := &api.ResourceRequirements{
+		Limits: api.ResourceList{
+			api.ResourceCPU:    resource.MustParse("500m"),
+			api.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+
+	if err := dm.UpdateContainerResources(containerID, resources); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updateConfig, found := fakeDocker.UpdatedConfigs[containerID.ID]
+	if !found {
+		t.Fatalf("expected an update for container %q, got: %v", containerID.ID, fakeDocker.UpdatedConfigs)
+	}
+	expectedQuota, expectedPeriod := milliCPUToQuota(500)
+	if updateConfig.CPUQuota != expectedQuota {
+		t.Errorf("expected cpu quota %d, got: %d", expectedQuota, updateConfig.CPUQuota)
+	}
+	if updateConfig.CPUPeriod != expectedPeriod {
+		t.Errorf("expected cpu period %d, got: %d", expectedPeriod, updateConfig.CPUPeriod)
+	}
+	if expectedMemory := int64(128 * 1024 * 1024); updateConfig.Memory != expectedMemory {
+		t.Errorf("expected memory limit %d, got: %d", expectedMemory, updateConfig.Memory)
+	}
+}
+
+func TestRuntimeStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		apiVersion         string
+		networkStatusError error
+		expectRuntimeReady bool
+		expectNetworkReady bool
+	}{
+		{
+			name:               "all-healthy",
+			apiVersion:         minimumDockerAPIVersion,
+			networkStatusError: nil,
+			expectRuntimeReady: true,
+			expectNetworkReady: true,
+		},
+		{
+			name:               "old-docker-version",
+			apiVersion:         "1.20",
+			networkStatusError: nil,
+			expectRuntimeReady: false,
+			expectNetworkReady: true,
+		},
+		{
+			name:               "network-plugin-not-ready",
+			apiVersion:         minimumDockerAPIVersion,
+			networkStatusError: fmt.Errorf("no CNI configuration found"),
+			expectRuntimeReady: true,
+			expectNetworkReady: false,
+		},
+		{
+			name:               "all-degraded",
+			apiVersion:         "1.20",
+			networkStatusError: fmt.Errorf("no CNI configuration found"),
+			expectRuntimeReady: false,
+			expectNetworkReady: false,
+		},
+	}
+	for _, test := range tests {
+		dm, _ := newTestDockerManagerWithVersion("1.8.1", test.apiVersion)
+		ctrl := gomock.NewController(t)
+		fnp := mock_network.NewMockNetworkPlugin(ctrl)
+		fnp.EXPECT().Name().Return("someNetworkPlugin").AnyTimes()
+		fnp.EXPECT().Status().Return(test.networkStatusError)
+		dm.networkPlugin = fnp
+
+		status, err := dm.Status()
+		if err != nil {
+			t.Errorf("test %q: unexpected error: %v", test.name, err)
+		}
+		runtimeReady := status.GetRuntimeCondition(kubecontainer.RuntimeReady)
+		if runtimeReady == nil || runtimeReady.Status != test.expectRuntimeReady {
+			t.Errorf("test %q: expected runtime ready %v, got: %v", test.name, test.expectRuntimeReady, runtimeReady)
+		}
+		networkReady := status.GetRuntimeCondition(kubecontainer.NetworkReady)
+		if networkReady == nil || networkReady.Status != test.expectNetworkReady {
+			t.Errorf("test %q: expected network ready %v, got: %v", test.name, test.expectNetworkReady, networkReady)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestSyncPodAppliesSysctls(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+			Annotations: map[string]string{
+				api.SysctlsPodAnnotationKey:       "kernel.shmmax=8192",
+				api.UnsafeSysctlsPodAnnotationKey: "net.core.somaxconn=1024",
+			},
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	expectedSysctls := map[string]string{
+		"kernel.shmmax":      "8192",
+		"net.core.somaxconn": "1024",
+	}
+	infraContainer, err := fakeDocker.InspectContainer(fakeDocker.Created[0])
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(infraContainer.HostConfig.Sysctls, expectedSysctls) {
+		t.Errorf("expected infra container sysctls %v, got %v", expectedSysctls, infraContainer.HostConfig.Sysctls)
+	}
+}
+
+func TestSyncPodRejectsNonNamespacedSysctl(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+			Annotations: map[string]string{
+				api.SysctlsPodAnnotationKey: "vm.max_map_count=65536",
+			},
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+
+	result := runSyncPod(t, dm, fakeDocker, pod, nil, true)
+	if err := result.Error(); !strings.Contains(err.Error(), "forbidden sysctl") {
+		t.Errorf("expected a forbidden sysctl error, got %v", err)
+	}
+	if len(fakeDocker.Created) != 0 {
+		t.Errorf("expected no containers to be created, got %v", fakeDocker.Created)
+	}
+}
+
+func TestSyncPodRejectsNetSysctlsWithHostNetwork(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+			Annotations: map[string]string{
+				api.UnsafeSysctlsPodAnnotationKey: "net.core.somaxconn=1024",
+			},
+		},
+		Spec: api.PodSpec{
+			SecurityContext: &api.PodSecurityContext{
+				HostNetwork: true,
+			},
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+
+	result := runSyncPod(t, dm, fakeDocker, pod, nil, true)
+	if err := result.Error(); !strings.Contains(err.Error(), "not allowed with host networking enabled") {
+		t.Errorf("expected a host networking rejection, got %v", err)
+	}
+	if len(fakeDocker.Created) != 0 {
+		t.Errorf("expected no containers to be created, got %v", fakeDocker.Created)
+	}
+}
+
+func TestValidatePodSysctls(t *testing.T) {
+	hostNetPod := &api.Pod{
+		Spec: api.PodSpec{
+			SecurityContext: &api.PodSecurityContext{HostNetwork: true},
+		},
+	}
+	tests := []struct {
+		name      string
+		pod       *api.Pod
+		sysctl    string
+		expectErr bool
+	}{
+		{"namespaced net sysctl", &api.Pod{}, "net.core.somaxconn", false},
+		{"namespaced ipc sysctl", &api.Pod{}, "kernel.shmmax", false},
+		{"namespaced mqueue sysctl", &api.Pod{}, "fs.mqueue.msg_max", false},
+		{"non-namespaced sysctl", &api.Pod{}, "vm.max_map_count", true},
+		{"net sysctl with host networking", hostNetPod, "net.core.somaxconn", true},
+		{"ipc sysctl with host networking", hostNetPod, "kernel.sem", false},
+	}
+	for _, test := range tests {
+		err := validatePodSysctls(test.pod, []api.Sysctl{{Name: test.sysctl, Value: "1"}})
+		if test.expectErr {
+			if _, ok := err.(*ForbiddenSysctlError); !ok {
+				t.Errorf("test %q: expected a ForbiddenSysctlError, got %v", test.name, err)
+			}
+		} else if err != nil {
+			t.Errorf("test %q: unexpected error: %v", test.name, err)
+		}
+	}
+}
+
+func countCalls(fakeDocker *FakeDockerClient, name string) int {
+	fakeDocker.Lock()
+	defer fakeDocker.Unlock()
+	count := 0
+	for _, called := range fakeDocker.getCalledNames() {
+		if called == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCachedInspectContainer(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	fakeDocker.SetFakeRunningContainers([]*FakeContainer{{
+		ID:   "c1",
+		Name: "/k8s_bar_foo_new_12345678_0",
+	}})
+
+	// The first inspect goes to docker; the second is served from the cache.
+	first, err := dm.cachedInspectContainer("c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := dm.cachedInspectContainer("c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected the second inspect to be served from the cache")
+	}
+	if count := countCalls(fakeDocker, "inspect_container"); count != 1 {
+		t.Errorf("expected 1 docker inspect call, got %d", count)
+	}
+
+	// Invalidating the entry forces the next inspect back to docker.
+	dm.invalidateInspectCache("c1")
+	if _, err := dm.cachedInspectContainer("c1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := countCalls(fakeDocker, "inspect_container"); count != 2 {
+		t.Errorf("expected 2 docker inspect calls, got %d", count)
+	}
+}
+
+func TestInspectCacheReducesCallsOnRepeatedSync(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	container := api.Container{Name: "bar"}
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{container},
+		},
+	}
+	fakeDocker.SetFakeRunningContainers([]*FakeContainer{
+		{
+			ID:   "9876",
+			Name: "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
+		},
+		{
+			ID:   "1234",
+			Name: "/k8s_bar." + strconv.FormatUint(kubecontainer.HashContainer(&container), 16) + "_foo_new_12345678_0",
+		},
+	})
+
+	backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+	syncPod := func() {
+		podStatus, err := dm.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result := dm.SyncPod(pod, api.PodStatus{}, podStatus, []api.Secret{}, backOff)
+		if err := result.Error(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The first sync inspects both running containers while computing the pod
+	// status.
+	syncPod()
+	if count := countCalls(fakeDocker, "inspect_container"); count != 2 {
+		t.Errorf("expected 2 docker inspect calls on the first sync, got %d", count)
+	}
+	fakeDocker.ClearCalls()
+
+	// A repeated sync within the cache TTL is served entirely from the cache.
+	syncPod()
+	if count := countCalls(fakeDocker, "inspect_container"); count != 0 {
+		t.Errorf("expected no docker inspect calls on a repeated sync, got %d", count)
+	}
+}
+
+func TestSyncPodTransientErrorDoesNotAdvanceBackoff(t *testing.T) {
+	tests := []struct {
+		name            string
+		inject          func(fakeDocker *FakeDockerClient)
+		expectedBackoff time.Duration
+	}{
+		// A transient docker failure resets the container's backoff entry so
+		// the next sync retries immediately.
+		{"transient create failure", func(fakeDocker *FakeDockerClient) { fakeDocker.InjectTransientError("create") }, 0},
+		// A fatal failure leaves the backoff advanced by doBackOff.
+		{"fatal create failure", func(fakeDocker *FakeDockerClient) { fakeDocker.InjectFatalError("create") }, time.Second},
+	}
+	for _, test := range tests {
+		dm, fakeDocker := newTestDockerManager()
+		containers := []api.Container{
+			{Name: "bad"},
+		}
+		pod := &api.Pod{
+			ObjectMeta: api.ObjectMeta{
+				UID:       "12345678",
+				Name:      "foo",
+				Namespace: "new",
+			},
+			Spec: api.PodSpec{
+				Containers: containers,
+			},
+		}
+		fakeDocker.SetFakeContainers([]*FakeContainer{
+			{
+				ID:        "9876",
+				Name:      "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
+				StartedAt: time.Now(),
+				Running:   true,
+			},
+			{
+				ID:         "5678",
+				Name:       "/k8s_bad." + strconv.FormatUint(kubecontainer.HashContainer(&containers[0]), 16) + "_foo_new_12345678_0",
+				ExitCode:   42,
+				StartedAt:  time.Now(),
+				FinishedAt: time.Now(),
+			},
+		})
+		test.inject(fakeDocker)
+
+		backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+		runSyncPod(t, dm, fakeDocker, pod, backOff, true)
+
+		key := containerBackoffKey(pod, &pod.Spec.Containers[0])
+		if got := backOff.Get(key); got != test.expectedBackoff {
+			t.Errorf("test %q: expected backoff %v for key %q, got %v", test.name, test.expectedBackoff, key, got)
+		}
+	}
+}
+
+func TestSyncPodRemovesStaleContainerOnNameConflict(t *testing.T) {
+	dm, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		},
+	}
+	fakeDocker.SetFakeContainers([]*FakeContainer{
+		{
+			ID:        "9876",
+			Name:      "/k8s_POD." + strconv.FormatUint(generatePodInfraContainerHash(pod), 16) + "_foo_new_12345678_0",
+			StartedAt: time.Now(),
+			Running:   true,
+		},
+		// A stopped leftover container holding the name "bar" wants.
+		{
+			ID:         "stale123",
+			Name:       "/stale_bar",
+			ExitCode:   0,
+			StartedAt:  time.Now(),
+			FinishedAt: time.Now(),
+		},
+	})
+	fakeDocker.InjectConflictError("create", "stale123")
+
+	runSyncPod(t, dm, fakeDocker, pod, nil, false)
+
+	fakeDocker.Lock()
+	defer fakeDocker.Unlock()
+	if len(fakeDocker.Removed) != 1 || fakeDocker.Removed[0] != "stale123" {
+		t.Errorf("expected the stale container to be removed, got %v", fakeDocker.Removed)
+	}
+	if len(fakeDocker.Created) != 1 {
+		t.Errorf("expected the container to be created after the retry, got %v", fakeDocker.Created)
+	}
+}
+
+func TestCheckpointContainer(t *testing.T) {
+	manager, fakeDocker := newTestDockerManager()
+	id := kubecontainer.ContainerID{Type: "docker", ID: "c1"}
+
+	if err := utilconfig.DefaultFeatureGate.Set("DockerCRIU=true"); err != nil {
+		t.Fatalf("failed to enable the DockerCRIU feature gate: %v", err)
+	}
+	defer utilconfig.DefaultFeatureGate.Set("")
+
+	if err := manager.CheckpointContainer(id, "/var/lib/kubelet/checkpoints/pod1"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := fakeDocker.AssertCallDetails([]calledDetail{{name: "create_checkpoint",
+		arguments: []interface{}{"c1", managedCheckpointID, "/var/lib/kubelet/checkpoints/pod1"}}}); err != nil {
+		t.Errorf("%v", err)
+	}
+
+	// With the feature gate off, the docker client must not be called.
+	utilconfig.DefaultFeatureGate.Set("")
+	if err := manager.CheckpointContainer(id, "/var/lib/kubelet/checkpoints/pod1"); err == nil {
+		t.Errorf("expected an error while the DockerCRIU feature gate is disabled")
+	}
+	if err := fakeDocker.AssertCallDetails([]calledDetail{{name: "create_checkpoint",
+		arguments: []interface{}{"c1", managedCheckpointID, "/var/lib/kubelet/checkpoints/pod1"}}}); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestRestoreContainer(t *testing.T) {
+	manager, fakeDocker := newTestDockerManager()
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			UID:       "12345678",
+			Name:      "foo",
+			Namespace: "new",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{{Name: "bar", Image: "baz"}},
+		},
+	}
+
+	if err := utilconfig.DefaultFeatureGate.Set("DockerCRIU=true"); err != nil {
+		t.Fatalf("failed to enable the DockerCRIU feature gate: %v", err)
+	}
+	defer utilconfig.DefaultFeatureGate.Set("")
+
+	id, err := manager.RestoreContainer("/var/lib/kubelet/checkpoints/pod1", pod, &pod.Spec.Containers[0])
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	verifyCalls(t, fakeDocker, []string{"create", "start_container_from_checkpoint"})
+	if id.ID == "" {
+		t.Errorf("expected the restored container to have an ID")
+	}
+	if err := fakeDocker.AssertCallDetails([]calledDetail{
+		{name: "create"},
+		{name: "start_container_from_checkpoint",
+			arguments: []interface{}{id.ID, managedCheckpointID, "/var/lib/kubelet/checkpoints/pod1"}},
+	}); err != nil {
+		t.Errorf("%v", err)
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/docker/docker/pkg/jsonmessage"
 	dockertypes "github.com/docker/engine-api/types"
+	dockercontainer "github.com/docker/engine-api/types/container"
 	dockernat "github.com/docker/go-connections/nat"
 	cadvisorapi "github.com/google/cadvisor/info/v1"
 	"github.com/stretchr/testify/assert"
@@ -315,19 +316,18 @@ func TestMatchImageTagOrSHA(t *testing.T) {
 	}
 }
 
-func TestApplyDefaultImageTag(t *testing.T) {
+func TestCanonicalImageSpelling(t *testing.T) {
 	for _, testCase := range []struct {
 		Input  string
 		Output string
 	}{
-		{Input: "root", Output: "root:latest"},
-		{Input: "root:tag", Output: "root:tag"},
-		{Input: "root@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Output: "root@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Input: "root", Output: "docker.io/library/root:latest"},
+		{Input: "root:tag", Output: "docker.io/library/root:tag"},
+		{Input: "root@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Output: "docker.io/library/root@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		// Unparseable references are passed through unchanged.
+		{Input: "invalid image", Output: "invalid image"},
 	} {
-		image, err := applyDefaultImageTag(testCase.Input)
-		if err != nil {
-			t.Errorf("applyDefaultTag(%s) failed: %v", testCase.Input, err)
-		} else if image != testCase.Output {
+		if image := canonicalImageSpelling(testCase.Input); image != testCase.Output {
 			t.Errorf("Expected image reference: %q, got %q", testCase.Output, image)
 		}
 	}
@@ -338,10 +338,11 @@ func TestPullWithNoSecrets(t *testing.T) {
 		imageName     string
 		expectedImage string
 	}{
-		{"ubuntu", "ubuntu:latest using {}"},
-		{"ubuntu:2342", "ubuntu:2342 using {}"},
-		{"ubuntu:latest", "ubuntu:latest using {}"},
-		{"foo/bar:445566", "foo/bar:445566 using {}"},
+		{"ubuntu", "docker.io/library/ubuntu:latest using {}"},
+		{"ubuntu:2342", "docker.io/library/ubuntu:2342 using {}"},
+		{"ubuntu:latest", "docker.io/library/ubuntu:latest using {}"},
+		{"docker.io/library/ubuntu", "docker.io/library/ubuntu:latest using {}"},
+		{"foo/bar:445566", "docker.io/foo/bar:445566 using {}"},
 		{"registry.example.com:5000/foobar", "registry.example.com:5000/foobar:latest using {}"},
 		{"registry.example.com:5000/foobar:5342", "registry.example.com:5000/foobar:5342 using {}"},
 		{"registry.example.com:5000/foobar:latest", "registry.example.com:5000/foobar:latest using {}"},
@@ -430,7 +431,7 @@ func TestPullWithSecrets(t *testing.T) {
 			"ubuntu",
 			[]api.Secret{},
 			credentialprovider.DockerConfig(map[string]credentialprovider.DockerConfigEntry{}),
-			[]string{"ubuntu:latest using {}"},
+			[]string{"docker.io/library/ubuntu:latest using {}"},
 		},
 		"default keyring secrets": {
 			"ubuntu",
@@ -438,7 +439,7 @@ func TestPullWithSecrets(t *testing.T) {
 			credentialprovider.DockerConfig(map[string]credentialprovider.DockerConfigEntry{
 				"index.docker.io/v1/": {Username: "built-in", Password: "password", Email: "email", Provider: nil},
 			}),
-			[]string{`ubuntu:latest using {"username":"built-in","password":"password","email":"email"}`},
+			[]string{`docker.io/library/ubuntu:latest using {"username":"built-in","password":"password","email":"email"}`},
 		},
 		"default keyring secrets unused": {
 			"ubuntu",
@@ -446,7 +447,7 @@ func TestPullWithSecrets(t *testing.T) {
 			credentialprovider.DockerConfig(map[string]credentialprovider.DockerConfigEntry{
 				"extraneous": {Username: "built-in", Password: "password", Email: "email", Provider: nil},
 			}),
-			[]string{`ubuntu:latest using {}`},
+			[]string{`docker.io/library/ubuntu:latest using {}`},
 		},
 		"builtin keyring secrets, but use passed": {
 			"ubuntu",
@@ -454,7 +455,7 @@ func TestPullWithSecrets(t *testing.T) {
 			credentialprovider.DockerConfig(map[string]credentialprovider.DockerConfigEntry{
 				"index.docker.io/v1/": {Username: "built-in", Password: "password", Email: "email", Provider: nil},
 			}),
-			[]string{`ubuntu:latest using {"username":"passed-user","password":"passed-password","email":"passed-email"}`},
+			[]string{`docker.io/library/ubuntu:latest using {"username":"passed-user","password":"passed-password","email":"passed-email"}`},
 		},
 		"builtin keyring secrets, but use passed with new docker config": {
 			"ubuntu",
@@ -462,7 +463,7 @@ func TestPullWithSecrets(t *testing.T) {
 			credentialprovider.DockerConfig(map[string]credentialprovider.DockerConfigEntry{
 				"index.docker.io/v1/": {Username: "built-in", Password: "password", Email: "email", Provider: nil},
 			}),
-			[]string{`ubuntu:latest using {"username":"passed-user","password":"passed-password","email":"passed-email"}`},
+			[]string{`docker.io/library/ubuntu:latest using {"username":"passed-user","password":"passed-password","email":"passed-email"}`},
 		},
 	}
 	for i, test := range tests {
@@ -503,11 +504,11 @@ func TestDockerKeyringLookupFails(t *testing.T) {
 		keyring: fakeKeyring,
 	}
 
-	err := dp.Pull("host/repository/image:version", []api.Secret{})
+	err := dp.Pull("registry.example.com/repository/image:version", []api.Secret{})
 	if err == nil {
 		t.Errorf("unexpected non-error")
 	}
-	msg := "image pull failed for host/repository/image:version, this may be because there are no credentials on this request.  details: (test error)"
+	msg := "image pull failed for registry.example.com/repository/image:version, this may be because there are no credentials on this request.  details: (test error)"
 	if err.Error() != msg {
 		t.Errorf("expected: %s, saw: %s", msg, err.Error())
 	}
@@ -643,9 +644,10 @@ func TestIsImagePresent(t *testing.T) {
 	puller := &dockerPuller{
 		client: cl,
 	}
+	// The presence check inspects the canonical spelling of the image.
 	_, _ = puller.IsImagePresent("abc:123")
-	if cl.imageName != "abc:123" {
-		t.Errorf("expected inspection of image abc:123, instead inspected image %v", cl.imageName)
+	if cl.imageName != "docker.io/library/abc:123" {
+		t.Errorf("expected inspection of image docker.io/library/abc:123, instead inspected image %v", cl.imageName)
 	}
 }
 
@@ -990,3 +992,28 @@ func TestLogSymLink(t *testing.T) {
 	expectedPath := path.Join(containerLogsDir, fmt.Sprintf("%s_%s-%s", podFullName, containerName, dockerId)[:251]+".log")
 	as.Equal(expectedPath, LogSymlink(containerLogsDir, podFullName, containerName, dockerId))
 }
+
+func TestAssertVolumeMounted(t *testing.T) {
+	fakeDocker := NewFakeDockerClient()
+	createResp, err := fakeDocker.CreateContainer(dockertypes.ContainerCreateConfig{
+		Name:   "k8s_foo",
+		Config: &dockercontainer.Config{},
+		HostConfig: &dockercontainer.HostConfig{
+			Binds: []string{"/host/data:/data", "/host/log:/var/log:ro"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating container: %v", err)
+	}
+	id := createResp.ID
+
+	// Present mounts, including bind options and host-path wildcards.
+	assert.NoError(t, fakeDocker.AssertVolumeMounted(id, "/host/data", "/data"))
+	assert.NoError(t, fakeDocker.AssertVolumeMounted(id, "/host/log", "/var/log"))
+	assert.NoError(t, fakeDocker.AssertVolumeMounted(id, "", "/data"))
+
+	// Absent mounts.
+	assert.Error(t, fakeDocker.AssertVolumeMounted(id, "/host/data", "/other"))
+	assert.Error(t, fakeDocker.AssertVolumeMounted(id, "/elsewhere", "/data"))
+	assert.Error(t, fakeDocker.AssertVolumeMounted("no-such-container", "/host/data", "/data"))
+}
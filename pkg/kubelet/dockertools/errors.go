@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DockerErrorCategory classifies errors returned by the docker client so that
+// callers can decide whether an operation is worth retrying.
+type DockerErrorCategory string
+
+const (
+	// DockerErrorTransient covers failures to reach the docker daemon, e.g.
+	// while it is restarting. The operation may succeed if simply retried.
+	DockerErrorTransient DockerErrorCategory = "Transient"
+	// DockerErrorNotFound covers operations on containers or images that the
+	// daemon does not know about.
+	DockerErrorNotFound DockerErrorCategory = "NotFound"
+	// DockerErrorConflict covers container creates that failed because the
+	// requested name is already in use by another container.
+	DockerErrorConflict DockerErrorCategory = "Conflict"
+	// DockerErrorFatal covers everything else; retrying without intervention
+	// is unlikely to help.
+	DockerErrorFatal DockerErrorCategory = "Fatal"
+)
+
+// DockerError wraps an error from the docker client with its category.
+type DockerError struct {
+	Category DockerErrorCategory
+	Err      error
+}
+
+func (e *DockerError) Error() string {
+	return e.Err.Error()
+}
+
+// transientErrorSubstrings are message fragments that indicate the docker
+// daemon was unreachable or timed out, rather than rejecting the operation.
+// Matching on messages is unfortunate but unavoidable: errors cross several
+// wrapping layers (e.g. runContainerInPod) which only preserve the string.
+var transientErrorSubstrings = []string{
+	"operation timeout",
+	"connection refused",
+	"cannot connect to the docker daemon",
+	"i/o timeout",
+	"connection reset by peer",
+	"broken pipe",
+}
+
+// notFoundErrorSubstrings match the messages of containerNotFoundError and
+// imageNotFoundError after they have passed through error wrapping.
+var notFoundErrorSubstrings = []string{
+	"no such container",
+	"no such image",
+}
+
+// conflictErrorRegexp matches the daemon's "name already in use" error and
+// captures the id of the container holding the name.
+var conflictErrorRegexp = regexp.MustCompile(`is already in use by container ([0-9a-zA-Z]+)`)
+
+// classifyDockerError wraps an error from the docker client in a DockerError
+// carrying its category. A nil error maps to nil, and an error that is
+// already a DockerError is returned unchanged.
+func classifyDockerError(err error) *DockerError {
+	if err == nil {
+		return nil
+	}
+	if derr, ok := err.(*DockerError); ok {
+		return derr
+	}
+	category := DockerErrorFatal
+	switch err.(type) {
+	case operationTimeout:
+		category = DockerErrorTransient
+	case containerNotFoundError, imageNotFoundError:
+		category = DockerErrorNotFound
+	default:
+		message := strings.ToLower(err.Error())
+		switch {
+		case conflictErrorRegexp.MatchString(err.Error()):
+			category = DockerErrorConflict
+		case containsAny(message, transientErrorSubstrings):
+			category = DockerErrorTransient
+		case containsAny(message, notFoundErrorSubstrings):
+			category = DockerErrorNotFound
+		}
+	}
+	return &DockerError{Category: category, Err: err}
+}
+
+// isTransientDockerError returns true if the error indicates the docker
+// daemon was unreachable and the operation may succeed if retried.
+func isTransientDockerError(err error) bool {
+	return err != nil && classifyDockerError(err).Category == DockerErrorTransient
+}
+
+// conflictingContainerID extracts the id of the container holding a name from
+// the daemon's conflict error, or returns "" if the id cannot be determined.
+func conflictingContainerID(err error) string {
+	matches := conflictErrorRegexp.FindStringSubmatch(err.Error())
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+func containsAny(message string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
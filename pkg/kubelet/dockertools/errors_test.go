@@ -0,0 +1,65 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassifyDockerError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		category DockerErrorCategory
+	}{
+		{"operation timeout", operationTimeout{err: fmt.Errorf("context deadline exceeded")}, DockerErrorTransient},
+		{"connection refused", fmt.Errorf("runContainer: dial unix /var/run/docker.sock: connection refused"), DockerErrorTransient},
+		{"daemon unreachable", fmt.Errorf("Cannot connect to the Docker daemon. Is the docker daemon running on this host?"), DockerErrorTransient},
+		{"container not found", containerNotFoundError{ID: "c1"}, DockerErrorNotFound},
+		{"image not found", imageNotFoundError{ID: "image"}, DockerErrorNotFound},
+		{"wrapped not found", fmt.Errorf("InspectContainer: no such container: \"c1\""), DockerErrorNotFound},
+		{"name conflict", fmt.Errorf("Conflict. The name is already in use by container abc123. You have to remove (or rename) that container to be able to reuse that name."), DockerErrorConflict},
+		{"anything else", fmt.Errorf("executable file not found in $PATH"), DockerErrorFatal},
+	}
+	for _, test := range tests {
+		derr := classifyDockerError(test.err)
+		if derr.Category != test.category {
+			t.Errorf("test %q: expected category %q, got %q", test.name, test.category, derr.Category)
+		}
+		if derr.Error() != test.err.Error() {
+			t.Errorf("test %q: expected message %q, got %q", test.name, test.err.Error(), derr.Error())
+		}
+		// Classifying an already classified error must not re-wrap it.
+		if reclassified := classifyDockerError(derr); reclassified != derr {
+			t.Errorf("test %q: expected the DockerError to be returned unchanged", test.name)
+		}
+	}
+	if derr := classifyDockerError(nil); derr != nil {
+		t.Errorf("expected nil for a nil error, got %v", derr)
+	}
+}
+
+func TestConflictingContainerID(t *testing.T) {
+	err := fmt.Errorf("Conflict. The name is already in use by container abc123. You have to remove (or rename) that container to be able to reuse that name.")
+	if id := conflictingContainerID(err); id != "abc123" {
+		t.Errorf("expected stale container id %q, got %q", "abc123", id)
+	}
+	if id := conflictingContainerID(fmt.Errorf("some other error")); id != "" {
+		t.Errorf("expected no stale container id, got %q", id)
+	}
+}
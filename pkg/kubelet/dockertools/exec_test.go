@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/util/term"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+// waitForResizeCalls polls the fake client until the expected number of
+// resize calls have been recorded by the resize goroutine.
+func waitForResizeCalls(t *testing.T, fakeDocker *FakeDockerClient, count int) []calledDetail {
+	var calls []calledDetail
+	err := wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		calls = fakeDocker.getResizeCalls()
+		return len(calls) >= count, nil
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for %d resize calls, got %v", count, calls)
+	}
+	return calls
+}
+
+func TestExecResizeTTY(t *testing.T) {
+	fakeDocker := NewFakeDockerClient()
+	resize := make(chan term.Size)
+	kubecontainer.HandleResizing(resize, func(size term.Size) {
+		fakeDocker.ResizeExecTTY("exec-foo", int(size.Height), int(size.Width))
+	})
+
+	resize <- term.Size{Height: 24, Width: 80}
+	resize <- term.Size{Height: 60, Width: 120}
+	close(resize)
+
+	calls := waitForResizeCalls(t, fakeDocker, 2)
+	expected := []calledDetail{
+		{name: "resize_exec", arguments: []interface{}{"exec-foo", 24, 80}},
+		{name: "resize_exec", arguments: []interface{}{"exec-foo", 60, 120}},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected resize calls %v, got %v", expected, calls)
+	}
+}
+
+func TestAttachResizeTTY(t *testing.T) {
+	fakeDocker := NewFakeDockerClient()
+	containerID := kubecontainer.ContainerID{Type: "docker", ID: "container-foo"}
+	resize := make(chan term.Size)
+	kubecontainer.HandleResizing(resize, func(size term.Size) {
+		fakeDocker.ResizeContainerTTY(containerID.ID, int(size.Height), int(size.Width))
+	})
+
+	resize <- term.Size{Height: 50, Width: 100}
+	// Events with non-positive dimensions must be skipped.
+	resize <- term.Size{Height: 0, Width: 0}
+	resize <- term.Size{Height: 51, Width: 101}
+	close(resize)
+
+	calls := waitForResizeCalls(t, fakeDocker, 2)
+	expected := []calledDetail{
+		{name: "resize_container", arguments: []interface{}{"container-foo", 50, 100}},
+		{name: "resize_container", arguments: []interface{}{"container-foo", 51, 101}},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected resize calls %v, got %v", expected, calls)
+	}
+}
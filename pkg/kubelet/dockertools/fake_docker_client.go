@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -60,8 +61,19 @@ type FakeDockerClient struct {
 	Information     dockertypes.Info
 	ExecInspect     *dockertypes.ContainerExecInspect
 	execCmd         []string
+	execContainerID string
 	EnableSleep     bool
 	ImageHistoryMap map[string][]dockertypes.ImageHistory
+	StatsMap        map[string]*dockertypes.Stats
+	UpdatedConfigs  map[string]dockercontainer.UpdateConfig
+
+	// VolumeMounts maps container IDs to the HostConfig binds they were
+	// created with.
+	VolumeMounts map[string][]string
+
+	// If set, CreateContainer calls this before taking the lock, so tests
+	// can hold a create in flight while other calls proceed.
+	CreateHook func(dockertypes.ContainerCreateConfig)
 }
 
 // We don't check docker version now, just set the docker version of fake docker client to 1.8.1.
@@ -85,6 +97,7 @@ func newClientWithVersionAndClock(version, apiVersion string, c clock.Clock) *Fa
 		VersionInfo:  dockertypes.Version{Version: version, APIVersion: apiVersion},
 		Errors:       make(map[string]error),
 		ContainerMap: make(map[string]*dockertypes.ContainerJSON),
+		VolumeMounts: make(map[string][]string),
 		Clock:        c,
 	}
 }
@@ -103,6 +116,31 @@ func (f *FakeDockerClient) InjectErrors(errs map[string]error) {
 	}
 }
 
+// InjectTransientError injects an error for the given operation that
+// classifies as DockerErrorTransient.
+func (f *FakeDockerClient) InjectTransientError(fn string) {
+	f.InjectError(fn, operationTimeout{err: fmt.Errorf("%s: fake transient error", fn)})
+}
+
+// InjectNotFoundError injects an error for the given operation that
+// classifies as DockerErrorNotFound.
+func (f *FakeDockerClient) InjectNotFoundError(fn string, id string) {
+	f.InjectError(fn, containerNotFoundError{ID: id})
+}
+
+// InjectConflictError injects an error for the given operation that
+// classifies as DockerErrorConflict, naming staleID as the container holding
+// the requested name.
+func (f *FakeDockerClient) InjectConflictError(fn string, staleID string) {
+	f.InjectError(fn, fmt.Errorf("Error response from daemon: Conflict. The name is already in use by container %s. You have to remove (or rename) that container to be able to reuse that name.", staleID))
+}
+
+// InjectFatalError injects an error for the given operation that classifies
+// as DockerErrorFatal.
+func (f *FakeDockerClient) InjectFatalError(fn string) {
+	f.InjectError(fn, fmt.Errorf("%s: fake fatal error", fn))
+}
+
 func (f *FakeDockerClient) ClearErrors() {
 	f.Lock()
 	defer f.Unlock()
@@ -134,10 +172,12 @@ type FakeContainer struct {
 	Name       string
 	Running    bool
 	ExitCode   int
+	OOMKilled  bool
 	Pid        int
 	CreatedAt  time.Time
 	StartedAt  time.Time
 	FinishedAt time.Time
+	LogPath    string
 	Config     *dockercontainer.Config
 	HostConfig *dockercontainer.HostConfig
 }
@@ -157,11 +197,13 @@ func convertFakeContainer(f *FakeContainer) *dockertypes.ContainerJSON {
 			State: &dockertypes.ContainerState{
 				Running:    f.Running,
 				ExitCode:   f.ExitCode,
+				OOMKilled:  f.OOMKilled,
 				Pid:        f.Pid,
 				StartedAt:  dockerTimestampToString(f.StartedAt),
 				FinishedAt: dockerTimestampToString(f.FinishedAt),
 			},
 			Created:    dockerTimestampToString(f.CreatedAt),
+			LogPath:    f.LogPath,
 			HostConfig: f.HostConfig,
 		},
 		Config:          f.Config,
@@ -252,6 +294,30 @@ func (f *FakeDockerClient) AssertStarted(started []string) error {
 	return nil
 }
 
+// AssertVolumeMounted checks that the container was created with a bind
+// mounting hostPath at containerPath. An empty hostPath or containerPath
+// matches any value, which is useful when the host side is a generated
+// directory.
+func (f *FakeDockerClient) AssertVolumeMounted(containerID, hostPath, containerPath string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	binds, ok := f.VolumeMounts[containerID]
+	if !ok {
+		return fmt.Errorf("no volume mounts recorded for container %q", containerID)
+	}
+	for _, bind := range binds {
+		parts := strings.SplitN(bind, ":", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		if (hostPath == "" || parts[0] == hostPath) && (containerPath == "" || parts[1] == containerPath) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no bind of %q at %q for container %q, got %#v", hostPath, containerPath, containerID, binds)
+}
+
 func (f *FakeDockerClient) AssertStopped(stopped []string) error {
 	f.Lock()
 	defer f.Unlock()
@@ -337,6 +403,9 @@ func (f *FakeDockerClient) normalSleep(mean, stdDev, cutOffMillis int) {
 // CreateContainer is a test-spy implementation of DockerInterface.CreateContainer.
 // It adds an entry "create" to the internal method call record.
 func (f *FakeDockerClient) CreateContainer(c dockertypes.ContainerCreateConfig) (*dockertypes.ContainerCreateResponse, error) {
+	if f.CreateHook != nil {
+		f.CreateHook(c)
+	}
 	f.Lock()
 	defer f.Unlock()
 	f.called = append(f.called, calledDetail{name: "create"})
@@ -354,6 +423,12 @@ func (f *FakeDockerClient) CreateContainer(c dockertypes.ContainerCreateConfig)
 	}, f.RunningContainerList...)
 	f.ContainerMap[name] = convertFakeContainer(&FakeContainer{
 		ID: id, Name: name, Config: c.Config, HostConfig: c.HostConfig, CreatedAt: f.Clock.Now()})
+	if c.HostConfig != nil {
+		if f.VolumeMounts == nil {
+			f.VolumeMounts = make(map[string][]string)
+		}
+		f.VolumeMounts[id] = append([]string{}, c.HostConfig.Binds...)
+	}
 	f.normalSleep(100, 25, 25)
 	return &dockertypes.ContainerCreateResponse{ID: id}, nil
 }
@@ -480,6 +555,7 @@ func (f *FakeDockerClient) CreateExec(id string, opts dockertypes.ExecConfig) (*
 	f.Lock()
 	defer f.Unlock()
 	f.execCmd = opts.Cmd
+	f.execContainerID = id
 	f.called = append(f.called, calledDetail{name: "create_exec"})
 	return &dockertypes.ContainerExecCreateResponse{ID: "12345678"}, nil
 }
@@ -505,7 +581,25 @@ func (f *FakeDockerClient) InspectExec(id string) (*dockertypes.ContainerExecIns
 func (f *FakeDockerClient) ListImages(opts dockertypes.ImageListOptions) ([]dockertypes.Image, error) {
 	f.called = append(f.called, calledDetail{name: "list_images"})
 	err := f.popError("list_images")
-	return f.Images, err
+	if err != nil {
+		return nil, err
+	}
+	images := f.Images
+	// Mimic docker's "before" image filter, which lists only the images
+	// after the named one.
+	if before := opts.Filters.Get("before"); len(before) > 0 {
+		images = []dockertypes.Image{}
+		found := false
+		for _, image := range f.Images {
+			if found {
+				images = append(images, image)
+			}
+			if image.ID == before[0] {
+				found = true
+			}
+		}
+	}
+	return images, nil
 }
 
 func (f *FakeDockerClient) RemoveImage(image string, opts dockertypes.ImageRemoveOptions) ([]dockertypes.ImageDelete, error) {
@@ -536,20 +630,80 @@ func (f *FakeDockerClient) updateContainerStatus(id, status string) {
 	}
 }
 
+func (f *FakeDockerClient) ContainerStats(id string) (*dockertypes.Stats, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, calledDetail{name: "container_stats"})
+	if err := f.popError("container_stats"); err != nil {
+		return nil, err
+	}
+	stats, ok := f.StatsMap[id]
+	if !ok {
+		return &dockertypes.Stats{}, nil
+	}
+	return stats, nil
+}
+
+func (f *FakeDockerClient) UpdateContainer(id string, updateConfig dockercontainer.UpdateConfig) error {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, calledDetail{name: "update_container"})
+	if err := f.popError("update_container"); err != nil {
+		return err
+	}
+	if f.UpdatedConfigs == nil {
+		f.UpdatedConfigs = make(map[string]dockercontainer.UpdateConfig)
+	}
+	f.UpdatedConfigs[id] = updateConfig
+	return nil
+}
+
+// CreateCheckpoint is a test-spy implementation of
+// DockerInterface.CreateCheckpoint. It records the call with its arguments.
+func (f *FakeDockerClient) CreateCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, calledDetail{name: "create_checkpoint", arguments: []interface{}{id, checkpointID, checkpointDir}})
+	return f.popError("create_checkpoint")
+}
+
+// StartContainerFromCheckpoint is a test-spy implementation of
+// DockerInterface.StartContainerFromCheckpoint. It records the call with its
+// arguments.
+func (f *FakeDockerClient) StartContainerFromCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, calledDetail{name: "start_container_from_checkpoint", arguments: []interface{}{id, checkpointID, checkpointDir}})
+	return f.popError("start_container_from_checkpoint")
+}
+
 func (f *FakeDockerClient) ResizeExecTTY(id string, height, width int) error {
 	f.Lock()
 	defer f.Unlock()
-	f.called = append(f.called, calledDetail{name: "resize_exec"})
+	f.called = append(f.called, calledDetail{name: "resize_exec", arguments: []interface{}{id, height, width}})
 	return nil
 }
 
 func (f *FakeDockerClient) ResizeContainerTTY(id string, height, width int) error {
 	f.Lock()
 	defer f.Unlock()
-	f.called = append(f.called, calledDetail{name: "resize_container"})
+	f.called = append(f.called, calledDetail{name: "resize_container", arguments: []interface{}{id, height, width}})
 	return nil
 }
 
+// getResizeCalls returns the recorded resize calls with their dimensions.
+func (f *FakeDockerClient) getResizeCalls() []calledDetail {
+	f.Lock()
+	defer f.Unlock()
+	var calls []calledDetail
+	for _, c := range f.called {
+		if c.name == "resize_exec" || c.name == "resize_container" {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
 // FakeDockerPuller is a stub implementation of DockerPuller.
 type FakeDockerPuller struct {
 	sync.Mutex
@@ -582,7 +736,8 @@ func (f *FakeDockerPuller) IsImagePresent(name string) (bool, error) {
 		return true, nil
 	}
 	for _, s := range f.HasImages {
-		if s == name {
+		// Match canonical spellings, like the real puller does.
+		if s == name || canonicalImageSpelling(s) == canonicalImageSpelling(name) {
 			return true, nil
 		}
 	}
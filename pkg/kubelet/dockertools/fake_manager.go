@@ -17,6 +17,8 @@ limitations under the License.
 package dockertools
 
 import (
+	"time"
+
 	cadvisorapi "github.com/google/cadvisor/info/v1"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client/record"
@@ -26,6 +28,8 @@ import (
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/kubelet/util/cache"
 	"k8s.io/kubernetes/pkg/types"
+	utilcache "k8s.io/kubernetes/pkg/util/cache"
+	"k8s.io/kubernetes/pkg/util/clock"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/util/oom"
 	"k8s.io/kubernetes/pkg/util/procfs"
@@ -51,8 +55,14 @@ func NewFakeDockerManager(
 	fakePodGetter := &fakePodGetter{}
 	dm := NewDockerManager(client, recorder, livenessManager, containerRefManager, fakePodGetter, machineInfo, podInfraContainerImage, qps,
 		burst, containerLogsDir, osInterface, networkPlugin, runtimeHelper, httpClient, &NativeExecHandler{},
-		fakeOOMAdjuster, fakeProcFs, false, imageBackOff, false, false, true, "/var/lib/kubelet/seccomp")
+		fakeOOMAdjuster, fakeProcFs, false, imageBackOff, false, 0, false, true, "/var/lib/kubelet/seccomp", nil)
 	dm.dockerPuller = &FakeDockerPuller{}
+	// Tests advance time through a fake clock instead of faking timestamps
+	// in docker state; fakeClock() gives them access to it.
+	dm.clock = clock.NewFakeClock(time.Now())
+	// Rebuild the inspect cache on the fake clock so its entries expire as
+	// tests advance time.
+	dm.inspectCache = utilcache.NewLRUExpireCacheWithClock(inspectCacheSize, dm.clock)
 
 	// ttl of version cache is set to 0 so we always call version api directly in tests.
 	dm.versionCache = cache.NewObjectCache(
@@ -64,6 +74,12 @@ func NewFakeDockerManager(
 	return dm
 }
 
+// fakeClock returns the fake clock installed by NewFakeDockerManager so
+// tests can advance time explicitly.
+func (dm *DockerManager) fakeClock() *clock.FakeClock {
+	return dm.clock.(*clock.FakeClock)
+}
+
 type fakePodGetter struct {
 	pods map[types.UID]*api.Pod
 }
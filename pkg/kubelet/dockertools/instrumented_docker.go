@@ -20,6 +20,7 @@ import (
 	"time"
 
 	dockertypes "github.com/docker/engine-api/types"
+	dockercontainer "github.com/docker/engine-api/types/container"
 	"k8s.io/kubernetes/pkg/kubelet/metrics"
 )
 
@@ -214,6 +215,42 @@ func (in instrumentedDockerInterface) ImageHistory(id string) ([]dockertypes.Ima
 	return out, err
 }
 
+func (in instrumentedDockerInterface) ContainerStats(id string) (*dockertypes.Stats, error) {
+	const operation = "container_stats"
+	defer recordOperation(operation, time.Now())
+
+	out, err := in.client.ContainerStats(id)
+	recordError(operation, err)
+	return out, err
+}
+
+func (in instrumentedDockerInterface) UpdateContainer(id string, updateConfig dockercontainer.UpdateConfig) error {
+	const operation = "update_container"
+	defer recordOperation(operation, time.Now())
+
+	err := in.client.UpdateContainer(id, updateConfig)
+	recordError(operation, err)
+	return err
+}
+
+func (in instrumentedDockerInterface) CreateCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	const operation = "create_checkpoint"
+	defer recordOperation(operation, time.Now())
+
+	err := in.client.CreateCheckpoint(id, checkpointID, checkpointDir)
+	recordError(operation, err)
+	return err
+}
+
+func (in instrumentedDockerInterface) StartContainerFromCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	const operation = "start_container_from_checkpoint"
+	defer recordOperation(operation, time.Now())
+
+	err := in.client.StartContainerFromCheckpoint(id, checkpointID, checkpointDir)
+	recordError(operation, err)
+	return err
+}
+
 func (in instrumentedDockerInterface) ResizeExecTTY(id string, height, width int) error {
 	const operation = "resize_exec"
 	defer recordOperation(operation, time.Now())
@@ -32,6 +32,7 @@ import (
 	dockerstdcopy "github.com/docker/docker/pkg/stdcopy"
 	dockerapi "github.com/docker/engine-api/client"
 	dockertypes "github.com/docker/engine-api/types"
+	dockercontainer "github.com/docker/engine-api/types/container"
 	"golang.org/x/net/context"
 )
 
@@ -468,6 +469,50 @@ func (d *kubeDockerClient) AttachToContainer(id string, opts dockertypes.Contain
 	return d.holdHijackedConnection(sopts.RawTerminal, sopts.InputStream, sopts.OutputStream, sopts.ErrorStream, resp)
 }
 
+// ContainerStats performs a one-shot stats request against the container and
+// decodes the single stats object docker returns for it.
+func (d *kubeDockerClient) ContainerStats(id string) (*dockertypes.Stats, error) {
+	ctx, cancel := d.getTimeoutContext()
+	defer cancel()
+	resp, err := d.client.ContainerStats(ctx, id, false)
+	if ctxErr := contextError(ctx); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	stats := &dockertypes.Stats{}
+	if err := json.NewDecoder(resp).Decode(stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// UpdateContainer updates the mutable attributes, e.g. the cgroup limits, of
+// a running container.
+func (d *kubeDockerClient) UpdateContainer(id string, updateConfig dockercontainer.UpdateConfig) error {
+	ctx, cancel := d.getTimeoutContext()
+	defer cancel()
+	err := d.client.ContainerUpdate(ctx, id, updateConfig)
+	if ctxErr := contextError(ctx); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// CreateCheckpoint is not implemented yet: the vendored docker API client
+// predates the checkpoint endpoints, so callers get a clear error instead of
+// a silent no-op until the dependency is bumped.
+func (d *kubeDockerClient) CreateCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	return fmt.Errorf("checkpointing container %q is not supported by the docker API client", id)
+}
+
+// StartContainerFromCheckpoint is not implemented yet, see CreateCheckpoint.
+func (d *kubeDockerClient) StartContainerFromCheckpoint(id string, checkpointID string, checkpointDir string) error {
+	return fmt.Errorf("restoring container %q is not supported by the docker API client", id)
+}
+
 func (d *kubeDockerClient) ResizeExecTTY(id string, height, width int) error {
 	ctx, cancel := d.getCancelableContext()
 	defer cancel()
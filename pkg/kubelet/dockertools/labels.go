@@ -34,15 +34,21 @@ import (
 //  * label setters and getters
 //  * label filters (maybe in the future)
 
+// Together with the pod and container name labels in the kubelet types package
+// (io.kubernetes.pod.name, io.kubernetes.pod.namespace, io.kubernetes.pod.uid and
+// io.kubernetes.container.name), the restart count and termination message path
+// labels below are set on every container we create, so that node-level tooling
+// can identify containers without querying the apiserver.
 const (
 	kubernetesPodDeletionGracePeriodLabel    = "io.kubernetes.pod.deletionGracePeriod"
 	kubernetesPodTerminationGracePeriodLabel = "io.kubernetes.pod.terminationGracePeriod"
 
-	kubernetesContainerHashLabel                   = "io.kubernetes.container.hash"
-	kubernetesContainerRestartCountLabel           = "io.kubernetes.container.restartCount"
-	kubernetesContainerTerminationMessagePathLabel = "io.kubernetes.container.terminationMessagePath"
-	kubernetesContainerPreStopHandlerLabel         = "io.kubernetes.container.preStopHandler"
-	kubernetesContainerPortsLabel                  = "io.kubernetes.container.ports" // Added in 1.4
+	kubernetesContainerHashLabel                     = "io.kubernetes.container.hash"
+	kubernetesContainerRestartCountLabel             = "io.kubernetes.container.restartCount"
+	kubernetesContainerTerminationMessagePathLabel   = "io.kubernetes.container.terminationMessagePath"
+	kubernetesContainerTerminationMessagePolicyLabel = "io.kubernetes.container.terminationMessagePolicy"
+	kubernetesContainerPreStopHandlerLabel           = "io.kubernetes.container.preStopHandler"
+	kubernetesContainerPortsLabel                    = "io.kubernetes.container.ports" // Added in 1.4
 
 	// TODO(random-liu): Keep this for old containers, remove this when we drop support for v1.1.
 	kubernetesPodLabel = "io.kubernetes.pod.data"
@@ -50,6 +56,18 @@ const (
 	cadvisorPrometheusMetricsLabel = "io.cadvisor.metric.prometheus"
 )
 
+const (
+	// TerminationMessagePolicyAnnotation is the pod annotation that selects how
+	// termination messages for its containers are produced. It is an annotation
+	// rather than an api.Container field until the API change lands.
+	TerminationMessagePolicyAnnotation = "kubernetes.io/termination-message-policy"
+
+	// TerminationMessageFallbackToLogsOnError makes the tail of the container
+	// log serve as the termination message when a container exits non-zero
+	// without writing its termination message file.
+	TerminationMessageFallbackToLogsOnError = "FallbackToLogsOnError"
+)
+
 // Container information which has been labelled on each docker container
 // TODO(random-liu): The type of Hash should be compliance with kubelet container status.
 type labelledContainerInfo struct {
@@ -62,6 +80,7 @@ type labelledContainerInfo struct {
 	Hash                      string
 	RestartCount              int
 	TerminationMessagePath    string
+	TerminationMessagePolicy  string
 	PreStopHandler            *api.Handler
 	Ports                     []api.ContainerPort
 }
@@ -82,6 +101,9 @@ func newLabels(container *api.Container, pod *api.Pod, restartCount int, enableC
 	labels[kubernetesContainerHashLabel] = strconv.FormatUint(kubecontainer.HashContainer(container), 16)
 	labels[kubernetesContainerRestartCountLabel] = strconv.Itoa(restartCount)
 	labels[kubernetesContainerTerminationMessagePathLabel] = container.TerminationMessagePath
+	if policy, found := pod.Annotations[TerminationMessagePolicyAnnotation]; found {
+		labels[kubernetesContainerTerminationMessagePolicyLabel] = policy
+	}
 	if container.Lifecycle != nil && container.Lifecycle.PreStop != nil {
 		// Using json enconding so that the PreStop handler object is readable after writing as a label
 		rawPreStop, err := json.Marshal(container.Lifecycle.PreStop)
@@ -118,6 +140,9 @@ func getContainerInfoFromLabel(labels map[string]string) *labelledContainerInfo
 		Name:         getStringValueFromLabel(labels, types.KubernetesContainerNameLabel),
 		Hash:         getStringValueFromLabel(labels, kubernetesContainerHashLabel),
 		TerminationMessagePath: getStringValueFromLabel(labels, kubernetesContainerTerminationMessagePathLabel),
+		// The termination message policy label is only present when the pod
+		// carries the policy annotation, so its absence is not logged.
+		TerminationMessagePolicy: labels[kubernetesContainerTerminationMessagePolicyLabel],
 	}
 	if containerInfo.RestartCount, err = getIntValueFromLabel(labels, kubernetesContainerRestartCountLabel); err != nil {
 		logError(containerInfo, kubernetesContainerRestartCountLabel, err)
@@ -214,6 +239,15 @@ func supplyContainerInfoWithOldLabel(labels map[string]string, containerInfo *la
 		logError(containerInfo, kubernetesPodLabel, err)
 		return
 	}
+	if containerInfo.PodName == "" {
+		containerInfo.PodName = pod.Name
+	}
+	if containerInfo.PodNamespace == "" {
+		containerInfo.PodNamespace = pod.Namespace
+	}
+	if containerInfo.PodUID == "" {
+		containerInfo.PodUID = pod.UID
+	}
 	if containerInfo.PodDeletionGracePeriod == nil {
 		containerInfo.PodDeletionGracePeriod = pod.DeletionGracePeriodSeconds
 	}
@@ -233,6 +267,9 @@ func supplyContainerInfoWithOldLabel(labels map[string]string, containerInfo *la
 		glog.Errorf("Unable to find container %q in pod %q", containerInfo.Name, format.Pod(pod))
 		return
 	}
+	if containerInfo.TerminationMessagePath == "" {
+		containerInfo.TerminationMessagePath = container.TerminationMessagePath
+	}
 	if containerInfo.PreStopHandler == nil && container.Lifecycle != nil {
 		containerInfo.PreStopHandler = container.Lifecycle.PreStop
 	}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/testapi"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/kubelet/util/format"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/intstr"
@@ -103,6 +104,20 @@ func TestLabels(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, containerInfo)
 	}
 
+	// Test that all the labels external tooling relies on are set on the container.
+	for _, label := range []string{
+		types.KubernetesPodNameLabel,
+		types.KubernetesPodNamespaceLabel,
+		types.KubernetesPodUIDLabel,
+		types.KubernetesContainerNameLabel,
+		kubernetesContainerRestartCountLabel,
+		kubernetesContainerTerminationMessagePathLabel,
+	} {
+		if _, found := labels[label]; !found {
+			t.Errorf("expected label %q to be set, got labels %v", label, labels)
+		}
+	}
+
 	// Test when DeletionGracePeriodSeconds, TerminationGracePeriodSeconds and Lifecycle are nil,
 	// the information got from label should also be nil
 	container.Lifecycle = nil
@@ -138,4 +153,26 @@ func TestLabels(t *testing.T) {
 	if !reflect.DeepEqual(containerInfo, expected) {
 		t.Errorf("expected %v, got %v", expected, containerInfo)
 	}
+
+	// Test a container created by an old kubelet, which only has the container name
+	// label and the old pod label. The pod and container information should still be
+	// recovered from the old pod label.
+	oldLabels := map[string]string{
+		types.KubernetesContainerNameLabel: container.Name,
+		kubernetesPodLabel:                 string(data),
+	}
+	expected = &labelledContainerInfo{
+		PodName:                   pod.Name,
+		PodNamespace:              pod.Namespace,
+		PodUID:                    pod.UID,
+		PodDeletionGracePeriod:    pod.DeletionGracePeriodSeconds,
+		PodTerminationGracePeriod: pod.Spec.TerminationGracePeriodSeconds,
+		Name: container.Name,
+		TerminationMessagePath: container.TerminationMessagePath,
+		PreStopHandler:         container.Lifecycle.PreStop,
+	}
+	containerInfo = getContainerInfoFromLabel(oldLabels)
+	if !reflect.DeepEqual(containerInfo, expected) {
+		t.Errorf("expected %v, got %v", expected, containerInfo)
+	}
 }
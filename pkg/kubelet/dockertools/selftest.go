@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+// selfTestCheckTimeout bounds each individual self-test check, so that a hung
+// docker daemon cannot stall kubelet startup indefinitely.
+const selfTestCheckTimeout = 30 * time.Second
+
+// lookPath is exec.LookPath, replaceable for testing.
+var lookPath = exec.LookPath
+
+// SelfTest validates the node prerequisites the docker runtime depends on and
+// returns one result per failed check. It is run once at kubelet startup, and
+// on demand via the kubelet's debug endpoint, so that misconfigured nodes fail
+// visibly instead of when the first pod exercises the broken path.
+func (dm *DockerManager) SelfTest() []kubecontainer.SelfTestCheckResult {
+	checks := []struct {
+		name     string
+		critical bool
+		check    func() error
+	}{
+		{"docker-version", true, dm.checkVersionCompatibility},
+		{"network-plugin", true, func() error { return dm.networkPlugin.Status() }},
+		// socat and nsenter are only needed for port-forwarding, so their
+		// absence does not make the node unable to run pods.
+		{"socat", false, func() error { _, err := lookPath("socat"); return err }},
+		{"nsenter", false, func() error { _, err := lookPath("nsenter"); return err }},
+		{"pod-infra-image", false, dm.checkPodInfraImage},
+	}
+	var failed []kubecontainer.SelfTestCheckResult
+	for _, c := range checks {
+		if result := kubecontainer.RunSelfTestCheck(c.name, c.critical, selfTestCheckTimeout, c.check); result != nil {
+			failed = append(failed, *result)
+		}
+	}
+	return failed
+}
+
+// checkPodInfraImage verifies that the pod infra container image is present on
+// the node. Absence is not fatal - the image is pulled on demand - but pulling
+// at pod start adds latency, and fails entirely on airgapped nodes.
+func (dm *DockerManager) checkPodInfraImage() error {
+	present, err := dm.IsImagePresent(kubecontainer.ImageSpec{Image: dm.podInfraContainerImage})
+	if err != nil {
+		return err
+	}
+	if !present {
+		return fmt.Errorf("pod infra container image %q is not present on the node", dm.podInfraContainerImage)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"fmt"
+	"testing"
+
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+// stubLookPath replaces lookPath with a stub that fails for the named
+// binaries, and returns a function restoring the original.
+func stubLookPath(missing ...string) func() {
+	origLookPath := lookPath
+	lookPath = func(file string) (string, error) {
+		for _, m := range missing {
+			if file == m {
+				return "", fmt.Errorf("exec: %q: executable file not found in $PATH", file)
+			}
+		}
+		return "/usr/bin/" + file, nil
+	}
+	return func() { lookPath = origLookPath }
+}
+
+func selfTestFailuresByName(results []kubecontainer.SelfTestCheckResult) map[string]kubecontainer.SelfTestCheckResult {
+	failures := make(map[string]kubecontainer.SelfTestCheckResult)
+	for _, result := range results {
+		failures[result.Name] = result
+	}
+	return failures
+}
+
+func TestSelfTestAllChecksPass(t *testing.T) {
+	dm, _ := newTestDockerManager()
+	defer stubLookPath()()
+
+	if results := dm.SelfTest(); len(results) != 0 {
+		t.Errorf("expected no self-test failures, got %v", results)
+	}
+}
+
+func TestSelfTestReportsFailedChecks(t *testing.T) {
+	// The API version is below minimumDockerAPIVersion, and socat is missing.
+	dm, _ := newTestDockerManagerWithVersion("1.9.1", "1.15")
+	defer stubLookPath("socat")()
+
+	failures := selfTestFailuresByName(dm.SelfTest())
+	versionFailure, ok := failures["docker-version"]
+	if !ok {
+		t.Errorf("expected a docker-version failure, got %v", failures)
+	} else if !versionFailure.Critical {
+		t.Errorf("expected the docker-version failure to be critical, got %v", versionFailure)
+	}
+	socatFailure, ok := failures["socat"]
+	if !ok {
+		t.Errorf("expected a socat failure, got %v", failures)
+	} else if socatFailure.Critical {
+		t.Errorf("expected the socat failure to be non-critical, got %v", socatFailure)
+	}
+	if _, ok := failures["nsenter"]; ok {
+		t.Errorf("did not expect an nsenter failure, got %v", failures["nsenter"])
+	}
+}
@@ -23,16 +23,19 @@ const (
 	FailedToCreateContainer = "Failed"
 	FailedToStartContainer  = "Failed"
 	KillingContainer        = "Killing"
+	OOMKilling              = "OOMKilling"
 	BackOffStartContainer   = "BackOff"
 	ExceededGracePeriod     = "ExceededGracePeriod"
 
 	// Image event reason list
-	PullingImage            = "Pulling"
-	PulledImage             = "Pulled"
-	FailedToPullImage       = "Failed"
-	FailedToInspectImage    = "InspectFailed"
-	ErrImageNeverPullPolicy = "ErrImageNeverPull"
-	BackOffPullImage        = "BackOff"
+	PullingImage             = "Pulling"
+	PulledImage              = "Pulled"
+	FailedToPullImage        = "Failed"
+	FailedToInspectImage     = "InspectFailed"
+	ErrImageNeverPullPolicy  = "ErrImageNeverPull"
+	BackOffPullImage         = "BackOff"
+	ImagePullPolicyViolation = "ImagePullPolicyViolation"
+	InvalidImagePullSecrets  = "InvalidImagePullSecrets"
 
 	// kubelet event reason list
 	NodeReady               = "NodeReady"
@@ -41,6 +44,9 @@ const (
 	NodeNotSchedulable      = "NodeNotSchedulable"
 	StartingKubelet         = "Starting"
 	KubeletSetupFailed      = "KubeletSetupFailed"
+	RuntimeSelfTestFailed   = "RuntimeSelfTestFailed"
+	SysctlForbidden         = "SysctlForbidden"
+	SupersededPod           = "SupersededPod"
 	FailedDetachVolume      = "FailedDetachVolume"
 	FailedMountVolume       = "FailedMount"
 	FailedUnMountVolume     = "FailedUnMount"
@@ -18,13 +18,17 @@ package images
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/credentialprovider"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/kubelet/events"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
+	"k8s.io/kubernetes/pkg/util/parsers"
 )
 
 // imageManager provides the functionalities for image pulling.
@@ -34,11 +38,16 @@ type imageManager struct {
 	backOff      *flowcontrol.Backoff
 	// It will check the presence of the image, and report the 'image pulling', image pulled' events correspondingly.
 	puller imagePuller
+	// Bounds how long a single image pull may take. Zero means pulls are
+	// not bounded by the kubelet; a pod's active deadline may still bound them.
+	imagePullTimeout time.Duration
+	// Restricts which registries images may be pulled from. May be nil.
+	registryPolicy *RegistryPolicy
 }
 
 var _ ImageManager = &imageManager{}
 
-func NewImageManager(recorder record.EventRecorder, imageService kubecontainer.ImageService, imageBackOff *flowcontrol.Backoff, serialized bool, qps float32, burst int) ImageManager {
+func NewImageManager(recorder record.EventRecorder, imageService kubecontainer.ImageService, imageBackOff *flowcontrol.Backoff, serialized bool, qps float32, burst int, imagePullTimeout time.Duration, registryPolicy *RegistryPolicy) ImageManager {
 	imageService = throttleImagePulling(imageService, qps, burst)
 
 	var puller imagePuller
@@ -48,10 +57,12 @@ func NewImageManager(recorder record.EventRecorder, imageService kubecontainer.I
 		puller = newParallelImagePuller(imageService)
 	}
 	return &imageManager{
-		recorder:     recorder,
-		imageService: imageService,
-		backOff:      imageBackOff,
-		puller:       puller,
+		recorder:         recorder,
+		imageService:     imageService,
+		backOff:          imageBackOff,
+		puller:           puller,
+		imagePullTimeout: imagePullTimeout,
+		registryPolicy:   registryPolicy,
 	}
 }
 
@@ -70,6 +81,27 @@ func shouldPullImage(container *api.Container, imagePresent bool) bool {
 	return false
 }
 
+// pullTimeout returns how long the image pull for the given pod may run: the
+// smaller of the kubelet-wide image pull timeout and the time remaining until
+// the pod's active deadline. Zero means the pull is not bounded.
+func (m *imageManager) pullTimeout(pod *api.Pod) time.Duration {
+	timeout := m.imagePullTimeout
+	if pod.Spec.ActiveDeadlineSeconds != nil && pod.Status.StartTime != nil {
+		deadline := pod.Status.StartTime.Add(time.Duration(*pod.Spec.ActiveDeadlineSeconds) * time.Second)
+		remaining := deadline.Sub(m.backOff.Clock.Now())
+		if remaining < time.Second {
+			// The pod is at (or nearly at) its active deadline and will be
+			// killed shortly; there is no point starting a pull that cannot
+			// finish, but avoid a timer that has already expired.
+			remaining = time.Second
+		}
+		if timeout == 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout
+}
+
 // records an event using ref, event msg.  log to glog using prefix, msg, logFn
 func (m *imageManager) logIt(ref *api.ObjectReference, eventtype, event, prefix, msg string, logFn func(args ...interface{})) {
 	if ref != nil {
@@ -87,6 +119,23 @@ func (m *imageManager) EnsureImageExists(pod *api.Pod, container *api.Container,
 		glog.Errorf("Couldn't make a ref to pod %v, container %v: '%v'", pod.Name, container.Name, err)
 	}
 
+	// Reject invalid references up front, so the user sees one clear start
+	// failure instead of a confusing inspect or pull error downstream. An
+	// empty image name is left alone: it has always been the runtime's job
+	// to report it.
+	backOffKey := fmt.Sprintf("%s_%s", pod.UID, container.Image)
+	if container.Image != "" {
+		imageRef, err := parsers.ParseImageRef(container.Image)
+		if err != nil {
+			msg := fmt.Sprintf("Invalid image name %q: %v", container.Image, err)
+			m.logIt(ref, api.EventTypeWarning, events.FailedToInspectImage, logPrefix, msg, glog.Warning)
+			return ErrInvalidImageName, msg
+		}
+		// Key the backoff on the canonical spelling, so equivalent spellings
+		// of the same image share one backoff entry.
+		backOffKey = fmt.Sprintf("%s_%s", pod.UID, imageRef.String())
+	}
+
 	spec := kubecontainer.ImageSpec{Image: container.Image}
 	present, err := m.imageService.IsImagePresent(spec)
 	if err != nil {
@@ -107,21 +156,54 @@ func (m *imageManager) EnsureImageExists(pod *api.Pod, container *api.Container,
 		}
 	}
 
-	backOffKey := fmt.Sprintf("%s_%s", pod.UID, container.Image)
+	if err := m.registryPolicy.Admit(container.Image); err != nil {
+		msg := fmt.Sprintf("Pulling image %q is not allowed by the registry pull policy: %v", container.Image, err)
+		m.logIt(ref, api.EventTypeWarning, events.ImagePullPolicyViolation, logPrefix, msg, glog.Warning)
+		return ErrImagePullPolicyViolation, msg
+	}
+
 	if m.backOff.IsInBackOffSinceUpdate(backOffKey, m.backOff.Clock.Now()) {
 		msg := fmt.Sprintf("Back-off pulling image %q", container.Image)
 		m.logIt(ref, api.EventTypeNormal, events.BackOffPullImage, logPrefix, msg, glog.Info)
 		return ErrImagePullBackOff, msg
 	}
+	// Validate the pull secrets up front so the user learns about unusable
+	// secrets from a single event; the pull is still attempted with whatever
+	// credentials did parse.
+	var badSecrets []string
+	for _, pullSecret := range pullSecrets {
+		if _, found, err := credentialprovider.ParseDockerConfigFromSecret(pullSecret); found && err != nil {
+			badSecrets = append(badSecrets, fmt.Sprintf("%s (%v)", pullSecret.Name, err))
+		}
+	}
+	if len(badSecrets) > 0 {
+		msg := fmt.Sprintf("Unable to parse image pull secrets %s; attempting the pull with the remaining credentials", strings.Join(badSecrets, ", "))
+		m.logIt(ref, api.EventTypeWarning, events.InvalidImagePullSecrets, logPrefix, msg, glog.Warning)
+	}
 	m.logIt(ref, api.EventTypeNormal, events.PullingImage, logPrefix, fmt.Sprintf("pulling image %q", container.Image), glog.Info)
-	errChan := make(chan error)
+	// The channel is buffered so an abandoned pull does not block the puller
+	// when it eventually delivers its result.
+	errChan := make(chan error, 1)
 	m.puller.pullImage(spec, pullSecrets, errChan)
-	if err := <-errChan; err != nil {
+	var pullErr error
+	if timeout := m.pullTimeout(pod); timeout != 0 {
+		select {
+		case pullErr = <-errChan:
+		case <-m.backOff.Clock.After(timeout):
+			pullErr = ErrImagePullDeadlineExceeded
+		}
+	} else {
+		pullErr = <-errChan
+	}
+	if err := pullErr; err != nil {
 		m.logIt(ref, api.EventTypeWarning, events.FailedToPullImage, logPrefix, fmt.Sprintf("Failed to pull image %q: %v", container.Image, err), glog.Warning)
 		m.backOff.Next(backOffKey, m.backOff.Clock.Now())
 		if err == RegistryUnavailable {
 			msg := fmt.Sprintf("image pull failed for %s because the registry is unavailable.", container.Image)
 			return err, msg
+		} else if err == ErrImagePullDeadlineExceeded {
+			msg := fmt.Sprintf("image pull for %s did not complete before its deadline.", container.Image)
+			return err, msg
 		} else {
 			return ErrImagePull, err.Error()
 		}
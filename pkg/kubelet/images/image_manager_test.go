@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/client/record"
 	. "k8s.io/kubernetes/pkg/kubelet/container"
 	ctest "k8s.io/kubernetes/pkg/kubelet/container/testing"
@@ -106,7 +107,7 @@ func TestParallelPuller(t *testing.T) {
 
 		fakeRuntime := &ctest.FakeRuntime{}
 		fakeRecorder := &record.FakeRecorder{}
-		puller := NewImageManager(fakeRecorder, fakeRuntime, backOff, false, 0, 0)
+		puller := NewImageManager(fakeRecorder, fakeRuntime, backOff, false, 0, 0, 0, nil)
 
 		fakeRuntime.ImageList = []Image{{ID: "present_image", Size: 1}}
 		fakeRuntime.Err = c.pullerErr
@@ -197,7 +198,7 @@ func TestSerializedPuller(t *testing.T) {
 
 		fakeRuntime := &ctest.FakeRuntime{}
 		fakeRecorder := &record.FakeRecorder{}
-		puller := NewImageManager(fakeRecorder, fakeRuntime, backOff, true, 0, 0)
+		puller := NewImageManager(fakeRecorder, fakeRuntime, backOff, true, 0, 0, 0, nil)
 
 		fakeRuntime.ImageList = []Image{{ID: "present_image"}}
 		fakeRuntime.Err = c.pullerErr
@@ -211,3 +212,63 @@ func TestSerializedPuller(t *testing.T) {
 		}
 	}
 }
+
+// blockingPuller never delivers a pull result; it simulates a pull hung on an
+// unresponsive registry.
+type blockingPuller struct{}
+
+func (b *blockingPuller) pullImage(ImageSpec, []api.Secret, chan<- error) {}
+
+func TestPullDeadlineExceeded(t *testing.T) {
+	backOff := flowcontrol.NewBackOff(time.Second, time.Minute)
+	fakeClock := clock.NewFakeClock(time.Now())
+	backOff.Clock = fakeClock
+
+	fakeRuntime := &ctest.FakeRuntime{}
+	fakeRecorder := &record.FakeRecorder{}
+	manager := &imageManager{
+		recorder:         fakeRecorder,
+		imageService:     fakeRuntime,
+		backOff:          backOff,
+		puller:           &blockingPuller{},
+		imagePullTimeout: 5 * time.Minute,
+	}
+
+	// The pod's remaining active deadline is shorter than the kubelet-wide
+	// image pull timeout, so it bounds the pull.
+	activeDeadlineSeconds := int64(60)
+	startTime := unversioned.NewTime(fakeClock.Now())
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name:            "test_pod",
+			Namespace:       "test-ns",
+			UID:             "bar",
+			ResourceVersion: "42",
+			SelfLink:        "/api/v1/pods/foo",
+		},
+		Spec:   api.PodSpec{ActiveDeadlineSeconds: &activeDeadlineSeconds},
+		Status: api.PodStatus{StartTime: &startTime},
+	}
+	container := &api.Container{
+		Name:            "container_name",
+		Image:           "missing_image",
+		ImagePullPolicy: api.PullAlways,
+	}
+
+	resultChan := make(chan error, 1)
+	go func() {
+		err, _ := manager.EnsureImageExists(pod, container, nil)
+		resultChan <- err
+	}()
+
+	// Wait for the pull to block on the deadline timer, then expire it.
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(time.Duration(activeDeadlineSeconds) * time.Second)
+	assert.Equal(t, ErrImagePullDeadlineExceeded, <-resultChan)
+
+	// The aborted pull feeds the regular image pull back-off handling.
+	err, _ := manager.EnsureImageExists(pod, container, nil)
+	assert.Equal(t, ErrImagePullBackOff, err)
+}
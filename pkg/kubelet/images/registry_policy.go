@@ -0,0 +1,111 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/parsers"
+)
+
+// RegistryPolicy decides whether the kubelet may pull an image, based on glob
+// patterns matched against the registry/repository portion of the image
+// reference. Deny patterns take precedence over allow patterns; if any allow
+// patterns are configured, only matching repositories may be pulled. A nil
+// policy admits every image.
+type RegistryPolicy struct {
+	allowed []string
+	denied  []string
+}
+
+// NewRegistryPolicy compiles the given allow and deny patterns into a
+// RegistryPolicy. An error is returned for malformed patterns, so that a bad
+// configuration fails kubelet startup instead of silently admitting images.
+func NewRegistryPolicy(allowed, denied []string) (*RegistryPolicy, error) {
+	for _, pattern := range append(append([]string{}, allowed...), denied...) {
+		if err := validateRegistryPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return &RegistryPolicy{allowed: allowed, denied: denied}, nil
+}
+
+// Admit returns nil if pulling the image is permitted by the policy, or an
+// error describing the violated rule.
+func (p *RegistryPolicy) Admit(image string) error {
+	if p == nil || (len(p.allowed) == 0 && len(p.denied) == 0) {
+		return nil
+	}
+	ref, err := parsers.ParseImageRef(image)
+	if err != nil {
+		return err
+	}
+	repository := ref.RegistryRepository()
+	for _, pattern := range p.denied {
+		if matchRepository(pattern, repository) {
+			return fmt.Errorf("repository %q matches denied registry pattern %q", repository, pattern)
+		}
+	}
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, pattern := range p.allowed {
+		if matchRepository(pattern, repository) {
+			return nil
+		}
+	}
+	return fmt.Errorf("repository %q does not match any allowed registry pattern", repository)
+}
+
+// validateRegistryPattern checks every path segment of the pattern with
+// path.Match so malformed globs are caught at compile time. The segment is
+// matched against itself because path.Match only reports ErrBadPattern for
+// the parts of a pattern it actually scans.
+func validateRegistryPattern(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, segment); err != nil {
+			return fmt.Errorf("invalid registry pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchRepository matches the repository against the pattern segment by
+// segment using path.Match. A trailing "**" segment matches any remainder, so
+// "gcr.io/**" covers every repository hosted on gcr.io.
+func matchRepository(pattern, repository string) bool {
+	patternSegments := strings.Split(pattern, "/")
+	repositorySegments := strings.Split(repository, "/")
+	if patternSegments[len(patternSegments)-1] == "**" {
+		patternSegments = patternSegments[:len(patternSegments)-1]
+		if len(repositorySegments) < len(patternSegments) {
+			return false
+		}
+		repositorySegments = repositorySegments[:len(patternSegments)]
+	} else if len(patternSegments) != len(repositorySegments) {
+		return false
+	}
+	for i := range patternSegments {
+		matched, err := path.Match(patternSegments[i], repositorySegments[i])
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
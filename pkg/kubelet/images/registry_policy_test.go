@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"testing"
+)
+
+func TestNewRegistryPolicyInvalidPattern(t *testing.T) {
+	cases := []struct {
+		allowed []string
+		denied  []string
+	}{
+		{allowed: []string{"gcr.io/[foo"}},
+		{denied: []string{"docker.io/library/[a-"}},
+	}
+	for i, c := range cases {
+		if _, err := NewRegistryPolicy(c.allowed, c.denied); err == nil {
+			t.Errorf("case %d: expected error compiling policy {allowed: %v, denied: %v}", i, c.allowed, c.denied)
+		}
+	}
+}
+
+func TestRegistryPolicyAdmit(t *testing.T) {
+	digest := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	cases := []struct {
+		name     string
+		allowed  []string
+		denied   []string
+		image    string
+		expected bool
+	}{
+		{"empty policy admits everything", nil, nil, "gcr.io/google_containers/pause", true},
+		{"allow list admits matching image", []string{"gcr.io/**"}, nil, "gcr.io/google_containers/pause", true},
+		{"allow list rejects non-matching image", []string{"gcr.io/**"}, nil, "quay.io/coreos/etcd", false},
+		{"deny takes precedence over allow", []string{"gcr.io/**"}, []string{"gcr.io/evil/**"}, "gcr.io/evil/pause", false},
+		{"deny alone admits non-matching image", nil, []string{"quay.io/**"}, "gcr.io/google_containers/pause", true},
+		{"deny alone rejects matching image", nil, []string{"quay.io/**"}, "quay.io/coreos/etcd", false},
+		{"official image is normalized to docker.io/library", []string{"docker.io/library/*"}, nil, "busybox", true},
+		{"user image is normalized to docker.io", []string{"docker.io/foo/*"}, nil, "foo/bar", true},
+		{"normalized image rejected by non-hub allow list", []string{"gcr.io/**"}, nil, "busybox", false},
+		{"tag is ignored when matching", nil, []string{"docker.io/library/*"}, "busybox:1.24", false},
+		{"digest reference is matched on the repository", []string{"gcr.io/**"}, nil, "gcr.io/google_containers/pause@" + digest, true},
+		{"digest reference rejected by deny pattern", nil, []string{"gcr.io/evil/**"}, "gcr.io/evil/pause@" + digest, false},
+		{"glob does not cross path segments", []string{"gcr.io/*"}, nil, "gcr.io/google_containers/pause", false},
+		{"localhost registry is not normalized", []string{"docker.io/**"}, nil, "localhost:5000/foo", false},
+	}
+	for _, c := range cases {
+		policy, err := NewRegistryPolicy(c.allowed, c.denied)
+		if err != nil {
+			t.Errorf("%s: unexpected error compiling policy: %v", c.name, err)
+			continue
+		}
+		err = policy.Admit(c.image)
+		if admitted := err == nil; admitted != c.expected {
+			t.Errorf("%s: expected admitted=%v for image %q, got error %v", c.name, c.expected, c.image, err)
+		}
+	}
+}
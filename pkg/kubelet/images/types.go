@@ -37,6 +37,15 @@ var (
 
 	// Get http error when pulling image from registry
 	RegistryUnavailable = errors.New("RegistryUnavailable")
+
+	// Image pull did not complete before its deadline elapsed
+	ErrImagePullDeadlineExceeded = errors.New("ImagePullDeadlineExceeded")
+
+	// Requested image comes from a registry that the pull policy forbids
+	ErrImagePullPolicyViolation = errors.New("ImagePullPolicyViolation")
+
+	// Requested image reference could not be parsed
+	ErrInvalidImageName = errors.New("InvalidImageName")
 )
 
 // ImageManager provides an interface to manage the lifecycle of images.
@@ -144,6 +144,10 @@ const (
 
 	// Minimum number of dead containers to keep in a pod
 	minDeadContainerInPod = 1
+
+	// podWorkersDrainTimeout bounds how long shutdown waits for in-flight
+	// pod syncs to complete before giving up on them.
+	podWorkersDrainTimeout = 30 * time.Second
 )
 
 // SyncHandler is an interface implemented by Kubelet, for testability
@@ -406,7 +410,7 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 		rootDirectory:                  kubeCfg.RootDirectory,
 		resyncInterval:                 kubeCfg.SyncFrequency.Duration,
 		containerRefManager:            containerRefManager,
-		httpClient:                     &http.Client{},
+		httpClient:                     lifecycle.NewHookHTTPClient(),
 		sourcesReady:                   config.NewSourcesReady(kubeDeps.PodConfig.SeenAllSources),
 		registerNode:                   kubeCfg.RegisterNode,
 		registerSchedulable:            kubeCfg.RegisterSchedulable,
@@ -506,6 +510,11 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 		}
 	}
 
+	registryPolicy, err := images.NewRegistryPolicy(kubeCfg.AllowedImageRegistries, kubeCfg.DeniedImageRegistries)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the runtime.
 	switch kubeCfg.ContainerRuntime {
 	case "docker":
@@ -528,6 +537,8 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 				kubeCfg.SerializeImagePulls,
 				float32(kubeCfg.RegistryPullQPS),
 				int(kubeCfg.RegistryBurst),
+				kubeCfg.ImagePullTimeout.Duration,
+				registryPolicy,
 				klet.cpuCFSQuota,
 				dockerService,
 				dockerService,
@@ -558,6 +569,7 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 				klet.cpuCFSQuota,
 				imageBackOff,
 				kubeCfg.SerializeImagePulls,
+				kubeCfg.ImagePullTimeout.Duration,
 				kubeCfg.EnableCustomMetrics,
 				// If using "kubenet", the Kubernetes network plugin that wraps
 				// CNI's bridge plugin, it knows how to set the hairpin veth flag
@@ -567,6 +579,7 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 				// runtime to set the flag instead.
 				klet.hairpinMode == componentconfig.HairpinVeth && kubeCfg.NetworkPluginName != "kubenet",
 				kubeCfg.SeccompProfileRoot,
+				registryPolicy,
 				kubeDeps.ContainerRuntimeOptions...,
 			)
 		}
@@ -594,6 +607,8 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 			kubeCfg.SerializeImagePulls,
 			float32(kubeCfg.RegistryPullQPS),
 			int(kubeCfg.RegistryBurst),
+			kubeCfg.ImagePullTimeout.Duration,
+			registryPolicy,
 			kubeCfg.RuntimeRequestTimeout.Duration,
 		)
 		if err != nil {
@@ -623,6 +638,8 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 			kubeCfg.SerializeImagePulls,
 			float32(kubeCfg.RegistryPullQPS),
 			int(kubeCfg.RegistryBurst),
+			kubeCfg.ImagePullTimeout.Duration,
+			registryPolicy,
 			klet.cpuCFSQuota,
 			remoteRuntimeService,
 			remoteImageService,
@@ -635,9 +652,15 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 	}
 
 	// TODO: Factor out "StatsProvider" from Kubelet so we don't have a cyclic dependency
-	klet.resourceAnalyzer = stats.NewResourceAnalyzer(klet, kubeCfg.VolumeStatsAggPeriod.Duration, klet.containerRuntime)
+	klet.resourceAnalyzer = stats.NewResourceAnalyzer(klet, kubeCfg.VolumeStatsAggPeriod.Duration, klet.containerRuntime, klet.getPodVolumesDir)
+
+	runtimeCache, err := kubecontainer.NewRuntimeCache(klet.containerRuntime)
+	if err != nil {
+		return nil, err
+	}
+	klet.runtimeCache = runtimeCache
 
-	klet.pleg = pleg.NewGenericPLEG(klet.containerRuntime, plegChannelCapacity, plegRelistPeriod, klet.podCache, clock.RealClock{})
+	klet.pleg = pleg.NewGenericPLEG(klet.containerRuntime, plegChannelCapacity, plegRelistPeriod, klet.podCache, klet.runtimeCache, clock.RealClock{})
 	klet.runtimeState = newRuntimeState(maxWaitForContainerRuntime)
 	klet.updatePodCIDR(kubeCfg.PodCIDR)
 
@@ -659,6 +682,13 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 	klet.runner = klet.containerRuntime
 	klet.statusManager = status.NewManager(kubeClient, klet.podManager)
 
+	// The teardown pipeline holds pod removal back until the runtime reports
+	// all of the pod's containers dead. No cleanup callback is wired here:
+	// the volume manager's populator polls the runtime itself and only
+	// removes a pod's volumes from the desired state once its containers
+	// have exited, so the pipeline's wait is what guarantees the ordering.
+	klet.podTeardown = newPodTeardownPipeline(klet.containerRuntime, podTeardownTimeout, podTeardownPollInterval, nil)
+
 	klet.probeManager = prober.NewManager(
 		klet.statusManager,
 		klet.livenessManager,
@@ -684,11 +714,6 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 		klet.getPodsDir(),
 		kubeDeps.Recorder)
 
-	runtimeCache, err := kubecontainer.NewRuntimeCache(klet.containerRuntime)
-	if err != nil {
-		return nil, err
-	}
-	klet.runtimeCache = runtimeCache
 	klet.reasonCache = NewReasonCache()
 	klet.workQueue = queue.NewBasicWorkQueue(klet.clock)
 	klet.podWorkers = newPodWorkers(klet.syncPod, kubeDeps.Recorder, klet.workQueue, klet.resyncInterval, backOffPeriod, klet.podCache)
@@ -736,6 +761,8 @@ func NewMainKubelet(kubeCfg *componentconfig.KubeletConfiguration, kubeDeps *Kub
 
 	klet.appArmorValidator = apparmor.NewValidator(kubeCfg.ContainerRuntime)
 	klet.AddPodAdmitHandler(lifecycle.NewAppArmorAdmitHandler(klet.appArmorValidator))
+	klet.AddPodAdmitHandler(lifecycle.NewHostPortAdmitHandler())
+	klet.AddPodAdmitHandler(lifecycle.NewMaxPodsAdmitHandler(klet.maxPods))
 	klet.AddPodAdmitHandler(lifecycle.NewPredicateAdmitHandler(klet.getNodeAnyWay))
 	// apply functional Option's
 	for _, opt := range kubeDeps.Options {
@@ -771,6 +798,10 @@ type Kubelet struct {
 	// podWorkers handle syncing Pods in response to events.
 	podWorkers PodWorkers
 
+	// podTeardown finalizes pod removal, ordering the runtime kill before
+	// the volume cleanup signal.
+	podTeardown *podTeardownPipeline
+
 	// resyncInterval is the interval between periodic full reconciliations of
 	// pods on this node.
 	resyncInterval time.Duration
@@ -1154,6 +1185,38 @@ func (kl *Kubelet) initializeRuntimeDependentModules() {
 	if err := kl.evictionManager.Start(kl, kl.getActivePods, evictionMonitoringPeriod); err != nil {
 		kl.runtimeState.setInternalError(fmt.Errorf("failed to start eviction manager %v", err))
 	}
+	// Validate the node prerequisites the runtime depends on, so that
+	// misconfigured nodes fail visibly at startup instead of when the first
+	// pod exercises the broken path.
+	kl.runRuntimeSelfTest()
+}
+
+// RuntimeSelfTest runs the container runtime's startup self-test, if the
+// runtime provides one, and returns one result per failed check. It is also
+// exposed on demand via the kubelet's debug endpoint.
+func (kl *Kubelet) RuntimeSelfTest() []kubecontainer.SelfTestCheckResult {
+	tester, ok := kl.containerRuntime.(kubecontainer.SelfTester)
+	if !ok {
+		return nil
+	}
+	return tester.SelfTest()
+}
+
+// runRuntimeSelfTest runs the container runtime's self-test, records a node
+// event for every failed check, and marks the runtime unhealthy (making the
+// node not ready) if any critical check failed.
+func (kl *Kubelet) runRuntimeSelfTest() {
+	var criticalFailures []string
+	for _, result := range kl.RuntimeSelfTest() {
+		glog.Errorf("Container runtime self-test check %q failed: %v", result.Name, result.Error)
+		kl.recorder.Eventf(kl.nodeRef, api.EventTypeWarning, events.RuntimeSelfTestFailed, "Container runtime self-test check %q failed: %v", result.Name, result.Error)
+		if result.Critical {
+			criticalFailures = append(criticalFailures, fmt.Sprintf("%s: %v", result.Name, result.Error))
+		}
+	}
+	if len(criticalFailures) > 0 {
+		kl.runtimeState.setInternalError(fmt.Errorf("container runtime self-test failed: %s", strings.Join(criticalFailures, "; ")))
+	}
 }
 
 // Run starts the kubelet reacting to config updates
@@ -1196,6 +1259,12 @@ func (kl *Kubelet) Run(updates <-chan kubetypes.PodUpdate) {
 	// Start the pod lifecycle event generator.
 	kl.pleg.Start()
 	kl.syncLoop(updates, kl)
+
+	// The sync loop only returns when the pod config channel is closed on
+	// shutdown. Let in-flight pod syncs finish before handing control back.
+	if err := kl.podWorkers.Drain(podWorkersDrainTimeout); err != nil {
+		glog.Warningf("Failed to drain pod workers on shutdown: %v", err)
+	}
 }
 
 // GetClusterDNS returns a list of the DNS servers and a list of the DNS search
@@ -1296,9 +1365,12 @@ func (kl *Kubelet) syncPod(o syncPodOptions) error {
 		}
 		apiPodStatus := killPodOptions.PodStatusFunc(pod, podStatus)
 		kl.statusManager.SetPodStatus(pod, apiPodStatus)
-		// we kill the pod with the specified grace period since this is a termination
-		if err := kl.killPod(pod, nil, podStatus, killPodOptions.PodTerminationGracePeriodSecondsOverride); err != nil {
-			// there was an error killing the pod, so we return that error directly
+		// we tear the pod down with the specified grace period since this is a
+		// termination; the pipeline kills the pod and defers volume cleanup
+		// until the runtime reports all of its containers dead
+		runningPod := kubecontainer.ConvertPodStatusToRunningPod(kl.GetRuntime().Type(), podStatus)
+		if err := kl.podTeardown.teardownPod(pod, runningPod, killPodOptions.PodTerminationGracePeriodSecondsOverride); err != nil {
+			// there was an error tearing down the pod, so we return that error directly
 			utilruntime.HandleError(err)
 			return err
 		}
@@ -1857,10 +1929,25 @@ func (kl *Kubelet) PLEGHealthCheck() (bool, error) {
 // and returns an error if the status check fails.  If the status check is OK,
 // update the container runtime uptime in the kubelet runtimeState.
 func (kl *Kubelet) updateRuntimeUp() {
-	if err := kl.containerRuntime.Status(); err != nil {
+	s, err := kl.containerRuntime.Status()
+	if err != nil {
 		glog.Errorf("Container runtime sanity check failed: %v", err)
 		return
 	}
+	glog.V(4).Infof("Container runtime status: %v", s)
+	// A degraded network condition blocks the scheduling of non-hostNetwork
+	// pods via the runtime state; the network sync loop clears it once the
+	// network plugin reports healthy again.
+	networkReady := s.GetRuntimeCondition(kubecontainer.NetworkReady)
+	if networkReady == nil || !networkReady.Status {
+		glog.Errorf("Container runtime network not ready: %v", networkReady)
+		kl.runtimeState.setNetworkState(fmt.Errorf("runtime network not ready: %v", networkReady))
+	}
+	runtimeReady := s.GetRuntimeCondition(kubecontainer.RuntimeReady)
+	if runtimeReady == nil || !runtimeReady.Status {
+		glog.Errorf("Container runtime not ready: %v", runtimeReady)
+		return
+	}
 	kl.oneTimeInitializer.Do(kl.initializeRuntimeDependentModules)
 	kl.runtimeState.setRuntimeSync(kl.clock.Now())
 }
@@ -18,9 +18,11 @@ package kubelet
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -36,6 +38,7 @@ import (
 	"k8s.io/kubernetes/pkg/fieldpath"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/kubelet/envvars"
+	"k8s.io/kubernetes/pkg/kubelet/events"
 	"k8s.io/kubernetes/pkg/kubelet/images"
 	"k8s.io/kubernetes/pkg/kubelet/status"
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
@@ -116,7 +119,11 @@ func makeMounts(pod *api.Pod, podDir string, container *api.Container, hostName,
 		})
 	}
 	if mountEtcHostsFile {
-		hostsMount, err := makeHostsMount(podDir, podIP, hostName, hostDomain)
+		hostAliases, err := parseHostAliases(pod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %q annotation of pod %q: %v", HostAliasesAnnotationKey, format.Pod(pod), err)
+		}
+		hostsMount, err := makeHostsMount(podDir, podIP, hostName, hostDomain, hostAliases)
 		if err != nil {
 			return nil, err
 		}
@@ -125,28 +132,59 @@ func makeMounts(pod *api.Pod, podDir string, container *api.Container, hostName,
 	return mounts, nil
 }
 
+// HostAliasesAnnotationKey is the annotation on a pod that carries extra
+// entries for the kubernetes-managed hosts file, as a JSON list of
+// {"ip": ..., "hostnames": [...]} objects.
+const HostAliasesAnnotationKey = "kubernetes.io/host-aliases"
+
+// hostAlias is a single entry of the HostAliasesAnnotationKey annotation.
+type hostAlias struct {
+	IP        string   `json:"ip"`
+	Hostnames []string `json:"hostnames"`
+}
+
+// parseHostAliases returns the extra hosts file entries requested by the
+// pod's host aliases annotation, or nil if the annotation is not set.
+func parseHostAliases(pod *api.Pod) ([]hostAlias, error) {
+	value, ok := pod.Annotations[HostAliasesAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	var hostAliases []hostAlias
+	if err := json.Unmarshal([]byte(value), &hostAliases); err != nil {
+		return nil, err
+	}
+	for _, alias := range hostAliases {
+		if net.ParseIP(alias.IP) == nil {
+			return nil, fmt.Errorf("invalid IP address %q", alias.IP)
+		}
+		if len(alias.Hostnames) == 0 {
+			return nil, fmt.Errorf("no hostnames given for IP address %q", alias.IP)
+		}
+	}
+	return hostAliases, nil
+}
+
 // makeHostsMount makes the mountpoint for the hosts file that the containers
-// in a pod are injected with.
-func makeHostsMount(podDir, podIP, hostName, hostDomainName string) (*kubecontainer.Mount, error) {
+// in a pod are injected with. The file is mounted read-only so containers
+// cannot diverge from the kubelet-managed content.
+func makeHostsMount(podDir, podIP, hostName, hostDomainName string, hostAliases []hostAlias) (*kubecontainer.Mount, error) {
 	hostsFilePath := path.Join(podDir, "etc-hosts")
-	if err := ensureHostsFile(hostsFilePath, podIP, hostName, hostDomainName); err != nil {
+	if err := ensureHostsFile(hostsFilePath, podIP, hostName, hostDomainName, hostAliases); err != nil {
 		return nil, err
 	}
 	return &kubecontainer.Mount{
 		Name:          "k8s-managed-etc-hosts",
 		ContainerPath: etcHostsPath,
 		HostPath:      hostsFilePath,
-		ReadOnly:      false,
+		ReadOnly:      true,
 	}, nil
 }
 
 // ensureHostsFile ensures that the given host file has an up-to-date ip, host
-// name, and domain name.
-func ensureHostsFile(fileName, hostIP, hostName, hostDomainName string) error {
-	if _, err := os.Stat(fileName); os.IsExist(err) {
-		glog.V(4).Infof("kubernetes-managed etc-hosts file exits. Will not be recreated: %q", fileName)
-		return nil
-	}
+// name, and domain name. The file is only rewritten when its content would
+// change, e.g. when the pod IP changes across infra container restarts.
+func ensureHostsFile(fileName, hostIP, hostName, hostDomainName string, hostAliases []hostAlias) error {
 	var buffer bytes.Buffer
 	buffer.WriteString("# Kubernetes-managed hosts file.\n")
 	buffer.WriteString("127.0.0.1\tlocalhost\n")                      // ipv4 localhost
@@ -160,6 +198,13 @@ func ensureHostsFile(fileName, hostIP, hostName, hostDomainName string) error {
 	} else {
 		buffer.WriteString(fmt.Sprintf("%s\t%s\n", hostIP, hostName))
 	}
+	for _, alias := range hostAliases {
+		buffer.WriteString(fmt.Sprintf("%s\t%s\n", alias.IP, strings.Join(alias.Hostnames, "\t")))
+	}
+	if current, err := ioutil.ReadFile(fileName); err == nil && bytes.Equal(current, buffer.Bytes()) {
+		glog.V(4).Infof("kubernetes-managed etc-hosts file is up to date, will not be recreated: %q", fileName)
+		return nil
+	}
 	return ioutil.WriteFile(fileName, buffer.Bytes(), 0644)
 }
 
@@ -609,6 +654,15 @@ func (kl *Kubelet) HandlePodCleanups() error {
 	kl.podWorkers.ForgetNonExistingPodWorkers(desiredPods)
 	kl.probeManager.CleanupPods(activePods)
 
+	// Static pods derive their UID from a hash of their spec, so a config
+	// change surfaces as a brand new pod with the same name and namespace.
+	// Track the desired full names so running containers belonging to a
+	// superseded UID can be reported before they are killed below.
+	desiredPodFullNames := make(map[string]*api.Pod)
+	for _, pod := range activePods {
+		desiredPodFullNames[kubecontainer.GetPodFullName(pod)] = pod
+	}
+
 	runningPods, err := kl.runtimeCache.GetPods()
 	if err != nil {
 		glog.Errorf("Error listing containers: %#v", err)
@@ -616,6 +670,13 @@ func (kl *Kubelet) HandlePodCleanups() error {
 	}
 	for _, pod := range runningPods {
 		if _, found := desiredPods[pod.ID]; !found {
+			// Only an exact name and namespace match with a different UID is
+			// treated as a superseded pod; anything else is an ordinary
+			// unwanted pod.
+			if desiredPod, ok := desiredPodFullNames[kubecontainer.BuildPodFullName(pod.Name, pod.Namespace)]; ok && desiredPod.UID != pod.ID {
+				glog.V(2).Infof("Killing containers of superseded pod %q (old UID %q, new UID %q)", format.Pod(desiredPod), pod.ID, desiredPod.UID)
+				kl.recorder.Eventf(desiredPod, api.EventTypeNormal, events.SupersededPod, "Killing leftover containers of superseded pod with old UID %v", pod.ID)
+			}
 			kl.podKillingCh <- &kubecontainer.PodPair{APIPod: nil, RunningPod: pod}
 		}
 	}
@@ -21,7 +21,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
+	"os"
+	"path"
 	"sort"
 	"testing"
 
@@ -111,6 +114,58 @@ func TestMakeMounts(t *testing.T) {
 	assert.Equal(t, expectedMounts, mounts, "mounts of container %+v", container)
 }
 
+func TestMakeHostsMountWithHostAliases(t *testing.T) {
+	podDir, err := ioutil.TempDir("", "kubelet-hosts-mount")
+	require.NoError(t, err)
+	defer os.RemoveAll(podDir)
+
+	pod := api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Annotations: map[string]string{
+				HostAliasesAnnotationKey: `[{"ip": "10.1.2.3", "hostnames": ["legacy-db", "legacy-db.corp"]}, {"ip": "10.1.2.4", "hostnames": ["legacy-cache"]}]`,
+			},
+		},
+	}
+	container := api.Container{}
+
+	mounts, err := makeMounts(&pod, podDir, &container, "podname", "", "4.3.2.1", kubecontainer.VolumeMap{})
+	require.NoError(t, err)
+	require.Len(t, mounts, 1)
+	mount := mounts[0]
+	assert.Equal(t, "k8s-managed-etc-hosts", mount.Name)
+	assert.Equal(t, "/etc/hosts", mount.ContainerPath)
+	assert.Equal(t, path.Join(podDir, "etc-hosts"), mount.HostPath)
+	assert.True(t, mount.ReadOnly, "managed hosts file should be mounted read-only")
+
+	content, err := ioutil.ReadFile(mount.HostPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "127.0.0.1\tlocalhost\n")
+	assert.Contains(t, string(content), "4.3.2.1\tpodname\n")
+	assert.Contains(t, string(content), "10.1.2.3\tlegacy-db\tlegacy-db.corp\n")
+	assert.Contains(t, string(content), "10.1.2.4\tlegacy-cache\n")
+
+	// A new pod IP across an infra container restart regenerates the file.
+	_, err = makeMounts(&pod, podDir, &container, "podname", "", "4.3.2.2", kubecontainer.VolumeMap{})
+	require.NoError(t, err)
+	content, err = ioutil.ReadFile(mount.HostPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "4.3.2.2\tpodname\n")
+	assert.NotContains(t, string(content), "4.3.2.1\tpodname\n")
+
+	// A host networking pod must not get the managed hosts file.
+	pod.Spec.SecurityContext = &api.PodSecurityContext{HostNetwork: true}
+	mounts, err = makeMounts(&pod, podDir, &container, "podname", "", "4.3.2.2", kubecontainer.VolumeMap{})
+	require.NoError(t, err)
+	assert.Len(t, mounts, 0)
+	pod.Spec.SecurityContext = nil
+
+	// A malformed annotation is surfaced as an error.
+	pod.Annotations[HostAliasesAnnotationKey] = `[{"ip": "not-an-ip", "hostnames": ["x"]}]`
+	if _, err := makeMounts(&pod, podDir, &container, "podname", "", "4.3.2.2", kubecontainer.VolumeMap{}); err == nil {
+		t.Errorf("expected an error for a malformed host aliases annotation")
+	}
+}
+
 type fakeContainerCommandRunner struct {
 	// what was passed in
 	Cmd    []string
@@ -173,6 +173,7 @@ func newTestKubeletWithImageList(
 		cache:     kubelet.podCache,
 		t:         t,
 	}
+	kubelet.podTeardown = newPodTeardownPipeline(fakeRuntime, podTeardownTimeout, podTeardownPollInterval, nil)
 
 	kubelet.probeManager = probetest.FakeManager{}
 	kubelet.livenessManager = proberesults.NewManager()
@@ -202,13 +203,13 @@ func newTestKubeletWithImageList(
 	}
 	kubelet.workQueue = queue.NewBasicWorkQueue(fakeClock)
 	// Relist period does not affect the tests.
-	kubelet.pleg = pleg.NewGenericPLEG(fakeRuntime, 100, time.Hour, nil, clock.RealClock{})
+	kubelet.pleg = pleg.NewGenericPLEG(fakeRuntime, 100, time.Hour, nil, kubelet.runtimeCache, clock.RealClock{})
 	kubelet.clock = fakeClock
 	kubelet.setNodeStatusFuncs = kubelet.defaultNodeStatusFuncs()
 
 	// TODO: Factor out "StatsProvider" from Kubelet so we don't have a cyclic dependency
 	volumeStatsAggPeriod := time.Second * 10
-	kubelet.resourceAnalyzer = stats.NewResourceAnalyzer(kubelet, volumeStatsAggPeriod, kubelet.containerRuntime)
+	kubelet.resourceAnalyzer = stats.NewResourceAnalyzer(kubelet, volumeStatsAggPeriod, kubelet.containerRuntime, kubelet.getPodVolumesDir)
 	nodeRef := &api.ObjectReference{
 		Kind:      "Node",
 		Name:      string(kubelet.nodeName),
@@ -369,6 +370,48 @@ func TestSyncPodsDeletesWhenSourcesAreReady(t *testing.T) {
 	fakeRuntime.AssertKilledPods([]string{"12345678"})
 }
 
+func TestHandlePodCleanupsKillsSupersededStaticPodContainers(t *testing.T) {
+	testKubelet := newTestKubelet(t, false /* controllerAttachDetachEnabled */)
+	testKubelet.fakeCadvisor.On("MachineInfo").Return(&cadvisorapi.MachineInfo{}, nil)
+	testKubelet.fakeCadvisor.On("ImagesFsInfo").Return(cadvisorapiv2.FsInfo{}, nil)
+	testKubelet.fakeCadvisor.On("RootFsInfo").Return(cadvisorapiv2.FsInfo{}, nil)
+	kubelet := testKubelet.kubelet
+	fakeRuntime := testKubelet.fakeRuntime
+
+	// A static pod's UID is derived from a hash of its config, so an edit
+	// recreates the pod with the same name and namespace but a new UID,
+	// leaving containers of the old UID running.
+	pods := []*api.Pod{
+		podWithUidNameNsSpec("new-uid", "foo", "new", api.PodSpec{
+			Containers: []api.Container{
+				{Name: "bar"},
+			},
+		}),
+	}
+	kubelet.podManager.SetPods(pods)
+	fakeRuntime.PodList = []*containertest.FakePod{
+		{Pod: &kubecontainer.Pod{
+			ID:        "old-uid",
+			Name:      "foo",
+			Namespace: "new",
+			Containers: []*kubecontainer.Container{
+				{Name: "bar"},
+			},
+		}},
+	}
+
+	kubelet.HandlePodCleanups()
+
+	select {
+	case podPair := <-kubelet.podKillingCh:
+		if podPair.RunningPod.ID != "old-uid" {
+			t.Errorf("expected containers of the superseded UID %q to be killed, got %q", "old-uid", podPair.RunningPod.ID)
+		}
+	default:
+		t.Fatalf("expected the superseded pod containers to be enqueued for killing")
+	}
+}
+
 type testNodeLister struct {
 	nodes []api.Node
 }
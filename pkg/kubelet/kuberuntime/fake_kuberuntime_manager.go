@@ -121,6 +121,8 @@ func NewFakeKubeRuntimeManager(runtimeService internalApi.RuntimeService, imageS
 		false,
 		0, // Disable image pull throttling by setting QPS to 0,
 		0,
+		0,
+		nil,
 	)
 	kubeRuntimeManager.runner = lifecycle.NewHandlerRunner(
 		&fakeHTTP{},
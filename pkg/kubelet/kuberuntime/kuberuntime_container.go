@@ -602,3 +602,15 @@ func (m *kubeGenericRuntimeManager) ExecInContainer(containerID kubecontainer.Co
 func (m *kubeGenericRuntimeManager) DeleteContainer(containerID kubecontainer.ContainerID) error {
 	return m.runtimeService.RemoveContainer(containerID.ID)
 }
+
+// GetContainerStats returns the resource usage of the container.
+// TODO: implement this when the runtime API supports stats collection.
+func (m *kubeGenericRuntimeManager) GetContainerStats(containerID kubecontainer.ContainerID) (*kubecontainer.ContainerStats, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// UpdateContainerResources updates the resource limits of a running container.
+// TODO: implement this when the runtime API supports container updates.
+func (m *kubeGenericRuntimeManager) UpdateContainerResources(containerID kubecontainer.ContainerID, resources *api.ResourceRequirements) error {
+	return fmt.Errorf("not implemented")
+}
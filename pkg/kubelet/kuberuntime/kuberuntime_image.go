@@ -35,9 +35,9 @@ func (m *kubeGenericRuntimeManager) PullImage(image kubecontainer.ImageSpec, pul
 		return err
 	}
 
-	keyring, err := credentialprovider.MakeDockerKeyring(pullSecrets, m.keyring)
-	if err != nil {
-		return err
+	keyring, secretErrors := credentialprovider.MakeDockerKeyring(pullSecrets, m.keyring)
+	for _, secretError := range secretErrors {
+		glog.Warningf("Unable to use image pull secret: %v", secretError)
 	}
 
 	imgSpec := &runtimeApi.ImageSpec{Image: &img}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/golang/glog"
@@ -116,6 +117,8 @@ func NewKubeGenericRuntimeManager(
 	serializeImagePulls bool,
 	imagePullQPS float32,
 	imagePullBurst int,
+	imagePullTimeout time.Duration,
+	registryPolicy *images.RegistryPolicy,
 	cpuCFSQuota bool,
 	runtimeService internalApi.RuntimeService,
 	imageService internalApi.ImageManagerService,
@@ -170,7 +173,9 @@ func NewKubeGenericRuntimeManager(
 		imageBackOff,
 		serializeImagePulls,
 		imagePullQPS,
-		imagePullBurst)
+		imagePullBurst,
+		imagePullTimeout,
+		registryPolicy)
 	kubeRuntimeManager.runner = lifecycle.NewHandlerRunner(httpClient, kubeRuntimeManager, kubeRuntimeManager)
 	kubeRuntimeManager.containerGC = NewContainerGC(runtimeService, podGetter, kubeRuntimeManager)
 
@@ -236,14 +241,21 @@ func (m *kubeGenericRuntimeManager) APIVersion() (kubecontainer.Version, error)
 }
 
 // Status returns error if the runtime is unhealthy; nil otherwise.
-func (m *kubeGenericRuntimeManager) Status() error {
+func (m *kubeGenericRuntimeManager) Status() (*kubecontainer.RuntimeStatus, error) {
 	_, err := m.runtimeService.Version(kubeRuntimeAPIVersion)
 	if err != nil {
 		glog.Errorf("Checkout remote runtime status failed: %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	// TODO: Fill in the runtime and network conditions from the runtime API
+	// once it reports them.
+	return &kubecontainer.RuntimeStatus{
+		Conditions: []kubecontainer.RuntimeCondition{
+			{Type: kubecontainer.RuntimeReady, Status: true},
+			{Type: kubecontainer.NetworkReady, Status: true},
+		},
+	}, nil
 }
 
 // GetPods returns a list of containers grouped by pods. The boolean parameter
@@ -23,6 +23,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/api"
@@ -35,12 +36,46 @@ import (
 	"k8s.io/kubernetes/pkg/util/intstr"
 )
 
+const (
+	// defaultHTTPHookTimeout bounds how long an HTTP lifecycle hook may run.
+	defaultHTTPHookTimeout = 10 * time.Second
+	// hookDialTimeout bounds connection establishment for hook requests.
+	hookDialTimeout = 5 * time.Second
+)
+
 type HandlerRunner struct {
 	httpGetter       kubetypes.HttpGetter
 	commandRunner    kubecontainer.ContainerCommandRunner
 	containerManager podStatusProvider
 }
 
+// NewHookHTTPClient returns the HTTP client used to deliver lifecycle hooks.
+// Unlike a bare http.Client it enforces connection and per-request timeouts,
+// so a hung endpoint cannot block container startup or teardown forever.
+func NewHookHTTPClient() kubetypes.HttpGetterWithTimeout {
+	return &hookHTTPClient{
+		transport: &http.Transport{
+			Dial: (&net.Dialer{Timeout: hookDialTimeout}).Dial,
+			ResponseHeaderTimeout: defaultHTTPHookTimeout,
+		},
+	}
+}
+
+type hookHTTPClient struct {
+	transport http.RoundTripper
+}
+
+func (c *hookHTTPClient) Get(url string) (*http.Response, error) {
+	return c.GetWithTimeout(url, defaultHTTPHookTimeout)
+}
+
+func (c *hookHTTPClient) GetWithTimeout(url string, timeout time.Duration) (*http.Response, error) {
+	// http.Client is a small struct, so building one per request to carry the
+	// timeout is cheap; the transport and its connection pool are shared.
+	client := &http.Client{Transport: c.transport, Timeout: timeout}
+	return client.Get(url)
+}
+
 type podStatusProvider interface {
 	GetPodStatus(uid types.UID, name, namespace string) (*kubecontainer.PodStatus, error)
 }
@@ -129,8 +164,46 @@ func (hr *HandlerRunner) runHTTPHandler(pod *api.Pod, container *api.Container,
 		}
 	}
 	url := fmt.Sprintf("http://%s/%s", net.JoinHostPort(host, strconv.Itoa(port)), handler.HTTPGet.Path)
-	resp, err := hr.httpGetter.Get(url)
-	return getHttpRespBody(resp), err
+	resp, err := hr.getWithTimeout(url, hr.httpHookTimeout(pod, container, handler))
+	body := getHttpRespBody(resp)
+	if err != nil {
+		return body, err
+	}
+	if resp != nil && (resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices) {
+		return body, fmt.Errorf("lifecycle hook (%s) returned unexpected status code %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// getWithTimeout issues the hook request with the given timeout when the
+// underlying getter supports one.
+func (hr *HandlerRunner) getWithTimeout(url string, timeout time.Duration) (*http.Response, error) {
+	if getter, ok := hr.httpGetter.(kubetypes.HttpGetterWithTimeout); ok {
+		return getter.GetWithTimeout(url, timeout)
+	}
+	return hr.httpGetter.Get(url)
+}
+
+// httpHookTimeout returns how long the HTTP hook may run. A PreStop hook
+// competes with the pod's termination grace period, so it must not consume
+// more than the grace that remains.
+func (hr *HandlerRunner) httpHookTimeout(pod *api.Pod, container *api.Container, handler *api.Handler) time.Duration {
+	timeout := defaultHTTPHookTimeout
+	if container.Lifecycle != nil && container.Lifecycle.PreStop == handler {
+		gracePeriod := int64(0)
+		switch {
+		case pod.DeletionGracePeriodSeconds != nil:
+			gracePeriod = *pod.DeletionGracePeriodSeconds
+		case pod.Spec.TerminationGracePeriodSeconds != nil:
+			gracePeriod = *pod.Spec.TerminationGracePeriodSeconds
+		}
+		if gracePeriod > 0 {
+			if graceTimeout := time.Duration(gracePeriod) * time.Second; graceTimeout < timeout {
+				timeout = graceTimeout
+			}
+		}
+	}
+	return timeout
 }
 
 func getHttpRespBody(resp *http.Response) string {
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
@@ -124,9 +125,10 @@ func TestRunHandlerExec(t *testing.T) {
 }
 
 type fakeHTTP struct {
-	url  string
-	err  error
-	resp *http.Response
+	url     string
+	timeout time.Duration
+	err     error
+	resp    *http.Response
 }
 
 func (f *fakeHTTP) Get(url string) (*http.Response, error) {
@@ -134,6 +136,11 @@ func (f *fakeHTTP) Get(url string) (*http.Response, error) {
 	return f.resp, f.err
 }
 
+func (f *fakeHTTP) GetWithTimeout(url string, timeout time.Duration) (*http.Response, error) {
+	f.timeout = timeout
+	return f.Get(url)
+}
+
 func TestRunHandlerHttp(t *testing.T) {
 	fakeHttp := fakeHTTP{}
 	handlerRunner := NewHandlerRunner(&fakeHttp, &fakeContainerCommandRunner{}, nil)
@@ -226,3 +233,82 @@ func TestRunHandlerHttpFailure(t *testing.T) {
 		t.Errorf("unexpected url: %s", fakeHttp.url)
 	}
 }
+
+func TestRunHandlerHttpNon2xxStatus(t *testing.T) {
+	fakeHttp := fakeHTTP{resp: &http.Response{
+		StatusCode: 404,
+		Body:       ioutil.NopCloser(strings.NewReader("not found")),
+	}}
+	handlerRunner := NewHandlerRunner(&fakeHttp, &fakeContainerCommandRunner{}, nil)
+	containerID := kubecontainer.ContainerID{Type: "test", ID: "abc1234"}
+	container := api.Container{
+		Name: "containerFoo",
+		Lifecycle: &api.Lifecycle{
+			PostStart: &api.Handler{
+				HTTPGet: &api.HTTPGetAction{
+					Host: "foo",
+					Port: intstr.FromInt(8080),
+					Path: "bar",
+				},
+			},
+		},
+	}
+	pod := api.Pod{}
+	pod.ObjectMeta.Name = "podFoo"
+	pod.ObjectMeta.Namespace = "nsFoo"
+	pod.Spec.Containers = []api.Container{container}
+	msg, err := handlerRunner.Run(containerID, &pod, &container, container.Lifecycle.PostStart)
+	if err == nil {
+		t.Fatal("expected an error for a non-2xx response")
+	}
+	if !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected the status code in the error, got: %v", err)
+	}
+	if msg != "not found" {
+		t.Errorf("unexpected response body: %q", msg)
+	}
+}
+
+func TestRunHandlerHttpTimeouts(t *testing.T) {
+	gracePeriod := int64(2)
+	handler := &api.Handler{
+		HTTPGet: &api.HTTPGetAction{
+			Host: "foo",
+			Port: intstr.FromInt(8080),
+			Path: "bar",
+		},
+	}
+	container := api.Container{
+		Name: "containerFoo",
+		Lifecycle: &api.Lifecycle{
+			PostStart: handler,
+			PreStop:   handler,
+		},
+	}
+	containerID := kubecontainer.ContainerID{Type: "test", ID: "abc1234"}
+
+	// A PostStart hook uses the default timeout.
+	fakeHttp := fakeHTTP{}
+	handlerRunner := NewHandlerRunner(&fakeHttp, &fakeContainerCommandRunner{}, nil)
+	pod := api.Pod{}
+	pod.ObjectMeta.Name = "podFoo"
+	pod.ObjectMeta.Namespace = "nsFoo"
+	pod.Spec.Containers = []api.Container{container}
+	if _, err := handlerRunner.Run(containerID, &pod, &container, container.Lifecycle.PostStart); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if fakeHttp.timeout != defaultHTTPHookTimeout {
+		t.Errorf("expected the default timeout %v, got %v", defaultHTTPHookTimeout, fakeHttp.timeout)
+	}
+
+	// A PreStop hook must not outlive the pod's remaining grace period.
+	container.Lifecycle.PostStart = nil
+	pod.Spec.Containers = []api.Container{container}
+	pod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	if _, err := handlerRunner.Run(containerID, &pod, &container, container.Lifecycle.PreStop); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if expected := time.Duration(gracePeriod) * time.Second; fakeHttp.timeout != expected {
+		t.Errorf("expected the grace period timeout %v, got %v", expected, fakeHttp.timeout)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/kubelet/util/format"
+)
+
+// HostPortConflictReason is the reason reported when a pod cannot be admitted
+// because one of its host ports is already taken by another pod on the node.
+const HostPortConflictReason = "HostPortConflict"
+
+type hostPortAdmitHandler struct{}
+
+var _ PodAdmitHandler = &hostPortAdmitHandler{}
+
+// NewHostPortAdmitHandler returns a PodAdmitHandler which rejects pods whose
+// host ports conflict with the ports already taken by other pods on the node.
+// Rejecting the pod here avoids creating containers that can never bind their
+// ports and would otherwise churn in the start backoff loop.
+func NewHostPortAdmitHandler() PodAdmitHandler {
+	return &hostPortAdmitHandler{}
+}
+
+// podHostPorts returns the ports the pod binds on the host. For pods running
+// in the host network namespace every container port is bound on the host,
+// whether or not a HostPort is declared.
+func podHostPorts(pod *api.Pod) []int {
+	var ports []int
+	hostNetwork := pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.HostNetwork
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			switch {
+			case port.HostPort > 0:
+				ports = append(ports, int(port.HostPort))
+			case hostNetwork && port.ContainerPort > 0:
+				ports = append(ports, int(port.ContainerPort))
+			}
+		}
+	}
+	return ports
+}
+
+func (w *hostPortAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	// Map of host port to the name of the pod that has taken it.
+	usedPorts := make(map[int]string)
+	for _, other := range attrs.OtherPods {
+		for _, port := range podHostPorts(other) {
+			usedPorts[port] = format.Pod(other)
+		}
+	}
+
+	for _, port := range podHostPorts(attrs.Pod) {
+		if otherPod, taken := usedPorts[port]; taken {
+			message := fmt.Sprintf("host port %d is already in use by pod %s", port, otherPod)
+			glog.V(2).Infof("Failed to admit pod %v - %s", format.Pod(attrs.Pod), message)
+			return PodAdmitResult{
+				Admit:   false,
+				Reason:  HostPortConflictReason,
+				Message: message,
+			}
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
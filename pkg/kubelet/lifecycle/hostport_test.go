@@ -0,0 +1,92 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func makeHostPortPod(name string, hostNetwork bool, ports ...api.ContainerPort) *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Spec: api.PodSpec{
+			SecurityContext: &api.PodSecurityContext{
+				HostNetwork: hostNetwork,
+			},
+			Containers: []api.Container{
+				{Name: "foo", Ports: ports},
+			},
+		},
+	}
+}
+
+func TestHostPortAdmitHandler(t *testing.T) {
+	handler := NewHostPortAdmitHandler()
+
+	tests := []struct {
+		name        string
+		pod         *api.Pod
+		otherPods   []*api.Pod
+		expectAdmit bool
+	}{
+		{
+			name:        "no ports",
+			pod:         makeHostPortPod("pod-a", false),
+			otherPods:   []*api.Pod{makeHostPortPod("pod-b", false, api.ContainerPort{HostPort: 8080})},
+			expectAdmit: true,
+		},
+		{
+			name:        "no conflict",
+			pod:         makeHostPortPod("pod-a", false, api.ContainerPort{HostPort: 8081}),
+			otherPods:   []*api.Pod{makeHostPortPod("pod-b", false, api.ContainerPort{HostPort: 8080})},
+			expectAdmit: true,
+		},
+		{
+			name:        "two pods fighting over the same host port",
+			pod:         makeHostPortPod("pod-a", false, api.ContainerPort{HostPort: 8080}),
+			otherPods:   []*api.Pod{makeHostPortPod("pod-b", false, api.ContainerPort{HostPort: 8080})},
+			expectAdmit: false,
+		},
+		{
+			name:        "host network pods fighting over the same container port",
+			pod:         makeHostPortPod("pod-a", true, api.ContainerPort{ContainerPort: 8080}),
+			otherPods:   []*api.Pod{makeHostPortPod("pod-b", true, api.ContainerPort{ContainerPort: 8080})},
+			expectAdmit: false,
+		},
+		{
+			name:        "container port without host port does not conflict",
+			pod:         makeHostPortPod("pod-a", false, api.ContainerPort{ContainerPort: 8080}),
+			otherPods:   []*api.Pod{makeHostPortPod("pod-b", false, api.ContainerPort{HostPort: 8080})},
+			expectAdmit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		result := handler.Admit(&PodAdmitAttributes{Pod: tt.pod, OtherPods: tt.otherPods})
+		if result.Admit != tt.expectAdmit {
+			t.Errorf("%s: expected admit %v, got %v (message: %q)", tt.name, tt.expectAdmit, result.Admit, result.Message)
+		}
+		if !result.Admit && result.Reason != HostPortConflictReason {
+			t.Errorf("%s: expected reason %q, got %q", tt.name, HostPortConflictReason, result.Reason)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/kubelet/util/format"
+)
+
+// OutOfPodsReason is the reason reported when a pod cannot be admitted because
+// the node already runs the maximum number of pods it is configured for.
+const OutOfPodsReason = "OutOfPods"
+
+type maxPodsAdmitHandler struct {
+	maxPods int
+}
+
+var _ PodAdmitHandler = &maxPodsAdmitHandler{}
+
+// NewMaxPodsAdmitHandler returns a PodAdmitHandler which rejects pods once the
+// node already hosts maxPods non-terminated pods. Rejecting at admission keeps
+// a misbehaving scheduler from drowning the kubelet in pods it can never sync.
+// A non-positive maxPods admits everything.
+func NewMaxPodsAdmitHandler(maxPods int) PodAdmitHandler {
+	return &maxPodsAdmitHandler{maxPods: maxPods}
+}
+
+func (m *maxPodsAdmitHandler) Admit(attrs *PodAdmitAttributes) PodAdmitResult {
+	if m.maxPods <= 0 {
+		return PodAdmitResult{Admit: true}
+	}
+
+	// Only pods that still occupy a slot on the node count toward the limit;
+	// terminated pods are done with their resources even if they have not been
+	// deleted yet.
+	count := 0
+	for _, other := range attrs.OtherPods {
+		if other.Status.Phase != api.PodSucceeded && other.Status.Phase != api.PodFailed {
+			count++
+		}
+	}
+
+	if count >= m.maxPods {
+		message := fmt.Sprintf("node already has %d out of a maximum of %d pods", count, m.maxPods)
+		glog.V(2).Infof("Failed to admit pod %v - %s", format.Pod(attrs.Pod), message)
+		return PodAdmitResult{
+			Admit:   false,
+			Reason:  OutOfPodsReason,
+			Message: message,
+		}
+	}
+	return PodAdmitResult{Admit: true}
+}
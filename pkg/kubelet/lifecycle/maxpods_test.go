@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+)
+
+func makeCountedPod(name string, phase api.PodPhase) *api.Pod {
+	return &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Status: api.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func TestMaxPodsAdmitHandler(t *testing.T) {
+	const maxPods = 3
+	handler := NewMaxPodsAdmitHandler(maxPods)
+
+	var otherPods []*api.Pod
+	// Admit pods up to the limit.
+	for i := 0; i < maxPods; i++ {
+		pod := makeCountedPod(fmt.Sprintf("pod-%d", i), api.PodRunning)
+		result := handler.Admit(&PodAdmitAttributes{Pod: pod, OtherPods: otherPods})
+		if !result.Admit {
+			t.Fatalf("expected pod %d to be admitted, got rejection: %q", i, result.Message)
+		}
+		otherPods = append(otherPods, pod)
+	}
+
+	// The next pod is over the limit.
+	rejected := makeCountedPod("one-too-many", api.PodPending)
+	result := handler.Admit(&PodAdmitAttributes{Pod: rejected, OtherPods: otherPods})
+	if result.Admit {
+		t.Fatalf("expected pod over the limit to be rejected")
+	}
+	if result.Reason != OutOfPodsReason {
+		t.Errorf("expected reason %q, got %q", OutOfPodsReason, result.Reason)
+	}
+
+	// A terminated pod frees up its slot.
+	otherPods[0].Status.Phase = api.PodSucceeded
+	result = handler.Admit(&PodAdmitAttributes{Pod: rejected, OtherPods: otherPods})
+	if !result.Admit {
+		t.Errorf("expected pod to be admitted after a slot freed up, got rejection: %q", result.Message)
+	}
+}
+
+func TestMaxPodsAdmitHandlerUnlimited(t *testing.T) {
+	handler := NewMaxPodsAdmitHandler(0)
+	otherPods := []*api.Pod{
+		makeCountedPod("pod-0", api.PodRunning),
+		makeCountedPod("pod-1", api.PodRunning),
+	}
+	result := handler.Admit(&PodAdmitAttributes{Pod: makeCountedPod("pod-2", api.PodPending), OtherPods: otherPods})
+	if !result.Admit {
+		t.Errorf("expected a non-positive limit to admit everything, got rejection: %q", result.Message)
+	}
+}
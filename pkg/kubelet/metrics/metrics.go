@@ -39,6 +39,19 @@ const (
 	PodWorkerStartLatencyKey      = "pod_worker_start_latency_microseconds"
 	PLEGRelistLatencyKey          = "pleg_relist_latency_microseconds"
 	PLEGRelistIntervalKey         = "pleg_relist_interval_microseconds"
+	PodSyncDurationKey            = "pod_sync_duration_seconds"
+	PodSyncErrorsKey              = "pod_sync_errors_total"
+	SyncPodOperationDurationKey   = "sync_pod_operation_duration_seconds"
+)
+
+// Values of the operation label of SyncPodOperationDuration.
+const (
+	PullImageOperation       = "pull_image"
+	CreateContainerOperation = "create_container"
+	StartContainerOperation  = "start_container"
+	SetupNetworkOperation    = "setup_network"
+	RunHandlerOperation      = "run_handler"
+	StopContainerOperation   = "stop_container"
 )
 
 var (
@@ -139,6 +152,30 @@ var (
 			Help:      "Interval in microseconds between relisting in PLEG.",
 		},
 	)
+	PodSyncDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: KubeletSubsystem,
+			Name:      PodSyncDurationKey,
+			Help:      "Duration in seconds a pod worker spends syncing a single pod, from dequeuing the work item to completion. Broken down by namespace and pod name.",
+		},
+		[]string{"namespace", "pod_name"},
+	)
+	PodSyncErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: KubeletSubsystem,
+			Name:      PodSyncErrorsKey,
+			Help:      "Cumulative number of pod sync errors by error type.",
+		},
+		[]string{"error_type"},
+	)
+	SyncPodOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: KubeletSubsystem,
+			Name:      SyncPodOperationDurationKey,
+			Help:      "Duration in seconds of the individual operations performed while syncing a pod. Broken down by operation.",
+		},
+		[]string{"operation"},
+	)
 )
 
 var registerMetrics sync.Once
@@ -161,6 +198,9 @@ func Register(containerCache kubecontainer.RuntimeCache) {
 		prometheus.MustRegister(newPodAndContainerCollector(containerCache))
 		prometheus.MustRegister(PLEGRelistLatency)
 		prometheus.MustRegister(PLEGRelistInterval)
+		prometheus.MustRegister(PodSyncDuration)
+		prometheus.MustRegister(PodSyncErrors)
+		prometheus.MustRegister(SyncPodOperationDuration)
 	})
 }
 
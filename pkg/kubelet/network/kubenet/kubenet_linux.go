@@ -21,6 +21,7 @@ package kubenet
 import (
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"sync"
 	"syscall"
@@ -61,6 +62,11 @@ const (
 	// fallbackMTU is used if an MTU is not specified, and we cannot determine the MTU
 	fallbackMTU = 1460
 
+	// Tunnel encapsulation overheads, in bytes, that deployments running pod
+	// traffic over an overlay need to reserve out of the uplink MTU.
+	vxlanTunnelOverhead = 50
+	greTunnelOverhead   = 24
+
 	// private mac prefix safe to use
 	// Universally administered and locally administered addresses are distinguished by setting the second-least-significant
 	// bit of the first octet of the address. If it is 1, the address is locally administered. For example, for address 0a:00:00:00:00:00,
@@ -74,6 +80,34 @@ const (
 // CNI plugins required by kubenet in /opt/cni/bin or vendor directory
 var requiredCNIPlugins = [...]string{"bridge", "host-local", "loopback"}
 
+// netlinkHandle abstracts the netlink operations kubenet performs on the
+// bridge, so they can be mocked in unit tests.
+type netlinkHandle interface {
+	LinkByName(name string) (netlink.Link, error)
+	LinkSetHardwareAddr(link netlink.Link, hwAddr net.HardwareAddr) error
+	AddrList(link netlink.Link, family int) ([]netlink.Addr, error)
+	AddrDel(link netlink.Link, addr *netlink.Addr) error
+}
+
+// realNetlinkHandle dispatches to the netlink package.
+type realNetlinkHandle struct{}
+
+func (realNetlinkHandle) LinkByName(name string) (netlink.Link, error) {
+	return netlink.LinkByName(name)
+}
+
+func (realNetlinkHandle) LinkSetHardwareAddr(link netlink.Link, hwAddr net.HardwareAddr) error {
+	return netlink.LinkSetHardwareAddr(link, hwAddr)
+}
+
+func (realNetlinkHandle) AddrList(link netlink.Link, family int) ([]netlink.Addr, error) {
+	return netlink.AddrList(link, family)
+}
+
+func (realNetlinkHandle) AddrDel(link netlink.Link, addr *netlink.Addr) error {
+	return netlink.AddrDel(link, addr)
+}
+
 type kubenetNetworkPlugin struct {
 	network.NoopNetworkPlugin
 
@@ -85,6 +119,13 @@ type kubenetNetworkPlugin struct {
 	mu              sync.Mutex //Mutex for protecting podIPs map, netConfig, and shaper initialization
 	podIPs          map[kubecontainer.ContainerID]string
 	mtu             int
+	// tunnelOverhead, when non-zero, is subtracted from the detected uplink
+	// MTU to leave room for a tunnel header (e.g. vxlanTunnelOverhead or
+	// greTunnelOverhead) around pod traffic.
+	tunnelOverhead int
+	// sysfsNetPath is where interface attributes are read from; tests may
+	// point it at a fake filesystem.
+	sysfsNetPath    string
 	execer          utilexec.Interface
 	nsenterPath     string
 	hairpinMode     componentconfig.HairpinMode
@@ -98,6 +139,7 @@ type kubenetNetworkPlugin struct {
 	nonMasqueradeCIDR string
 	podCidr           string
 	gateway           net.IP
+	netlinkHandle     netlinkHandle
 }
 
 func NewPlugin(networkPluginDir string) network.NetworkPlugin {
@@ -114,6 +156,8 @@ func NewPlugin(networkPluginDir string) network.NetworkPlugin {
 		vendorDir:         networkPluginDir,
 		hostportHandler:   hostport.NewHostportHandler(),
 		nonMasqueradeCIDR: "10.0.0.0/8",
+		sysfsNetPath:      "/sys/class/net",
+		netlinkHandle:     realNetlinkHandle{},
 	}
 }
 
@@ -126,12 +170,12 @@ func (plugin *kubenetNetworkPlugin) Init(host network.Host, hairpinMode componen
 	}
 
 	if mtu == network.UseDefaultMTU {
-		if link, err := findMinMTU(); err == nil {
-			plugin.mtu = link.MTU
-			glog.V(5).Infof("Using interface %s MTU %d as bridge MTU", link.Name, link.MTU)
+		if detectedMTU, err := plugin.detectMTU(BridgeName); err == nil {
+			plugin.mtu = detectedMTU
+			glog.V(5).Infof("Using detected MTU %d for bridge %s", detectedMTU, BridgeName)
 		} else {
 			plugin.mtu = fallbackMTU
-			glog.Warningf("Failed to find default bridge MTU, using %d: %v", fallbackMTU, err)
+			glog.Warningf("Failed to detect bridge MTU, using %d: %v", fallbackMTU, err)
 		}
 	} else {
 		plugin.mtu = mtu
@@ -182,28 +226,63 @@ func (plugin *kubenetNetworkPlugin) ensureMasqRule() error {
 	return nil
 }
 
-func findMinMTU() (*net.Interface, error) {
-	intfs, err := net.Interfaces()
+// Interface flag bits as exported by /sys/class/net/<iface>/flags.
+const (
+	sysfsFlagUp           = 0x1
+	sysfsFlagLoopback     = 0x8
+	sysfsFlagPointToPoint = 0x10
+)
+
+// readSysfsInt reads a single integer attribute, such as an interface's MTU
+// or flags, from the given sysfs file.
+func readSysfsInt(path string) (int64, error) {
+	data, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 0, 32)
+}
+
+// detectMTU derives the MTU for the given bridge from its uplink: the up,
+// non-loopback, non-point-to-point interface with the smallest MTU, as read
+// from sysfs. A configured tunnelOverhead is subtracted so encapsulated pod
+// traffic still fits in the uplink MTU.
+func (plugin *kubenetNetworkPlugin) detectMTU(bridgeName string) (int, error) {
+	intfs, err := ioutil.ReadDir(plugin.sysfsNetPath)
+	if err != nil {
+		return 0, err
 	}
 
 	mtu := 999999
-	defIntfIndex := -1
-	for i, intf := range intfs {
-		if ((intf.Flags & net.FlagUp) != 0) && (intf.Flags&(net.FlagLoopback|net.FlagPointToPoint) == 0) {
-			if intf.MTU < mtu {
-				mtu = intf.MTU
-				defIntfIndex = i
-			}
+	for _, intf := range intfs {
+		if intf.Name() == bridgeName {
+			continue
+		}
+		flags, err := readSysfsInt(filepath.Join(plugin.sysfsNetPath, intf.Name(), "flags"))
+		if err != nil {
+			continue
+		}
+		if flags&sysfsFlagUp == 0 || flags&(sysfsFlagLoopback|sysfsFlagPointToPoint) != 0 {
+			continue
+		}
+		intfMTU, err := readSysfsInt(filepath.Join(plugin.sysfsNetPath, intf.Name(), "mtu"))
+		if err != nil {
+			continue
+		}
+		if int(intfMTU) < mtu {
+			mtu = int(intfMTU)
 		}
 	}
 
-	if mtu >= 999999 || mtu < 576 || defIntfIndex < 0 {
-		return nil, fmt.Errorf("no suitable interface: %v", BridgeName)
+	if mtu >= 999999 || mtu < 576 {
+		return 0, fmt.Errorf("no suitable uplink interface for %s", bridgeName)
 	}
 
-	return &intfs[defIntfIndex], nil
+	mtu -= plugin.tunnelOverhead
+	if mtu < 576 {
+		return 0, fmt.Errorf("uplink MTU minus tunnel overhead %d is too small", plugin.tunnelOverhead)
+	}
+	return mtu, nil
 }
 
 const NET_CONFIG_TEMPLATE = `{
@@ -272,12 +351,12 @@ func (plugin *kubenetNetworkPlugin) Event(name string, details map[string]interf
 }
 
 func (plugin *kubenetNetworkPlugin) clearBridgeAddressesExcept(keep *net.IPNet) {
-	bridge, err := netlink.LinkByName(BridgeName)
+	bridge, err := plugin.netlinkHandle.LinkByName(BridgeName)
 	if err != nil {
 		return
 	}
 
-	addrs, err := netlink.AddrList(bridge, syscall.AF_INET)
+	addrs, err := plugin.netlinkHandle.AddrList(bridge, syscall.AF_INET)
 	if err != nil {
 		return
 	}
@@ -285,7 +364,7 @@ func (plugin *kubenetNetworkPlugin) clearBridgeAddressesExcept(keep *net.IPNet)
 	for _, addr := range addrs {
 		if !utilnet.IPNetEqual(addr.IPNet, keep) {
 			glog.V(2).Infof("Removing old address %s from %s", addr.IPNet.String(), BridgeName)
-			netlink.AddrDel(bridge, &addr)
+			plugin.netlinkHandle.AddrDel(bridge, &addr)
 		}
 	}
 }
@@ -296,7 +375,7 @@ func (plugin *kubenetNetworkPlugin) clearBridgeAddressesExcept(keep *net.IPNet)
 // used as the packet limit for FIFO traffic shapers, which drops packets.
 // TODO: remove when we can depend on a fixed CNI
 func (plugin *kubenetNetworkPlugin) ensureBridgeTxQueueLen() {
-	bridge, err := netlink.LinkByName(BridgeName)
+	bridge, err := plugin.netlinkHandle.LinkByName(BridgeName)
 	if err != nil {
 		return
 	}
@@ -350,7 +429,7 @@ func (plugin *kubenetNetworkPlugin) setup(namespace string, name string, id kube
 
 	// Explicitly assign mac address to cbr0. If bridge mac address is not explicitly set will adopt the lowest MAC address of the attached veths.
 	// TODO: Remove this once upstream cni bridge plugin handles this
-	link, err := netlink.LinkByName(BridgeName)
+	link, err := plugin.netlinkHandle.LinkByName(BridgeName)
 	if err != nil {
 		return fmt.Errorf("failed to lookup %q: %v", BridgeName, err)
 	}
@@ -359,7 +438,7 @@ func (plugin *kubenetNetworkPlugin) setup(namespace string, name string, id kube
 		return err
 	}
 	glog.V(3).Infof("Configure %q mac address to %v", BridgeName, macAddr)
-	err = netlink.LinkSetHardwareAddr(link, macAddr)
+	err = plugin.netlinkHandle.LinkSetHardwareAddr(link, macAddr)
 	if err != nil {
 		return fmt.Errorf("Failed to configure %q mac address to %q: %v", BridgeName, macAddr, err)
 	}
@@ -513,11 +592,9 @@ func (plugin *kubenetNetworkPlugin) TearDownPod(namespace string, name string, i
 func (plugin *kubenetNetworkPlugin) GetPodNetworkStatus(namespace string, name string, id kubecontainer.ContainerID) (*network.PodNetworkStatus, error) {
 	plugin.mu.Lock()
 	defer plugin.mu.Unlock()
-	// Assuming the ip of pod does not change. Try to retrieve ip from kubenet map first.
-	if podIP, ok := plugin.podIPs[id]; ok {
-		return &network.PodNetworkStatus{IP: net.ParseIP(podIP)}, nil
-	}
-
+	// Always read the address currently assigned to the pod's interface
+	// instead of trusting the stored map, so that a network namespace that
+	// was rebuilt behind the kubelet's back is not reported with a stale IP.
 	netnsPath, err := plugin.host.GetRuntime().GetNetNS(id)
 	if err != nil {
 		return nil, fmt.Errorf("Kubenet failed to retrieve network namespace path: %v", err)
@@ -526,6 +603,14 @@ func (plugin *kubenetNetworkPlugin) GetPodNetworkStatus(namespace string, name s
 	if err != nil {
 		return nil, err
 	}
+	// Sanity-check the address against the bridge IPAM range when the net
+	// config is available; an IP outside the pod CIDR means the pod is not
+	// attached to the bridge we manage.
+	if plugin.podCidr != "" {
+		if _, cidr, err := net.ParseCIDR(plugin.podCidr); err == nil && !cidr.Contains(ip) {
+			return nil, fmt.Errorf("Kubenet found pod IP %v outside the pod CIDR %v", ip, plugin.podCidr)
+		}
+	}
 
 	plugin.podIPs[id] = ip.String()
 	return &network.PodNetworkStatus{IP: ip}, nil
@@ -18,13 +18,20 @@ package kubenet
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 
+	"github.com/containernetworking/cni/libcni"
+	cnitypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/vishvananda/netlink"
 
 	"testing"
 
+	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/apis/componentconfig"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/kubelet/network"
@@ -40,26 +47,52 @@ import (
 // test it fulfills the NetworkPlugin interface
 var _ network.NetworkPlugin = &kubenetNetworkPlugin{}
 
+// fakeNetlinkHandle is a mock netlinkHandle that only knows about the
+// configured link; lookups of any other link fail, like netlink does for
+// missing devices.
+type fakeNetlinkHandle struct {
+	linkName string
+	// setHWAddrs records the hardware addresses set on the link.
+	setHWAddrs []string
+}
+
+func (f *fakeNetlinkHandle) LinkByName(name string) (netlink.Link, error) {
+	if name != f.linkName {
+		return nil, fmt.Errorf("no such link: %q", name)
+	}
+	return &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: name}}, nil
+}
+
+func (f *fakeNetlinkHandle) LinkSetHardwareAddr(link netlink.Link, hwAddr net.HardwareAddr) error {
+	f.setHWAddrs = append(f.setHWAddrs, hwAddr.String())
+	return nil
+}
+
+func (f *fakeNetlinkHandle) AddrList(link netlink.Link, family int) ([]netlink.Addr, error) {
+	return nil, nil
+}
+
+func (f *fakeNetlinkHandle) AddrDel(link netlink.Link, addr *netlink.Addr) error {
+	return nil
+}
+
 func newFakeKubenetPlugin(initMap map[kubecontainer.ContainerID]string, execer exec.Interface, host network.Host) *kubenetNetworkPlugin {
 	return &kubenetNetworkPlugin{
-		podIPs: initMap,
-		execer: execer,
-		mtu:    1460,
-		host:   host,
+		podIPs:        initMap,
+		execer:        execer,
+		mtu:           1460,
+		host:          host,
+		netlinkHandle: &fakeNetlinkHandle{},
 	}
 }
 
 func TestGetPodNetworkStatus(t *testing.T) {
-	podIPMap := make(map[kubecontainer.ContainerID]string)
-	podIPMap[kubecontainer.ContainerID{ID: "1"}] = "10.245.0.2"
-	podIPMap[kubecontainer.ContainerID{ID: "2"}] = "10.245.0.3"
-
 	testCases := []struct {
 		id          string
 		expectError bool
 		expectIP    string
 	}{
-		//in podCIDR map
+		// IPs assigned from the bridge IPAM range.
 		{
 			"1",
 			false,
@@ -70,28 +103,43 @@ func TestGetPodNetworkStatus(t *testing.T) {
 			false,
 			"10.245.0.3",
 		},
-		//not in podCIDR map
+		// No IP assigned.
 		{
 			"3",
 			true,
 			"",
 		},
-		//TODO: add test cases for retrieving ip inside container network namespace
+		// IP outside the pod CIDR.
+		{
+			"4",
+			true,
+			"",
+		},
 	}
 
+	// The fake commands below return the addresses assigned inside the pods'
+	// network namespaces, in `ip -o addr show` format.
+	addrOutput := func(ip string) exec.FakeCombinedOutputAction {
+		return func() ([]byte, error) {
+			return []byte(fmt.Sprintf("2: eth0    inet %s/16 scope global eth0\\       valid_lft forever preferred_lft forever", ip)), nil
+		}
+	}
+	noAddrOutput := func() ([]byte, error) {
+		return nil, fmt.Errorf("no address assigned")
+	}
+	// Pod "3" has no address, so the IPv4 query falls back to an IPv6 one and
+	// consumes two commands.
+	outputs := []exec.FakeCombinedOutputAction{
+		addrOutput("10.245.0.2"),
+		addrOutput("10.245.0.3"),
+		noAddrOutput,
+		noAddrOutput,
+		addrOutput("192.168.0.2"),
+	}
 	fakeCmds := make([]exec.FakeCommandAction, 0)
-	for _, t := range testCases {
-		// the fake commands return the IP from the given index, or an error
+	for i := range outputs {
 		fCmd := exec.FakeCmd{
-			CombinedOutputScript: []exec.FakeCombinedOutputAction{
-				func() ([]byte, error) {
-					ip, ok := podIPMap[kubecontainer.ContainerID{ID: t.id}]
-					if !ok {
-						return nil, fmt.Errorf("Pod IP %q not found", t.id)
-					}
-					return []byte(ip), nil
-				},
-			},
+			CombinedOutputScript: []exec.FakeCombinedOutputAction{outputs[i]},
 		}
 		fakeCmds = append(fakeCmds, func(cmd string, args ...string) exec.Cmd {
 			return exec.InitFakeCmd(&fCmd, cmd, args...)
@@ -105,7 +153,10 @@ func TestGetPodNetworkStatus(t *testing.T) {
 	}
 
 	fhost := nettest.NewFakeHost(nil)
-	fakeKubenet := newFakeKubenetPlugin(podIPMap, &fexec, fhost)
+	// The plugin starts with an empty map to prove that the status is read
+	// from the pod's interface rather than from the stored map.
+	fakeKubenet := newFakeKubenetPlugin(map[kubecontainer.ContainerID]string{}, &fexec, fhost)
+	fakeKubenet.podCidr = "10.245.0.0/16"
 
 	for i, tc := range testCases {
 		out, err := fakeKubenet.GetPodNetworkStatus("", "", kubecontainer.ContainerID{ID: tc.id})
@@ -122,6 +173,9 @@ func TestGetPodNetworkStatus(t *testing.T) {
 		if tc.expectIP != out.IP.String() {
 			t.Errorf("Test case %d expects ip %s but got %s", i, tc.expectIP, out.IP.String())
 		}
+		if cached, ok := fakeKubenet.podIPs[kubecontainer.ContainerID{ID: tc.id}]; !ok || cached != tc.expectIP {
+			t.Errorf("Test case %d expects ip %s to be cached, got %q", i, tc.expectIP, cached)
+		}
 	}
 }
 
@@ -160,6 +214,86 @@ func TestTeardownCallsShaper(t *testing.T) {
 	mockcni.AssertExpectations(t)
 }
 
+// TestSetUpPodBandwidth tests that SetUpPod applies the pod's bandwidth
+// annotations to the shaper after the pod is hooked up to the bridge.
+func TestSetUpPodBandwidth(t *testing.T) {
+	// Make the required CNI plugins discoverable in the vendor dir.
+	vendorDir, err := ioutil.TempDir("", "kubenet_test")
+	if err != nil {
+		t.Fatalf("Cannot create vendor dir: %v", err)
+	}
+	defer os.RemoveAll(vendorDir)
+	for _, cniPlugin := range requiredCNIPlugins {
+		if err := ioutil.WriteFile(filepath.Join(vendorDir, cniPlugin), []byte{}, 0755); err != nil {
+			t.Fatalf("Cannot create fake CNI plugin %q: %v", cniPlugin, err)
+		}
+	}
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+			Annotations: map[string]string{
+				"kubernetes.io/ingress-bandwidth": "10M",
+				"kubernetes.io/egress-bandwidth":  "5M",
+			},
+		},
+	}
+
+	fexec := &exec.FakeExec{
+		CommandScript: []exec.FakeCommandAction{},
+		LookPathFunc: func(file string) (string, error) {
+			return fmt.Sprintf("/fake-bin/%s", file), nil
+		},
+	}
+	fhost := nettest.NewFakeHost(nil)
+	fhost.Pods = []*api.Pod{pod}
+	fshaper := &bandwidth.FakeShaper{}
+	fnetlink := &fakeNetlinkHandle{linkName: BridgeName}
+	mockcni := &mock_cni.MockCNI{}
+	kubenet := newFakeKubenetPlugin(map[kubecontainer.ContainerID]string{}, fexec, fhost)
+	kubenet.cniConfig = mockcni
+	kubenet.iptables = ipttest.NewFake()
+	kubenet.bandwidthShaper = fshaper
+	kubenet.netlinkHandle = fnetlink
+	kubenet.hostportHandler = hostporttest.NewFakeHostportHandler()
+	kubenet.vendorDir = vendorDir
+	kubenet.loConfig, err = libcni.ConfFromBytes([]byte(`{
+  "cniVersion": "0.1.0",
+  "name": "kubenet-loopback",
+  "type": "loopback"
+}`))
+	if err != nil {
+		t.Fatalf("Cannot generate loopback config: %v", err)
+	}
+
+	podIP := net.ParseIP("10.0.0.2")
+	mockcni.On("AddNetwork", mock.AnythingOfType("*libcni.NetworkConfig"), mock.AnythingOfType("*libcni.RuntimeConf")).Return(
+		&cnitypes.Result{IP4: &cnitypes.IPConfig{IP: net.IPNet{IP: podIP, Mask: net.CIDRMask(24, 32)}}}, nil)
+
+	details := make(map[string]interface{})
+	details[network.NET_PLUGIN_EVENT_POD_CIDR_CHANGE_DETAIL_CIDR] = "10.0.0.0/24"
+	kubenet.Event(network.NET_PLUGIN_EVENT_POD_CIDR_CHANGE, details)
+
+	if err := kubenet.SetUpPod("namespace", "name", kubecontainer.BuildContainerID("docker", "123")); err != nil {
+		t.Fatalf("Unexpected error in SetUpPod: %v", err)
+	}
+
+	if len(fshaper.ReconcileCIDRCalls) != 1 {
+		t.Fatalf("expected one ReconcileCIDR call, got %v", fshaper.ReconcileCIDRCalls)
+	}
+	call := fshaper.ReconcileCIDRCalls[0]
+	assert.Equal(t, "10.0.0.2/32", call.CIDR, "shaper should be given the pod's IP")
+	if call.Egress == nil || call.Egress.String() != "5M" {
+		t.Errorf("expected egress bandwidth 5M, got %v", call.Egress)
+	}
+	if call.Ingress == nil || call.Ingress.String() != "10M" {
+		t.Errorf("expected ingress bandwidth 10M, got %v", call.Ingress)
+	}
+
+	mockcni.AssertExpectations(t)
+}
+
 // TestInit tests that a `Init` call with an MTU sets the MTU
 func TestInit_MTU(t *testing.T) {
 	var fakeCmds []exec.FakeCommandAction
@@ -199,6 +333,52 @@ func TestInit_MTU(t *testing.T) {
 	assert.Equal(t, 1, sysctl.Settings["net/bridge/bridge-nf-call-iptables"], "net/bridge/bridge-nf-call-iptables sysctl should have been set")
 }
 
+// TestDetectMTU tests deriving the bridge MTU from the uplink interface using
+// a fake sysfs tree.
+func TestDetectMTU(t *testing.T) {
+	sysfsDir, err := ioutil.TempDir("", "kubenet-sysfs")
+	if err != nil {
+		t.Fatalf("Failed to create fake sysfs dir: %v", err)
+	}
+	defer os.RemoveAll(sysfsDir)
+
+	writeIntf := func(name string, flags, mtu int) {
+		intfDir := filepath.Join(sysfsDir, name)
+		if err := os.MkdirAll(intfDir, 0755); err != nil {
+			t.Fatalf("Failed to create fake interface dir: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(intfDir, "flags"), []byte(fmt.Sprintf("0x%x\n", flags)), 0644); err != nil {
+			t.Fatalf("Failed to write fake flags: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(intfDir, "mtu"), []byte(fmt.Sprintf("%d\n", mtu)), 0644); err != nil {
+			t.Fatalf("Failed to write fake mtu: %v", err)
+		}
+	}
+	writeIntf("lo", sysfsFlagUp|sysfsFlagLoopback, 65536)
+	writeIntf("eth0", sysfsFlagUp|0x1000, 1500)
+	writeIntf("tun0", sysfsFlagUp|sysfsFlagPointToPoint, 1280)
+	writeIntf("down0", 0x1000, 900)
+	writeIntf(BridgeName, sysfsFlagUp, 1460)
+
+	kubenet := newFakeKubenetPlugin(map[kubecontainer.ContainerID]string{}, nil, nettest.NewFakeHost(nil))
+	kubenet.sysfsNetPath = sysfsDir
+
+	// eth0 is the only suitable uplink; lo, tun0, down0 and the bridge
+	// itself must be ignored.
+	mtu, err := kubenet.detectMTU(BridgeName)
+	if err != nil {
+		t.Fatalf("Unexpected error in detectMTU: %v", err)
+	}
+	assert.Equal(t, 1500, mtu, "detected MTU should match the uplink MTU")
+
+	kubenet.tunnelOverhead = vxlanTunnelOverhead
+	mtu, err = kubenet.detectMTU(BridgeName)
+	if err != nil {
+		t.Fatalf("Unexpected error in detectMTU: %v", err)
+	}
+	assert.Equal(t, 1500-vxlanTunnelOverhead, mtu, "detected MTU should subtract the tunnel overhead")
+}
+
 func TestGenerateMacAddress(t *testing.T) {
 	testCases := []struct {
 		ip          net.IP
@@ -20,6 +20,7 @@ package kubenet
 
 import (
 	"fmt"
+	goruntime "runtime"
 
 	"k8s.io/kubernetes/pkg/apis/componentconfig"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
@@ -34,8 +35,15 @@ func NewPlugin(networkPluginDir string) network.NetworkPlugin {
 	return &kubenetNetworkPlugin{}
 }
 
+// notSupportedError names the os/arch of the build that lacks kubenet, so the
+// error in the kubelet log points at the actual problem instead of a generic
+// "not supported".
+func notSupportedError() error {
+	return fmt.Errorf("kubenet is not supported on %s/%s, it requires a linux build of the kubelet (see https://github.com/kubernetes/kubernetes/tree/master/build for build instructions)", goruntime.GOOS, goruntime.GOARCH)
+}
+
 func (plugin *kubenetNetworkPlugin) Init(host network.Host, hairpinMode componentconfig.HairpinMode, nonMasqueradeCIDR string, mtu int) error {
-	return fmt.Errorf("Kubenet is not supported in this build")
+	return notSupportedError()
 }
 
 func (plugin *kubenetNetworkPlugin) Name() string {
@@ -43,13 +51,13 @@ func (plugin *kubenetNetworkPlugin) Name() string {
 }
 
 func (plugin *kubenetNetworkPlugin) SetUpPod(namespace string, name string, id kubecontainer.ContainerID) error {
-	return fmt.Errorf("Kubenet is not supported in this build")
+	return notSupportedError()
 }
 
 func (plugin *kubenetNetworkPlugin) TearDownPod(namespace string, name string, id kubecontainer.ContainerID) error {
-	return fmt.Errorf("Kubenet is not supported in this build")
+	return notSupportedError()
 }
 
 func (plugin *kubenetNetworkPlugin) GetPodNetworkStatus(namespace string, name string, id kubecontainer.ContainerID) (*network.PodNetworkStatus, error) {
-	return nil, fmt.Errorf("Kubenet is not supported in this build")
+	return nil, notSupportedError()
 }
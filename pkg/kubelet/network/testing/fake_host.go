@@ -28,6 +28,10 @@ import (
 
 type fakeNetworkHost struct {
 	kubeClient clientset.Interface
+
+	// Pods are returned by GetPodByName; tests can populate this to make
+	// the host aware of pods.
+	Pods []*api.Pod
 }
 
 func NewFakeHost(kubeClient clientset.Interface) *fakeNetworkHost {
@@ -35,7 +39,12 @@ func NewFakeHost(kubeClient clientset.Interface) *fakeNetworkHost {
 	return host
 }
 
-func (fnh *fakeNetworkHost) GetPodByName(name, namespace string) (*api.Pod, bool) {
+func (fnh *fakeNetworkHost) GetPodByName(namespace, name string) (*api.Pod, bool) {
+	for _, pod := range fnh.Pods {
+		if pod.Namespace == namespace && pod.Name == name {
+			return pod, true
+		}
+	}
 	return nil, false
 }
 
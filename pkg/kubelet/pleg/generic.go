@@ -58,6 +58,9 @@ type GenericPLEG struct {
 	relistTime atomic.Value
 	// Cache for storing the runtime states required for syncing pods.
 	cache kubecontainer.Cache
+	// Cache of the pods listed from the runtime; updated from the relist
+	// snapshot so that pod workers do not force additional relists.
+	runtimeCache kubecontainer.RuntimeCache
 	// For testability.
 	clock clock.Clock
 	// Pods that failed to have their status retrieved during a relist. These pods will be
@@ -101,13 +104,15 @@ type podRecord struct {
 type podRecords map[types.UID]*podRecord
 
 func NewGenericPLEG(runtime kubecontainer.Runtime, channelCapacity int,
-	relistPeriod time.Duration, cache kubecontainer.Cache, clock clock.Clock) PodLifecycleEventGenerator {
+	relistPeriod time.Duration, cache kubecontainer.Cache,
+	runtimeCache kubecontainer.RuntimeCache, clock clock.Clock) PodLifecycleEventGenerator {
 	return &GenericPLEG{
 		relistPeriod: relistPeriod,
 		runtime:      runtime,
 		eventChannel: make(chan *PodLifecycleEvent, channelCapacity),
 		podRecords:   make(podRecords),
 		cache:        cache,
+		runtimeCache: runtimeCache,
 		clock:        clock,
 	}
 }
@@ -200,6 +205,12 @@ func (g *GenericPLEG) relist() {
 	pods := kubecontainer.Pods(podList)
 	g.podRecords.setCurrent(pods)
 
+	if g.runtimeCache != nil {
+		// Share the snapshot with the runtime cache so that pod workers
+		// reading the cache do not trigger another relist.
+		g.runtimeCache.UpdatePods(podList, timestamp)
+	}
+
 	// Compare the old and the current pods, and generate events.
 	eventsByPodID := map[types.UID][]*PodLifecycleEvent{}
 	for pid := range g.podRecords {
@@ -157,6 +157,33 @@ func TestRelisting(t *testing.T) {
 	verifyEvents(t, expected, actual)
 }
 
+func TestRelistUpdatesRuntimeCache(t *testing.T) {
+	testPleg := newTestGenericPLEG()
+	pleg, runtime := testPleg.pleg, testPleg.runtime
+	runtimeCache := kubecontainer.NewTestRuntimeCache(runtime)
+	pleg.runtimeCache = runtimeCache
+
+	runtime.AllPodList = []*containertest.FakePod{
+		{Pod: &kubecontainer.Pod{
+			ID: "1234",
+			Containers: []*kubecontainer.Container{
+				createTestContainer("c1", kubecontainer.ContainerStateRunning),
+			},
+		}},
+	}
+	// Move the fake clock forward so the relist timestamp is newer than the
+	// (zero) cache time.
+	testPleg.clock.Step(time.Minute)
+	pleg.relist()
+
+	// The runtime cache should be populated from the relist snapshot without
+	// going back to the runtime.
+	pods := runtimeCache.GetCachedPods()
+	if len(pods) != 1 || pods[0].ID != "1234" {
+		t.Errorf("unexpected cached pods: %#v", pods)
+	}
+}
+
 func TestDetectingContainerDeaths(t *testing.T) {
 	// Vary the number of relists after the container started and before the
 	// container died to account for the changes in pleg's internal states.
@@ -0,0 +1,106 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/util/format"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+const (
+	// podTeardownTimeout is how long the teardown pipeline waits for the
+	// runtime to report all of a pod's containers dead before giving up.
+	podTeardownTimeout = 30 * time.Second
+
+	// podTeardownPollInterval is how often the runtime is polled while
+	// waiting for the containers to die.
+	podTeardownPollInterval = 500 * time.Millisecond
+)
+
+// teardownRuntime is the subset of the container runtime the teardown
+// pipeline needs: the graceful kill, which stops the app containers, tears
+// down the pod network and then stops the infra container, and the status
+// poll used to confirm that every container is dead.
+type teardownRuntime interface {
+	KillPod(pod *api.Pod, runningPod kubecontainer.Pod, gracePeriodOverride *int64) error
+	GetPodStatus(uid types.UID, name, namespace string) (*kubecontainer.PodStatus, error)
+}
+
+// volumeCleanupFunc signals that all of the pod's containers are dead and it
+// is safe to clean up the pod's volumes.
+type volumeCleanupFunc func(pod *api.Pod)
+
+// podTeardownPipeline finalizes the removal of a pod in a fixed order: the
+// runtime kill, a wait until the runtime reports every container dead, and
+// only then the volume cleanup signal. Waiting before signaling prevents
+// volumes, in particular network filesystems like NFS, from being unmounted
+// under containers that are still dying.
+type podTeardownPipeline struct {
+	runtime       teardownRuntime
+	waitTimeout   time.Duration
+	pollInterval  time.Duration
+	volumeCleanup volumeCleanupFunc
+}
+
+func newPodTeardownPipeline(runtime teardownRuntime, waitTimeout, pollInterval time.Duration, volumeCleanup volumeCleanupFunc) *podTeardownPipeline {
+	return &podTeardownPipeline{
+		runtime:       runtime,
+		waitTimeout:   waitTimeout,
+		pollInterval:  pollInterval,
+		volumeCleanup: volumeCleanup,
+	}
+}
+
+// teardownPod runs the pipeline for the pod. The volume cleanup signal is
+// skipped when the kill fails or the wait hits its deadline, so the error is
+// surfaced and the sync is retried instead of cleaning up under live
+// containers.
+func (p *podTeardownPipeline) teardownPod(pod *api.Pod, runningPod kubecontainer.Pod, gracePeriodOverride *int64) error {
+	if err := p.runtime.KillPod(pod, runningPod, gracePeriodOverride); err != nil {
+		return err
+	}
+	if err := p.waitForContainersDead(pod); err != nil {
+		return fmt.Errorf("error waiting for containers of pod %q to die: %v", format.Pod(pod), err)
+	}
+	if p.volumeCleanup != nil {
+		p.volumeCleanup(pod)
+	}
+	return nil
+}
+
+// waitForContainersDead polls the runtime until no container of the pod is
+// reported running, or the deadline passes.
+func (p *podTeardownPipeline) waitForContainersDead(pod *api.Pod) error {
+	return wait.PollImmediate(p.pollInterval, p.waitTimeout, func() (bool, error) {
+		status, err := p.runtime.GetPodStatus(pod.UID, pod.Name, pod.Namespace)
+		if err != nil {
+			return false, err
+		}
+		for _, container := range status.ContainerStatuses {
+			if container.State == kubecontainer.ContainerStateRunning {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+func TestTeardownPodOrdering(t *testing.T) {
+	fakeRuntime := &containertest.FakeRuntime{}
+	pod := newPod("teardown-pod-uid", "teardown-pod")
+
+	var cleaned []types.UID
+	var callsAtCleanup []string
+	pipeline := newPodTeardownPipeline(fakeRuntime, time.Second, time.Millisecond, func(p *api.Pod) {
+		callsAtCleanup = append([]string{}, fakeRuntime.CalledFunctions...)
+		cleaned = append(cleaned, p.UID)
+	})
+
+	if err := pipeline.teardownPod(pod, kubecontainer.Pod{}, nil); err != nil {
+		t.Fatalf("unexpected teardown error: %v", err)
+	}
+	// The kill and the containers-dead confirmation must precede the volume
+	// cleanup signal.
+	if expected := []string{"KillPod", "GetPodStatus"}; !reflect.DeepEqual(expected, callsAtCleanup) {
+		t.Errorf("expected runtime calls %v before volume cleanup, got %v", expected, callsAtCleanup)
+	}
+	if expected := []types.UID{pod.UID}; !reflect.DeepEqual(expected, cleaned) {
+		t.Errorf("expected volume cleanup for %v, got %v", expected, cleaned)
+	}
+}
+
+func TestTeardownPodDeadline(t *testing.T) {
+	fakeRuntime := &containertest.FakeRuntime{}
+	fakeRuntime.PodStatus = kubecontainer.PodStatus{
+		ContainerStatuses: []*kubecontainer.ContainerStatus{
+			{Name: "immortal", State: kubecontainer.ContainerStateRunning},
+		},
+	}
+	pod := newPod("immortal-pod-uid", "immortal-pod")
+
+	var cleaned []types.UID
+	pipeline := newPodTeardownPipeline(fakeRuntime, 50*time.Millisecond, 10*time.Millisecond, func(p *api.Pod) {
+		cleaned = append(cleaned, p.UID)
+	})
+
+	if err := pipeline.teardownPod(pod, kubecontainer.Pod{}, nil); err == nil {
+		t.Errorf("expected a deadline error when containers refuse to die")
+	}
+	if len(cleaned) != 0 {
+		t.Errorf("volume cleanup must not be signaled while containers are running, got %v", cleaned)
+	}
+}
+
+func TestTeardownPodKillFailure(t *testing.T) {
+	fakeRuntime := &containertest.FakeRuntime{}
+	fakeRuntime.Err = errors.New("kill failed")
+	pod := newPod("kill-failed-pod-uid", "kill-failed-pod")
+
+	var cleaned []types.UID
+	pipeline := newPodTeardownPipeline(fakeRuntime, time.Second, time.Millisecond, func(p *api.Pod) {
+		cleaned = append(cleaned, p.UID)
+	})
+
+	if err := pipeline.teardownPod(pod, kubecontainer.Pod{}, nil); err == nil {
+		t.Errorf("expected the kill error to be surfaced")
+	}
+	if len(cleaned) != 0 {
+		t.Errorf("volume cleanup must not be signaled when the kill fails, got %v", cleaned)
+	}
+}
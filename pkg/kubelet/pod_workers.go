@@ -17,16 +17,19 @@ limitations under the License.
 package kubelet
 
 import (
+	"container/heap"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/golang/glog"
+	"golang.org/x/net/context"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client/record"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/kubelet/events"
 	"k8s.io/kubernetes/pkg/kubelet/eviction"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/kubelet/util/queue"
 	"k8s.io/kubernetes/pkg/types"
@@ -72,10 +75,14 @@ type PodWorkers interface {
 	UpdatePod(options *UpdatePodOptions)
 	ForgetNonExistingPodWorkers(desiredPods map[types.UID]empty)
 	ForgetWorker(uid types.UID)
+	Drain(timeout time.Duration) error
 }
 
 // syncPodOptions provides the arguments to a SyncPod operation.
 type syncPodOptions struct {
+	// the context for the sync; it is canceled when the pod workers are
+	// closed so that in-flight syncs can abort early
+	ctx context.Context
 	// the mirror pod for the pod to sync, if it is a static pod
 	mirrorPod *api.Pod
 	// pod to sync
@@ -97,14 +104,132 @@ const (
 
 	// jitter factor for backOffPeriod
 	workerBackOffPeriodJitterFactor = 0.5
+
+	// workerExitTimeout bounds how long a forget call waits for removed
+	// per-pod goroutines to exit; a worker stuck in a long sync should not
+	// block pod cleanup indefinitely.
+	workerExitTimeout = 2 * time.Second
+
+	// drainPollInterval is how often Drain rechecks whether all workers
+	// have gone idle.
+	drainPollInterval = 100 * time.Millisecond
+)
+
+const (
+	// podPriorityDefault is the priority of ordinary pod work updates.
+	podPriorityDefault = 0
+	// podPrioritySystem is the priority of work updates for system pods;
+	// they are dispatched before all default priority updates.
+	podPrioritySystem = 1000
+
+	// podPriorityClassAnnotation is an alpha annotation naming the pod's
+	// priority class. "system" is the only class recognized today.
+	podPriorityClassAnnotation = "scheduler.alpha.kubernetes.io/priority-class"
 )
 
+// podUpdatePriority returns the priority of a pod's work updates. System pods
+// - those in the system namespace, or carrying the system priority class
+// annotation - are processed before everything else.
+func podUpdatePriority(pod *api.Pod) int {
+	if pod.Namespace == api.NamespaceSystem {
+		return podPrioritySystem
+	}
+	if pod.Annotations[podPriorityClassAnnotation] == "system" {
+		return podPrioritySystem
+	}
+	return podPriorityDefault
+}
+
+// workUpdate is an UpdatePodOptions queued for dispatch, together with the
+// priority of the pod it belongs to.
+type workUpdate struct {
+	options  UpdatePodOptions
+	priority int
+	// seq preserves arrival order between updates of equal priority.
+	seq int64
+}
+
+// updateHeap orders queued work updates by descending priority, breaking ties
+// in arrival order.
+type updateHeap []*workUpdate
+
+func (h updateHeap) Len() int { return len(h) }
+func (h updateHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h updateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *updateHeap) Push(x interface{}) { *h = append(*h, x.(*workUpdate)) }
+func (h *updateHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// podUpdateQueue is the single priority queue of pod work updates shared
+// across all pods. enqueue never blocks; dequeue blocks until an update is
+// available or the queue is closed.
+type podUpdateQueue struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	heap    updateHeap
+	nextSeq int64
+	closed  bool
+}
+
+func newPodUpdateQueue() *podUpdateQueue {
+	q := &podUpdateQueue{}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+// enqueue adds an update to the queue and reports whether it was accepted;
+// updates are dropped once the queue has been closed.
+func (q *podUpdateQueue) enqueue(options UpdatePodOptions, priority int) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return false
+	}
+	heap.Push(&q.heap, &workUpdate{options: options, priority: priority, seq: q.nextSeq})
+	q.nextSeq++
+	q.cond.Signal()
+	return true
+}
+
+// dequeue returns the highest priority queued update. The second return value
+// is false once the queue has been closed and drained.
+func (q *podUpdateQueue) dequeue() (UpdatePodOptions, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for len(q.heap) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.heap) == 0 {
+		return UpdatePodOptions{}, false
+	}
+	update := heap.Pop(&q.heap).(*workUpdate)
+	return update.options, true
+}
+
+func (q *podUpdateQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
 type podWorkers struct {
 	// Protects all per worker fields.
 	podLock sync.Mutex
 
 	// Tracks all running per-pod goroutines - per-pod goroutine will be
-	// processing updates received through its corresponding channel.
+	// processing updates handed to it by the dispatcher through its
+	// corresponding channel.
 	podUpdates map[types.UID]chan UpdatePodOptions
 	// Track the current state of per-pod goroutines.
 	// Currently all update request for a given pod coming when another
@@ -113,9 +238,16 @@ type podWorkers struct {
 	// Tracks the last undelivered work item for this pod - a work item is
 	// undelivered if it comes in while the worker is working.
 	lastUndeliveredWorkUpdate map[types.UID]UpdatePodOptions
+	// Tracks per-pod goroutine exit - the channel is closed when the
+	// goroutine processing the pod's updates returns.
+	podDone map[types.UID]chan struct{}
 
 	workQueue queue.WorkQueue
 
+	// updateQueue holds work updates for all pods, ordered by pod priority,
+	// until the dispatcher delivers them to the per-pod goroutines.
+	updateQueue *podUpdateQueue
+
 	// This function is run to sync the desired stated of pod.
 	// NOTE: This function has to be thread-safe - it can be called for
 	// different pods at the same time.
@@ -132,26 +264,90 @@ type podWorkers struct {
 
 	// podCache stores kubecontainer.PodStatus for all pods.
 	podCache kubecontainer.Cache
+
+	// ctx is the master context all pod syncs are run under; cancel aborts
+	// it when the pod workers are closed.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func newPodWorkers(syncPodFn syncPodFnType, recorder record.EventRecorder, workQueue queue.WorkQueue,
 	resyncInterval, backOffPeriod time.Duration, podCache kubecontainer.Cache) *podWorkers {
-	return &podWorkers{
+	ctx, cancel := context.WithCancel(context.Background())
+	pw := &podWorkers{
 		podUpdates:                map[types.UID]chan UpdatePodOptions{},
 		isWorking:                 map[types.UID]bool{},
 		lastUndeliveredWorkUpdate: map[types.UID]UpdatePodOptions{},
+		podDone:                   map[types.UID]chan struct{}{},
+		updateQueue:               newPodUpdateQueue(),
 		syncPodFn:                 syncPodFn,
 		recorder:                  recorder,
 		workQueue:                 workQueue,
 		resyncInterval:            resyncInterval,
 		backOffPeriod:             backOffPeriod,
 		podCache:                  podCache,
+		ctx:                       ctx,
+		cancel:                    cancel,
+	}
+	go pw.dispatchUpdates()
+	return pw
+}
+
+// Close cancels the master context, which propagates to all in-flight pod
+// syncs, and stops the dispatcher. It does not wait for the syncs to return.
+func (p *podWorkers) Close() {
+	p.cancel()
+	p.updateQueue.close()
+}
+
+// Drain closes the pod workers so that no new work is accepted, then waits
+// for all in-flight pod syncs to complete. If some pods are still syncing
+// when the timeout expires, a non-nil error naming their UIDs is returned.
+func (p *podWorkers) Drain(timeout time.Duration) error {
+	p.Close()
+
+	if err := wait.PollImmediate(drainPollInterval, timeout, func() (bool, error) {
+		return len(p.activePodUIDs()) == 0, nil
+	}); err != nil {
+		if active := p.activePodUIDs(); len(active) > 0 {
+			return fmt.Errorf("timed out waiting for pod workers to drain, still syncing pods: %v", active)
+		}
+	}
+	return nil
+}
+
+// activePodUIDs returns the UIDs of the pods whose workers are currently
+// processing an update.
+func (p *podWorkers) activePodUIDs() []types.UID {
+	p.podLock.Lock()
+	defer p.podLock.Unlock()
+	var active []types.UID
+	for uid, working := range p.isWorking {
+		if working {
+			active = append(active, uid)
+		}
+	}
+	return active
+}
+
+// podSyncErrorType buckets a pod sync error for the pod_sync_errors_total
+// metric.
+func podSyncErrorType(err error) string {
+	switch err {
+	case kubecontainer.ErrRunContainer, kubecontainer.ErrKillContainer,
+		kubecontainer.ErrVerifyNonRoot, kubecontainer.ErrRunInitContainer,
+		kubecontainer.ErrSetupNetwork, kubecontainer.ErrTeardownNetwork,
+		kubecontainer.ErrCrashLoopBackOff:
+		return err.Error()
+	default:
+		return "OtherError"
 	}
 }
 
 func (p *podWorkers) managePodLoop(podUpdates <-chan UpdatePodOptions) {
 	var lastSyncTime time.Time
 	for update := range podUpdates {
+		syncStart := time.Now()
 		err := func() error {
 			podUID := update.Pod.UID
 			// This is a blocking call that would return only if the cache
@@ -164,6 +360,7 @@ func (p *podWorkers) managePodLoop(podUpdates <-chan UpdatePodOptions) {
 				return err
 			}
 			err = p.syncPodFn(syncPodOptions{
+				ctx:            p.ctx,
 				mirrorPod:      update.MirrorPod,
 				pod:            update.Pod,
 				podStatus:      status,
@@ -176,6 +373,10 @@ func (p *podWorkers) managePodLoop(podUpdates <-chan UpdatePodOptions) {
 			}
 			return nil
 		}()
+		metrics.PodSyncDuration.WithLabelValues(update.Pod.Namespace, update.Pod.Name).Observe(time.Since(syncStart).Seconds())
+		if err != nil {
+			metrics.PodSyncErrors.WithLabelValues(podSyncErrorType(err)).Inc()
+		}
 		// notify the call-back function if the operation succeeded or not
 		if update.OnCompleteFunc != nil {
 			update.OnCompleteFunc(err)
@@ -194,18 +395,17 @@ func (p *podWorkers) managePodLoop(podUpdates <-chan UpdatePodOptions) {
 func (p *podWorkers) UpdatePod(options *UpdatePodOptions) {
 	pod := options.Pod
 	uid := pod.UID
-	var podUpdates chan UpdatePodOptions
-	var exists bool
 
 	p.podLock.Lock()
 	defer p.podLock.Unlock()
-	if podUpdates, exists = p.podUpdates[uid]; !exists {
-		// We need to have a buffer here, because checkForUpdates() method that
-		// puts an update into channel is called from the same goroutine where
-		// the channel is consumed. However, it is guaranteed that in such case
-		// the channel is empty, so buffer of size 1 is enough.
-		podUpdates = make(chan UpdatePodOptions, 1)
+	if _, exists := p.podUpdates[uid]; !exists {
+		// A buffer of size 1 is enough: the dispatcher hands over at most one
+		// update per pod at a time, since the next update is only queued after
+		// the previous sync finished (see checkForUpdates).
+		podUpdates := make(chan UpdatePodOptions, 1)
 		p.podUpdates[uid] = podUpdates
+		done := make(chan struct{})
+		p.podDone[uid] = done
 
 		// Creating a new pod worker either means this is a new pod, or that the
 		// kubelet just restarted. In either case the kubelet is willing to believe
@@ -213,12 +413,17 @@ func (p *podWorkers) UpdatePod(options *UpdatePodOptions) {
 		// comment in syncPod.
 		go func() {
 			defer runtime.HandleCrash()
+			defer close(done)
 			p.managePodLoop(podUpdates)
 		}()
 	}
 	if !p.isWorking[pod.UID] {
-		p.isWorking[pod.UID] = true
-		podUpdates <- *options
+		// The pod only counts as working if the queue accepted the update;
+		// after a close the update is dropped and the pod must stay idle so
+		// that Drain can complete.
+		if p.updateQueue.enqueue(*options, podUpdatePriority(pod)) {
+			p.isWorking[pod.UID] = true
+		}
 	} else {
 		// if a request to kill a pod is pending, we do not let anything overwrite that request.
 		update, found := p.lastUndeliveredWorkUpdate[pod.UID]
@@ -228,32 +433,85 @@ func (p *podWorkers) UpdatePod(options *UpdatePodOptions) {
 	}
 }
 
-func (p *podWorkers) removeWorker(uid types.UID) {
-	if ch, ok := p.podUpdates[uid]; ok {
-		close(ch)
-		delete(p.podUpdates, uid)
-		// If there is an undelivered work update for this pod we need to remove it
-		// since per-pod goroutine won't be able to put it to the already closed
-		// channel when it finish processing the current work update.
-		if _, cached := p.lastUndeliveredWorkUpdate[uid]; cached {
-			delete(p.lastUndeliveredWorkUpdate, uid)
+// dispatchUpdates delivers queued work updates to the per-pod goroutines in
+// priority order. It returns when the update queue is closed.
+func (p *podWorkers) dispatchUpdates() {
+	for {
+		update, ok := p.updateQueue.dequeue()
+		if !ok {
+			return
+		}
+		p.podLock.Lock()
+		if podUpdates, exists := p.podUpdates[update.Pod.UID]; exists {
+			// Never blocks; see the buffer comment in UpdatePod.
+			podUpdates <- update
 		}
+		// A missing channel means the worker was forgotten after the update
+		// was queued; drop the update.
+		p.podLock.Unlock()
 	}
 }
+
+// removeWorker stops the per-pod goroutine and returns the channel that is
+// closed when the goroutine exits, or nil if no worker exists for the pod.
+// The caller must hold podLock.
+func (p *podWorkers) removeWorker(uid types.UID) chan struct{} {
+	ch, ok := p.podUpdates[uid]
+	if !ok {
+		return nil
+	}
+	close(ch)
+	delete(p.podUpdates, uid)
+	// The dispatcher drops queued updates for removed pods, so the pod must
+	// not be left marked as working.
+	delete(p.isWorking, uid)
+	// If there is an undelivered work update for this pod we need to remove it
+	// since per-pod goroutine won't be able to put it to the already closed
+	// channel when it finish processing the current work update.
+	if _, cached := p.lastUndeliveredWorkUpdate[uid]; cached {
+		delete(p.lastUndeliveredWorkUpdate, uid)
+	}
+	done := p.podDone[uid]
+	delete(p.podDone, uid)
+	return done
+}
+
+// waitForWorkers waits for the given per-pod goroutines to exit, giving up
+// after workerExitTimeout. The caller must not hold podLock, since a worker
+// finishing its current update needs the lock before it can exit.
+func (p *podWorkers) waitForWorkers(doneChans []chan struct{}) {
+	deadline := time.After(workerExitTimeout)
+	for _, done := range doneChans {
+		select {
+		case <-done:
+		case <-deadline:
+			glog.V(2).Infof("Timed out waiting for pod worker goroutines to exit")
+			return
+		}
+	}
+}
+
 func (p *podWorkers) ForgetWorker(uid types.UID) {
 	p.podLock.Lock()
-	defer p.podLock.Unlock()
-	p.removeWorker(uid)
+	done := p.removeWorker(uid)
+	p.podLock.Unlock()
+	if done != nil {
+		p.waitForWorkers([]chan struct{}{done})
+	}
 }
 
 func (p *podWorkers) ForgetNonExistingPodWorkers(desiredPods map[types.UID]empty) {
 	p.podLock.Lock()
-	defer p.podLock.Unlock()
+	var doneChans []chan struct{}
 	for key := range p.podUpdates {
 		if _, exists := desiredPods[key]; !exists {
-			p.removeWorker(key)
+			if done := p.removeWorker(key); done != nil {
+				doneChans = append(doneChans, done)
+			}
 		}
 	}
+	p.podLock.Unlock()
+	p.waitForWorkers(doneChans)
 }
 
 func (p *podWorkers) wrapUp(uid types.UID, syncErr error) {
@@ -273,7 +531,12 @@ func (p *podWorkers) checkForUpdates(uid types.UID) {
 	p.podLock.Lock()
 	defer p.podLock.Unlock()
 	if workUpdate, exists := p.lastUndeliveredWorkUpdate[uid]; exists {
-		p.podUpdates[uid] <- workUpdate
+		// Hand the update back to the shared queue so it competes with other
+		// pods' updates by priority again. If the queue has been closed the
+		// update is dropped and the pod goes idle instead.
+		if !p.updateQueue.enqueue(workUpdate, podUpdatePriority(workUpdate.Pod)) {
+			p.isWorking[uid] = false
+		}
 		delete(p.lastUndeliveredWorkUpdate, uid)
 	} else {
 		p.isWorking[uid] = false
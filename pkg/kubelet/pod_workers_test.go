@@ -17,19 +17,25 @@ limitations under the License.
 package kubelet
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/client/record"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	containertest "k8s.io/kubernetes/pkg/kubelet/container/testing"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 	kubetypes "k8s.io/kubernetes/pkg/kubelet/types"
 	"k8s.io/kubernetes/pkg/kubelet/util/queue"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/clock"
+	"k8s.io/kubernetes/pkg/util/wait"
 )
 
 // fakePodWorkers runs sync pod function in serial, so we can have
@@ -46,6 +52,7 @@ func (f *fakePodWorkers) UpdatePod(options *UpdatePodOptions) {
 		f.t.Errorf("Unexpected error: %v", err)
 	}
 	if err := f.syncPodFn(syncPodOptions{
+		ctx:            context.Background(),
 		mirrorPod:      options.MirrorPod,
 		pod:            options.Pod,
 		podStatus:      status,
@@ -60,6 +67,8 @@ func (f *fakePodWorkers) ForgetNonExistingPodWorkers(desiredPods map[types.UID]e
 
 func (f *fakePodWorkers) ForgetWorker(uid types.UID) {}
 
+func (f *fakePodWorkers) Drain(timeout time.Duration) error { return nil }
+
 type TestingInterface interface {
 	Errorf(format string, args ...interface{})
 }
@@ -124,6 +133,151 @@ func drainWorkers(podWorkers *podWorkers, numPods int) {
 	}
 }
 
+// newPodWorkersWithSyncFn creates podWorkers backed by a fake cache that run
+// the given sync function.
+func newPodWorkersWithSyncFn(fn syncPodFnType) *podWorkers {
+	fakeRecorder := &record.FakeRecorder{}
+	fakeRuntime := &containertest.FakeRuntime{}
+	fakeCache := containertest.NewFakeCache(fakeRuntime)
+	return newPodWorkers(fn, fakeRecorder, queue.NewBasicWorkQueue(&clock.RealClock{}), time.Second, time.Second, fakeCache)
+}
+
+func TestDrainWaitsForSlowSync(t *testing.T) {
+	syncStarted := make(chan struct{})
+	podWorkers := newPodWorkersWithSyncFn(func(options syncPodOptions) error {
+		close(syncStarted)
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	podWorkers.UpdatePod(&UpdatePodOptions{
+		Pod:        newPod("1", "slow"),
+		UpdateType: kubetypes.SyncPodCreate,
+	})
+	<-syncStarted
+
+	if err := podWorkers.Drain(wait.ForeverTestTimeout); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if active := podWorkers.activePodUIDs(); len(active) != 0 {
+		t.Errorf("expected no active pods after drain, got %v", active)
+	}
+}
+
+func TestDrainTimesOutOnStuckSync(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	syncStarted := make(chan struct{})
+	podWorkers := newPodWorkersWithSyncFn(func(options syncPodOptions) error {
+		close(syncStarted)
+		<-release
+		return nil
+	})
+	podWorkers.UpdatePod(&UpdatePodOptions{
+		Pod:        newPod("stuck-uid", "stuck"),
+		UpdateType: kubetypes.SyncPodCreate,
+	})
+	<-syncStarted
+
+	err := podWorkers.Drain(300 * time.Millisecond)
+	if err == nil {
+		t.Fatalf("expected drain to time out")
+	}
+	if !strings.Contains(err.Error(), "stuck-uid") {
+		t.Errorf("expected the stuck pod's UID in the error, got: %v", err)
+	}
+}
+
+func TestManagePodLoopMetrics(t *testing.T) {
+	pod := newPod("metrics-pod-uid", "metrics-pod")
+
+	// A successful sync should be observed in the duration histogram.
+	podWorkers, _ := createPodWorkers()
+	done := make(chan error, 1)
+	podWorkers.UpdatePod(&UpdatePodOptions{
+		Pod:            pod,
+		UpdateType:     kubetypes.SyncPodCreate,
+		OnCompleteFunc: func(err error) { done <- err },
+	})
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected sync error: %v", err)
+	}
+
+	m := &dto.Metric{}
+	if err := metrics.PodSyncDuration.WithLabelValues(pod.Namespace, pod.Name).Write(m); err != nil {
+		t.Fatalf("failed to read the duration histogram: %v", err)
+	}
+	if count := m.GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected 1 observed sync, got %d", count)
+	}
+
+	// A failed sync should bump the error counter for the error type.
+	fakeRuntime := &containertest.FakeRuntime{}
+	failingWorkers := newPodWorkers(
+		func(options syncPodOptions) error { return kubecontainer.ErrRunContainer },
+		&record.FakeRecorder{},
+		queue.NewBasicWorkQueue(&clock.RealClock{}),
+		time.Second,
+		time.Second,
+		containertest.NewFakeCache(fakeRuntime),
+	)
+	failingWorkers.UpdatePod(&UpdatePodOptions{
+		Pod:            pod,
+		UpdateType:     kubetypes.SyncPodCreate,
+		OnCompleteFunc: func(err error) { done <- err },
+	})
+	if err := <-done; err != kubecontainer.ErrRunContainer {
+		t.Fatalf("expected %v, got %v", kubecontainer.ErrRunContainer, err)
+	}
+
+	m.Reset()
+	if err := metrics.PodSyncErrors.WithLabelValues(kubecontainer.ErrRunContainer.Error()).Write(m); err != nil {
+		t.Fatalf("failed to read the error counter: %v", err)
+	}
+	if count := m.GetCounter().GetValue(); count != 1 {
+		t.Errorf("expected 1 sync error, got %v", count)
+	}
+}
+
+func TestCloseCancelsPodSync(t *testing.T) {
+	fakeRuntime := &containertest.FakeRuntime{}
+	syncStarted := make(chan struct{})
+	blocked := make(chan struct{})
+	podWorkers := newPodWorkers(
+		func(options syncPodOptions) error {
+			close(syncStarted)
+			select {
+			case <-options.ctx.Done():
+				return options.ctx.Err()
+			case <-blocked:
+				return nil
+			}
+		},
+		&record.FakeRecorder{},
+		queue.NewBasicWorkQueue(&clock.RealClock{}),
+		time.Second,
+		time.Second,
+		containertest.NewFakeCache(fakeRuntime),
+	)
+
+	done := make(chan error, 1)
+	podWorkers.UpdatePod(&UpdatePodOptions{
+		Pod:            newPod("canceled-pod-uid", "canceled-pod"),
+		UpdateType:     kubetypes.SyncPodCreate,
+		OnCompleteFunc: func(err error) { done <- err },
+	})
+	<-syncStarted
+	podWorkers.Close()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for the sync to be unblocked by Close")
+	}
+}
+
 func TestUpdatePod(t *testing.T) {
 	podWorkers, processed := createPodWorkers()
 
@@ -162,6 +316,48 @@ func TestUpdatePod(t *testing.T) {
 	}
 }
 
+func TestPodUpdateQueuePriorityOrder(t *testing.T) {
+	q := newPodUpdateQueue()
+
+	// Enqueue 10 pods at varying priorities: system pods - whether by
+	// namespace or by annotation - must be dequeued before all default
+	// priority pods, and arrival order must be kept within a priority.
+	var wantSystem, wantDefault []string
+	for i := 0; i < 10; i++ {
+		pod := newPod(string(i), fmt.Sprintf("pod%d", i))
+		switch i % 3 {
+		case 0:
+			pod.Namespace = api.NamespaceSystem
+			wantSystem = append(wantSystem, pod.Name)
+		case 1:
+			pod.Annotations = map[string]string{podPriorityClassAnnotation: "system"}
+			wantSystem = append(wantSystem, pod.Name)
+		default:
+			wantDefault = append(wantDefault, pod.Name)
+		}
+		q.enqueue(UpdatePodOptions{Pod: pod, UpdateType: kubetypes.SyncPodUpdate}, podUpdatePriority(pod))
+	}
+
+	want := append(wantSystem, wantDefault...)
+	var got []string
+	for range want {
+		update, ok := q.dequeue()
+		if !ok {
+			t.Fatalf("queue closed unexpectedly")
+		}
+		got = append(got, update.Pod.Name)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected dequeue order %v, got %v", want, got)
+	}
+
+	// Dequeue reports a closed queue instead of blocking forever.
+	q.close()
+	if _, ok := q.dequeue(); ok {
+		t.Errorf("expected dequeue to report the queue as closed")
+	}
+}
+
 func TestUpdatePodDoesNotForgetSyncPodKill(t *testing.T) {
 	podWorkers, processed := createPodWorkers()
 	numPods := 20
@@ -238,6 +434,49 @@ func TestForgetNonExistingPodWorkers(t *testing.T) {
 	}
 }
 
+func TestForgetNonExistingPodWorkersWaitsForWorkerExit(t *testing.T) {
+	podWorkers, _ := createPodWorkers()
+
+	numPods := 5
+	for i := 0; i < numPods; i++ {
+		podWorkers.UpdatePod(&UpdatePodOptions{
+			Pod:        newPod(string(i), "name"),
+			UpdateType: kubetypes.SyncPodUpdate,
+		})
+	}
+	drainWorkers(podWorkers, numPods)
+
+	// Watch every per-pod goroutine through its done channel.
+	var wg sync.WaitGroup
+	podWorkers.podLock.Lock()
+	for _, done := range podWorkers.podDone {
+		wg.Add(1)
+		done := done
+		go func() {
+			defer wg.Done()
+			<-done
+		}()
+	}
+	podWorkers.podLock.Unlock()
+
+	podWorkers.ForgetNonExistingPodWorkers(map[types.UID]empty{})
+
+	// Every goroutine must have exited by the time the forget call returns.
+	exited := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("timed out waiting for the worker goroutines to exit")
+	}
+	if len(podWorkers.podDone) != 0 {
+		t.Errorf("Incorrect number of tracked worker goroutines %v", len(podWorkers.podDone))
+	}
+}
+
 type simpleFakeKubelet struct {
 	pod       *api.Pod
 	mirrorPod *api.Pod
@@ -18,9 +18,15 @@ package rkt
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
 	rktapi "github.com/coreos/rkt/api/v1alpha"
 	"golang.org/x/net/context"
+
+	"k8s.io/kubernetes/pkg/util/errors"
 )
 
 // Config stores the global configuration for the rkt runtime.
@@ -74,6 +80,80 @@ func (c *Config) buildGlobalOptions() []string {
 	return result
 }
 
+// insecureOptionValues are the values rkt accepts in --insecure-options.
+var insecureOptionValues = []string{"none", "http", "image", "tls", "ondisk", "pubkey", "capabilities", "paths", "seccomp", "all-fetch", "all-run", "all"}
+
+// Validate checks the configuration for problems that would otherwise only
+// surface once the kubelet starts failing pods: a nonexistent data directory,
+// an unwritable local config directory, a missing stage1 image and malformed
+// insecure options. All problems found are returned at once in an aggregate.
+// New calls it after the configuration has been merged with the rkt api
+// service defaults, and it can be invoked on its own to dry-run a
+// configuration before constructing the runtime. Empty fields are not
+// validated; rkt supplies its own defaults for them.
+func (c *Config) Validate() error {
+	var errlist []error
+
+	if c.Path != "" {
+		if info, err := os.Stat(c.Path); err != nil {
+			errlist = append(errlist, fmt.Errorf("rkt binary %q: %v", c.Path, err))
+		} else if info.IsDir() || info.Mode()&0111 == 0 {
+			errlist = append(errlist, fmt.Errorf("rkt binary %q is not an executable file", c.Path))
+		}
+	}
+
+	if c.Dir != "" {
+		if info, err := os.Stat(c.Dir); err != nil {
+			errlist = append(errlist, fmt.Errorf("data directory %q: %v", c.Dir, err))
+		} else if !info.IsDir() {
+			errlist = append(errlist, fmt.Errorf("data directory %q is not a directory", c.Dir))
+		}
+	}
+
+	if c.LocalConfigDir != "" {
+		if info, err := os.Stat(c.LocalConfigDir); err != nil {
+			errlist = append(errlist, fmt.Errorf("local config directory %q: %v", c.LocalConfigDir, err))
+		} else if !info.IsDir() {
+			errlist = append(errlist, fmt.Errorf("local config directory %q is not a directory", c.LocalConfigDir))
+		} else if f, err := ioutil.TempFile(c.LocalConfigDir, ".kubelet-validate-"); err != nil {
+			// The kubelet writes network configuration under the local
+			// config directory, so it must be writable.
+			errlist = append(errlist, fmt.Errorf("local config directory %q is not writable: %v", c.LocalConfigDir, err))
+		} else {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}
+
+	// A stage1 image can be given either as a path or as an image name; only
+	// a path can be checked without asking rkt.
+	if filepath.IsAbs(c.Stage1Image) {
+		if _, err := os.Stat(c.Stage1Image); err != nil {
+			errlist = append(errlist, fmt.Errorf("stage1 image %q: %v", c.Stage1Image, err))
+		}
+	}
+
+	// The api service merge can produce a leading comma (e.g. ",image"),
+	// which rkt tolerates, so empty elements are not an error.
+	for _, opt := range strings.Split(c.InsecureOptions, ",") {
+		if opt == "" {
+			continue
+		}
+		valid := false
+		for _, value := range insecureOptionValues {
+			if opt == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			errlist = append(errlist, fmt.Errorf("invalid insecure option %q, must be one of %v", opt, insecureOptionValues))
+		}
+	}
+
+	return errors.NewAggregate(errlist)
+}
+
 // getConfig gets configurations from the rkt API service
 // and merge it with the existing config. The merge rule is
 // that the fields in the provided config will override the
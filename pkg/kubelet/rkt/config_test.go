@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rkt-config-validate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	regularFile := filepath.Join(tmpDir, "file")
+	if err := ioutil.WriteFile(regularFile, []byte("x"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stage1Image := filepath.Join(tmpDir, "stage1-fly.aci")
+	if err := ioutil.WriteFile(stage1Image, []byte("x"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	missing := filepath.Join(tmpDir, "does-not-exist")
+
+	tests := []struct {
+		config Config
+		// Substrings expected in the aggregated error; empty means the
+		// configuration is valid.
+		expectedErrors []string
+	}{
+		// Empty fields are left to rkt's own defaults.
+		{Config{}, nil},
+		{Config{Dir: tmpDir, LocalConfigDir: tmpDir, Stage1Image: stage1Image, InsecureOptions: "image,ondisk"}, nil},
+		// A stage1 image name (as opposed to a path) cannot be checked offline.
+		{Config{Stage1Image: "coreos.com/rkt/stage1-fly"}, nil},
+		// The api service merge can leave a leading comma.
+		{Config{InsecureOptions: ",image"}, nil},
+		{Config{Path: missing}, []string{"rkt binary"}},
+		{Config{Path: regularFile}, []string{"not an executable file"}},
+		{Config{Dir: missing}, []string{"data directory"}},
+		{Config{Dir: regularFile}, []string{"not a directory"}},
+		{Config{LocalConfigDir: missing}, []string{"local config directory"}},
+		{Config{LocalConfigDir: regularFile}, []string{"not a directory"}},
+		{Config{Stage1Image: missing}, []string{"stage1 image"}},
+		{Config{InsecureOptions: "image,bogus"}, []string{`invalid insecure option "bogus"`}},
+		// All problems are reported at once, not just the first.
+		{Config{Dir: missing, InsecureOptions: "bogus"}, []string{"data directory", "invalid insecure option"}},
+	}
+
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		err := tt.config.Validate()
+		if len(tt.expectedErrors) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", testCaseHint, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected error, got none", testCaseHint)
+			continue
+		}
+		for _, expected := range tt.expectedErrors {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected error to contain %q, got: %v", testCaseHint, expected, err)
+			}
+		}
+	}
+}
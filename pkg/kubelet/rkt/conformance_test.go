@@ -0,0 +1,91 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	rktapi "github.com/coreos/rkt/api/v1alpha"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/componentconfig"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	containertesting "k8s.io/kubernetes/pkg/kubelet/container/testing"
+	"k8s.io/kubernetes/pkg/kubelet/container/testsuite"
+	"k8s.io/kubernetes/pkg/kubelet/network"
+	nettest "k8s.io/kubernetes/pkg/kubelet/network/testing"
+)
+
+// rktConformanceHarness runs the shared runtime conformance suite against
+// the rkt runtime backed by the fake rkt API service. The sync scenarios
+// are not supported because rkt's sync path requires systemd and on-disk
+// unit files.
+type rktConformanceHarness struct {
+	runtime *Runtime
+	apisvc  *fakeRktInterface
+}
+
+func newRktConformanceHarness(t *testing.T) testsuite.Harness {
+	fr := newFakeRktInterface()
+	networkPlugin, _ := network.InitNetworkPlugin(
+		[]network.NetworkPlugin{},
+		"",
+		nettest.NewFakeHost(nil),
+		componentconfig.HairpinNone,
+		"10.0.0.0/8",
+		network.UseDefaultMTU)
+	r := &Runtime{
+		apisvc:         fr,
+		systemd:        newFakeSystemd(),
+		runtimeHelper:  &fakeRuntimeHelper{},
+		os:             &containertesting.FakeOS{},
+		networkPlugin:  networkPlugin,
+		requestTimeout: time.Second,
+	}
+	return &rktConformanceHarness{runtime: r, apisvc: fr}
+}
+
+func (h *rktConformanceHarness) Runtime() kubecontainer.Runtime {
+	return h.runtime
+}
+
+func (h *rktConformanceHarness) InjectRunningPod(pod *api.Pod) {
+	var appNames, imgIDs, imgNames, containerHashes []string
+	var appStates []rktapi.AppState
+	var exitCodes []int32
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		appNames = append(appNames, container.Name)
+		imgIDs = append(imgIDs, "img-id-"+container.Name)
+		imgNames = append(imgNames, container.Image)
+		containerHashes = append(containerHashes, strconv.FormatUint(kubecontainer.HashContainer(container), 10))
+		appStates = append(appStates, rktapi.AppState_APP_STATE_RUNNING)
+		exitCodes = append(exitCodes, 0)
+	}
+	h.apisvc.pods = []*rktapi.Pod{
+		makeRktPod(rktapi.PodState_POD_STATE_RUNNING,
+			"uuid-1000", string(pod.UID), pod.Name, pod.Namespace,
+			10*1e9, 20*1e9, "0",
+			appNames, imgIDs, imgNames, containerHashes,
+			appStates, exitCodes, nil),
+	}
+}
+
+func TestRuntimeConformance(t *testing.T) {
+	testsuite.RunConformance(t, newRktConformanceHarness)
+}
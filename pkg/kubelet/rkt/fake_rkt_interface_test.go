@@ -17,10 +17,14 @@ limitations under the License.
 package rkt
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/coreos/go-systemd/dbus"
 	rktapi "github.com/coreos/rkt/api/v1alpha"
@@ -108,7 +112,13 @@ func (f *fakeRktInterface) GetLogs(ctx context.Context, in *rktapi.GetLogsReques
 type fakeSystemd struct {
 	sync.Mutex
 	called           []string
+	stoppedUnits     []string
 	resetFailedUnits []string
+	killedUnits      []string
+	killedSignals    []int32
+	subStates        map[string]string
+	subStateErr      error
+	reloads          int
 	version          string
 	err              error
 }
@@ -137,7 +147,12 @@ func (f *fakeSystemd) ListUnits() ([]dbus.UnitStatus, error) {
 }
 
 func (f *fakeSystemd) StopUnit(name string, mode string, ch chan<- string) (int, error) {
-	return 0, fmt.Errorf("Not implemented")
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, "StopUnit")
+	f.stoppedUnits = append(f.stoppedUnits, name)
+	go func() { ch <- "done" }()
+	return 0, f.err
 }
 
 func (f *fakeSystemd) RestartUnit(name string, mode string, ch chan<- string) (int, error) {
@@ -150,6 +165,32 @@ func (f *fakeSystemd) ResetFailedUnit(name string) error {
 	return f.err
 }
 
+func (f *fakeSystemd) KillUnit(name string, signal int32) {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, "KillUnit")
+	f.killedUnits = append(f.killedUnits, name)
+	f.killedSignals = append(f.killedSignals, signal)
+}
+
+func (f *fakeSystemd) GetUnitSubState(name string) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, "GetUnitSubState")
+	if f.subStateErr != nil {
+		return "", f.subStateErr
+	}
+	return f.subStates[name], nil
+}
+
+func (f *fakeSystemd) Reload() error {
+	f.Lock()
+	defer f.Unlock()
+	f.called = append(f.called, "Reload")
+	f.reloads++
+	return f.err
+}
+
 // fakeRuntimeHelper implementes kubecontainer.RuntimeHelper interfaces for testing purpose.
 type fakeRuntimeHelper struct {
 	dnsServers  []string
@@ -157,6 +198,7 @@ type fakeRuntimeHelper struct {
 	hostName    string
 	hostDomain  string
 	err         error
+	podDir      string
 }
 
 func (f *fakeRuntimeHelper) GenerateRunContainerOptions(pod *api.Pod, container *api.Container, podIP string) (*kubecontainer.RunContainerOptions, error) {
@@ -172,6 +214,9 @@ func (f *fakeRuntimeHelper) GeneratePodHostNameAndDomain(pod *api.Pod) (string,
 }
 
 func (f *fakeRuntimeHelper) GetPodDir(podUID types.UID) string {
+	if f.podDir != "" {
+		return f.podDir
+	}
 	return "/poddir/" + string(podUID)
 }
 
@@ -219,3 +264,47 @@ func (f fakePodGetter) GetPodByUID(uid types.UID) (*api.Pod, bool) {
 	p, found := f.pods[uid]
 	return p, found
 }
+
+// fakeJournal mocks the journalInterface with canned journal entries
+// keyed by unit name.
+type fakeJournal struct {
+	sync.Mutex
+	entries map[string][]journalEntry
+	called  []string
+	err     error
+}
+
+func newFakeJournal() *fakeJournal {
+	return &fakeJournal{entries: make(map[string][]journalEntry)}
+}
+
+func (f *fakeJournal) ReadLogs(unit string, since time.Time, follow bool) (io.ReadCloser, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.called = append(f.called, "ReadLogs")
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var buf bytes.Buffer
+	for _, e := range f.entries[unit] {
+		if !since.IsZero() && e.Timestamp.Before(since) {
+			continue
+		}
+		buf.WriteString(e.line())
+		buf.WriteByte('\n')
+	}
+	return ioutil.NopCloser(&buf), nil
+}
+
+func (f *fakeJournal) ReadEvents(unit string) ([]journalEntry, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.called = append(f.called, "ReadEvents")
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.entries[unit], nil
+}
@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	appcschema "github.com/appc/spec/schema"
 	rktapi "github.com/coreos/rkt/api/v1alpha"
@@ -35,6 +36,8 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/credentialprovider"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/images"
+	"k8s.io/kubernetes/pkg/util/errors"
 	"k8s.io/kubernetes/pkg/util/parsers"
 )
 
@@ -53,9 +56,9 @@ func (r *Runtime) PullImage(image kubecontainer.ImageSpec, pullSecrets []api.Sec
 		return err
 	}
 
-	keyring, err := credentialprovider.MakeDockerKeyring(pullSecrets, r.dockerKeyring)
-	if err != nil {
-		return err
+	keyring, secretErrors := credentialprovider.MakeDockerKeyring(pullSecrets, r.dockerKeyring)
+	for _, secretError := range secretErrors {
+		glog.Warningf("Unable to use image pull secret: %v", secretError)
 	}
 
 	creds, ok := keyring.Lookup(repoToPull)
@@ -92,10 +95,34 @@ func (r *Runtime) IsImagePresent(image kubecontainer.ImageSpec) (bool, error) {
 }
 
 // ListImages lists all the available appc images on the machine by invoking 'rkt image list'.
+// The result is sorted by image ID.
 func (r *Runtime) ListImages() ([]kubecontainer.Image, error) {
+	return r.ListImagesWithFilter(nil)
+}
+
+// ListImagesWithFilter lists the images matching the given filter, sorted by
+// image ID so the order is deterministic. The name prefix and label filters
+// are handed to the api-service so targeted queries do not transfer every
+// image record; appc images are always named, so a dangling filter matches
+// nothing.
+func (r *Runtime) ListImagesWithFilter(filter *kubecontainer.ImageListFilter) ([]kubecontainer.Image, error) {
+	listReq := &rktapi.ListImagesRequest{}
+	if filter != nil {
+		rktFilter := &rktapi.ImageFilter{}
+		if len(filter.NamePrefix) > 0 {
+			rktFilter.Prefixes = []string{filter.NamePrefix}
+		}
+		for k, v := range filter.Labels {
+			rktFilter.Labels = append(rktFilter.Labels, &rktapi.KeyValue{Key: k, Value: v})
+		}
+		if len(rktFilter.Prefixes) > 0 || len(rktFilter.Labels) > 0 {
+			listReq.Filters = []*rktapi.ImageFilter{rktFilter}
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
 	defer cancel()
-	listResp, err := r.apisvc.ListImages(ctx, &rktapi.ListImagesRequest{})
+	listResp, err := r.apisvc.ListImages(ctx, listReq)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't list images: %v", err)
 	}
@@ -108,9 +135,66 @@ func (r *Runtime) ListImages() ([]kubecontainer.Image, error) {
 			Size:     image.Size,
 		}
 	}
+	images = kubecontainer.FilterImages(images, filter)
+	sort.Sort(kubecontainer.ImagesByID(images))
 	return images, nil
 }
 
+// GarbageCollectImages removes images that are not referenced by any pod
+// known to the api-service and that were imported earlier than policy.MinAge
+// ago, using 'rkt image rm'.
+func (r *Runtime) GarbageCollectImages(policy images.ImageGCPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
+	defer cancel()
+	listResp, err := r.apisvc.ListImages(ctx, &rktapi.ListImagesRequest{})
+	if err != nil {
+		return fmt.Errorf("couldn't list images: %v", err)
+	}
+
+	imagesInUse, err := r.imagesInUse()
+	if err != nil {
+		return err
+	}
+
+	var errlist []error
+	now := time.Now()
+	for _, image := range listResp.Images {
+		if _, ok := imagesInUse[image.Id]; ok {
+			continue
+		}
+		if now.Sub(time.Unix(image.ImportTimestamp, 0)) < policy.MinAge {
+			continue
+		}
+		glog.V(4).Infof("rkt: Removing unused image %q (%s:%s)", image.Id, image.Name, image.Version)
+		if _, err := r.cli.RunCommand(nil, "image", "rm", image.Id); err != nil {
+			errlist = append(errlist, fmt.Errorf("rkt: Failed to remove image %q: %v", image.Id, err))
+		}
+	}
+	return errors.NewAggregate(errlist)
+}
+
+// imagesInUse returns the IDs of the images referenced by the apps of the
+// pods known to the api-service, including exited pods that are not garbage
+// collected yet.
+func (r *Runtime) imagesInUse() (map[string]struct{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
+	defer cancel()
+	listResp, err := r.apisvc.ListPods(ctx, &rktapi.ListPodsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list pods: %v", err)
+	}
+
+	imagesInUse := make(map[string]struct{})
+	for _, pod := range listResp.Pods {
+		for _, app := range pod.Apps {
+			if app.Image != nil {
+				imagesInUse[app.Image.Id] = struct{}{}
+			}
+		}
+	}
+	return imagesInUse, nil
+}
+
 // RemoveImage removes an on-disk image using 'rkt image rm'.
 func (r *Runtime) RemoveImage(image kubecontainer.ImageSpec) error {
 	imageID, err := r.getImageID(image.Image)
@@ -0,0 +1,186 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/kubernetes/pkg/api"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/events"
+	utilexec "k8s.io/kubernetes/pkg/util/exec"
+)
+
+const journalctlCommand = "journalctl"
+
+// journalEntry is a single systemd journal record of a unit.
+type journalEntry struct {
+	// Timestamp is the realtime timestamp of the entry.
+	Timestamp time.Time
+	// Message is the MESSAGE field of the entry.
+	Message string
+}
+
+// line returns the entry in the same "<timestamp> MESSAGE=<message>" format
+// that the rkt api-service uses for log lines, so both log sources can be
+// parsed by processLines.
+func (e *journalEntry) line() string {
+	return fmt.Sprintf("%s MESSAGE=%s", e.Timestamp.Format(journalTimestampLayout), e.Message)
+}
+
+// journalInterface abstracts read access to the systemd journal, so the
+// journald-backed features (log fallback, event translation) can be tested
+// without a running journald.
+type journalInterface interface {
+	// ReadLogs returns a stream of journal lines of the given unit, in the
+	// "<timestamp> MESSAGE=<message>" format understood by processLines.
+	// A zero 'since' means reading from the beginning of the journal.
+	// The caller is responsible for closing the stream.
+	ReadLogs(unit string, since time.Time, follow bool) (io.ReadCloser, error)
+	// ReadEvents returns all journal entries recorded for the given unit.
+	// A unit with no entries yields an empty result, not an error.
+	ReadEvents(unit string) ([]journalEntry, error)
+}
+
+// journalctlReader implements journalInterface by invoking journalctl.
+type journalctlReader struct {
+	execer utilexec.Interface
+}
+
+func newJournalctlReader(execer utilexec.Interface) journalInterface {
+	return &journalctlReader{execer: execer}
+}
+
+// journalctlJSONEntry mirrors the fields we consume from journalctl's
+// JSON output.
+type journalctlJSONEntry struct {
+	Message           string `json:"MESSAGE"`
+	RealtimeTimestamp string `json:"__REALTIME_TIMESTAMP"`
+}
+
+// readEntries invokes journalctl for the unit and parses its JSON output.
+// Some journalctl versions exit non-zero when the unit has no entries, so
+// that outcome is translated into an empty result rather than an error.
+func (j *journalctlReader) readEntries(unit string, since time.Time) ([]journalEntry, error) {
+	args := []string{"-q", "--no-pager", "-o", "json", "-u", unit}
+	if !since.IsZero() {
+		args = append(args, "--since", since.Format("2006-01-02 15:04:05"))
+	}
+
+	out, err := j.execer.Command(journalctlCommand, args...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(out)), "no entries") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("rkt: journalctl failed for unit %q: %v: %q", unit, err, strings.TrimSpace(string(out)))
+	}
+	return parseJournalctlJSON(out)
+}
+
+// parseJournalctlJSON parses journalctl's JSON output, which is one JSON
+// object per line.
+func parseJournalctlJSON(out []byte) ([]journalEntry, error) {
+	var entries []journalEntry
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var raw journalctlJSONEntry
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("rkt: cannot parse journalctl output: %v", err)
+		}
+
+		usec, err := strconv.ParseInt(raw.RealtimeTimestamp, 10, 64)
+		if err != nil {
+			glog.Warningf("rkt: Invalid journal timestamp %q: %v", raw.RealtimeTimestamp, err)
+			continue
+		}
+		entries = append(entries, journalEntry{
+			Timestamp: time.Unix(usec/1e6, (usec%1e6)*1e3),
+			Message:   raw.Message,
+		})
+	}
+	return entries, nil
+}
+
+func (j *journalctlReader) ReadLogs(unit string, since time.Time, follow bool) (io.ReadCloser, error) {
+	// The exec abstraction used here cannot stream a long-running command,
+	// so following the journal is not supported by this implementation.
+	if follow {
+		return nil, fmt.Errorf("rkt: following the journal of unit %q is not supported", unit)
+	}
+
+	entries, err := j.readEntries(unit, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i := range entries {
+		buf.WriteString(entries[i].line())
+		buf.WriteByte('\n')
+	}
+	return ioutil.NopCloser(&buf), nil
+}
+
+func (j *journalctlReader) ReadEvents(unit string) ([]journalEntry, error) {
+	return j.readEntries(unit, time.Time{})
+}
+
+// generateJournalEvents translates noteworthy journal entries of the pod's
+// service unit into API events. Only entries that indicate abnormal process
+// death (OOM kills, core dumps) are surfaced; everything else in the journal
+// is ignored.
+func (r *Runtime) generateJournalEvents(serviceName string, runtimePod *kubecontainer.Pod) {
+	entries, err := r.journal.ReadEvents(serviceName)
+	if err != nil {
+		glog.Warningf("rkt: Failed to read journal events of unit %q: %v", serviceName, err)
+		return
+	}
+
+	// The journal is recorded per pod unit, so attach the events to the
+	// first container that still has a reference.
+	var ref *api.ObjectReference
+	for _, c := range runtimePod.Containers {
+		if cref, ok := r.containerRefManager.GetRef(c.ID); ok {
+			ref = cref
+			break
+		}
+	}
+	if ref == nil {
+		glog.Warningf("rkt: No ref for pod %q, skipping journal events", runtimePod.Name)
+		return
+	}
+
+	for _, e := range entries {
+		msg := strings.ToLower(e.Message)
+		switch {
+		case strings.Contains(msg, "oom-kill"):
+			r.recorder.Event(ref, api.EventTypeWarning, events.OOMKilling, e.Message)
+		case strings.Contains(msg, "core dumped"):
+			r.recorder.Event(ref, api.EventTypeWarning, "CoreDumped", e.Message)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package rkt
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
@@ -81,6 +82,12 @@ func (r *Runtime) GetContainerLogs(pod *api.Pod, containerID kubecontainer.Conta
 		return err
 	}
 
+	if !r.SupportsFeature(featureLogs) {
+		// The connected api-service predates the GetLogs API; fall back to
+		// reading the journal of the pod's service unit directly.
+		return r.getContainerLogsFromJournal(id.uuid, logOptions, stdout, stderr)
+	}
+
 	var since int64
 	if logOptions.SinceSeconds != nil {
 		t := unversioned.Now().Add(-time.Duration(*logOptions.SinceSeconds) * time.Second)
@@ -121,3 +128,40 @@ func (r *Runtime) GetContainerLogs(pod *api.Pod, containerID kubecontainer.Conta
 
 	return nil
 }
+
+// getContainerLogsFromJournal reads the logs of the pod's service unit from
+// the systemd journal. It is used as a fallback for pods that predate the
+// api-service's GetLogs support.
+func (r *Runtime) getContainerLogsFromJournal(uuid string, logOptions *api.PodLogOptions, stdout, stderr io.Writer) error {
+	var since time.Time
+	if logOptions.SinceSeconds != nil {
+		since = time.Now().Add(-time.Duration(*logOptions.SinceSeconds) * time.Second)
+	}
+	if logOptions.SinceTime != nil {
+		since = logOptions.SinceTime.Time
+	}
+
+	stream, err := r.journal.ReadLogs(makePodServiceFileName(uuid), since, logOptions.Follow)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// The journalctl-backed reader does not follow the journal, so the
+	// stream is finite and buffering it to apply the tail is fine.
+	var lines []string
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if logOptions.TailLines != nil && int64(len(lines)) > *logOptions.TailLines {
+		lines = lines[int64(len(lines))-*logOptions.TailLines:]
+	}
+
+	processLines(lines, logOptions, stdout, stderr)
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// renameFile is os.Rename, swappable so tests can inject failures between
+// writing the temp file and publishing it.
+var renameFile = os.Rename
+
+// cniNetConfig is the subset of the CNI network configuration that we
+// generate for rkt's net.d directory.
+type cniNetConfig struct {
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	Bridge    string        `json:"bridge,omitempty"`
+	Master    string        `json:"master,omitempty"`
+	IsGateway bool          `json:"isGateway,omitempty"`
+	IPAM      cniIPAMConfig `json:"ipam"`
+}
+
+// cniIPAMConfig is the IPAM section of a CNI network configuration.
+type cniIPAMConfig struct {
+	Type   string `json:"type"`
+	Subnet string `json:"subnet"`
+}
+
+// WriteDualStackNetConfig writes CNI network configurations for a dual-stack
+// pod network under the rkt local config directory: a "bridge" network for the
+// IPv4 range and an "ipvlan" network for the IPv6 range. The IPv6 range must
+// be a /64 or a shorter (larger) prefix, since longer prefixes cannot hold the
+// per-pod addresses.
+// TODO(yifan): Remove this once rkt can use the network config under the CNI
+// directory specified by kubelet, see the comment on defaultNetworkName.
+func (r *Runtime) WriteDualStackNetConfig(bridgeName string, cidr4, cidr6 *net.IPNet) error {
+	if bridgeName == "" {
+		return fmt.Errorf("rkt: bridge name must not be empty")
+	}
+	if cidr4 == nil || cidr4.IP.To4() == nil {
+		return fmt.Errorf("rkt: invalid IPv4 CIDR %v", cidr4)
+	}
+	if cidr6 == nil || cidr6.IP.To4() != nil || cidr6.IP.To16() == nil {
+		return fmt.Errorf("rkt: invalid IPv6 CIDR %v", cidr6)
+	}
+	if ones, bits := cidr6.Mask.Size(); bits != net.IPv6len*8 || ones > 64 {
+		return fmt.Errorf("rkt: IPv6 CIDR %v must be a /64 or shorter prefix", cidr6)
+	}
+
+	r.netConfigLock.Lock()
+	defer r.netConfigLock.Unlock()
+
+	netDir := filepath.Join(r.config.LocalConfigDir, "net.d")
+	if err := os.MkdirAll(netDir, 0755); err != nil {
+		return fmt.Errorf("rkt: cannot create network config directory %q: %v", netDir, err)
+	}
+
+	configs := []cniNetConfig{
+		{
+			Name:      defaultNetworkName,
+			Type:      "bridge",
+			Bridge:    bridgeName,
+			IsGateway: true,
+			IPAM:      cniIPAMConfig{Type: "host-local", Subnet: cidr4.String()},
+		},
+		{
+			Name:   defaultNetworkName + "-ipv6",
+			Type:   "ipvlan",
+			Master: bridgeName,
+			IPAM:   cniIPAMConfig{Type: "host-local", Subnet: cidr6.String()},
+		},
+	}
+	for _, config := range configs {
+		if err := writeNetConfigFile(netDir, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNetConfigFile atomically publishes a network config in netDir by
+// writing it to a temp file and renaming it into place, so that a kubelet
+// restart mid-write can never leave a truncated config behind for rkt to
+// choke on. The marshalled JSON is verified to round-trip before the rename,
+// and the directory is fsynced afterwards so the rename survives a crash.
+func writeNetConfigFile(netDir string, config cniNetConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("rkt: cannot marshal network config %q: %v", config.Name, err)
+	}
+	if err := json.Unmarshal(data, &cniNetConfig{}); err != nil {
+		return fmt.Errorf("rkt: network config %q does not round-trip: %v", config.Name, err)
+	}
+
+	tmpFile, err := ioutil.TempFile(netDir, config.Name+".tmp")
+	if err != nil {
+		return fmt.Errorf("rkt: cannot create temp file for network config %q: %v", config.Name, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("rkt: cannot write network config %q: %v", config.Name, err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("rkt: cannot sync network config %q: %v", config.Name, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("rkt: cannot close network config %q: %v", config.Name, err)
+	}
+
+	configPath := filepath.Join(netDir, config.Name+".conf")
+	if err := renameFile(tmpFile.Name(), configPath); err != nil {
+		return fmt.Errorf("rkt: cannot publish network config %q: %v", configPath, err)
+	}
+	return syncDir(netDir)
+}
+
+// syncDir fsyncs a directory so that a completed rename in it is durable.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("rkt: cannot open directory %q for sync: %v", dir, err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("rkt: cannot sync directory %q: %v", dir, err)
+	}
+	return nil
+}
+
+// quarantineBrokenNetConfigs scans the rkt net.d directory for network
+// configs that do not parse as JSON, e.g. files truncated by a crash of an
+// older kubelet that wrote them non-atomically, and renames them with a
+// .broken suffix so rkt ignores them instead of rejecting every pod.
+func (r *Runtime) quarantineBrokenNetConfigs() error {
+	r.netConfigLock.Lock()
+	defer r.netConfigLock.Unlock()
+
+	netDir := filepath.Join(r.config.LocalConfigDir, "net.d")
+	files, err := ioutil.ReadDir(netDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("rkt: cannot read network config directory %q: %v", netDir, err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".conf") {
+			continue
+		}
+		configPath := filepath.Join(netDir, file.Name())
+		data, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("rkt: cannot read network config %q: %v", configPath, err)
+		}
+		if json.Unmarshal(data, &cniNetConfig{}) == nil {
+			continue
+		}
+		brokenPath := configPath + ".broken"
+		glog.Warningf("rkt: Network config %q does not parse, quarantining it as %q", configPath, brokenPath)
+		if err := renameFile(configPath, brokenPath); err != nil {
+			return fmt.Errorf("rkt: cannot quarantine network config %q: %v", configPath, err)
+		}
+	}
+	return nil
+}
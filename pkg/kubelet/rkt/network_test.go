@@ -0,0 +1,180 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	_, cidr, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatalf("Cannot parse CIDR %q: %v", s, err)
+	}
+	return cidr
+}
+
+func TestWriteDualStackNetConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rkt_net_config_test")
+	if err != nil {
+		t.Fatalf("Cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	r := &Runtime{config: &Config{LocalConfigDir: tmpDir}}
+	cidr4 := mustParseCIDR(t, "10.10.0.0/16")
+	cidr6 := mustParseCIDR(t, "2001:db8::/64")
+
+	// Invalid inputs should be rejected before anything is written.
+	for i, tt := range []struct {
+		bridgeName string
+		cidr4      *net.IPNet
+		cidr6      *net.IPNet
+	}{
+		{"", cidr4, cidr6},                                    // No bridge name.
+		{"rkt0", cidr6, cidr6},                                // IPv6 range in the IPv4 slot.
+		{"rkt0", cidr4, cidr4},                                // IPv4 range in the IPv6 slot.
+		{"rkt0", cidr4, mustParseCIDR(t, "2001:db8::/96")},    // IPv6 prefix longer than /64.
+	} {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		err := r.WriteDualStackNetConfig(tt.bridgeName, tt.cidr4, tt.cidr6)
+		assert.Error(t, err, testCaseHint)
+	}
+
+	if err := r.WriteDualStackNetConfig("rkt0", cidr4, cidr6); err != nil {
+		t.Fatalf("Cannot write dual-stack network config: %v", err)
+	}
+
+	// Both generated files should parse as valid JSON and carry the
+	// expected CNI type and address family.
+	for _, tt := range []struct {
+		fileName string
+		cniType  string
+		isIPv4   bool
+	}{
+		{defaultNetworkName + ".conf", "bridge", true},
+		{defaultNetworkName + "-ipv6.conf", "ipvlan", false},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(tmpDir, "net.d", tt.fileName))
+		if err != nil {
+			t.Fatalf("Cannot read network config %q: %v", tt.fileName, err)
+		}
+		var config cniNetConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			t.Fatalf("Cannot unmarshal network config %q: %v", tt.fileName, err)
+		}
+		assert.Equal(t, tt.cniType, config.Type, tt.fileName)
+		ip, _, err := net.ParseCIDR(config.IPAM.Subnet)
+		if err != nil {
+			t.Fatalf("Cannot parse subnet %q in network config %q: %v", config.IPAM.Subnet, tt.fileName, err)
+		}
+		assert.Equal(t, tt.isIPv4, ip.To4() != nil, tt.fileName)
+	}
+}
+
+func TestWriteNetConfigFailedRename(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rkt_net_config_test")
+	if err != nil {
+		t.Fatalf("Cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	r := &Runtime{config: &Config{LocalConfigDir: tmpDir}}
+	cidr4 := mustParseCIDR(t, "10.10.0.0/16")
+	cidr6 := mustParseCIDR(t, "2001:db8::/64")
+
+	// Fail the rename to simulate a crash between writing the temp file
+	// and publishing the config.
+	defer func() { renameFile = os.Rename }()
+	renameFile = func(oldpath, newpath string) error {
+		return fmt.Errorf("injected rename failure")
+	}
+	err = r.WriteDualStackNetConfig("rkt0", cidr4, cidr6)
+	assert.Error(t, err)
+
+	// The failed write must not leave a partial config behind.
+	files, err := ioutil.ReadDir(filepath.Join(tmpDir, "net.d"))
+	if err != nil {
+		t.Fatalf("Cannot read network config directory: %v", err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".conf") {
+			t.Errorf("unexpected network config %q after failed write", file.Name())
+		}
+	}
+
+	// The next invocation should succeed and publish valid configs.
+	renameFile = os.Rename
+	if err := r.WriteDualStackNetConfig("rkt0", cidr4, cidr6); err != nil {
+		t.Fatalf("Cannot write dual-stack network config: %v", err)
+	}
+	for _, fileName := range []string{defaultNetworkName + ".conf", defaultNetworkName + "-ipv6.conf"} {
+		data, err := ioutil.ReadFile(filepath.Join(tmpDir, "net.d", fileName))
+		if err != nil {
+			t.Fatalf("Cannot read network config %q: %v", fileName, err)
+		}
+		if err := json.Unmarshal(data, &cniNetConfig{}); err != nil {
+			t.Errorf("network config %q does not parse: %v", fileName, err)
+		}
+	}
+}
+
+func TestQuarantineBrokenNetConfigs(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "rkt_net_config_test")
+	if err != nil {
+		t.Fatalf("Cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	netDir := filepath.Join(tmpDir, "net.d")
+	if err := os.MkdirAll(netDir, 0755); err != nil {
+		t.Fatalf("Cannot create network config directory: %v", err)
+	}
+	brokenPath := filepath.Join(netDir, "broken.conf")
+	validPath := filepath.Join(netDir, "valid.conf")
+	// A config truncated in the middle of a non-atomic write.
+	if err := ioutil.WriteFile(brokenPath, []byte(`{"name": "rkt.kubernetes`), 0644); err != nil {
+		t.Fatalf("Cannot write broken network config: %v", err)
+	}
+	if err := ioutil.WriteFile(validPath, []byte(`{"name": "valid", "type": "bridge", "ipam": {"type": "host-local", "subnet": "10.10.0.0/16"}}`), 0644); err != nil {
+		t.Fatalf("Cannot write valid network config: %v", err)
+	}
+
+	r := &Runtime{config: &Config{LocalConfigDir: tmpDir}}
+	if err := r.quarantineBrokenNetConfigs(); err != nil {
+		t.Fatalf("Cannot quarantine broken network configs: %v", err)
+	}
+
+	if _, err := os.Stat(brokenPath); !os.IsNotExist(err) {
+		t.Errorf("expected broken network config %q to be quarantined", brokenPath)
+	}
+	if _, err := os.Stat(brokenPath + ".broken"); err != nil {
+		t.Errorf("expected quarantined network config %q: %v", brokenPath+".broken", err)
+	}
+	if _, err := os.Stat(validPath); err != nil {
+		t.Errorf("expected valid network config %q to be left alone: %v", validPath, err)
+	}
+}
@@ -123,6 +123,10 @@ const (
 	// TODO(yifan): Reuse this const with Docker runtime.
 	minimumGracePeriodInSeconds = 2
 
+	// The interval at which the unit's state is polled while waiting for
+	// it to exit after a SIGTERM.
+	unitExitPollInterval = 100 * time.Millisecond
+
 	// The network name of the network when no-op plugin is being used.
 	// TODO(yifan): This is not ideal since today we cannot make the rkt's 'net.d' dir point to the
 	// CNI directory specified by kubelet. Once that is fixed, we can just use the network config
@@ -135,6 +139,10 @@ const (
 
 	etcHostsPath      = "/etc/hosts"
 	etcResolvConfPath = "/etc/resolv.conf"
+
+	// The name of the volume that carries the pod-level resolv.conf built
+	// from the cluster DNS settings.
+	dnsResolvConfVolumeName = "kubernetes-dns-resolv-conf"
 )
 
 // Runtime implements the Containerruntime for rkt. The implementation
@@ -143,6 +151,7 @@ const (
 type Runtime struct {
 	cli     cliInterface
 	systemd systemdInterface
+	journal journalInterface
 	// The grpc client for rkt api-service.
 	apisvcConn *grpc.ClientConn
 	apisvc     rktapi.PublicAPIClient
@@ -163,6 +172,9 @@ type Runtime struct {
 	// Network plugin.
 	networkPlugin network.NetworkPlugin
 
+	// Serializes writers to the network config directory within the process.
+	netConfigLock sync.Mutex
+
 	// If true, the "hairpin mode" flag is set on container interfaces.
 	// A false value means the kubelet just backs off from setting it,
 	// it might already be true.
@@ -212,10 +224,12 @@ func New(
 	serializeImagePulls bool,
 	imagePullQPS float32,
 	imagePullBurst int,
+	imagePullTimeout time.Duration,
+	registryPolicy *images.RegistryPolicy,
 	requestTimeout time.Duration,
 ) (*Runtime, error) {
 	// Create dbus connection.
-	systemd, err := newSystemd()
+	systemd, err := newSystemd(execer)
 	if err != nil {
 		return nil, fmt.Errorf("rkt: cannot create systemd interface: %v", err)
 	}
@@ -253,6 +267,7 @@ func New(
 	rkt := &Runtime{
 		os:                  kubecontainer.RealOS{},
 		systemd:             systemd,
+		journal:             newJournalctlReader(execer),
 		apisvcConn:          apisvcConn,
 		apisvc:              rktapi.NewPublicAPIClient(apisvcConn),
 		config:              config,
@@ -274,9 +289,21 @@ func New(
 		return nil, fmt.Errorf("rkt: cannot get config from rkt api service: %v", err)
 	}
 
+	// Validate only after the merge above, so that values inherited from the
+	// api service are covered as well.
+	if err := rkt.config.Validate(); err != nil {
+		return nil, fmt.Errorf("rkt: invalid configuration: %v", err)
+	}
+
+	// A previous kubelet may have crashed in the middle of writing a network
+	// config; move anything unparseable out of rkt's way before starting pods.
+	if err := rkt.quarantineBrokenNetConfigs(); err != nil {
+		glog.Warningf("rkt: cannot quarantine broken network configs: %v", err)
+	}
+
 	rkt.runner = lifecycle.NewHandlerRunner(httpClient, rkt, rkt)
 
-	rkt.imagePuller = images.NewImageManager(recorder, rkt, imageBackOff, serializeImagePulls, imagePullQPS, imagePullBurst)
+	rkt.imagePuller = images.NewImageManager(recorder, rkt, imageBackOff, serializeImagePulls, imagePullQPS, imagePullBurst, imagePullTimeout, registryPolicy)
 
 	if err := rkt.getVersions(); err != nil {
 		return nil, fmt.Errorf("rkt: error getting version info: %v", err)
@@ -636,6 +663,9 @@ func (r *Runtime) makePodManifest(pod *api.Pod, podIP string, pullSecrets []api.
 	manifest.Annotations.Set(*appctypes.MustACIdentifier(types.KubernetesContainerNameLabel), leaky.PodInfraContainerName)
 	manifest.Annotations.Set(*appctypes.MustACIdentifier(k8sRktRestartCountAnno), strconv.Itoa(restartCount))
 	if stage1Name, ok := pod.Annotations[k8sRktStage1NameAnno]; ok {
+		if err := validateStage1Name(stage1Name); err != nil {
+			return nil, err
+		}
 		requiresPrivileged = true
 		manifest.Annotations.Set(*appctypes.MustACIdentifier(k8sRktStage1NameAnno), stage1Name)
 	}
@@ -659,6 +689,26 @@ func copyfile(src, dst string) error {
 	return ioutil.WriteFile(dst, data, 0644)
 }
 
+// writePodResolvConf writes a pod-level resolv.conf under the pod's directory
+// using the given cluster DNS servers and search domains. It returns the path
+// of the written file so it can be mounted into the pod's apps.
+func (r *Runtime) writePodResolvConf(pod *api.Pod, dnsServers, dnsSearches []string) (string, error) {
+	var buf bytes.Buffer
+	for _, server := range dnsServers {
+		fmt.Fprintf(&buf, "nameserver %s\n", server)
+	}
+	if len(dnsSearches) > 0 {
+		fmt.Fprintf(&buf, "search %s\n", strings.Join(dnsSearches, " "))
+	}
+	fmt.Fprintf(&buf, "options %s\n", defaultDNSOption)
+
+	resolvPath := filepath.Join(r.runtimeHelper.GetPodDir(pod.UID), "etc-resolv-conf")
+	if err := ioutil.WriteFile(resolvPath, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write resolv.conf for pod %s: %v", format.Pod(pod), err)
+	}
+	return resolvPath, nil
+}
+
 // TODO(yifan): Can make rkt handle this when '--net=host'. See https://github.com/coreos/rkt/issues/2430.
 func makeHostNetworkMount(opts *kubecontainer.RunContainerOptions) (*kubecontainer.Mount, *kubecontainer.Mount, error) {
 	mountHosts, mountResolvConf := true, true
@@ -1003,14 +1053,27 @@ func (r *Runtime) generateRunCommand(pod *api.Pod, uuid, netnsName string) (stri
 		if err != nil {
 			return "", err
 		}
-		for _, server := range dnsServers {
-			runPrepared = append(runPrepared, fmt.Sprintf("--dns=%s", server))
-		}
-		for _, search := range dnsSearches {
-			runPrepared = append(runPrepared, fmt.Sprintf("--dns-search=%s", search))
-		}
-		if len(dnsServers) > 0 || len(dnsSearches) > 0 {
-			runPrepared = append(runPrepared, fmt.Sprintf("--dns-opt=%s", defaultDNSOption))
+		if pod.Spec.DNSPolicy == api.DNSClusterFirst {
+			// Write a pod-level resolv.conf with the cluster DNS settings,
+			// and mount it over /etc/resolv.conf in every app so in-cluster
+			// service discovery works regardless of the stage1's resolver
+			// setup. Pods with DNSPolicy 'Default' keep the host behavior.
+			resolvPath, err := r.writePodResolvConf(pod, dnsServers, dnsSearches)
+			if err != nil {
+				return "", err
+			}
+			runPrepared = append(runPrepared, fmt.Sprintf("--volume=%s,kind=host,source=%s,readOnly=true", dnsResolvConfVolumeName, resolvPath))
+			runPrepared = append(runPrepared, fmt.Sprintf("--mount=volume=%s,target=%s", dnsResolvConfVolumeName, etcResolvConfPath))
+		} else {
+			for _, server := range dnsServers {
+				runPrepared = append(runPrepared, fmt.Sprintf("--dns=%s", server))
+			}
+			for _, search := range dnsSearches {
+				runPrepared = append(runPrepared, fmt.Sprintf("--dns-search=%s", search))
+			}
+			if len(dnsServers) > 0 || len(dnsSearches) > 0 {
+				runPrepared = append(runPrepared, fmt.Sprintf("--dns-opt=%s", defaultDNSOption))
+			}
 		}
 
 		// TODO(yifan): host domain is not being used.
@@ -1058,6 +1121,24 @@ func (r *Runtime) cleanupPodNetwork(pod *api.Pod) error {
 	return teardownErr
 }
 
+// stage1NameInvalidChars are shell metacharacters that must never appear in a
+// stage1 name annotation. rkt is invoked without a shell, but the value is
+// user-controlled and ends up on a command line, so reject anything
+// suspicious outright.
+const stage1NameInvalidChars = "$&|;<>`\\'\" \t\n()*?[]{}!#~"
+
+// validateStage1Name rejects stage1 name annotation values that could not be
+// a valid image name or path.
+func validateStage1Name(name string) error {
+	if name == "" {
+		return fmt.Errorf("rkt: stage1 name annotation must not be empty")
+	}
+	if idx := strings.IndexAny(name, stage1NameInvalidChars); idx != -1 {
+		return fmt.Errorf("rkt: stage1 name %q contains invalid character %q", name, string(name[idx]))
+	}
+	return nil
+}
+
 func (r *Runtime) preparePodArgs(manifest *appcschema.PodManifest, manifestFileName string) []string {
 	// Order of precedence for the stage1:
 	// 1) pod annotation (stage1 name)
@@ -1629,8 +1710,9 @@ func (r *Runtime) waitPreStopHooks(pod *api.Pod, runningPod *kubecontainer.Pod)
 	}
 }
 
-// KillPod invokes 'systemctl kill' to kill the unit that runs the pod.
-// TODO: add support for gracePeriodOverride which is used in eviction scenarios
+// KillPod stops the unit that runs the pod, giving it the pod's termination
+// grace period (or the kubelet's override, e.g. during evictions) to exit
+// before escalating to SIGKILL.
 func (r *Runtime) KillPod(pod *api.Pod, runningPod kubecontainer.Pod, gracePeriodOverride *int64) error {
 	glog.V(4).Infof("Rkt is killing pod: name %q.", runningPod.Name)
 
@@ -1652,23 +1734,47 @@ func (r *Runtime) KillPod(pod *api.Pod, runningPod kubecontainer.Pod, gracePerio
 	serviceFile := serviceFilePath(serviceName)
 
 	r.generateEvents(&runningPod, "Killing", nil)
+	// Surface abnormal process deaths (OOM kills, core dumps) recorded in
+	// the unit's journal before the container refs are cleared.
+	r.generateJournalEvents(serviceName, &runningPod)
 	for _, c := range runningPod.Containers {
 		r.containerRefManager.ClearRef(c.ID)
 	}
 
-	// Since all service file have 'KillMode=mixed', the processes in
-	// the unit's cgroup will receive a SIGKILL if the normal stop timeouts.
-	reschan := make(chan string)
-	if _, err = r.systemd.StopUnit(serviceName, "replace", reschan); err != nil {
-		glog.Errorf("rkt: Failed to stop unit %q: %v", serviceName, err)
-		return err
-	}
+	// An explicit grace period override from the kubelet (e.g. eviction)
+	// takes precedence over the pod's own grace period.
+	var gracePeriod int64
+	switch {
+	case gracePeriodOverride != nil:
+		gracePeriod = *gracePeriodOverride
+	case pod != nil && pod.Spec.TerminationGracePeriodSeconds != nil && *pod.Spec.TerminationGracePeriodSeconds != 0:
+		gracePeriod = getPodTerminationGracePeriodInSecond(pod)
+	}
+
+	if gracePeriod > 0 {
+		// Give the unit the grace period to exit on its own before falling
+		// back to SIGKILL.
+		glog.V(4).Infof("rkt: Stopping unit %q with a grace period of %d seconds", serviceName, gracePeriod)
+		if err := r.stopUnitWithGrace(serviceName, time.Duration(gracePeriod)*time.Second); err != nil {
+			glog.Errorf("rkt: Failed to stop unit %q: %v", serviceName, err)
+			return err
+		}
+	} else {
+		// Since all service file have 'KillMode=mixed', the processes in
+		// the unit's cgroup will receive a SIGKILL if the normal stop timeouts.
+		glog.V(4).Infof("rkt: Stopping unit %q without a grace period", serviceName)
+		reschan := make(chan string)
+		if _, err = r.systemd.StopUnit(serviceName, "replace", reschan); err != nil {
+			glog.Errorf("rkt: Failed to stop unit %q: %v", serviceName, err)
+			return err
+		}
 
-	res := <-reschan
-	if res != "done" {
-		err := fmt.Errorf("invalid result: %s", res)
-		glog.Errorf("rkt: Failed to stop unit %q: %v", serviceName, err)
-		return err
+		res := <-reschan
+		if res != "done" {
+			err := fmt.Errorf("invalid result: %s", res)
+			glog.Errorf("rkt: Failed to stop unit %q: %v", serviceName, err)
+			return err
+		}
 	}
 
 	// Clean up networking. Use the service file to get pod details since 'pod' can be nil.
@@ -1680,6 +1786,82 @@ func (r *Runtime) KillPod(pod *api.Pod, runningPod kubecontainer.Pod, gracePerio
 	return nil
 }
 
+// StopWithGrace stops all the units that run the given pod by sending them a
+// SIGTERM first, waiting up to gracePeriod for each unit to exit, and falling
+// back to SIGKILL for units that are still running afterwards.
+func (r *Runtime) StopWithGrace(pod *api.Pod, gracePeriod time.Duration) error {
+	glog.V(4).Infof("Rkt is stopping pod %q with a grace period of %v.", format.Pod(pod), gracePeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.requestTimeout)
+	defer cancel()
+	listResp, err := r.apisvc.ListPods(ctx, &rktapi.ListPodsRequest{
+		Filters: kubernetesPodFilters(pod.UID),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't list pods: %v", err)
+	}
+
+	var errlist []error
+	for _, rktpod := range listResp.Pods {
+		if rktpod.State != rktapi.PodState_POD_STATE_RUNNING {
+			continue
+		}
+		if err := r.stopUnitWithGrace(makePodServiceFileName(rktpod.Id), gracePeriod); err != nil {
+			errlist = append(errlist, err)
+		}
+	}
+	return errors.NewAggregate(errlist)
+}
+
+// stopUnitWithGrace initiates a regular stop of the unit, polls systemd until
+// the unit has exited or the grace period has elapsed, and sends a SIGKILL if
+// the unit is still running at that point.
+func (r *Runtime) stopUnitWithGrace(serviceName string, gracePeriod time.Duration) error {
+	// Use a regular stop so the unit's ExecStop/KillMode semantics apply.
+	// The job result is drained in the background; the unit's state is what
+	// decides whether we escalate.
+	reschan := make(chan string, 1)
+	if _, err := r.systemd.StopUnit(serviceName, "replace", reschan); err != nil {
+		return err
+	}
+	go func() {
+		if res := <-reschan; res != "done" {
+			glog.Warningf("rkt: The stop job for unit %q finished with result %q", serviceName, res)
+		}
+	}()
+
+	deadline := time.Now().Add(gracePeriod)
+	for time.Now().Before(deadline) {
+		exited, err := r.unitExited(serviceName)
+		if err != nil {
+			return err
+		}
+		if exited {
+			glog.V(4).Infof("rkt: Unit %q exited within the %v grace period", serviceName, gracePeriod)
+			return nil
+		}
+		time.Sleep(unitExitPollInterval)
+	}
+
+	glog.V(2).Infof("rkt: Unit %q did not exit within %v, sending SIGKILL", serviceName, gracePeriod)
+	r.systemd.KillUnit(serviceName, int32(syscall.SIGKILL))
+	return nil
+}
+
+// unitExited returns true if the unit is no longer running, i.e. its systemd
+// SubState reports that all its processes are gone.
+func (r *Runtime) unitExited(serviceName string) (bool, error) {
+	subState, err := r.systemd.GetUnitSubState(serviceName)
+	if err != nil {
+		return false, err
+	}
+	switch subState {
+	case "exited", "dead", "failed":
+		return true, nil
+	}
+	return false, nil
+}
+
 func (r *Runtime) Type() string {
 	return RktType
 }
@@ -1696,9 +1878,26 @@ func (r *Runtime) APIVersion() (kubecontainer.Version, error) {
 	return r.versions.apiVersion, nil
 }
 
-// Status returns error if rkt is unhealthy, nil otherwise.
-func (r *Runtime) Status() error {
-	return r.checkVersion(minimumRktBinVersion, minimumRktApiVersion, minimumSystemdVersion)
+// Status returns the status of the rkt runtime. The runtime is considered
+// ready when rkt, the rkt api-service and systemd meet the minimum version
+// requirements, which is checked via the GetInfo RPC. Network readiness is
+// delegated to the network plugin.
+func (r *Runtime) Status() (*kubecontainer.RuntimeStatus, error) {
+	runtimeReady := kubecontainer.RuntimeCondition{Type: kubecontainer.RuntimeReady, Status: true}
+	if err := r.checkVersion(minimumRktBinVersion, minimumRktApiVersion, minimumSystemdVersion); err != nil {
+		runtimeReady.Status = false
+		runtimeReady.Reason = "RktNotReady"
+		runtimeReady.Message = fmt.Sprintf("rkt is not healthy: %v", err)
+	}
+	networkReady := kubecontainer.RuntimeCondition{Type: kubecontainer.NetworkReady, Status: true}
+	if err := r.networkPlugin.Status(); err != nil {
+		networkReady.Status = false
+		networkReady.Reason = "NetworkPluginNotReady"
+		networkReady.Message = fmt.Sprintf("network plugin %q is not ready: %v", r.networkPlugin.Name(), err)
+	}
+	return &kubecontainer.RuntimeStatus{
+		Conditions: []kubecontainer.RuntimeCondition{runtimeReady, networkReady},
+	}, nil
 }
 
 // SyncPod syncs the running pod to match the specified desired pod.
@@ -1857,6 +2056,17 @@ func (r *Runtime) DeleteContainer(containerID kubecontainer.ContainerID) error {
 	return fmt.Errorf("unimplemented")
 }
 
+// GetContainerStats is not supported by rkt; resource usage is collected by cAdvisor.
+func (r *Runtime) GetContainerStats(containerID kubecontainer.ContainerID) (*kubecontainer.ContainerStats, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+// UpdateContainerResources is not supported by rkt; updating the limits of a
+// running app requires restarting the pod.
+func (r *Runtime) UpdateContainerResources(containerID kubecontainer.ContainerID, resources *api.ResourceRequirements) error {
+	return fmt.Errorf("unimplemented")
+}
+
 // GarbageCollect collects the pods/containers.
 // After one GC iteration:
 // - The deleted pods will be removed.
@@ -1872,6 +2082,13 @@ func (r *Runtime) GarbageCollect(gcPolicy kubecontainer.ContainerGCPolicy, allSo
 
 	glog.V(4).Infof("rkt: Garbage collecting triggered with policy %v", gcPolicy)
 
+	// Let rkt reclaim the disk space of exited pods first. The grace period
+	// keeps pods that exited recently around for inspection, matching the
+	// minimum age the GC policy guarantees for containers.
+	if _, err := r.cli.RunCommand(nil, "gc", fmt.Sprintf("--grace-period=%v", gcPolicy.MinAge)); err != nil {
+		glog.Errorf("rkt: Failed to gc: %v", err)
+	}
+
 	// GC all inactive systemd service files and pods.
 	files, err := r.os.ReadDir(systemdServiceDir)
 	if err != nil {
@@ -1909,6 +2126,7 @@ func (r *Runtime) GarbageCollect(gcPolicy kubecontainer.ContainerGCPolicy, allSo
 	}
 
 	// Remove any orphan service files.
+	unitsChanged := false
 	for _, f := range files {
 		serviceName := f.Name()
 		if strings.HasPrefix(serviceName, kubernetesUnitPrefix) {
@@ -1916,6 +2134,12 @@ func (r *Runtime) GarbageCollect(gcPolicy kubecontainer.ContainerGCPolicy, allSo
 			if _, ok := allPods[rktUUID]; !ok {
 				glog.V(4).Infof("rkt: No rkt pod found for service file %q, will remove it", serviceName)
 
+				reschan := make(chan string)
+				if _, err := r.systemd.StopUnit(serviceName, "replace", reschan); err != nil {
+					glog.Warningf("rkt: Failed to stop the systemd service %q: %v", serviceName, err)
+				} else {
+					<-reschan
+				}
 				if err := r.systemd.ResetFailedUnit(serviceName); err != nil {
 					glog.Warningf("rkt: Failed to reset the failed systemd service %q: %v", serviceName, err)
 				}
@@ -1927,6 +2151,8 @@ func (r *Runtime) GarbageCollect(gcPolicy kubecontainer.ContainerGCPolicy, allSo
 				}
 				if err := r.os.Remove(serviceFile); err != nil {
 					errlist = append(errlist, fmt.Errorf("rkt: Failed to remove service file %q: %v", serviceFile, err))
+				} else {
+					unitsChanged = true
 				}
 			}
 		}
@@ -1952,6 +2178,15 @@ func (r *Runtime) GarbageCollect(gcPolicy kubecontainer.ContainerGCPolicy, allSo
 	for _, pod := range removeCandidates {
 		if err := r.removePod(pod.Id); err != nil {
 			errlist = append(errlist, fmt.Errorf("rkt: Failed to clean up rkt pod %q: %v", pod.Id, err))
+		} else {
+			unitsChanged = true
+		}
+	}
+
+	// Coalesce the daemon reloads for all the unit removals above into one.
+	if unitsChanged {
+		if err := r.systemd.Reload(); err != nil {
+			errlist = append(errlist, fmt.Errorf("rkt: Failed to reload systemd: %v", err))
 		}
 	}
 
@@ -2027,8 +2262,40 @@ func newRktExitError(e error) error {
 	return e
 }
 
+// Note: In rkt, the container ID is in the form of "UUID:appName", where UUID is
+// the rkt UUID, and appName is the container name.
+// Attaching relies on the experimental 'rkt attach' subcommand, so it is gated
+// on the rkt binary version.
 func (r *Runtime) AttachContainer(containerID kubecontainer.ContainerID, stdin io.Reader, stdout, stderr io.WriteCloser, tty bool, resize <-chan term.Size) error {
-	return fmt.Errorf("unimplemented")
+	glog.V(4).Infof("Rkt attaching in container.")
+
+	if !r.SupportsFeature(featureAttach) {
+		return fmt.Errorf("rkt: attach requires rkt >= %s", rktFeatureMinimums[featureAttach].minimumRktBinVersion)
+	}
+
+	id, err := parseContainerID(containerID)
+	if err != nil {
+		return err
+	}
+	command := buildCommand(r.config, attachArgs(id.uuid, id.appName, tty)...)
+	return r.runStreamingCommand(command, stdin, stdout, stderr, tty, resize)
+}
+
+// attachArgs returns the 'rkt attach' arguments that bridge the given app's
+// standard streams, or its tty when one was allocated.
+func attachArgs(uuid, appName string, tty bool) []string {
+	mode := "stdin,stdout,stderr"
+	if tty {
+		mode = "tty"
+	}
+	return []string{"attach", fmt.Sprintf("--app=%s", appName), fmt.Sprintf("--mode=%s", mode), uuid}
+}
+
+// enterArgs returns the 'rkt enter' arguments that run the given command
+// inside the app of the pod identified by the rkt UUID.
+func enterArgs(uuid, appName string, cmd []string) []string {
+	args := []string{"enter", fmt.Sprintf("--app=%s", appName), uuid}
+	return append(args, cmd...)
 }
 
 // Note: In rkt, the container ID is in the form of "UUID:appName", where UUID is
@@ -2041,10 +2308,13 @@ func (r *Runtime) ExecInContainer(containerID kubecontainer.ContainerID, cmd []s
 	if err != nil {
 		return err
 	}
-	args := []string{"enter", fmt.Sprintf("--app=%s", id.appName), id.uuid}
-	args = append(args, cmd...)
-	command := buildCommand(r.config, args...)
+	command := buildCommand(r.config, enterArgs(id.uuid, id.appName, cmd)...)
+	return r.runStreamingCommand(command, stdin, stdout, stderr, tty, resize)
+}
 
+// runStreamingCommand starts the given rkt command and bridges the provided
+// streams to it, allocating a pty when tty is requested.
+func (r *Runtime) runStreamingCommand(command *exec.Cmd, stdin io.Reader, stdout, stderr io.WriteCloser, tty bool, resize <-chan term.Size) error {
 	if tty {
 		p, err := kubecontainer.StartPty(command)
 		if err != nil {
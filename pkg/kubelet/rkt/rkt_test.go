@@ -17,12 +17,15 @@ limitations under the License.
 package rkt
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
 	"sort"
+	"syscall"
 	"testing"
 	"time"
 
@@ -33,9 +36,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/record"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	containertesting "k8s.io/kubernetes/pkg/kubelet/container/testing"
 	kubetesting "k8s.io/kubernetes/pkg/kubelet/container/testing"
+	"k8s.io/kubernetes/pkg/kubelet/images"
 	"k8s.io/kubernetes/pkg/kubelet/lifecycle"
 	"k8s.io/kubernetes/pkg/kubelet/network"
 	"k8s.io/kubernetes/pkg/kubelet/network/kubenet"
@@ -166,6 +172,76 @@ func makeRktPod(rktPodState rktapi.PodState,
 	}
 }
 
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		rktVersion         string
+		networkStatusError error
+		expectRuntimeReady bool
+		expectNetworkReady bool
+	}{
+		{
+			name:               "all-healthy",
+			rktVersion:         minimumRktBinVersion,
+			networkStatusError: nil,
+			expectRuntimeReady: true,
+			expectNetworkReady: true,
+		},
+		{
+			name:               "old-rkt-version",
+			rktVersion:         "1.12.0",
+			networkStatusError: nil,
+			expectRuntimeReady: false,
+			expectNetworkReady: true,
+		},
+		{
+			name:               "network-plugin-not-ready",
+			rktVersion:         minimumRktBinVersion,
+			networkStatusError: fmt.Errorf("no net config written"),
+			expectRuntimeReady: true,
+			expectNetworkReady: false,
+		},
+		{
+			name:               "all-degraded",
+			rktVersion:         "1.12.0",
+			networkStatusError: fmt.Errorf("no net config written"),
+			expectRuntimeReady: false,
+			expectNetworkReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		fr := newFakeRktInterface()
+		fs := newFakeSystemd()
+		ctrl := gomock.NewController(t)
+		fnp := mock_network.NewMockNetworkPlugin(ctrl)
+		fnp.EXPECT().Name().Return("someNetworkPlugin").AnyTimes()
+		fnp.EXPECT().Status().Return(tt.networkStatusError)
+		r := &Runtime{apisvc: fr, systemd: fs, networkPlugin: fnp}
+
+		fr.info = rktapi.Info{
+			RktVersion:  tt.rktVersion,
+			AppcVersion: "1.2.4",
+			ApiVersion:  minimumRktApiVersion,
+		}
+		fs.version = minimumSystemdVersion
+
+		status, err := r.Status()
+		if err != nil {
+			t.Errorf("test %q: unexpected error: %v", tt.name, err)
+		}
+		runtimeReady := status.GetRuntimeCondition(kubecontainer.RuntimeReady)
+		if runtimeReady == nil || runtimeReady.Status != tt.expectRuntimeReady {
+			t.Errorf("test %q: expected runtime ready %v, got: %v", tt.name, tt.expectRuntimeReady, runtimeReady)
+		}
+		networkReady := status.GetRuntimeCondition(kubecontainer.NetworkReady)
+		if networkReady == nil || networkReady.Status != tt.expectNetworkReady {
+			t.Errorf("test %q: expected network ready %v, got: %v", tt.name, tt.expectNetworkReady, networkReady)
+		}
+		ctrl.Finish()
+	}
+}
+
 func TestCheckVersion(t *testing.T) {
 	fr := newFakeRktInterface()
 	fs := newFakeSystemd()
@@ -261,6 +337,85 @@ func TestCheckVersion(t *testing.T) {
 	}
 }
 
+func TestSupportsFeature(t *testing.T) {
+	setVersions := func(r *Runtime, binVersion, apiVersion string) {
+		var err error
+		r.versions.binVersion, err = newRktVersion(binVersion)
+		assert.NoError(t, err)
+		r.versions.apiVersion, err = newRktVersion(apiVersion)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		binVersion string
+		apiVersion string
+		feature    rktFeature
+		expect     bool
+	}{
+		// Versions exactly at the minimums.
+		{"1.9.0", "1.0.0-alpha", featureLogs, true},
+		{"1.13.0", "1.0.0-alpha", featureAppLevelAPI, true},
+		{"0.16.0", "1.0.0-alpha", featureListViaAPI, true},
+		// Versions above the minimums.
+		{"1.14.0", "1.0.0", featureLogs, true},
+		{"1.14.0", "1.0.0", featureAppLevelAPI, true},
+		// Binary version right below the minimum.
+		{"1.8.9", "1.0.0-alpha", featureLogs, false},
+		{"1.12.9", "1.0.0-alpha", featureAppLevelAPI, false},
+		// API version below the minimum.
+		{"1.13.0", "0.9.0", featureAppLevelAPI, false},
+		// Unknown feature.
+		{"10.0.0", "10.0.0", rktFeature("warp-drive"), false},
+	}
+
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		r := &Runtime{}
+		setVersions(r, tt.binVersion, tt.apiVersion)
+		assert.Equal(t, tt.expect, r.SupportsFeature(tt.feature), testCaseHint)
+	}
+
+	// Unnegotiated versions should report unsupported.
+	r := &Runtime{}
+	assert.False(t, r.SupportsFeature(featureLogs))
+}
+
+func TestAttachContainer(t *testing.T) {
+	setVersions := func(r *Runtime, binVersion, apiVersion string) {
+		var err error
+		r.versions.binVersion, err = newRktVersion(binVersion)
+		assert.NoError(t, err)
+		r.versions.apiVersion, err = newRktVersion(apiVersion)
+		assert.NoError(t, err)
+	}
+	containerID := kubecontainer.BuildContainerID("rkt", "uuid-1000:app-1")
+
+	// An rkt binary without the attach subcommand must be rejected with an
+	// error that names the required version.
+	r := &Runtime{}
+	setVersions(r, "1.25.0", "1.0.0")
+	err := r.AttachContainer(containerID, nil, nil, nil, false, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "attach requires rkt >= 1.26.0")
+	}
+
+	// Verify the 'rkt attach' invocations that would be run against a recent
+	// enough binary.
+	config := &Config{Path: "/usr/bin/rkt"}
+	tests := []struct {
+		tty      bool
+		expected []string
+	}{
+		{false, []string{"/usr/bin/rkt", "attach", "--app=app-1", "--mode=stdin,stdout,stderr", "uuid-1000"}},
+		{true, []string{"/usr/bin/rkt", "attach", "--app=app-1", "--mode=tty", "uuid-1000"}},
+	}
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		command := buildCommand(config, attachArgs("uuid-1000", "app-1", tt.tty)...)
+		assert.Equal(t, tt.expected, command.Args, testCaseHint)
+	}
+}
+
 func TestListImages(t *testing.T) {
 	fr := newFakeRktInterface()
 	fs := newFakeSystemd()
@@ -328,6 +483,78 @@ func TestListImages(t *testing.T) {
 
 		fr.CleanCalls()
 	}
+
+	// Out-of-order api-service results are sorted by image ID.
+	fr.images = []*rktapi.Image{
+		{Id: "sha512-cc", Name: "quay.io/coreos/etcd", Version: "v3.0.0"},
+		{Id: "sha512-aa", Name: "quay.io/coreos/alpine-sh", Version: "latest"},
+		{Id: "sha512-bb", Name: "coreos.com/rkt/stage1-coreos", Version: "0.10.0"},
+	}
+	imageIDs := func(images []kubecontainer.Image) []string {
+		ids := []string{}
+		for _, image := range images {
+			ids = append(ids, image.ID)
+		}
+		return ids
+	}
+	images, err := r.ListImages()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	assert.Equal(t, []string{"sha512-aa", "sha512-bb", "sha512-cc"}, imageIDs(images))
+
+	// A name prefix filter narrows the listing.
+	images, err = r.ListImagesWithFilter(&kubecontainer.ImageListFilter{NamePrefix: "quay.io/coreos/"})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	assert.Equal(t, []string{"sha512-aa", "sha512-cc"}, imageIDs(images))
+}
+
+func TestGarbageCollectImages(t *testing.T) {
+	fr := newFakeRktInterface()
+	cli := newFakeRktCli()
+	r := &Runtime{apisvc: fr, cli: cli}
+
+	now := time.Now()
+	fr.images = []*rktapi.Image{
+		{
+			Id:              "sha512-old-unused",
+			Name:            "quay.io/coreos/alpine-sh",
+			Version:         "latest",
+			ImportTimestamp: now.Add(-2 * time.Hour).Unix(),
+		},
+		{
+			Id:              "sha512-old-used",
+			Name:            "example.com/app",
+			Version:         "1.0.0",
+			ImportTimestamp: now.Add(-2 * time.Hour).Unix(),
+		},
+		{
+			Id:              "sha512-new-unused",
+			Name:            "example.com/other",
+			Version:         "latest",
+			ImportTimestamp: now.Unix(),
+		},
+	}
+	fr.pods = []*rktapi.Pod{
+		{
+			Id: "uuid-foo",
+			Apps: []*rktapi.App{
+				{
+					Name:  "app-foo",
+					Image: &rktapi.Image{Id: "sha512-old-used"},
+				},
+			},
+		},
+	}
+
+	err := r.GarbageCollectImages(images.ImageGCPolicy{MinAge: time.Hour})
+	assert.NoError(t, err)
+
+	// Only the old image that no pod references should be removed.
+	assert.Equal(t, []string{"rkt image rm sha512-old-unused"}, cli.cmds)
+	assert.Equal(t, []string{"ListImages", "ListPods"}, fr.called)
 }
 
 func TestGetPods(t *testing.T) {
@@ -1357,7 +1584,7 @@ func TestGenerateRunCommand(t *testing.T) {
 	for i, tt := range tests {
 		testCaseHint := fmt.Sprintf("test case #%d", i)
 		rkt.networkPlugin = tt.networkPlugin
-		rkt.runtimeHelper = &fakeRuntimeHelper{tt.dnsServers, tt.dnsSearches, tt.hostName, "", tt.err}
+		rkt.runtimeHelper = &fakeRuntimeHelper{tt.dnsServers, tt.dnsSearches, tt.hostName, "", tt.err, ""}
 		rkt.execer = &utilexec.FakeExec{CommandScript: []utilexec.FakeCommandAction{func(cmd string, args ...string) utilexec.Cmd {
 			return utilexec.InitFakeCmd(&utilexec.FakeCmd{}, cmd, args...)
 		}}}
@@ -1370,6 +1597,87 @@ func TestGenerateRunCommand(t *testing.T) {
 	}
 }
 
+func TestEnterArgs(t *testing.T) {
+	tests := []struct {
+		uuid    string
+		appName string
+		cmd     []string
+
+		expect []string
+	}{
+		{
+			"rkt-uuid-foo",
+			"app-foo",
+			[]string{"/bin/sh"},
+			[]string{"enter", "--app=app-foo", "rkt-uuid-foo", "/bin/sh"},
+		},
+		{
+			"rkt-uuid-bar",
+			"app-bar",
+			[]string{"ls", "-l", "/tmp"},
+			[]string{"enter", "--app=app-bar", "rkt-uuid-bar", "ls", "-l", "/tmp"},
+		},
+	}
+
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		assert.Equal(t, tt.expect, enterArgs(tt.uuid, tt.appName, tt.cmd), testCaseHint)
+	}
+}
+
+func TestExecInContainerMalformedContainerID(t *testing.T) {
+	rkt := &Runtime{}
+	err := rkt.ExecInContainer(kubecontainer.ContainerID{Type: "rkt", ID: "no-app-name"}, []string{"/bin/sh"}, nil, nil, nil, false, nil)
+	assert.Error(t, err)
+}
+
+func TestGenerateRunCommandClusterFirstDNS(t *testing.T) {
+	tmpDir, err := utiltesting.MkTmpdir("rkt_test")
+	if err != nil {
+		t.Fatalf("error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name: "pod-name-foo",
+			UID:  "pod-uid-foo",
+		},
+		Spec: api.PodSpec{
+			DNSPolicy:  api.DNSClusterFirst,
+			Containers: []api.Container{{Name: "container-foo"}},
+		},
+	}
+
+	rkt := &Runtime{
+		os:            &kubetesting.FakeOS{},
+		networkPlugin: &network.NoopNetworkPlugin{},
+		runtimeHelper: &fakeRuntimeHelper{[]string{"10.0.0.10"}, []string{"default.svc.cluster.local", "svc.cluster.local"}, "pod-hostname-foo", "", nil, tmpDir},
+		config: &Config{
+			Path:            "/bin/rkt/rkt",
+			Stage1Image:     "/bin/rkt/stage1-coreos.aci",
+			Dir:             "/var/data",
+			InsecureOptions: "image,ondisk",
+			LocalConfigDir:  "/var/rkt/local/data",
+		},
+	}
+
+	result, err := rkt.generateRunCommand(pod, "rkt-uuid-foo", "")
+	assert.NoError(t, err)
+
+	resolvPath := filepath.Join(tmpDir, "etc-resolv-conf")
+	expect := fmt.Sprintf("/bin/rkt/rkt --insecure-options=image,ondisk --local-config=/var/rkt/local/data --dir=/var/data run-prepared --net=rkt.kubernetes.io --volume=%s,kind=host,source=%s,readOnly=true --mount=volume=%s,target=/etc/resolv.conf --hostname=pod-hostname-foo rkt-uuid-foo",
+		dnsResolvConfVolumeName, resolvPath, dnsResolvConfVolumeName)
+	assert.Equal(t, expect, result)
+
+	content, err := ioutil.ReadFile(resolvPath)
+	if err != nil {
+		t.Fatalf("error reading the pod's resolv.conf: %v", err)
+	}
+	expectedContent := "nameserver 10.0.0.10\nsearch default.svc.cluster.local svc.cluster.local\noptions ndots:5\n"
+	assert.Equal(t, expectedContent, string(content))
+}
+
 func TestLifeCycleHooks(t *testing.T) {
 	runner := lifecycle.NewFakeHandlerRunner()
 	fr := newFakeRktInterface()
@@ -1596,6 +1904,7 @@ func TestGarbageCollect(t *testing.T) {
 		pods                 []*rktapi.Pod
 		serviceFilesOnDisk   []string
 		expectedCommands     []string
+		expectedStopUnits    []string
 		expectedServiceFiles []string
 	}{
 		// All running pods, should not be gc'd.
@@ -1682,7 +1991,8 @@ func TestGarbageCollect(t *testing.T) {
 				},
 			},
 			[]string{"k8s_dead-old.service", "k8s_deleted-foo.service", "k8s_non-existing-bar.service"},
-			[]string{"rkt rm dead-old", "rkt rm deleted-foo"},
+			[]string{"rkt gc --grace-period=0s", "rkt rm dead-old", "rkt rm deleted-foo"},
+			[]string{"k8s_non-existing-bar.service"},
 			[]string{"/run/systemd/system/k8s_dead-old.service", "/run/systemd/system/k8s_deleted-foo.service", "/run/systemd/system/k8s_non-existing-bar.service"},
 		},
 		// gcPolicy.MaxContainers should be enforced.
@@ -1739,7 +2049,8 @@ func TestGarbageCollect(t *testing.T) {
 				},
 			},
 			[]string{"k8s_dead-0.service", "k8s_dead-1.service", "k8s_dead-2.service"},
-			[]string{"rkt rm dead-0", "rkt rm dead-1"},
+			[]string{"rkt gc --grace-period=0s", "rkt rm dead-0", "rkt rm dead-1"},
+			nil,
 			[]string{"/run/systemd/system/k8s_dead-0.service", "/run/systemd/system/k8s_dead-1.service"},
 		},
 	}
@@ -1786,11 +2097,22 @@ func TestGarbageCollect(t *testing.T) {
 		}
 		assert.Equal(t, expectedService, fs.resetFailedUnits, testCaseHint)
 
+		// Only orphan units are stopped; units for pods rkt still knows
+		// about are left to removePod.
+		sort.Sort(sortedStringList(tt.expectedStopUnits))
+		sort.Sort(sortedStringList(fs.stoppedUnits))
+		assert.Equal(t, tt.expectedStopUnits, fs.stoppedUnits, testCaseHint)
+
+		// All the unit removals above are coalesced into a single reload.
+		assert.Equal(t, 1, fs.reloads, testCaseHint)
+
 		// Cleanup after each test.
 		cli.Reset()
 		ctrl.Finish()
 		fakeOS.Removes = []string{}
 		fs.resetFailedUnits = []string{}
+		fs.stoppedUnits = nil
+		fs.reloads = 0
 		getter.pods = make(map[kubetypes.UID]*api.Pod)
 	}
 }
@@ -1929,3 +2251,212 @@ func TestPreparePodArgs(t *testing.T) {
 		assert.Equal(t, testCase.cmd, cmd, fmt.Sprintf("Test case #%d", i))
 	}
 }
+
+func TestValidateStage1Name(t *testing.T) {
+	testCases := []struct {
+		name        string
+		expectError bool
+	}{
+		{"coreos.com/rkt/stage1-coreos", false},
+		{"stage1-fly.aci", false},
+		{"/usr/lib/rkt/stage1-fly.aci", false},
+		{"", true},
+		{"stage1; rm -rf /", true},
+		{"stage1$(reboot)", true},
+		{"stage1 --insecure-options=all", true},
+		{"stage1\nname", true},
+		{"stage1`id`", true},
+	}
+
+	for i, testCase := range testCases {
+		err := validateStage1Name(testCase.name)
+		if testCase.expectError {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d", i))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d", i))
+		}
+	}
+}
+
+func TestStopUnitWithGrace(t *testing.T) {
+	serviceName := "k8s_0001.service"
+
+	// The unit exits within the grace period, so no signal is sent at all.
+	fs := newFakeSystemd()
+	fs.subStates = map[string]string{serviceName: "exited"}
+	r := &Runtime{systemd: fs}
+	if err := r.stopUnitWithGrace(serviceName, 5*time.Second); err != nil {
+		t.Fatalf("stopUnitWithGrace failed: %v", err)
+	}
+	assert.Equal(t, []string{serviceName}, fs.stoppedUnits)
+	assert.Empty(t, fs.killedUnits)
+
+	// The unit keeps running past the grace period, so the stop is
+	// escalated to a SIGKILL.
+	fs = newFakeSystemd()
+	fs.subStates = map[string]string{serviceName: "running"}
+	r.systemd = fs
+	if err := r.stopUnitWithGrace(serviceName, 2*unitExitPollInterval); err != nil {
+		t.Fatalf("stopUnitWithGrace failed: %v", err)
+	}
+	assert.Equal(t, []string{serviceName}, fs.stoppedUnits)
+	assert.Equal(t, []string{serviceName}, fs.killedUnits)
+	assert.Equal(t, []int32{int32(syscall.SIGKILL)}, fs.killedSignals)
+
+	// Errors from the state poll are returned without a SIGKILL, so the
+	// caller can retry.
+	fs = newFakeSystemd()
+	fs.subStateErr = fmt.Errorf("no such unit")
+	r.systemd = fs
+	if err := r.stopUnitWithGrace(serviceName, 5*time.Second); err == nil {
+		t.Errorf("expected an error when the unit state cannot be polled")
+	}
+	assert.Equal(t, []string{serviceName}, fs.stoppedUnits)
+	assert.Empty(t, fs.killedUnits)
+}
+
+func TestGetContainerLogsFromJournal(t *testing.T) {
+	fj := newFakeJournal()
+	rkt := &Runtime{journal: fj}
+
+	unit := makePodServiceFileName("uuid-foo")
+	base := time.Date(2016, 11, 3, 10, 0, 0, 0, time.UTC)
+	fj.entries[unit] = []journalEntry{
+		{Timestamp: base, Message: "hello"},
+		{Timestamp: base.Add(time.Second), Message: "world"},
+		{Timestamp: base.Add(2 * time.Second), Message: "bye"},
+	}
+
+	tail := int64(1)
+	sinceTime := unversioned.NewTime(base.Add(time.Second))
+
+	tests := []struct {
+		logOptions *api.PodLogOptions
+		expected   string
+	}{
+		// Case 0, all lines.
+		{
+			&api.PodLogOptions{},
+			"hello\nworld\nbye\n",
+		},
+		// Case 1, tail the last line.
+		{
+			&api.PodLogOptions{TailLines: &tail},
+			"bye\n",
+		},
+		// Case 2, only lines at or after the given time.
+		{
+			&api.PodLogOptions{SinceTime: &sinceTime},
+			"world\nbye\n",
+		},
+		// Case 3, with timestamps.
+		{
+			&api.PodLogOptions{Timestamps: true, TailLines: &tail},
+			"2016-11-03T10:00:02Z bye\n",
+		},
+	}
+
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+
+		var stdout, stderr bytes.Buffer
+		err := rkt.getContainerLogsFromJournal("uuid-foo", tt.logOptions, &stdout, &stderr)
+		assert.NoError(t, err, testCaseHint)
+		assert.Equal(t, tt.expected, stdout.String(), testCaseHint)
+		assert.Empty(t, stderr.String(), testCaseHint)
+	}
+}
+
+func TestGenerateJournalEvents(t *testing.T) {
+	fj := newFakeJournal()
+	recorder := record.NewFakeRecorder(10)
+	refManager := kubecontainer.NewRefManager()
+
+	pod := &api.Pod{
+		ObjectMeta: api.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: "ns-1",
+			UID:       "uid-1",
+			// GenerateContainerRef refuses pods without a self link.
+			SelfLink: "/api/v1/namespaces/ns-1/pods/pod-1",
+		},
+		Spec: api.PodSpec{
+			Containers: []api.Container{
+				{Name: "container-1"},
+			},
+		},
+	}
+	containerID := kubecontainer.BuildContainerID("rkt", "uuid-foo:container-1")
+	ref, err := kubecontainer.GenerateContainerRef(pod, &pod.Spec.Containers[0])
+	if err != nil {
+		t.Fatalf("cannot generate container ref: %v", err)
+	}
+	refManager.SetRef(containerID, ref)
+
+	unit := makePodServiceFileName("uuid-foo")
+	base := time.Date(2016, 11, 3, 10, 0, 0, 0, time.UTC)
+	fj.entries[unit] = []journalEntry{
+		{Timestamp: base, Message: "Started container-1."},
+		{Timestamp: base.Add(time.Second), Message: "Out of memory: oom-kill process 1234 (app)"},
+		{Timestamp: base.Add(2 * time.Second), Message: "container-1: main process exited (core dumped)"},
+	}
+
+	rkt := &Runtime{
+		journal:             fj,
+		recorder:            recorder,
+		containerRefManager: refManager,
+	}
+	rkt.generateJournalEvents(unit, &kubecontainer.Pod{
+		Name: "pod-1",
+		Containers: []*kubecontainer.Container{
+			{ID: containerID},
+		},
+	})
+
+	assert.Equal(t, []string{"ReadEvents"}, fj.called)
+	assert.Equal(t, 2, len(recorder.Events))
+	assert.Equal(t, "Warning OOMKilling Out of memory: oom-kill process 1234 (app)", <-recorder.Events)
+	assert.Equal(t, "Warning CoreDumped container-1: main process exited (core dumped)", <-recorder.Events)
+}
+
+func TestJournalctlReader(t *testing.T) {
+	fcmd := utilexec.FakeCmd{
+		CombinedOutputScript: []utilexec.FakeCombinedOutputAction{
+			// Case 0, a unit without entries is an empty journal, not an error.
+			func() ([]byte, error) { return []byte("-- No entries --\n"), &utilexec.FakeExitError{Status: 1} },
+			// Case 1, any other failure is a real error.
+			func() ([]byte, error) { return []byte("Failed to access the journal"), &utilexec.FakeExitError{Status: 1} },
+			// Case 2, entries are parsed from the JSON output.
+			func() ([]byte, error) {
+				return []byte(`{"MESSAGE":"hello","__REALTIME_TIMESTAMP":"1478167200000000"}` + "\n" +
+					`{"MESSAGE":"world","__REALTIME_TIMESTAMP":"1478167201000000"}` + "\n"), nil
+			},
+		},
+	}
+	fexec := utilexec.FakeExec{
+		CommandScript: []utilexec.FakeCommandAction{
+			func(cmd string, args ...string) utilexec.Cmd { return utilexec.InitFakeCmd(&fcmd, cmd, args...) },
+			func(cmd string, args ...string) utilexec.Cmd { return utilexec.InitFakeCmd(&fcmd, cmd, args...) },
+			func(cmd string, args ...string) utilexec.Cmd { return utilexec.InitFakeCmd(&fcmd, cmd, args...) },
+		},
+	}
+	reader := newJournalctlReader(&fexec)
+
+	entries, err := reader.ReadEvents("foo.service")
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = reader.ReadEvents("foo.service")
+	assert.Error(t, err)
+
+	entries, err = reader.ReadEvents("foo.service")
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, "hello", entries[0].Message)
+		assert.Equal(t, time.Unix(1478167200, 0), entries[0].Timestamp)
+		assert.Equal(t, "world", entries[1].Message)
+	}
+
+	// Following the journal is not supported by the journalctl reader.
+	_, err = reader.ReadLogs("foo.service", time.Time{}, true)
+	assert.Error(t, err)
+}
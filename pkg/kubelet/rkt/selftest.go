@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"fmt"
+	"time"
+
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+)
+
+// selfTestCheckTimeout bounds each individual self-test check, so that an
+// unresponsive rkt api service cannot stall kubelet startup indefinitely.
+const selfTestCheckTimeout = 30 * time.Second
+
+// SelfTest validates the node prerequisites the rkt runtime depends on and
+// returns one result per failed check. It is run once at kubelet startup, and
+// on demand via the kubelet's debug endpoint, so that misconfigured nodes fail
+// visibly instead of when the first pod exercises the broken path.
+func (r *Runtime) SelfTest() []kubecontainer.SelfTestCheckResult {
+	checks := []struct {
+		name     string
+		critical bool
+		check    func() error
+	}{
+		{"rkt-version", true, func() error {
+			return r.checkVersion(minimumRktBinVersion, minimumRktApiVersion, minimumSystemdVersion)
+		}},
+		{"network-plugin", true, func() error { return r.networkPlugin.Status() }},
+		// socat and nsenter are only needed for port-forwarding, so their
+		// absence does not make the node unable to run pods.
+		{"socat", false, func() error { _, err := r.execer.LookPath("socat"); return err }},
+		{"nsenter", false, func() error { _, err := r.execer.LookPath("nsenter"); return err }},
+		{"stage1-image", false, r.checkStage1Image},
+	}
+	var failed []kubecontainer.SelfTestCheckResult
+	for _, c := range checks {
+		if result := kubecontainer.RunSelfTestCheck(c.name, c.critical, selfTestCheckTimeout, c.check); result != nil {
+			failed = append(failed, *result)
+		}
+	}
+	return failed
+}
+
+// checkStage1Image verifies that the kubelet's configured stage1 image is
+// present on the node. When no stage1 image is configured, rkt falls back to
+// the stage1 baked into the binary, so there is nothing to check.
+func (r *Runtime) checkStage1Image() error {
+	if r.config.Stage1Image == "" {
+		return nil
+	}
+	present, err := r.IsImagePresent(kubecontainer.ImageSpec{Image: r.config.Stage1Image})
+	if err != nil {
+		return err
+	}
+	if !present {
+		return fmt.Errorf("stage1 image %q is not present on the node", r.config.Stage1Image)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"fmt"
+	"testing"
+
+	rktapi "github.com/coreos/rkt/api/v1alpha"
+	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/network"
+	utilexec "k8s.io/kubernetes/pkg/util/exec"
+)
+
+func TestSelfTestReportsFailedChecks(t *testing.T) {
+	fr := newFakeRktInterface()
+	fs := newFakeSystemd()
+	r := &Runtime{
+		apisvc:  fr,
+		systemd: fs,
+		config:  &Config{Stage1Image: "example.com/rkt/stage1-coreos"},
+		execer: &utilexec.FakeExec{LookPathFunc: func(file string) (string, error) {
+			if file == "socat" {
+				return "", fmt.Errorf("exec: %q: executable file not found in $PATH", file)
+			}
+			return "/usr/bin/" + file, nil
+		}},
+		networkPlugin: &network.NoopNetworkPlugin{},
+	}
+	// The binary version is below minimumRktBinVersion, and the stage1 image
+	// is not present (the fake rkt interface has no images).
+	fr.info = rktapi.Info{
+		RktVersion:  "1.0.0",
+		AppcVersion: "1.2.4",
+		ApiVersion:  minimumRktApiVersion,
+	}
+	fs.version = minimumSystemdVersion
+
+	failures := make(map[string]kubecontainer.SelfTestCheckResult)
+	for _, result := range r.SelfTest() {
+		failures[result.Name] = result
+	}
+	versionFailure, ok := failures["rkt-version"]
+	if !ok {
+		t.Errorf("expected an rkt-version failure, got %v", failures)
+	} else if !versionFailure.Critical {
+		t.Errorf("expected the rkt-version failure to be critical, got %v", versionFailure)
+	}
+	socatFailure, ok := failures["socat"]
+	if !ok {
+		t.Errorf("expected a socat failure, got %v", failures)
+	} else if socatFailure.Critical {
+		t.Errorf("expected the socat failure to be non-critical, got %v", socatFailure)
+	}
+	stage1Failure, ok := failures["stage1-image"]
+	if !ok {
+		t.Errorf("expected a stage1-image failure, got %v", failures)
+	} else if stage1Failure.Critical {
+		t.Errorf("expected the stage1-image failure to be non-critical, got %v", stage1Failure)
+	}
+	for _, name := range []string{"nsenter", "network-plugin"} {
+		if _, ok := failures[name]; ok {
+			t.Errorf("did not expect a %s failure, got %v", name, failures[name])
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/coreos/go-systemd/dbus"
+	utilexec "k8s.io/kubernetes/pkg/util/exec"
 )
 
 // systemdVersion is a type wraps the int to implement kubecontainer.Version interface.
@@ -61,32 +62,84 @@ type systemdInterface interface {
 	RestartUnit(name string, mode string, ch chan<- string) (int, error)
 	// ResetFailedUnit resets the "failed" state of a specific unit.
 	ResetFailedUnit(name string) error
+	// Reload instructs systemd to scan for and reload unit files.
+	Reload() error
+	// KillUnit sends a signal to the processes of the unit.
+	KillUnit(name string, signal int32)
+	// GetUnitSubState returns the systemd SubState of the unit, e.g.
+	// "running", "exited" or "dead".
+	GetUnitSubState(name string) (string, error)
 }
 
 // systemd implements the systemdInterface using dbus and systemctl.
 // All the functions other then Version() are already implemented by go-systemd/dbus.
 type systemd struct {
 	*dbus.Conn
+	execer utilexec.Interface
 }
 
 // newSystemd creates a systemd object that implements systemdInterface.
-func newSystemd() (*systemd, error) {
+func newSystemd(execer utilexec.Interface) (*systemd, error) {
 	dbusConn, err := dbus.New()
 	if err != nil {
 		return nil, err
 	}
-	return &systemd{dbusConn}, nil
+	return &systemd{dbusConn, execer}, nil
 }
 
-// Version returns the version of the systemd.
+// Version returns the version of the systemd running on the host. It tries
+// 'systemctl --version' first, then 'machinectl --version' for hosts that do
+// not ship systemctl in PATH, and finally falls back to the D-Bus systemd
+// manager property.
 func (s *systemd) Version() (systemdVersion, error) {
-	versionStr, err := s.Conn.GetManagerProperty("Version")
-	if err != nil {
-		return -1, err
+	var lastErr error
+	for _, binary := range []string{"systemctl", "machinectl"} {
+		output, err := s.execer.Command(binary, "--version").CombinedOutput()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		version, err := parseSystemdVersion(string(output))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return version, nil
+	}
+	if s.Conn != nil {
+		versionStr, err := s.Conn.GetManagerProperty("Version")
+		if err != nil {
+			return -1, err
+		}
+		return parseSystemdVersion(strings.Trim(versionStr, "\""))
 	}
-	result, err := strconv.Atoi(strings.Trim(versionStr, "\""))
+	return -1, fmt.Errorf("rkt: cannot determine systemd version: %v", lastErr)
+}
+
+// GetUnitSubState returns the SubState property of the named unit from the
+// D-Bus systemd manager.
+func (s *systemd) GetUnitSubState(name string) (string, error) {
+	props, err := s.Conn.GetUnitProperties(name)
 	if err != nil {
-		return -1, err
+		return "", err
+	}
+	subState, ok := props["SubState"].(string)
+	if !ok {
+		return "", fmt.Errorf("rkt: unit %q has no SubState property", name)
+	}
+	return subState, nil
+}
+
+// parseSystemdVersion extracts the systemd version from the output of
+// 'systemctl --version' or 'machinectl --version'. Only the first line is
+// considered, and the first integer token wins, so vendor suffixes such as
+// "systemd 219 (219-57.el7)" do not break the parse.
+func parseSystemdVersion(output string) (systemdVersion, error) {
+	firstLine := strings.SplitN(output, "\n", 2)[0]
+	for _, field := range strings.Fields(firstLine) {
+		if version, err := strconv.Atoi(field); err == nil {
+			return systemdVersion(version), nil
+		}
 	}
-	return systemdVersion(result), nil
+	return -1, fmt.Errorf("rkt: cannot parse systemd version from %q", firstLine)
 }
@@ -0,0 +1,98 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rkt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	utilexec "k8s.io/kubernetes/pkg/util/exec"
+)
+
+func TestParseSystemdVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		version systemdVersion
+		err     bool
+	}{
+		// Fedora 24.
+		{"systemd 231\n+PAM +AUDIT +SELINUX +IMA -APPARMOR +SMACK", 231, false},
+		// CentOS 7 with a vendor suffix on the first line.
+		{"systemd 219 (219-57.el7)\n+PAM +AUDIT +SELINUX +IMA", 219, false},
+		// Debian stretch.
+		{"systemd 232\n+PAM +AUDIT +SELINUX +IMA +APPARMOR", 232, false},
+		// Output without a version.
+		{"not systemd at all", -1, true},
+		{"", -1, true},
+	}
+
+	for i, tt := range tests {
+		testCaseHint := fmt.Sprintf("test case #%d", i)
+		version, err := parseSystemdVersion(tt.output)
+		assert.Equal(t, tt.err, err != nil, testCaseHint)
+		assert.Equal(t, tt.version, version, testCaseHint)
+	}
+}
+
+// scriptedCommand returns a FakeCommandAction whose command produces the
+// given combined output and error.
+func scriptedCommand(output string, err error) utilexec.FakeCommandAction {
+	return func(cmd string, args ...string) utilexec.Cmd {
+		return utilexec.InitFakeCmd(&utilexec.FakeCmd{
+			CombinedOutputScript: []utilexec.FakeCombinedOutputAction{
+				func() ([]byte, error) { return []byte(output), err },
+			},
+		}, cmd, args...)
+	}
+}
+
+func TestSystemdVersionFromSystemctl(t *testing.T) {
+	execer := &utilexec.FakeExec{CommandScript: []utilexec.FakeCommandAction{
+		scriptedCommand("systemd 219 (219-57.el7)\n+PAM +AUDIT", nil),
+	}}
+	s := &systemd{execer: execer}
+
+	version, err := s.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, systemdVersion(219), version)
+	assert.Equal(t, 1, execer.CommandCalls)
+}
+
+func TestSystemdVersionFallsBackToMachinectl(t *testing.T) {
+	execer := &utilexec.FakeExec{CommandScript: []utilexec.FakeCommandAction{
+		scriptedCommand("", fmt.Errorf("exec: \"systemctl\": executable file not found in $PATH")),
+		scriptedCommand("systemd 231\n+PAM +AUDIT +SELINUX", nil),
+	}}
+	s := &systemd{execer: execer}
+
+	version, err := s.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, systemdVersion(231), version)
+	assert.Equal(t, 2, execer.CommandCalls)
+}
+
+func TestSystemdVersionAllProbesFail(t *testing.T) {
+	execer := &utilexec.FakeExec{CommandScript: []utilexec.FakeCommandAction{
+		scriptedCommand("", fmt.Errorf("exec: \"systemctl\": executable file not found in $PATH")),
+		scriptedCommand("", fmt.Errorf("exec: \"machinectl\": executable file not found in $PATH")),
+	}}
+	s := &systemd{execer: execer}
+
+	_, err := s.Version()
+	assert.Error(t, err)
+}
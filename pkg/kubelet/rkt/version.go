@@ -95,6 +95,63 @@ func (r *Runtime) getVersions() error {
 	return nil
 }
 
+// rktFeature names an optional capability of rkt or its api-service that
+// the kubelet can degrade gracefully without.
+type rktFeature string
+
+const (
+	// featureLogs indicates that the api-service can stream journal logs
+	// via GetLogs.
+	featureLogs rktFeature = "logs"
+	// featureAppLevelAPI indicates support for per-app operations such as
+	// app-level kill.
+	featureAppLevelAPI rktFeature = "app-level-api"
+	// featureListViaAPI indicates that pods can be listed through the
+	// api-service instead of parsing unit files.
+	featureListViaAPI rktFeature = "list-via-api"
+	// featureAttach indicates that the rkt binary ships the experimental
+	// 'rkt attach' subcommand.
+	featureAttach rktFeature = "attach"
+)
+
+// rktFeatureMinimums maps each optional feature to the minimum rkt binary
+// and api-service versions that provide it.
+var rktFeatureMinimums = map[rktFeature]struct {
+	minimumRktBinVersion string
+	minimumRktApiVersion string
+}{
+	featureLogs:        {"1.9.0", "1.0.0-alpha"},
+	featureAppLevelAPI: {"1.13.0", "1.0.0-alpha"},
+	featureListViaAPI:  {"0.16.0", "1.0.0-alpha"},
+	featureAttach:      {"1.26.0", "1.0.0-alpha"},
+}
+
+// SupportsFeature reports whether the rkt binary and api-service the runtime
+// is connected to are recent enough to provide the given optional feature.
+// Callers are expected to fall back rather than fail when this returns false.
+// Unknown features are reported as unsupported.
+func (r *Runtime) SupportsFeature(feature rktFeature) bool {
+	minimums, ok := rktFeatureMinimums[feature]
+	if !ok {
+		return false
+	}
+
+	r.versions.RLock()
+	defer r.versions.RUnlock()
+
+	if r.versions.binVersion.Version == nil || r.versions.apiVersion.Version == nil {
+		// The versions are not negotiated yet.
+		return false
+	}
+	if result, err := r.versions.binVersion.Compare(minimums.minimumRktBinVersion); err != nil || result < 0 {
+		return false
+	}
+	if result, err := r.versions.apiVersion.Compare(minimums.minimumRktApiVersion); err != nil || result < 0 {
+		return false
+	}
+	return true
+}
+
 // checkVersion tests whether the rkt/systemd/rkt-api-service that meet the version requirement.
 // If all version requirements are met, it returns nil.
 func (r *Runtime) checkVersion(minimumRktBinVersion, minimumRktApiVersion, minimumSystemdVersion string) error {
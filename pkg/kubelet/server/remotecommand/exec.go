@@ -25,6 +25,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	apierrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubelet/dockertools"
 	"k8s.io/kubernetes/pkg/types"
 	utilexec "k8s.io/kubernetes/pkg/util/exec"
 	"k8s.io/kubernetes/pkg/util/runtime"
@@ -73,6 +74,10 @@ func ServeExec(w http.ResponseWriter, req *http.Request, executor Executor, podN
 				},
 				Message: fmt.Sprintf("command terminated with non-zero exit code: %v", exitErr),
 			}})
+		} else if dockertools.IsNoPodInfraContainer(err) {
+			// The pod's sandbox is gone; this is a user-visible not-found
+			// condition, not an internal error.
+			ctx.writeStatus(apierrors.NewNotFound(api.Resource("pods"), podName))
 		} else {
 			err = fmt.Errorf("error executing command in container: %v", err)
 			runtime.HandleError(err)
@@ -47,6 +47,7 @@ import (
 	"k8s.io/kubernetes/pkg/httplog"
 	"k8s.io/kubernetes/pkg/kubelet/cm"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
+	"k8s.io/kubernetes/pkg/kubelet/dockertools"
 	"k8s.io/kubernetes/pkg/kubelet/server/portforward"
 	"k8s.io/kubernetes/pkg/kubelet/server/remotecommand"
 	"k8s.io/kubernetes/pkg/kubelet/server/stats"
@@ -176,6 +177,7 @@ type HostInterface interface {
 	RootFsInfo() (cadvisorapiv2.FsInfo, error)
 	ListVolumesForPod(podUID types.UID) (map[string]volume.Volume, bool)
 	PLEGHealthCheck() (bool, error)
+	RuntimeSelfTest() []kubecontainer.SelfTestCheckResult
 }
 
 // NewServer initializes and configures a kubelet.Server object to handle HTTP requests.
@@ -386,6 +388,14 @@ func (s *Server) InstallDebuggingHandlers() {
 		To(s.getRunningPods).
 		Operation("getRunningPods"))
 	s.restfulCont.Add(ws)
+
+	ws = new(restful.WebService)
+	ws.
+		Path("/selftest")
+	ws.Route(ws.GET("").
+		To(s.getRuntimeSelfTest).
+		Operation("getRuntimeSelfTest"))
+	s.restfulCont.Add(ws)
 }
 
 type httpHandler struct {
@@ -545,6 +555,25 @@ func (s *Server) getRunningPods(request *restful.Request, response *restful.Resp
 	writeJsonResponse(response, data)
 }
 
+// getRuntimeSelfTest re-runs the container runtime's startup self-test and
+// reports the failed checks as plain text, one per line, or "ok" if every
+// check passed (or the runtime has no self-test).
+func (s *Server) getRuntimeSelfTest(request *restful.Request, response *restful.Response) {
+	results := s.host.RuntimeSelfTest()
+	response.Header().Set("Content-Type", "text/plain")
+	if len(results) == 0 {
+		response.Write([]byte("ok\n"))
+		return
+	}
+	for _, result := range results {
+		severity := "warning"
+		if result.Critical {
+			severity = "critical"
+		}
+		fmt.Fprintf(response, "%s: check %q failed: %v\n", severity, result.Name, result.Error)
+	}
+}
+
 // getLogs handles logs requests against the Kubelet.
 func (s *Server) getLogs(request *restful.Request, response *restful.Response) {
 	s.host.ServeLogs(response, request.Request)
@@ -893,7 +922,11 @@ func (h *portForwardStreamHandler) portForward(p *portForwardStreamPair) {
 
 	if err != nil {
 		msg := fmt.Errorf("error forwarding port %d to pod %s, uid %v: %v", port, h.pod, h.uid, err)
-		utilruntime.HandleError(msg)
+		if !dockertools.IsNoPodInfraContainer(err) {
+			// A missing pod infra container is a user-visible not-found
+			// condition, not an internal error worth logging server-side.
+			utilruntime.HandleError(msg)
+		}
 		fmt.Fprint(p.errorStream, msg.Error())
 	}
 }
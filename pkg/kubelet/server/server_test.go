@@ -138,6 +138,8 @@ func (fk *fakeKubelet) StreamingConnectionIdleTimeout() time.Duration {
 
 func (fk *fakeKubelet) PLEGHealthCheck() (bool, error) { return fk.plegHealth, nil }
 
+func (fk *fakeKubelet) RuntimeSelfTest() []kubecontainer.SelfTestCheckResult { return nil }
+
 // Unused functions
 func (_ *fakeKubelet) GetContainerInfoV2(_ string, _ cadvisorapiv2.RequestOptions) (map[string]cadvisorapiv2.ContainerInfo, error) {
 	return nil, nil
@@ -210,7 +212,7 @@ func newServerTest() *serverTestFramework {
 	}
 	server := NewServer(
 		fw.fakeKubelet,
-		stats.NewResourceAnalyzer(fw.fakeKubelet, time.Minute, &kubecontainertesting.FakeRuntime{}),
+		stats.NewResourceAnalyzer(fw.fakeKubelet, time.Minute, &kubecontainertesting.FakeRuntime{}, nil),
 		fw.fakeAuth,
 		true,
 		&kubecontainertesting.Mock{})
@@ -21,6 +21,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
 	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/wait"
 
@@ -42,16 +43,22 @@ type fsResourceAnalyzer struct {
 	calcPeriod        time.Duration
 	cachedVolumeStats atomic.Value
 	startOnce         sync.Once
+	usageCollector    *volumeUsageCollector
 }
 
 var _ fsResourceAnalyzerInterface = &fsResourceAnalyzer{}
 
-// newFsResourceAnalyzer returns a new fsResourceAnalyzer implementation
-func newFsResourceAnalyzer(statsProvider StatsProvider, calcVolumePeriod time.Duration) *fsResourceAnalyzer {
+// newFsResourceAnalyzer returns a new fsResourceAnalyzer implementation.
+// podVolumesDir may be nil, in which case the du-style volume usage
+// collection is disabled and only volume plugin metrics are reported.
+func newFsResourceAnalyzer(statsProvider StatsProvider, calcVolumePeriod time.Duration, podVolumesDir PodVolumesDirFunc) *fsResourceAnalyzer {
 	r := &fsResourceAnalyzer{
 		statsProvider: statsProvider,
 		calcPeriod:    calcVolumePeriod,
 	}
+	if podVolumesDir != nil {
+		r.usageCollector = newVolumeUsageCollector(statsProvider, podVolumesDir)
+	}
 	r.cachedVolumeStats.Store(make(Cache))
 	return r
 }
@@ -65,6 +72,9 @@ func (s *fsResourceAnalyzer) Start() {
 		}
 		glog.Info("Starting FS ResourceAnalyzer")
 		go wait.Forever(func() { s.updateCachedPodVolumeStats() }, s.calcPeriod)
+		if s.usageCollector != nil {
+			go wait.Forever(s.usageCollector.collect, s.calcPeriod)
+		}
 	})
 }
 
@@ -96,12 +106,35 @@ func (s *fsResourceAnalyzer) updateCachedPodVolumeStats() {
 // GetPodVolumeStats returns the PodVolumeStats for a given pod.  Results are looked up from a cache that
 // is eagerly populated in the background, and never calculated on the fly.
 func (s *fsResourceAnalyzer) GetPodVolumeStats(uid types.UID) (PodVolumeStats, bool) {
+	result := PodVolumeStats{}
+	found := false
 	cache := s.cachedVolumeStats.Load().(Cache)
-	if statCalc, found := cache[uid]; !found {
+	if statCalc, ok := cache[uid]; ok {
 		// TODO: Differentiate between stats being empty
 		// See issue #20679
-		return PodVolumeStats{}, false
-	} else {
-		return statCalc.GetLatest()
+		result, found = statCalc.GetLatest()
+	}
+	if s.usageCollector == nil {
+		return result, found
+	}
+	// Add walked usage for volumes the plugin metrics did not cover (e.g.
+	// emptyDir), so they still surface in the summary.
+	walked, ok := s.usageCollector.GetPodVolumeStats(uid)
+	if !ok {
+		return result, found
+	}
+	// Copy before appending: the cached slice is shared with other readers.
+	merged := make([]stats.VolumeStats, 0, len(result.Volumes)+len(walked))
+	merged = append(merged, result.Volumes...)
+	reported := make(map[string]bool)
+	for _, v := range result.Volumes {
+		reported[v.Name] = true
+	}
+	for _, v := range walked {
+		if !reported[v.Name] {
+			merged = append(merged, v)
+		}
 	}
+	result.Volumes = merged
+	return result, true
 }
@@ -38,9 +38,11 @@ type resourceAnalyzer struct {
 
 var _ ResourceAnalyzer = &resourceAnalyzer{}
 
-// NewResourceAnalyzer returns a new ResourceAnalyzer
-func NewResourceAnalyzer(statsProvider StatsProvider, calVolumeFrequency time.Duration, runtime container.Runtime) ResourceAnalyzer {
-	fsAnalyzer := newFsResourceAnalyzer(statsProvider, calVolumeFrequency)
+// NewResourceAnalyzer returns a new ResourceAnalyzer. podVolumesDir locates
+// the volume directories of a pod for du-style usage collection; it may be
+// nil to disable that collection.
+func NewResourceAnalyzer(statsProvider StatsProvider, calVolumeFrequency time.Duration, runtime container.Runtime, podVolumesDir PodVolumesDirFunc) ResourceAnalyzer {
+	fsAnalyzer := newFsResourceAnalyzer(statsProvider, calVolumeFrequency, podVolumesDir)
 	summaryProvider := NewSummaryProvider(statsProvider, fsAnalyzer, runtime)
 	return &resourceAnalyzer{fsAnalyzer, summaryProvider}
 }
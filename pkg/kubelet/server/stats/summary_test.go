@@ -152,7 +152,7 @@ func TestBuildSummary(t *testing.T) {
 	}
 
 	sb := &summaryBuilder{
-		newFsResourceAnalyzer(&MockStatsProvider{}, time.Minute*5), &node, nodeConfig, rootfs, imagefs, container.ImageStats{}, infos}
+		newFsResourceAnalyzer(&MockStatsProvider{}, time.Minute*5, nil), &node, nodeConfig, rootfs, imagefs, container.ImageStats{}, infos}
 	summary, err := sb.build()
 
 	assert.NoError(t, err)
@@ -0,0 +1,242 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	"k8s.io/kubernetes/pkg/types"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// maxConcurrentVolumeWalks bounds how many volume directory walks may run
+	// at once, so usage collection cannot saturate the disk.
+	maxConcurrentVolumeWalks = 2
+	// volumeWalkTimeout bounds a single volume directory walk. A walk over
+	// the deadline is abandoned and the volume keeps its cached usage.
+	volumeWalkTimeout = time.Minute
+)
+
+// errVolumeWalkTimeout aborts a walk that exceeded its deadline.
+var errVolumeWalkTimeout = errors.New("volume walk timed out")
+
+// PodVolumesDirFunc returns the directory holding the volumes of the given
+// pod, with one subdirectory per volume plugin and below that one directory
+// per volume.
+type PodVolumesDirFunc func(podUID types.UID) string
+
+// volumePath identifies one volume directory of a pod.
+type volumePath struct {
+	name string
+	path string
+}
+
+// volumeUsageCollector periodically computes bytes used and inodes for every
+// pod volume with a du-style walk of the volume directory. Unlike the
+// volumeStatCalculator it does not depend on volume plugins supporting
+// metrics, so it covers emptyDir volumes filling the node disk. Results are
+// cached; readers never trigger a walk.
+type volumeUsageCollector struct {
+	statsProvider StatsProvider
+	podVolumesDir PodVolumesDirFunc
+	walkTimeout   time.Duration
+	// Bounds the number of walks running at once.
+	walkSem chan struct{}
+
+	lock sync.Mutex
+	// Volume directories with a walk currently in flight.
+	walking map[string]bool
+	// Latest usage per volume directory path.
+	usage map[string]stats.VolumeStats
+	// Volume directories of each pod, as of the latest collection.
+	podVolumes map[types.UID][]volumePath
+}
+
+func newVolumeUsageCollector(statsProvider StatsProvider, podVolumesDir PodVolumesDirFunc) *volumeUsageCollector {
+	return &volumeUsageCollector{
+		statsProvider: statsProvider,
+		podVolumesDir: podVolumesDir,
+		walkTimeout:   volumeWalkTimeout,
+		walkSem:       make(chan struct{}, maxConcurrentVolumeWalks),
+		walking:       make(map[string]bool),
+		usage:         make(map[string]stats.VolumeStats),
+		podVolumes:    make(map[types.UID][]volumePath),
+	}
+}
+
+// collect refreshes the usage of every volume of every pod known to the
+// kubelet. Volumes are walked concurrently, bounded by walkSem; a volume
+// whose previous walk has not finished yet is skipped and keeps its cached
+// value.
+func (c *volumeUsageCollector) collect() {
+	podVolumes := make(map[types.UID][]volumePath)
+	var wg sync.WaitGroup
+	for _, pod := range c.statsProvider.GetPods() {
+		volumes, err := c.findVolumeDirs(pod.UID)
+		if err != nil {
+			glog.V(4).Infof("Cannot list volume directories of pod %q: %v", pod.UID, err)
+			continue
+		}
+		podVolumes[pod.UID] = volumes
+		for _, v := range volumes {
+			if !c.markWalking(v.path) {
+				glog.V(4).Infof("Previous walk of volume %q has not finished, skipping", v.path)
+				continue
+			}
+			wg.Add(1)
+			go func(v volumePath) {
+				defer wg.Done()
+				defer c.unmarkWalking(v.path)
+				c.walkSem <- struct{}{}
+				defer func() { <-c.walkSem }()
+				usage, err := walkVolume(v, c.walkTimeout)
+				if err != nil {
+					glog.V(4).Infof("Cannot compute usage of volume %q: %v", v.path, err)
+					return
+				}
+				c.lock.Lock()
+				c.usage[v.path] = usage
+				c.lock.Unlock()
+			}(v)
+		}
+	}
+
+	c.lock.Lock()
+	c.podVolumes = podVolumes
+	// Forget volumes that no longer belong to any pod.
+	current := make(map[string]bool)
+	for _, volumes := range podVolumes {
+		for _, v := range volumes {
+			current[v.path] = true
+		}
+	}
+	for path := range c.usage {
+		if !current[path] && !c.walking[path] {
+			delete(c.usage, path)
+		}
+	}
+	c.lock.Unlock()
+
+	wg.Wait()
+}
+
+// findVolumeDirs lists the volume directories of the given pod. A missing
+// volumes directory is not an error; the pod simply has no volumes yet.
+func (c *volumeUsageCollector) findVolumeDirs(podUID types.UID) ([]volumePath, error) {
+	volumesDir := c.podVolumesDir(podUID)
+	pluginDirs, err := ioutil.ReadDir(volumesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var volumes []volumePath
+	for _, pluginDir := range pluginDirs {
+		if !pluginDir.IsDir() {
+			continue
+		}
+		volumeDirs, err := ioutil.ReadDir(filepath.Join(volumesDir, pluginDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, volumeDir := range volumeDirs {
+			if !volumeDir.IsDir() {
+				continue
+			}
+			volumes = append(volumes, volumePath{
+				name: volumeDir.Name(),
+				path: filepath.Join(volumesDir, pluginDir.Name(), volumeDir.Name()),
+			})
+		}
+	}
+	return volumes, nil
+}
+
+// markWalking records that a walk of the given volume directory is in flight.
+// It returns false if one is already running, in which case the caller must
+// not start another.
+func (c *volumeUsageCollector) markWalking(path string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.walking[path] {
+		return false
+	}
+	c.walking[path] = true
+	return true
+}
+
+func (c *volumeUsageCollector) unmarkWalking(path string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.walking, path)
+}
+
+// GetPodVolumeStats returns the most recently collected usage of the pod's
+// volumes. The second return value is false when no collection has seen the
+// pod yet.
+func (c *volumeUsageCollector) GetPodVolumeStats(podUID types.UID) ([]stats.VolumeStats, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	volumes, found := c.podVolumes[podUID]
+	if !found {
+		return nil, false
+	}
+	result := make([]stats.VolumeStats, 0, len(volumes))
+	for _, v := range volumes {
+		if usage, ok := c.usage[v.path]; ok {
+			result = append(result, usage)
+		}
+	}
+	return result, true
+}
+
+// walkVolume computes the bytes and inodes used under the volume directory in
+// the manner of du. Symlinks are counted as themselves and never followed, so
+// a link pointing out of the volume directory cannot inflate its usage.
+func walkVolume(v volumePath, timeout time.Duration) (stats.VolumeStats, error) {
+	var usedBytes, inodesUsed uint64
+	deadline := time.Now().Add(timeout)
+	err := filepath.Walk(v.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return errVolumeWalkTimeout
+		}
+		inodesUsed++
+		if info.Mode().IsRegular() {
+			usedBytes += uint64(info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		return stats.VolumeStats{}, err
+	}
+	return stats.VolumeStats{
+		Name:    v.name,
+		FsStats: stats.FsStats{UsedBytes: &usedBytes, InodesUsed: &inodesUsed},
+	}, nil
+}
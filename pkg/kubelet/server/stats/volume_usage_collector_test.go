@@ -0,0 +1,143 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+const testPodUID = types.UID("volume-usage-pod")
+
+// newTestVolumeUsageCollector returns a collector rooted at a temp directory
+// together with the directory of the test pod's single emptyDir volume.
+func newTestVolumeUsageCollector(t *testing.T) (*volumeUsageCollector, string, string) {
+	rootDir, err := ioutil.TempDir("", "volume-usage-collector")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	volumeDir := filepath.Join(rootDir, string(testPodUID), "volumes", "kubernetes.io~empty-dir", "vol1")
+	if err := os.MkdirAll(volumeDir, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	provider := &MockStatsProvider{}
+	provider.On("GetPods").Return([]*api.Pod{
+		{ObjectMeta: api.ObjectMeta{UID: testPodUID}},
+	})
+	c := newVolumeUsageCollector(provider, func(podUID types.UID) string {
+		return filepath.Join(rootDir, string(podUID), "volumes")
+	})
+	return c, rootDir, volumeDir
+}
+
+func writeTestFile(t *testing.T, path string, size int) {
+	if err := ioutil.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestVolumeUsageCollector(t *testing.T) {
+	c, rootDir, volumeDir := newTestVolumeUsageCollector(t)
+	defer os.RemoveAll(rootDir)
+
+	writeTestFile(t, filepath.Join(volumeDir, "file1"), 100)
+	if err := os.Mkdir(filepath.Join(volumeDir, "sub"), 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writeTestFile(t, filepath.Join(volumeDir, "sub", "file2"), 50)
+
+	// A symlink pointing out of the volume directory must not be followed, so
+	// the target's size may not show up in the volume's usage.
+	outside := filepath.Join(rootDir, "outside")
+	writeTestFile(t, outside, 4096)
+	if err := os.Symlink(outside, filepath.Join(volumeDir, "escape")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.collect()
+
+	usage, found := c.GetPodVolumeStats(testPodUID)
+	if !found {
+		t.Fatalf("expected stats for pod %q", testPodUID)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("expected stats for one volume, got %v", usage)
+	}
+	v := usage[0]
+	if v.Name != "vol1" {
+		t.Errorf("expected volume name %q, got %q", "vol1", v.Name)
+	}
+	if *v.UsedBytes != 150 {
+		t.Errorf("expected 150 bytes used, got %d", *v.UsedBytes)
+	}
+	// The volume directory, two files, one subdirectory and the symlink.
+	if *v.InodesUsed != 5 {
+		t.Errorf("expected 5 inodes used, got %d", *v.InodesUsed)
+	}
+
+	if _, found := c.GetPodVolumeStats(types.UID("unknown-pod")); found {
+		t.Errorf("expected no stats for an unknown pod")
+	}
+}
+
+func TestVolumeUsageCollectorSkipsInFlightWalk(t *testing.T) {
+	c, rootDir, volumeDir := newTestVolumeUsageCollector(t)
+	defer os.RemoveAll(rootDir)
+
+	writeTestFile(t, filepath.Join(volumeDir, "file1"), 100)
+
+	// Simulate a walk from the previous period that has not finished yet.
+	if !c.markWalking(volumeDir) {
+		t.Fatalf("expected to mark the volume as walking")
+	}
+	c.collect()
+
+	usage, found := c.GetPodVolumeStats(testPodUID)
+	if !found {
+		t.Fatalf("expected the pod to be known")
+	}
+	if len(usage) != 0 {
+		t.Errorf("expected no usage while the previous walk is in flight, got %v", usage)
+	}
+
+	// Once the walk finishes, the next collection picks the volume up again.
+	c.unmarkWalking(volumeDir)
+	c.collect()
+	usage, _ = c.GetPodVolumeStats(testPodUID)
+	if len(usage) != 1 || *usage[0].UsedBytes != 100 {
+		t.Errorf("expected usage of 100 bytes after the walk finished, got %v", usage)
+	}
+}
+
+func TestWalkVolumeTimeout(t *testing.T) {
+	_, rootDir, volumeDir := newTestVolumeUsageCollector(t)
+	defer os.RemoveAll(rootDir)
+
+	writeTestFile(t, filepath.Join(volumeDir, "file1"), 100)
+
+	if _, err := walkVolume(volumePath{name: "vol1", path: volumeDir}, -time.Second); err != errVolumeWalkTimeout {
+		t.Errorf("expected %v, got %v", errVolumeWalkTimeout, err)
+	}
+}
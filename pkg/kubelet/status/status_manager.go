@@ -63,7 +63,7 @@ type manager struct {
 	podStatusChannel chan podStatusSyncRequest
 	// Map from (mirror) pod UID to latest status version successfully sent to the API server.
 	// apiStatusVersions must only be accessed from the sync thread.
-	apiStatusVersions map[types.UID]uint64
+	apiStatusVersions statusVersionTracker
 }
 
 // PodStatusProvider knows how to provide status for a pod.  It's intended to be used by other components
@@ -106,7 +106,7 @@ func NewManager(kubeClient clientset.Interface, podManager kubepod.Manager) Mana
 		podManager:        podManager,
 		podStatuses:       make(map[types.UID]versionedPodStatus),
 		podStatusChannel:  make(chan podStatusSyncRequest, 1000), // Buffer up to 1000 statuses
-		apiStatusVersions: make(map[types.UID]uint64),
+		apiStatusVersions: newStatusVersionTracker(),
 	}
 }
 
@@ -361,13 +361,11 @@ func (m *manager) syncBatch() {
 		defer m.podStatusesLock.RUnlock()
 
 		// Clean up orphaned versions.
-		for uid := range m.apiStatusVersions {
+		m.apiStatusVersions.cleanupOrphaned(func(uid types.UID) bool {
 			_, hasPod := m.podStatuses[uid]
 			_, hasMirror := mirrorToPod[uid]
-			if !hasPod && !hasMirror {
-				delete(m.apiStatusVersions, uid)
-			}
-		}
+			return hasPod || hasMirror
+		})
 
 		for uid, status := range m.podStatuses {
 			syncedUID := uid
@@ -385,7 +383,7 @@ func (m *manager) syncBatch() {
 				// In most cases the deleted apiStatusVersions here should be filled
 				// soon after the following syncPod() [If the syncPod() sync an update
 				// successfully].
-				delete(m.apiStatusVersions, syncedUID)
+				m.apiStatusVersions.forget(syncedUID)
 				updatedStatuses = append(updatedStatuses, podStatusSyncRequest{uid, status})
 			}
 		}
@@ -423,7 +421,7 @@ func (m *manager) syncPod(uid types.UID, status versionedPodStatus) {
 		pod, err = m.kubeClient.Core().Pods(pod.Namespace).UpdateStatus(pod)
 		if err == nil {
 			glog.V(3).Infof("Status for pod %q updated successfully: %+v", format.Pod(pod), status)
-			m.apiStatusVersions[pod.UID] = status.version
+			m.apiStatusVersions.markSynced(pod.UID, status.version)
 			if kubepod.IsMirrorPod(pod) {
 				// We don't handle graceful deletion of mirror pods.
 				return
@@ -453,8 +451,7 @@ func (m *manager) syncPod(uid types.UID, status versionedPodStatus) {
 // needsUpdate returns whether the status is stale for the given pod UID.
 // This method is not thread safe, and most only be accessed by the sync thread.
 func (m *manager) needsUpdate(uid types.UID, status versionedPodStatus) bool {
-	latest, ok := m.apiStatusVersions[uid]
-	return !ok || latest < status.version
+	return m.apiStatusVersions.needsUpdate(uid, status.version)
 }
 
 // needsReconcile compares the given status with the status in the pod manager (which
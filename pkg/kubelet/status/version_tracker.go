@@ -0,0 +1,64 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// statusVersionTracker records, for each pod, the latest status version that
+// was successfully written to the API server. It is used to drop status
+// updates that were computed before the last written one (e.g. out-of-order
+// completions after a retry), so an older status can never overwrite a newer
+// one. It is not thread safe and must only be accessed from the sync thread.
+type statusVersionTracker map[types.UID]uint64
+
+func newStatusVersionTracker() statusVersionTracker {
+	return make(statusVersionTracker)
+}
+
+// needsUpdate returns whether the given version is newer than the latest
+// version written for the pod.
+func (t statusVersionTracker) needsUpdate(uid types.UID, version uint64) bool {
+	latest, ok := t[uid]
+	return !ok || latest < version
+}
+
+// markSynced records a successful write of the given version. The recorded
+// version only moves forward, so a late completion of an older update cannot
+// mask a newer one.
+func (t statusVersionTracker) markSynced(uid types.UID, version uint64) {
+	if latest, ok := t[uid]; !ok || latest < version {
+		t[uid] = version
+	}
+}
+
+// forget drops the recorded version for the pod, forcing the next update to
+// be written.
+func (t statusVersionTracker) forget(uid types.UID) {
+	delete(t, uid)
+}
+
+// cleanupOrphaned removes the recorded versions of all pods for which keep
+// returns false.
+func (t statusVersionTracker) cleanupOrphaned(keep func(types.UID) bool) {
+	for uid := range t {
+		if !keep(uid) {
+			delete(t, uid)
+		}
+	}
+}
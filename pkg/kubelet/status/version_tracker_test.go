@@ -0,0 +1,87 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/types"
+)
+
+func TestVersionTrackerNeedsUpdate(t *testing.T) {
+	tracker := newStatusVersionTracker()
+	uid := types.UID("pod-uid")
+
+	// Every version of an unknown pod needs an update.
+	if !tracker.needsUpdate(uid, 1) {
+		t.Errorf("expected update to be needed for an unknown pod")
+	}
+
+	tracker.markSynced(uid, 2)
+	if tracker.needsUpdate(uid, 1) {
+		t.Errorf("expected no update to be needed for an older version")
+	}
+	if tracker.needsUpdate(uid, 2) {
+		t.Errorf("expected no update to be needed for the synced version")
+	}
+	if !tracker.needsUpdate(uid, 3) {
+		t.Errorf("expected update to be needed for a newer version")
+	}
+}
+
+func TestVersionTrackerOutOfOrderCompletions(t *testing.T) {
+	tracker := newStatusVersionTracker()
+	uid := types.UID("pod-uid")
+
+	// A slow write of version 1 completes after version 3 was already
+	// written; it must not roll the recorded version back.
+	tracker.markSynced(uid, 3)
+	tracker.markSynced(uid, 1)
+	if tracker.needsUpdate(uid, 2) {
+		t.Errorf("a late completion of an older version should not mask the newer one")
+	}
+	if !tracker.needsUpdate(uid, 4) {
+		t.Errorf("expected update to be needed for version newer than the latest written")
+	}
+}
+
+func TestVersionTrackerForget(t *testing.T) {
+	tracker := newStatusVersionTracker()
+	uid := types.UID("pod-uid")
+
+	tracker.markSynced(uid, 5)
+	tracker.forget(uid)
+	if !tracker.needsUpdate(uid, 1) {
+		t.Errorf("expected update to be needed after the pod is forgotten")
+	}
+}
+
+func TestVersionTrackerCleanupOrphaned(t *testing.T) {
+	tracker := newStatusVersionTracker()
+	keep, orphan := types.UID("keep"), types.UID("orphan")
+
+	tracker.markSynced(keep, 1)
+	tracker.markSynced(orphan, 1)
+	tracker.cleanupOrphaned(func(uid types.UID) bool { return uid == keep })
+
+	if tracker.needsUpdate(keep, 1) {
+		t.Errorf("the kept pod should not have been removed")
+	}
+	if !tracker.needsUpdate(orphan, 1) {
+		t.Errorf("the orphaned pod should have been removed")
+	}
+}
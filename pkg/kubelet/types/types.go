@@ -29,6 +29,13 @@ type HttpGetter interface {
 	Get(url string) (*http.Response, error)
 }
 
+// HttpGetterWithTimeout is an HttpGetter whose requests can additionally be
+// bounded by a caller-supplied timeout.
+type HttpGetterWithTimeout interface {
+	HttpGetter
+	GetWithTimeout(url string, timeout time.Duration) (*http.Response, error)
+}
+
 // Timestamp wraps around time.Time and offers utilities to format and parse
 // the time using RFC3339Nano
 type Timestamp struct {
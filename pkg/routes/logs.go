@@ -17,14 +17,33 @@ limitations under the License.
 package routes
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful"
 
 	"k8s.io/kubernetes/pkg/apiserver"
+	"k8s.io/kubernetes/pkg/util/flushwriter"
 )
 
+const (
+	logdir = "/var/log"
+	// logTailChunkSize is the size of the chunks read backwards from the end
+	// of a file when locating the offset for tailLines, so large files do not
+	// have to be read whole.
+	logTailChunkSize = 64 * 1024
+)
+
+// logFollowPollPeriod is how often a follow=true request checks the file for
+// appended data. A variable so tests can shorten it.
+var logFollowPollPeriod = time.Second
+
 // Logs adds handlers for the /logs path serving log files from /var/log.
 type Logs struct{}
 
@@ -41,12 +60,142 @@ func (l Logs) Install(mux *apiserver.PathRecorderMux, c *restful.Container) {
 }
 
 func logFileHandler(req *restful.Request, resp *restful.Response) {
-	logdir := "/var/log"
-	actual := path.Join(logdir, req.PathParameter("logpath"))
-	http.ServeFile(resp.ResponseWriter, req.Request, actual)
+	serveLogFile(resp.ResponseWriter, req.Request, logdir, req.PathParameter("logpath"))
 }
 
 func logFileListHandler(req *restful.Request, resp *restful.Response) {
-	logdir := "/var/log"
 	http.ServeFile(resp.ResponseWriter, req.Request, logdir)
 }
+
+// serveLogFile serves a single file from the log directory. Plain requests
+// keep the stock file server semantics, including byte ranges and directory
+// listings; a tailLines query parameter serves only the trailing lines of the
+// file, and follow=true streams data appended to the file until the client
+// disconnects.
+func serveLogFile(w http.ResponseWriter, req *http.Request, logdir, logpath string) {
+	actual := path.Join(logdir, logpath)
+	// path.Join has cleaned ".." elements, so a path escaping the log
+	// directory no longer has it as a prefix.
+	if actual != logdir && !strings.HasPrefix(actual, logdir+"/") {
+		http.Error(w, fmt.Sprintf("log path %q is outside of %s", logpath, logdir), http.StatusForbidden)
+		return
+	}
+
+	query := req.URL.Query()
+	follow := query.Get("follow") == "true"
+	var tailLines int64 = -1
+	if tail := query.Get("tailLines"); len(tail) > 0 {
+		n, err := strconv.ParseInt(tail, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid tailLines %q", tail), http.StatusBadRequest)
+			return
+		}
+		tailLines = n
+	}
+
+	if !follow && tailLines < 0 {
+		http.ServeFile(w, req, actual)
+		return
+	}
+
+	file, err := os.Open(actual)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if info.IsDir() {
+		// Tailing or following a directory is meaningless; fall back to the
+		// directory listing.
+		http.ServeFile(w, req, actual)
+		return
+	}
+
+	offset := int64(0)
+	if tailLines >= 0 {
+		if offset, err = tailOffset(file, info.Size(), tailLines); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if _, err := file.Seek(offset, os.SEEK_SET); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	out := flushwriter.Wrap(w)
+	if _, err := io.Copy(out, file); err != nil {
+		return
+	}
+	if !follow {
+		return
+	}
+
+	// Stream data appended to the file until the client goes away. A nil
+	// stop channel from a ResponseWriter without CloseNotify support simply
+	// never fires; the copy error after the disconnect ends the loop then.
+	var stop <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		stop = notifier.CloseNotify()
+	}
+	ticker := time.NewTicker(logFollowPollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if _, err := io.Copy(out, file); err != nil {
+			return
+		}
+	}
+}
+
+// tailOffset returns the offset of the first byte of the trailing n lines of
+// the file, reading backwards in bounded chunks.
+func tailOffset(file *os.File, size int64, n int64) (int64, error) {
+	if n == 0 {
+		return size, nil
+	}
+	var newlines int64
+	at := size
+	buf := make([]byte, logTailChunkSize)
+	for at > 0 {
+		chunk := int64(len(buf))
+		if at < chunk {
+			chunk = at
+		}
+		at -= chunk
+		if _, err := file.ReadAt(buf[:chunk], at); err != nil {
+			return 0, err
+		}
+		for i := chunk - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				continue
+			}
+			if at+i == size-1 {
+				// The trailing newline terminates the last line rather
+				// than starting another one.
+				continue
+			}
+			newlines++
+			if newlines == n {
+				return at + i + 1, nil
+			}
+		}
+	}
+	// The file holds no more than n lines; serve it whole.
+	return 0, nil
+}
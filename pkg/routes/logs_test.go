@@ -0,0 +1,148 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routes
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServeLogFileTailLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var content string
+	for i := 0; i < 10; i++ {
+		content += fmt.Sprintf("line %d\n", i)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "test.log"), []byte(content), 0644); err != nil {
+		t.Fatalf("unexpected error writing log file: %v", err)
+	}
+
+	tests := []struct {
+		query    string
+		code     int
+		expected string
+	}{
+		{"", http.StatusOK, content},
+		{"?tailLines=3", http.StatusOK, "line 7\nline 8\nline 9\n"},
+		{"?tailLines=0", http.StatusOK, ""},
+		{"?tailLines=100", http.StatusOK, content},
+		{"?tailLines=bogus", http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest("GET", "/logs/test.log"+test.query, nil)
+		recorder := httptest.NewRecorder()
+		serveLogFile(recorder, req, dir, "test.log")
+		if recorder.Code != test.code {
+			t.Errorf("%q: expected status %d, got %d", test.query, test.code, recorder.Code)
+			continue
+		}
+		if test.code == http.StatusOK && recorder.Body.String() != test.expected {
+			t.Errorf("%q: expected body %q, got %q", test.query, test.expected, recorder.Body.String())
+		}
+	}
+}
+
+func TestServeLogFilePathTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	req, _ := http.NewRequest("GET", "/logs/", nil)
+	recorder := httptest.NewRecorder()
+	serveLogFile(recorder, req, dir, "../../etc/passwd")
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, recorder.Code)
+	}
+}
+
+func TestServeLogFileFollow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logName := filepath.Join(dir, "grow.log")
+	if err := ioutil.WriteFile(logName, []byte("first\n"), 0644); err != nil {
+		t.Fatalf("unexpected error writing log file: %v", err)
+	}
+
+	oldPollPeriod := logFollowPollPeriod
+	logFollowPollPeriod = 10 * time.Millisecond
+	defer func() { logFollowPollPeriod = oldPollPeriod }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		serveLogFile(w, req, dir, "grow.log")
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/?follow=true")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	readExactly := func(n int) string {
+		buf := make([]byte, n)
+		result := make(chan error, 1)
+		go func() {
+			_, err := io.ReadFull(resp.Body, buf)
+			result <- err
+		}()
+		select {
+		case err := <-result:
+			if err != nil {
+				t.Fatalf("unexpected error reading stream: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out reading %d bytes from the stream", n)
+		}
+		return string(buf)
+	}
+
+	if got := readExactly(len("first\n")); got != "first\n" {
+		t.Errorf("expected initial content %q, got %q", "first\n", got)
+	}
+
+	// Append to the file and expect the new data on the open stream.
+	f, err := os.OpenFile(logName, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("unexpected error opening log file: %v", err)
+	}
+	if _, err := f.WriteString("second\n"); err != nil {
+		t.Fatalf("unexpected error appending to log file: %v", err)
+	}
+	f.Close()
+
+	if got := readExactly(len("second\n")); got != "second\n" {
+		t.Errorf("expected appended content %q, got %q", "second\n", got)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"k8s.io/kubernetes/pkg/api"
+	apierrors "k8s.io/kubernetes/pkg/api/errors"
+)
+
+// fakeGetter is an in-memory ServiceAccountTokenGetter, so the token lookup
+// path can be tested without an etcd-backed registry.
+type fakeGetter struct {
+	serviceAccounts map[string]*api.ServiceAccount
+	secrets         map[string]*api.Secret
+}
+
+// NewFakeGetter returns a ServiceAccountTokenGetter that serves the given
+// service accounts and secrets from memory. Lookups of objects that are not
+// in the lists fail with a NotFound error, just like the etcd-backed getters.
+func NewFakeGetter(serviceAccounts []*api.ServiceAccount, secrets []*api.Secret) ServiceAccountTokenGetter {
+	f := &fakeGetter{
+		serviceAccounts: make(map[string]*api.ServiceAccount),
+		secrets:         make(map[string]*api.Secret),
+	}
+	for _, serviceAccount := range serviceAccounts {
+		f.serviceAccounts[serviceAccount.Namespace+"/"+serviceAccount.Name] = serviceAccount
+	}
+	for _, secret := range secrets {
+		f.secrets[secret.Namespace+"/"+secret.Name] = secret
+	}
+	return f
+}
+
+func (f *fakeGetter) GetServiceAccount(namespace, name string) (*api.ServiceAccount, error) {
+	serviceAccount, ok := f.serviceAccounts[namespace+"/"+name]
+	if !ok {
+		return nil, apierrors.NewNotFound(api.Resource("serviceaccounts"), name)
+	}
+	return serviceAccount, nil
+}
+
+func (f *fakeGetter) GetSecret(namespace, name string) (*api.Secret, error) {
+	secret, ok := f.secrets[namespace+"/"+name]
+	if !ok {
+		return nil, apierrors.NewNotFound(api.Resource("secrets"), name)
+	}
+	return secret, nil
+}
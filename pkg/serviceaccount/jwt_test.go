@@ -21,14 +21,12 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dgrijalva/jwt-go"
 
 	"k8s.io/kubernetes/pkg/api"
-	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
-	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
-	serviceaccountcontroller "k8s.io/kubernetes/pkg/controller/serviceaccount"
 	"k8s.io/kubernetes/pkg/serviceaccount"
 )
 
@@ -159,29 +157,30 @@ func TestTokenGenerateAndValidate(t *testing.T) {
 	}
 
 	testCases := map[string]struct {
-		Client clientset.Interface
+		Getter serviceaccount.ServiceAccountTokenGetter
 		Keys   []*rsa.PublicKey
 
-		ExpectedErr      bool
-		ExpectedOK       bool
-		ExpectedUserName string
-		ExpectedUserUID  string
-		ExpectedGroups   []string
+		ExpectedErr       bool
+		ExpectedErrString string
+		ExpectedOK        bool
+		ExpectedUserName  string
+		ExpectedUserUID   string
+		ExpectedGroups    []string
 	}{
 		"no keys": {
-			Client:      nil,
+			Getter:      nil,
 			Keys:        []*rsa.PublicKey{},
 			ExpectedErr: false,
 			ExpectedOK:  false,
 		},
 		"invalid keys": {
-			Client:      nil,
+			Getter:      nil,
 			Keys:        []*rsa.PublicKey{getPublicKey(otherPublicKey)},
 			ExpectedErr: true,
 			ExpectedOK:  false,
 		},
 		"valid key": {
-			Client:           nil,
+			Getter:           nil,
 			Keys:             []*rsa.PublicKey{getPublicKey(publicKey)},
 			ExpectedErr:      false,
 			ExpectedOK:       true,
@@ -190,7 +189,7 @@ func TestTokenGenerateAndValidate(t *testing.T) {
 			ExpectedGroups:   []string{"system:serviceaccounts", "system:serviceaccounts:test"},
 		},
 		"rotated keys": {
-			Client:           nil,
+			Getter:           nil,
 			Keys:             []*rsa.PublicKey{getPublicKey(otherPublicKey), getPublicKey(publicKey)},
 			ExpectedErr:      false,
 			ExpectedOK:       true,
@@ -199,7 +198,7 @@ func TestTokenGenerateAndValidate(t *testing.T) {
 			ExpectedGroups:   []string{"system:serviceaccounts", "system:serviceaccounts:test"},
 		},
 		"valid lookup": {
-			Client:           fake.NewSimpleClientset(serviceAccount, secret),
+			Getter:           serviceaccount.NewFakeGetter([]*api.ServiceAccount{serviceAccount}, []*api.Secret{secret}),
 			Keys:             []*rsa.PublicKey{getPublicKey(publicKey)},
 			ExpectedErr:      false,
 			ExpectedOK:       true,
@@ -207,23 +206,24 @@ func TestTokenGenerateAndValidate(t *testing.T) {
 			ExpectedUserUID:  expectedUserUID,
 			ExpectedGroups:   []string{"system:serviceaccounts", "system:serviceaccounts:test"},
 		},
-		"invalid secret lookup": {
-			Client:      fake.NewSimpleClientset(serviceAccount),
-			Keys:        []*rsa.PublicKey{getPublicKey(publicKey)},
-			ExpectedErr: true,
-			ExpectedOK:  false,
+		"deleted secret lookup": {
+			Getter:            serviceaccount.NewFakeGetter([]*api.ServiceAccount{serviceAccount}, nil),
+			Keys:              []*rsa.PublicKey{getPublicKey(publicKey)},
+			ExpectedErr:       true,
+			ExpectedErrString: "Token has been invalidated",
+			ExpectedOK:        false,
 		},
-		"invalid serviceaccount lookup": {
-			Client:      fake.NewSimpleClientset(secret),
-			Keys:        []*rsa.PublicKey{getPublicKey(publicKey)},
-			ExpectedErr: true,
-			ExpectedOK:  false,
+		"deleted serviceaccount lookup": {
+			Getter:            serviceaccount.NewFakeGetter(nil, []*api.Secret{secret}),
+			Keys:              []*rsa.PublicKey{getPublicKey(publicKey)},
+			ExpectedErr:       true,
+			ExpectedErrString: `serviceaccounts "my-service-account" not found`,
+			ExpectedOK:        false,
 		},
 	}
 
 	for k, tc := range testCases {
-		getter := serviceaccountcontroller.NewGetterFromClient(tc.Client)
-		authenticator := serviceaccount.JWTTokenAuthenticator(tc.Keys, tc.Client != nil, getter)
+		authenticator := serviceaccount.JWTTokenAuthenticator(tc.Keys, tc.Getter != nil, tc.Getter)
 
 		// An invalid, non-JWT token should always fail
 		if _, ok, err := authenticator.AuthenticateToken("invalid token"); err != nil || ok {
@@ -236,6 +236,10 @@ func TestTokenGenerateAndValidate(t *testing.T) {
 			t.Errorf("%s: Expected error=%v, got %v", k, tc.ExpectedErr, err)
 			continue
 		}
+		if err != nil && tc.ExpectedErrString != "" && !strings.Contains(err.Error(), tc.ExpectedErrString) {
+			t.Errorf("%s: Expected error containing %q, got %v", k, tc.ExpectedErrString, err)
+			continue
+		}
 
 		if ok != tc.ExpectedOK {
 			t.Errorf("%s: Expected ok=%v, got %v", k, tc.ExpectedOK, ok)
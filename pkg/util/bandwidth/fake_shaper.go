@@ -22,9 +22,17 @@ import (
 	"k8s.io/kubernetes/pkg/api/resource"
 )
 
+// ReconcileCIDRCall records the arguments of a FakeShaper.ReconcileCIDR call.
+type ReconcileCIDRCall struct {
+	CIDR    string
+	Egress  *resource.Quantity
+	Ingress *resource.Quantity
+}
+
 type FakeShaper struct {
-	CIDRs      []string
-	ResetCIDRs []string
+	CIDRs              []string
+	ResetCIDRs         []string
+	ReconcileCIDRCalls []ReconcileCIDRCall
 }
 
 func (f *FakeShaper) Limit(cidr string, egress, ingress *resource.Quantity) error {
@@ -41,7 +49,8 @@ func (f *FakeShaper) ReconcileInterface() error {
 }
 
 func (f *FakeShaper) ReconcileCIDR(cidr string, egress, ingress *resource.Quantity) error {
-	return errors.New("unimplemented")
+	f.ReconcileCIDRCalls = append(f.ReconcileCIDRCalls, ReconcileCIDRCall{CIDR: cidr, Egress: egress, Ingress: ingress})
+	return nil
 }
 
 func (f *FakeShaper) GetCIDRs() ([]string, error) {
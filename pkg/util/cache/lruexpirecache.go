@@ -78,6 +78,11 @@ func (c *LRUExpireCache) Get(key lru.Key) (interface{}, bool) {
 	return e.(*cacheEntry).value, true
 }
 
+// Remove removes the key from the cache, if present.
+func (c *LRUExpireCache) Remove(key lru.Key) {
+	c.remove(key)
+}
+
 func (c *LRUExpireCache) remove(key lru.Key) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
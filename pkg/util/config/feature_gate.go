@@ -40,6 +40,7 @@ const (
 	allAlphaGate              = "AllAlpha"
 	externalTrafficLocalOnly  = "AllowExtTrafficLocalEndpoints"
 	appArmor                  = "AppArmor"
+	dockerCRIU                = "DockerCRIU"
 	dynamicKubeletConfig      = "DynamicKubeletConfig"
 	dynamicVolumeProvisioning = "DynamicVolumeProvisioning"
 )
@@ -51,6 +52,7 @@ var (
 		allAlphaGate:              {false, alpha},
 		externalTrafficLocalOnly:  {false, alpha},
 		appArmor:                  {true, beta},
+		dockerCRIU:                {false, alpha},
 		dynamicKubeletConfig:      {false, alpha},
 		dynamicVolumeProvisioning: {true, alpha},
 	}
@@ -104,6 +106,10 @@ type FeatureGate interface {
 	// alpha: v1.3
 	DynamicVolumeProvisioning() bool
 
+	// owner: @yifan-gu
+	// alpha: v1.5
+	DockerCRIU() bool
+
 	// owner: mtaufen
 	// alpha: v1.4
 	DynamicKubeletConfig() bool
@@ -186,6 +192,12 @@ func (f *featureGate) AppArmor() bool {
 }
 
 // DynamicKubeletConfig returns value for dynamicKubeletConfig
+// DockerCRIU returns whether checkpoint/restore of docker containers via
+// CRIU is enabled.
+func (f *featureGate) DockerCRIU() bool {
+	return f.lookup(dockerCRIU)
+}
+
 func (f *featureGate) DynamicKubeletConfig() bool {
 	return f.lookup(dynamicKubeletConfig)
 }
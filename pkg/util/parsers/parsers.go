@@ -18,12 +18,21 @@ package parsers
 
 import (
 	"fmt"
+	"strings"
 
 	dockerref "github.com/docker/distribution/reference"
 )
 
 const (
 	DefaultImageTag = "latest"
+
+	// DefaultRegistry is the registry that unqualified image references
+	// resolve to.
+	DefaultRegistry = "docker.io"
+
+	// defaultRepositoryPrefix qualifies bare official repositories
+	// ("busybox") on the default registry.
+	defaultRepositoryPrefix = "library/"
 )
 
 // ParseImageName parses a docker image string into three parts: repo, tag and digest.
@@ -52,3 +61,57 @@ func ParseImageName(image string) (string, string, string, error) {
 	}
 	return repoToPull, tag, digest, nil
 }
+
+// ImageRef is a parsed, normalized image reference. The implicit parts of the
+// reference are made explicit: a missing registry resolves to DefaultRegistry,
+// a bare official repository gains the "library/" prefix, and a reference with
+// neither tag nor digest gets DefaultImageTag.
+type ImageRef struct {
+	Registry   string
+	Repository string // the repository path, without the registry host
+	Tag        string
+	Digest     string
+}
+
+// String returns the canonical spelling of the reference, so that two
+// different spellings of the same image compare equal.
+func (r ImageRef) String() string {
+	s := r.Registry + "/" + r.Repository
+	if len(r.Tag) > 0 {
+		s += ":" + r.Tag
+	}
+	if len(r.Digest) > 0 {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// RegistryRepository returns the "registry/repository" portion of the
+// reference, which is what registry pull policies match against.
+func (r ImageRef) RegistryRepository() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// ParseImageRef parses a docker image string into a normalized ImageRef.
+func ParseImageRef(image string) (ImageRef, error) {
+	repository, tag, digest, err := ParseImageName(image)
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	// Split the registry host off the repository. A first path component
+	// that contains a "." or ":", or equals "localhost", names a registry;
+	// anything else is a repository on the default registry.
+	registry := DefaultRegistry
+	if i := strings.Index(repository, "/"); i != -1 {
+		if first := repository[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			registry = first
+			repository = repository[i+1:]
+		}
+	}
+	if registry == DefaultRegistry && !strings.Contains(repository, "/") {
+		repository = defaultRepositoryPrefix + repository
+	}
+
+	return ImageRef{Registry: registry, Repository: repository, Tag: tag, Digest: digest}, nil
+}
@@ -49,3 +49,52 @@ func TestParseImageName(t *testing.T) {
 		}
 	}
 }
+
+func TestParseImageRef(t *testing.T) {
+	testCases := []struct {
+		Input     string
+		Canonical string
+	}{
+		// Shorthand official images.
+		{Input: "busybox", Canonical: "docker.io/library/busybox:latest"},
+		{Input: "busybox:1.24", Canonical: "docker.io/library/busybox:1.24"},
+		{Input: "library/busybox", Canonical: "docker.io/library/busybox:latest"},
+		{Input: "docker.io/busybox", Canonical: "docker.io/library/busybox:latest"},
+		// User repositories on the default registry.
+		{Input: "user/repo", Canonical: "docker.io/user/repo:latest"},
+		{Input: "docker.io/user/repo:tag", Canonical: "docker.io/user/repo:tag"},
+		// Explicit registries, with and without ports.
+		{Input: "gcr.io/google_containers/pause:3.0", Canonical: "gcr.io/google_containers/pause:3.0"},
+		{Input: "url:5000/repo", Canonical: "url:5000/repo:latest"},
+		{Input: "localhost/repo:tag", Canonical: "localhost/repo:tag"},
+		// Digest references.
+		{Input: "busybox@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Canonical: "docker.io/library/busybox@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{Input: "url:5000/repo@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Canonical: "url:5000/repo@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	for _, testCase := range testCases {
+		ref, err := ParseImageRef(testCase.Input)
+		if err != nil {
+			t.Errorf("ParseImageRef(%s) failed: %v", testCase.Input, err)
+		} else if ref.String() != testCase.Canonical {
+			t.Errorf("Expected canonical reference %q for %q, got %q", testCase.Canonical, testCase.Input, ref.String())
+		}
+	}
+
+	// Equivalent spellings share one canonical form.
+	a, err := ParseImageRef("busybox")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ParseImageRef("docker.io/library/busybox:latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a != b {
+		t.Errorf("expected %#v and %#v to be equal", a, b)
+	}
+
+	// Invalid references are rejected.
+	if _, err := ParseImageRef("invalid image"); err == nil {
+		t.Errorf("expected an error for an invalid reference")
+	}
+}